@@ -22,6 +22,9 @@ import (
 // swagger:model PathStatus
 type PathStatus struct {
 
+	// Connectivity status of the mutual authentication handshake port
+	Auth *ConnectivityStatus `json:"auth,omitempty"`
+
 	// Connectivity status without policy applied
 	HTTP *ConnectivityStatus `json:"http,omitempty"`
 
@@ -36,6 +39,10 @@ type PathStatus struct {
 func (m *PathStatus) Validate(formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.validateAuth(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateHTTP(formats); err != nil {
 		res = append(res, err)
 	}
@@ -50,6 +57,25 @@ func (m *PathStatus) Validate(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *PathStatus) validateAuth(formats strfmt.Registry) error {
+	if swag.IsZero(m.Auth) { // not required
+		return nil
+	}
+
+	if m.Auth != nil {
+		if err := m.Auth.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("auth")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("auth")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (m *PathStatus) validateHTTP(formats strfmt.Registry) error {
 	if swag.IsZero(m.HTTP) { // not required
 		return nil
@@ -92,6 +118,10 @@ func (m *PathStatus) validateIcmp(formats strfmt.Registry) error {
 func (m *PathStatus) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.contextValidateAuth(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.contextValidateHTTP(ctx, formats); err != nil {
 		res = append(res, err)
 	}
@@ -106,6 +136,27 @@ func (m *PathStatus) ContextValidate(ctx context.Context, formats strfmt.Registr
 	return nil
 }
 
+func (m *PathStatus) contextValidateAuth(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.Auth != nil {
+
+		if swag.IsZero(m.Auth) { // not required
+			return nil
+		}
+
+		if err := m.Auth.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("auth")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("auth")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (m *PathStatus) contextValidateHTTP(ctx context.Context, formats strfmt.Registry) error {
 
 	if m.HTTP != nil {
@@ -226,6 +226,10 @@ func init() {
       "description": "Connectivity status via different paths, for example using different\npolicies or service redirection\n",
       "type": "object",
       "properties": {
+        "auth": {
+          "description": "Connectivity status of the mutual authentication handshake port",
+          "$ref": "#/definitions/ConnectivityStatus"
+        },
         "http": {
           "description": "Connectivity status without policy applied",
           "$ref": "#/definitions/ConnectivityStatus"
@@ -476,6 +480,10 @@ func init() {
       "description": "Connectivity status via different paths, for example using different\npolicies or service redirection\n",
       "type": "object",
       "properties": {
+        "auth": {
+          "description": "Connectivity status of the mutual authentication handshake port",
+          "$ref": "#/definitions/ConnectivityStatus"
+        },
         "http": {
           "description": "Connectivity status without policy applied",
           "$ref": "#/definitions/ConnectivityStatus"
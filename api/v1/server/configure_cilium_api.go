@@ -232,6 +232,11 @@ func configureAPI(api *restapi.CiliumAPIAPI) http.Handler {
 			return middleware.NotImplemented("operation prefilter.PatchPrefilter has not yet been implemented")
 		})
 	}
+	if api.PolicyPostAuthHandler == nil {
+		api.PolicyPostAuthHandler = policy.PostAuthHandlerFunc(func(params policy.PostAuthParams) middleware.Responder {
+			return middleware.NotImplemented("operation policy.PostAuth has not yet been implemented")
+		})
+	}
 	if api.IpamPostIpamHandler == nil {
 		api.IpamPostIpamHandler = ipam.PostIpamHandlerFunc(func(params ipam.PostIpamParams) middleware.Responder {
 			return middleware.NotImplemented("operation ipam.PostIpam has not yet been implemented")
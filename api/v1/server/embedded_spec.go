@@ -35,6 +35,47 @@ func init() {
   },
   "basePath": "/v1",
   "paths": {
+    "/auth": {
+      "post": {
+        "description": "Runs the configured auth handler for the given identity pair against\nthe given remote node, independently of datapath drops, and returns\nthe negotiated expiration of the authentication.\n",
+        "tags": [
+          "policy"
+        ],
+        "summary": "Trigger an on-demand mutual authentication",
+        "parameters": [
+          {
+            "name": "request",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/AuthRequest"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "$ref": "#/definitions/AuthResponse"
+            }
+          },
+          "400": {
+            "description": "Invalid request",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            },
+            "x-go-name": "Invalid"
+          },
+          "500": {
+            "description": "Authentication failed",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            },
+            "x-go-name": "Failure"
+          }
+        }
+      }
+    },
     "/bgp/peers": {
       "get": {
         "description": "Retrieves current operational state of BGP peers created by\nCilium BGP virtual router. This includes session state, uptime,\ninformation per address family, etc.\n",
@@ -1804,6 +1845,35 @@ func init() {
         "tcx"
       ]
     },
+    "AuthRequest": {
+      "description": "Request to run an on-demand mutual authentication",
+      "type": "object",
+      "properties": {
+        "local-identity": {
+          "description": "Numeric identity of the local endpoint",
+          "type": "integer"
+        },
+        "remote-identity": {
+          "description": "Numeric identity of the remote endpoint",
+          "type": "integer"
+        },
+        "remote-node-ip": {
+          "description": "IP address of the remote node to authenticate against",
+          "type": "string"
+        }
+      }
+    },
+    "AuthResponse": {
+      "description": "Result of an on-demand mutual authentication",
+      "type": "object",
+      "properties": {
+        "expiration": {
+          "description": "Expiration of the negotiated authentication",
+          "type": "string",
+          "format": "date-time"
+        }
+      }
+    },
     "BPFMap": {
       "description": "BPF map definition and content",
       "type": "object",
@@ -5520,6 +5590,47 @@ func init() {
   },
   "basePath": "/v1",
   "paths": {
+    "/auth": {
+      "post": {
+        "description": "Runs the configured auth handler for the given identity pair against\nthe given remote node, independently of datapath drops, and returns\nthe negotiated expiration of the authentication.\n",
+        "tags": [
+          "policy"
+        ],
+        "summary": "Trigger an on-demand mutual authentication",
+        "parameters": [
+          {
+            "name": "request",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/AuthRequest"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "$ref": "#/definitions/AuthResponse"
+            }
+          },
+          "400": {
+            "description": "Invalid request",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            },
+            "x-go-name": "Invalid"
+          },
+          "500": {
+            "description": "Authentication failed",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            },
+            "x-go-name": "Failure"
+          }
+        }
+      }
+    },
     "/bgp/peers": {
       "get": {
         "description": "Retrieves current operational state of BGP peers created by\nCilium BGP virtual router. This includes session state, uptime,\ninformation per address family, etc.\n",
@@ -7512,6 +7623,35 @@ func init() {
         "tcx"
       ]
     },
+    "AuthRequest": {
+      "description": "Request to run an on-demand mutual authentication",
+      "type": "object",
+      "properties": {
+        "local-identity": {
+          "description": "Numeric identity of the local endpoint",
+          "type": "integer"
+        },
+        "remote-identity": {
+          "description": "Numeric identity of the remote endpoint",
+          "type": "integer"
+        },
+        "remote-node-ip": {
+          "description": "IP address of the remote node to authenticate against",
+          "type": "string"
+        }
+      }
+    },
+    "AuthResponse": {
+      "description": "Result of an on-demand mutual authentication",
+      "type": "object",
+      "properties": {
+        "expiration": {
+          "description": "Expiration of the negotiated authentication",
+          "type": "string",
+          "format": "date-time"
+        }
+      }
+    },
     "BPFMap": {
       "description": "BPF map definition and content",
       "type": "object",
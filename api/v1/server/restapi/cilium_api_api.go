@@ -199,6 +199,9 @@ func NewCiliumAPIAPI(spec *loads.Document) *CiliumAPIAPI {
 		PrefilterPatchPrefilterHandler: prefilter.PatchPrefilterHandlerFunc(func(params prefilter.PatchPrefilterParams) middleware.Responder {
 			return middleware.NotImplemented("operation prefilter.PatchPrefilter has not yet been implemented")
 		}),
+		PolicyPostAuthHandler: policy.PostAuthHandlerFunc(func(params policy.PostAuthParams) middleware.Responder {
+			return middleware.NotImplemented("operation policy.PostAuth has not yet been implemented")
+		}),
 		IpamPostIpamHandler: ipam.PostIpamHandlerFunc(func(params ipam.PostIpamParams) middleware.Responder {
 			return middleware.NotImplemented("operation ipam.PostIpam has not yet been implemented")
 		}),
@@ -349,6 +352,8 @@ type CiliumAPIAPI struct {
 	EndpointPatchEndpointIDLabelsHandler endpoint.PatchEndpointIDLabelsHandler
 	// PrefilterPatchPrefilterHandler sets the operation handler for the patch prefilter operation
 	PrefilterPatchPrefilterHandler prefilter.PatchPrefilterHandler
+	// PolicyPostAuthHandler sets the operation handler for the post auth operation
+	PolicyPostAuthHandler policy.PostAuthHandler
 	// IpamPostIpamHandler sets the operation handler for the post ipam operation
 	IpamPostIpamHandler ipam.PostIpamHandler
 	// IpamPostIpamIPHandler sets the operation handler for the post ipam IP operation
@@ -582,6 +587,9 @@ func (o *CiliumAPIAPI) Validate() error {
 	if o.PrefilterPatchPrefilterHandler == nil {
 		unregistered = append(unregistered, "prefilter.PatchPrefilterHandler")
 	}
+	if o.PolicyPostAuthHandler == nil {
+		unregistered = append(unregistered, "policy.PostAuthHandler")
+	}
 	if o.IpamPostIpamHandler == nil {
 		unregistered = append(unregistered, "ipam.PostIpamHandler")
 	}
@@ -883,6 +891,10 @@ func (o *CiliumAPIAPI) initHandlerCache() {
 	if o.handlers["POST"] == nil {
 		o.handlers["POST"] = make(map[string]http.Handler)
 	}
+	o.handlers["POST"]["/auth"] = policy.NewPostAuth(o.context, o.PolicyPostAuthHandler)
+	if o.handlers["POST"] == nil {
+		o.handlers["POST"] = make(map[string]http.Handler)
+	}
 	o.handlers["POST"]["/ipam"] = ipam.NewPostIpam(o.context, o.IpamPostIpamHandler)
 	if o.handlers["POST"] == nil {
 		o.handlers["POST"] = make(map[string]http.Handler)
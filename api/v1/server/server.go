@@ -113,6 +113,7 @@ type apiParams struct {
 	EndpointPatchEndpointIDConfigHandler endpoint.PatchEndpointIDConfigHandler
 	EndpointPatchEndpointIDLabelsHandler endpoint.PatchEndpointIDLabelsHandler
 	PrefilterPatchPrefilterHandler       prefilter.PatchPrefilterHandler
+	PolicyPostAuthHandler                policy.PostAuthHandler
 	IpamPostIpamHandler                  ipam.PostIpamHandler
 	IpamPostIpamIPHandler                ipam.PostIpamIPHandler
 	EndpointPutEndpointIDHandler         endpoint.PutEndpointIDHandler
@@ -174,6 +175,7 @@ func newAPI(p apiParams) *restapi.CiliumAPIAPI {
 	api.EndpointPatchEndpointIDConfigHandler = p.EndpointPatchEndpointIDConfigHandler
 	api.EndpointPatchEndpointIDLabelsHandler = p.EndpointPatchEndpointIDLabelsHandler
 	api.PrefilterPatchPrefilterHandler = p.PrefilterPatchPrefilterHandler
+	api.PolicyPostAuthHandler = p.PolicyPostAuthHandler
 	api.IpamPostIpamHandler = p.IpamPostIpamHandler
 	api.IpamPostIpamIPHandler = p.IpamPostIpamIPHandler
 	api.EndpointPutEndpointIDHandler = p.EndpointPutEndpointIDHandler
@@ -400,6 +400,8 @@ func ciliumInfoCommands(cmdDir string, k8sPods []string) []string {
 		"cilium-dbg encrypt status",
 		"cilium-dbg endpoint list",
 		"cilium-dbg bpf auth list",
+		"cilium-dbg auth summary -o json",
+		"cilium-dbg metrics list -p mesh_auth",
 		"cilium-dbg bpf bandwidth list",
 		"cilium-dbg bpf config list",
 		"cilium-dbg bpf tunnel list",
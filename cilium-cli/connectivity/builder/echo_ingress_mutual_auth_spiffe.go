@@ -33,9 +33,16 @@ func echoIngressMutualAuthSpiffeTest(ct *check.ConnectivityTest, portRanges bool
 		testName = "echo-ingress-mutual-auth-spiffe-port-range"
 		policyYAML = echoIngressMutualAuthPolicyPortRangeYAML
 	}
-	// Test mutual auth with SPIFFE
+	// Test mutual auth with SPIFFE. The initial pod-to-pod traffic incurs
+	// the handshake, after which the BPF auth map must hold unexpired
+	// entries. Flushing the map afterwards mimics revocation/rotation and
+	// traffic has to keep flowing through re-authentication.
 	newTest(testName, ct).
 		WithCiliumPolicy(policyYAML).
 		WithFeatureRequirements(features.RequireEnabled(features.AuthSpiffe)).
-		WithScenarios(tests.PodToPod())
+		WithScenarios(
+			tests.PodToPod(),
+			tests.AuthMapPopulated(),
+			tests.PodToPodAfterAuthMapFlush(),
+		)
 }
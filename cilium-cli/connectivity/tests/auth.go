@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cilium/cilium/cilium-cli/connectivity/check"
+	"github.com/cilium/cilium/cilium-cli/defaults"
+	"github.com/cilium/cilium/cilium-cli/utils/features"
+)
+
+type bpfAuthMapEntry struct {
+	LocalIdentity  uint32    `json:"LocalIdentity"`
+	RemoteIdentity uint32    `json:"RemoteIdentity"`
+	RemoteNodeID   uint16    `json:"RemoteNodeID"`
+	AuthType       uint8     `json:"AuthType"`
+	Expiration     time.Time `json:"Expiration"`
+}
+
+// AuthMapPopulated validates that the mutual authentication handshakes
+// triggered by earlier scenarios populated the BPF auth map with unexpired
+// entries on at least one node.
+func AuthMapPopulated() check.Scenario {
+	return &authMapPopulated{}
+}
+
+type authMapPopulated struct{}
+
+func (s *authMapPopulated) Name() string {
+	return "auth-map-populated"
+}
+
+func (s *authMapPopulated) Run(ctx context.Context, t *check.Test) {
+	ct := t.Context()
+
+	entries := 0
+	for _, pod := range ct.CiliumPods() {
+		for _, entry := range listAuthMapEntries(ctx, t, pod) {
+			if entry.Expiration.After(time.Now()) {
+				entries++
+			}
+		}
+	}
+
+	if entries == 0 {
+		t.Fatal("No unexpired entries found in the BPF auth map on any node, expected at least one from earlier authenticated connections")
+	}
+	ct.Debugf("Found %d unexpired BPF auth map entries", entries)
+}
+
+// PodToPodAfterAuthMapFlush flushes the BPF auth map on all nodes and then
+// validates pod-to-pod connectivity. With a policy requiring authentication
+// in place, this forces a fresh handshake and mimics the datapath view of a
+// certificate revocation or rotation: existing auth entries disappear and
+// traffic must keep flowing through re-authentication.
+func PodToPodAfterAuthMapFlush(opts ...Option) check.Scenario {
+	options := &labelsOption{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return &podToPodAfterAuthMapFlush{
+		sourceLabels:      options.sourceLabels,
+		destinationLabels: options.destinationLabels,
+	}
+}
+
+type podToPodAfterAuthMapFlush struct {
+	sourceLabels      map[string]string
+	destinationLabels map[string]string
+}
+
+func (s *podToPodAfterAuthMapFlush) Name() string {
+	return "pod-to-pod-after-auth-map-flush"
+}
+
+func (s *podToPodAfterAuthMapFlush) Run(ctx context.Context, t *check.Test) {
+	ct := t.Context()
+
+	for _, pod := range ct.CiliumPods() {
+		cmd := strings.Split("cilium bpf auth flush", " ")
+		_, err := pod.K8sClient.ExecInPod(ctx, pod.Pod.Namespace, pod.Pod.Name, defaults.AgentContainerName, cmd)
+		if err != nil {
+			t.Fatalf("Failed to flush the BPF auth map on node %s: %s", pod.Pod.Spec.NodeName, err)
+		}
+	}
+
+	var i int
+	for _, client := range ct.ClientPods() {
+		if !hasAllLabels(client, s.sourceLabels) {
+			continue
+		}
+		for _, echo := range ct.EchoPods() {
+			if !hasAllLabels(echo, s.destinationLabels) {
+				continue
+			}
+			t.ForEachIPFamily(func(ipFam features.IPFamily) {
+				t.NewAction(s, fmt.Sprintf("curl-%s-%d", ipFam, i), &client, echo, ipFam).Run(func(a *check.Action) {
+					a.ExecInPod(ctx, ct.CurlCommand(echo, ipFam))
+
+					a.ValidateFlows(ctx, client, a.GetEgressRequirements(check.FlowParameters{}))
+					a.ValidateFlows(ctx, echo, a.GetIngressRequirements(check.FlowParameters{}))
+				})
+			})
+
+			i++
+		}
+	}
+}
+
+func listAuthMapEntries(ctx context.Context, t *check.Test, pod check.Pod) []bpfAuthMapEntry {
+	cmd := strings.Split("cilium bpf auth list -o json", " ")
+	stdout, err := pod.K8sClient.ExecInPod(ctx, pod.Pod.Namespace, pod.Pod.Name, defaults.AgentContainerName, cmd)
+	if err != nil {
+		t.Fatalf("Failed to list the BPF auth map on node %s: %s", pod.Pod.Spec.NodeName, err)
+	}
+
+	var entries []bpfAuthMapEntry
+	if err := json.Unmarshal(stdout.Bytes(), &entries); err != nil {
+		t.Fatalf("Failed to unmarshal BPF auth map entries of node %s: %s", pod.Pod.Spec.NodeName, err)
+	}
+	return entries
+}
@@ -0,0 +1,18 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// AuthCmd represents the auth command
+var AuthCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Mutual authentication",
+}
+
+func init() {
+	RootCmd.AddCommand(AuthCmd)
+}
@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/cilium/pkg/identity"
+)
+
+// AuthCertCmd represents the auth cert command
+var AuthCertCmd = &cobra.Command{
+	Use:   "cert",
+	Short: "Certificates used for mutual authentication",
+}
+
+var authCertShowCmd = func() *cobra.Command {
+	var (
+		host        string
+		port        int
+		trustDomain string
+		timeout     time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "show <identity>",
+		Short: "Show the certificate currently served for a local identity",
+		Long: `Prints the certificate chain the local mutual auth listener currently
+serves for the given numeric identity, by performing a handshake against it
+with the SNI of that identity. This shows what SPIRE actually issued, without
+dumping any private key material.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			id, err := identity.ParseNumericIdentity(args[0])
+			if err != nil {
+				Fatalf("Invalid numeric identity %q: %s", args[0], err)
+			}
+
+			chain, err := fetchServedCertificate(net.JoinHostPort(host, strconv.Itoa(port)), id.String()+"."+trustDomain, timeout)
+			if err != nil {
+				Fatalf("Failed to retrieve certificate for identity %s: %s", id, err)
+			}
+
+			for i, cert := range chain {
+				if i > 0 {
+					fmt.Println()
+				}
+				printCertificate(cert)
+			}
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&host, "host", "127.0.0.1", "Address of the mutual auth listener")
+	flags.IntVar(&port, "port", 4250, "Port of the mutual auth listener")
+	flags.StringVar(&trustDomain, "trust-domain", "spiffe.cilium", "The trust domain of the SPIFFE identity")
+	flags.DurationVar(&timeout, "timeout", 5*time.Second, "Timeout of the handshake")
+
+	return cmd
+}()
+
+func init() {
+	AuthCmd.AddCommand(AuthCertCmd)
+	AuthCertCmd.AddCommand(authCertShowCmd)
+}
+
+// fetchServedCertificate captures the certificate chain the listener serves
+// for the given SNI. The handshake itself is allowed to fail afterwards, the
+// chain is recorded as soon as the server presented it.
+func fetchServedCertificate(target, sni string, timeout time.Duration) ([]*x509.Certificate, error) {
+	var chain []*x509.Certificate
+
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := tls.DialWithDialer(&dialer, "tcp", target, &tls.Config{
+		ServerName:         sni,
+		MinVersion:         tls.VersionTLS13,
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			for _, rawCert := range rawCerts {
+				cert, err := x509.ParseCertificate(rawCert)
+				if err != nil {
+					return fmt.Errorf("failed to parse server certificate: %w", err)
+				}
+				chain = append(chain, cert)
+			}
+			return nil
+		},
+	})
+	if err == nil {
+		conn.Close()
+	}
+	if len(chain) == 0 {
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("server did not present a certificate")
+	}
+	return chain, nil
+}
+
+func printCertificate(cert *x509.Certificate) {
+	kind := "Leaf"
+	if cert.IsCA {
+		kind = "CA"
+	}
+	fmt.Printf("%s certificate:\n", kind)
+	fmt.Printf("  Subject:    %s\n", cert.Subject)
+	fmt.Printf("  Issuer:     %s\n", cert.Issuer)
+	for _, uri := range cert.URIs {
+		fmt.Printf("  URI SAN:    %s\n", uri)
+	}
+	fmt.Printf("  Serial:     %s\n", cert.SerialNumber)
+	fmt.Printf("  Not before: %s\n", cert.NotBefore)
+	fmt.Printf("  Not after:  %s (%s remaining)\n", cert.NotAfter, time.Until(cert.NotAfter).Round(time.Second))
+}
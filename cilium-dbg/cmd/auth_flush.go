@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/cilium/pkg/common"
+	"github.com/cilium/cilium/pkg/maps/authmap"
+)
+
+var authFlushCmd = func() *cobra.Command {
+	filter := &authEntryFilter{}
+	var expiredOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "flush",
+		Short: "Invalidate mutual authentication state",
+		Long: `Deletes auth map entries matching the given filters, forcing the datapath
+to request a fresh authentication for them on the next connection. Without
+filters all entries are deleted.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			common.RequireRootPrivilege("cilium auth flush")
+
+			authMap, err := authmap.LoadAuthMap()
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					fmt.Fprintln(os.Stderr, "Cannot find auth bpf map")
+					return
+				}
+
+				Fatalf("Cannot load auth bpf map: %s", err)
+			}
+
+			now := time.Now()
+			var keys []authmap.AuthKey
+			collect := func(key *authmap.AuthKey, val *authmap.AuthInfo) {
+				if !filter.matches(key) {
+					return
+				}
+				if expiredOnly && val.Expiration.Time().After(now) {
+					return
+				}
+				keys = append(keys, *key)
+			}
+			if err := authMap.IterateWithCallback(collect); err != nil {
+				Fatalf("Error dumping contents of the auth map: %s\n", err)
+			}
+
+			deleted := 0
+			for _, key := range keys {
+				if err := authMap.Delete(key); err != nil {
+					fmt.Fprintf(os.Stderr, "Error deleting auth map entry %s: %s\n", key.String(), err)
+					continue
+				}
+				deleted++
+			}
+
+			fmt.Printf("Flushed %d entries\n", deleted)
+		},
+	}
+
+	filter.flags(cmd)
+	cmd.Flags().BoolVar(&expiredOnly, "expired", false, "Only delete entries which already expired")
+
+	return cmd
+}()
+
+func init() {
+	AuthCmd.AddCommand(authFlushCmd)
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/cilium/pkg/command"
+	"github.com/cilium/cilium/pkg/common"
+	"github.com/cilium/cilium/pkg/maps/authmap"
+	"github.com/cilium/cilium/pkg/policy"
+)
+
+// authEntryFilter selects auth map entries by their key fields. A zero value
+// of a field matches any entry.
+type authEntryFilter struct {
+	localIdentity  uint32
+	remoteIdentity uint32
+	remoteNodeID   uint32
+	authType       string
+}
+
+func (f *authEntryFilter) flags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.Uint32Var(&f.localIdentity, "local-identity", 0, "Only entries of this local numeric identity")
+	flags.Uint32Var(&f.remoteIdentity, "remote-identity", 0, "Only entries of this remote numeric identity")
+	flags.Uint32Var(&f.remoteNodeID, "remote-node-id", 0, "Only entries of this remote node ID")
+	flags.StringVar(&f.authType, "auth-type", "", "Only entries of this auth type (e.g. spire)")
+}
+
+func (f *authEntryFilter) matches(key *authmap.AuthKey) bool {
+	if f.localIdentity != 0 && key.LocalIdentity != f.localIdentity {
+		return false
+	}
+	if f.remoteIdentity != 0 && key.RemoteIdentity != f.remoteIdentity {
+		return false
+	}
+	if f.remoteNodeID != 0 && uint32(key.RemoteNodeID) != f.remoteNodeID {
+		return false
+	}
+	if f.authType != "" && policy.AuthType(key.AuthType).String() != f.authType {
+		return false
+	}
+	return true
+}
+
+var authListCmd = func() *cobra.Command {
+	filter := &authEntryFilter{}
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List the current mutual authentication state",
+		Long:    "Dumps the auth map entries with their identities, remote node and expiration, optionally filtered by the key fields",
+		Aliases: []string{"ls"},
+		Run: func(cmd *cobra.Command, args []string) {
+			common.RequireRootPrivilege("cilium auth list")
+
+			authMap, err := authmap.LoadAuthMap()
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					fmt.Fprintln(os.Stderr, "Cannot find auth bpf map")
+					return
+				}
+
+				Fatalf("Cannot load auth bpf map: %s", err)
+			}
+
+			var authList []authEntry
+			parse := func(key *authmap.AuthKey, val *authmap.AuthInfo) {
+				if !filter.matches(key) {
+					return
+				}
+				authList = append(authList, authEntry{
+					LocalIdentity:  key.LocalIdentity,
+					RemoteIdentity: key.RemoteIdentity,
+					RemoteNodeID:   key.RemoteNodeID,
+					AuthType:       key.AuthType,
+					Expiration:     val.Expiration.Time(),
+				})
+			}
+
+			if err := authMap.IterateWithCallback(parse); err != nil {
+				Fatalf("Error dumping contents of the auth map: %s\n", err)
+			}
+
+			if command.OutputOption() {
+				if err := command.PrintOutput(authList); err != nil {
+					Fatalf("error getting output of map in JSON: %s\n", err)
+				}
+				return
+			}
+
+			if len(authList) == 0 {
+				fmt.Fprintf(os.Stderr, "No entries found.\n")
+			} else {
+				printAuthList(authList)
+			}
+		},
+	}
+
+	filter.flags(cmd)
+
+	return cmd
+}()
+
+func init() {
+	AuthCmd.AddCommand(authListCmd)
+	command.AddOutputOption(authListCmd)
+}
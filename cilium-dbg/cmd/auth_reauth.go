@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/cilium/pkg/common"
+	"github.com/cilium/cilium/pkg/maps/authmap"
+)
+
+var authReauthCmd = func() *cobra.Command {
+	var (
+		localIdentity  uint32
+		remoteIdentity uint32
+		remoteNode     string
+		port           int
+		trustDomain    string
+		caFile         string
+		certFile       string
+		keyFile        string
+		timeout        time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "reauth",
+		Short: "Force re-authentication of a specific identity pair",
+		Long: `Invalidates the BPF auth map entries of the given identity pair, forcing
+the datapath to request a fresh authentication on the next connection. If
+--remote-node and certificate files are given, a test handshake against the
+peer's listener is performed synchronously and its outcome reported, so fixes
+can be verified without waiting for entry expiry.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			common.RequireRootPrivilege("cilium auth reauth")
+
+			deleted := invalidateAuthMapEntries(localIdentity, remoteIdentity)
+			fmt.Printf("Invalidated %d auth map entr(y/ies) for identity pair %d -> %d\n", deleted, localIdentity, remoteIdentity)
+
+			if remoteNode == "" {
+				fmt.Println("No --remote-node given, the next connection will trigger the new handshake")
+				return
+			}
+			if caFile == "" || certFile == "" || keyFile == "" {
+				Fatalf("--remote-node requires --ca, --cert and --key to perform the test handshake")
+			}
+
+			sni := strconv.FormatUint(uint64(remoteIdentity), 10) + "." + trustDomain
+			tlsConfig, err := authTLSClientConfig(caFile, certFile, keyFile, sni)
+			if err != nil {
+				Fatalf("Failed to build TLS config: %s", err)
+			}
+
+			target := net.JoinHostPort(remoteNode, strconv.Itoa(port))
+			dialer := net.Dialer{Timeout: timeout}
+			start := time.Now()
+			conn, err := tls.DialWithDialer(&dialer, "tcp", target, tlsConfig)
+			if err != nil {
+				Fatalf("Handshake with %s failed: %s", target, err)
+			}
+			conn.Close()
+			fmt.Printf("Handshake with %s succeeded in %s\n", target, time.Since(start).Round(time.Millisecond))
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.Uint32Var(&localIdentity, "local-identity", 0, "Numeric identity of the local endpoint")
+	flags.Uint32Var(&remoteIdentity, "remote-identity", 0, "Numeric identity of the remote endpoint")
+	flags.StringVar(&remoteNode, "remote-node", "", "IP address of the remote node to handshake with (optional)")
+	flags.IntVar(&port, "port", 4250, "Port of the mutual auth listener on the remote node")
+	flags.StringVar(&trustDomain, "trust-domain", "spiffe.cilium", "The trust domain of the SPIFFE identity")
+	flags.StringVar(&caFile, "ca", "", "Path to the PEM encoded CA bundle of the trust domain")
+	flags.StringVar(&certFile, "cert", "", "Path to the PEM encoded client certificate")
+	flags.StringVar(&keyFile, "key", "", "Path to the PEM encoded client private key")
+	flags.DurationVar(&timeout, "timeout", 5*time.Second, "Timeout of the handshake")
+	cmd.MarkFlagRequired("local-identity")
+	cmd.MarkFlagRequired("remote-identity")
+
+	return cmd
+}()
+
+func init() {
+	AuthCmd.AddCommand(authReauthCmd)
+}
+
+// invalidateAuthMapEntries removes all auth map entries of the given identity
+// pair, regardless of remote node ID and auth type, and returns the number of
+// deleted entries.
+func invalidateAuthMapEntries(localIdentity, remoteIdentity uint32) int {
+	authMap, err := authmap.LoadAuthMap()
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			fmt.Fprintln(os.Stderr, "Cannot find auth bpf map")
+			return 0
+		}
+
+		Fatalf("Cannot load auth bpf map: %s", err)
+	}
+
+	var keys []authmap.AuthKey
+	collect := func(key *authmap.AuthKey, _ *authmap.AuthInfo) {
+		if key.LocalIdentity == localIdentity && key.RemoteIdentity == remoteIdentity {
+			keys = append(keys, *key)
+		}
+	}
+	if err := authMap.IterateWithCallback(collect); err != nil {
+		Fatalf("Error dumping contents of the auth map: %s\n", err)
+	}
+
+	for _, key := range keys {
+		if err := authMap.Delete(key); err != nil {
+			Fatalf("Failed to delete auth map entry %s: %s", key.String(), err)
+		}
+	}
+	return len(keys)
+}
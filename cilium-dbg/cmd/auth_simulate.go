@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	identityApi "github.com/cilium/cilium/api/v1/client/policy"
+	"github.com/cilium/cilium/api/v1/models"
+	"github.com/cilium/cilium/pkg/api"
+	pkg "github.com/cilium/cilium/pkg/client"
+	"github.com/cilium/cilium/pkg/command"
+	"github.com/cilium/cilium/pkg/labels"
+	policyapi "github.com/cilium/cilium/pkg/policy/api"
+)
+
+// authPair is an identity pair which will require authentication under the
+// current policy set.
+type authPair struct {
+	LocalIdentity  int64
+	RemoteIdentity int64
+	Mode           string
+}
+
+var authSimulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Simulate the authentication impact of the current policy set",
+	Long: `Evaluates the current policy set against the known identities and reports
+which identity pairs will require authentication, to predict SPIRE and
+handshake load before enabling enforcement.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runAuthSimulate()
+	},
+}
+
+func init() {
+	AuthCmd.AddCommand(authSimulateCmd)
+	command.AddOutputOption(authSimulateCmd)
+}
+
+func runAuthSimulate() {
+	resp, err := client.PolicyGet(nil)
+	if err != nil {
+		Fatalf("Cannot get policy: %s\n", err)
+	}
+	var rules policyapi.Rules
+	if err := json.Unmarshal([]byte(resp.Policy), &rules); err != nil {
+		Fatalf("Cannot parse policy: %s\n", err)
+	}
+
+	params := identityApi.NewGetIdentityParams().WithTimeout(api.ClientTimeout)
+	identities, err := client.Policy.GetIdentity(params)
+	if err != nil {
+		Fatalf("Cannot get identities: %s\n", pkg.Hint(err))
+	}
+
+	pairs := simulateAuthPairs(rules, identities.Payload)
+
+	if command.OutputOption() {
+		if err := command.PrintOutput(pairs); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
+	printAuthPairs(pairs)
+}
+
+// simulateAuthPairs matches the given identities against all rules carrying
+// an authentication requirement and returns the identity pairs which will
+// have to authenticate. Peers selected through entities or CIDRs rather than
+// endpoint selectors are not part of the result.
+func simulateAuthPairs(rules policyapi.Rules, identities []*models.Identity) []authPair {
+	idLabels := make(map[int64]labels.LabelArray, len(identities))
+	for _, id := range identities {
+		idLabels[id.ID] = labels.NewLabelsFromModel(id.Labels).LabelArray()
+	}
+
+	matching := func(selector policyapi.EndpointSelector) []int64 {
+		var ids []int64
+		for _, id := range identities {
+			if selector.Matches(idLabels[id.ID]) {
+				ids = append(ids, id.ID)
+			}
+		}
+		return ids
+	}
+
+	seen := map[authPair]struct{}{}
+	var pairs []authPair
+	add := func(local, remote int64, mode policyapi.AuthenticationMode) {
+		pair := authPair{LocalIdentity: local, RemoteIdentity: remote, Mode: string(mode)}
+		if _, ok := seen[pair]; ok {
+			return
+		}
+		seen[pair] = struct{}{}
+		pairs = append(pairs, pair)
+	}
+
+	for _, rule := range rules {
+		selected := matching(rule.EndpointSelector)
+
+		for _, ingress := range rule.Ingress {
+			if ingress.Authentication == nil || ingress.Authentication.Mode == policyapi.AuthenticationModeDisabled {
+				continue
+			}
+			for _, fromSelector := range ingress.FromEndpoints {
+				for _, local := range selected {
+					for _, remote := range matching(fromSelector) {
+						add(local, remote, ingress.Authentication.Mode)
+					}
+				}
+			}
+		}
+
+		for _, egress := range rule.Egress {
+			if egress.Authentication == nil || egress.Authentication.Mode == policyapi.AuthenticationModeDisabled {
+				continue
+			}
+			for _, toSelector := range egress.ToEndpoints {
+				for _, local := range selected {
+					for _, remote := range matching(toSelector) {
+						add(local, remote, egress.Authentication.Mode)
+					}
+				}
+			}
+		}
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].LocalIdentity != pairs[j].LocalIdentity {
+			return pairs[i].LocalIdentity < pairs[j].LocalIdentity
+		}
+		return pairs[i].RemoteIdentity < pairs[j].RemoteIdentity
+	})
+	return pairs
+}
+
+func printAuthPairs(pairs []authPair) {
+	if len(pairs) == 0 {
+		fmt.Println("No identity pairs require authentication under the current policy set")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 5, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "LOCAL IDENTITY\tREMOTE IDENTITY\tAUTH MODE")
+	for _, pair := range pairs {
+		fmt.Fprintf(w, "%d\t%d\t%s\n", pair.LocalIdentity, pair.RemoteIdentity, pair.Mode)
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d identity pair(s) will require authentication\n", len(pairs))
+	fmt.Printf("Each pair incurs up to one handshake per node pair hosting the endpoints and per certificate rotation\n")
+}
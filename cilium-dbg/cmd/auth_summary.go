@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/cilium/pkg/command"
+	"github.com/cilium/cilium/pkg/common"
+	"github.com/cilium/cilium/pkg/maps/authmap"
+	"github.com/cilium/cilium/pkg/policy"
+)
+
+type authSummary struct {
+	TotalEntries       int
+	ExpiredEntries     int
+	EntriesPerAuthType map[string]int
+	RemoteNodes        int
+	EarliestExpiration time.Time
+	LatestExpiration   time.Time
+}
+
+var authSummaryCmd = &cobra.Command{
+	Use:   "summary",
+	Short: "Print a redacted summary of the BPF auth map",
+	Long: `Aggregates the contents of the BPF auth map into counters per auth type
+and remote node without printing any identities, suitable for inclusion in
+support archives.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		common.RequireRootPrivilege("cilium auth summary")
+
+		authMap, err := authmap.LoadAuthMap()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				fmt.Fprintln(os.Stderr, "Cannot find auth bpf map")
+				return
+			}
+
+			Fatalf("Cannot load auth bpf map: %s", err)
+		}
+
+		summary := authSummary{
+			EntriesPerAuthType: map[string]int{},
+		}
+		remoteNodes := map[uint16]struct{}{}
+		now := time.Now()
+		parse := func(key *authmap.AuthKey, val *authmap.AuthInfo) {
+			summary.TotalEntries++
+			expiration := val.Expiration.Time()
+			if expiration.Before(now) {
+				summary.ExpiredEntries++
+			}
+			summary.EntriesPerAuthType[policy.AuthType(key.AuthType).String()]++
+			remoteNodes[key.RemoteNodeID] = struct{}{}
+			if summary.EarliestExpiration.IsZero() || expiration.Before(summary.EarliestExpiration) {
+				summary.EarliestExpiration = expiration
+			}
+			if expiration.After(summary.LatestExpiration) {
+				summary.LatestExpiration = expiration
+			}
+		}
+
+		if err := authMap.IterateWithCallback(parse); err != nil {
+			Fatalf("Error dumping contents of the auth map: %s\n", err)
+		}
+		summary.RemoteNodes = len(remoteNodes)
+
+		if command.OutputOption() {
+			if err := command.PrintOutput(summary); err != nil {
+				Fatalf("error getting output of map in JSON: %s\n", err)
+			}
+			return
+		}
+
+		printAuthSummary(summary)
+	},
+}
+
+func printAuthSummary(summary authSummary) {
+	w := tabwriter.NewWriter(os.Stdout, 5, 0, 3, ' ', 0)
+
+	fmt.Fprintf(w, "Total entries:\t%d\n", summary.TotalEntries)
+	fmt.Fprintf(w, "Expired entries:\t%d\n", summary.ExpiredEntries)
+	fmt.Fprintf(w, "Remote nodes:\t%d\n", summary.RemoteNodes)
+	for authType, count := range summary.EntriesPerAuthType {
+		fmt.Fprintf(w, "Entries with auth type %s:\t%d\n", authType, count)
+	}
+	if !summary.EarliestExpiration.IsZero() {
+		fmt.Fprintf(w, "Earliest expiration:\t%s\n", summary.EarliestExpiration)
+		fmt.Fprintf(w, "Latest expiration:\t%s\n", summary.LatestExpiration)
+	}
+
+	w.Flush()
+}
+
+func init() {
+	AuthCmd.AddCommand(authSummaryCmd)
+	command.AddOutputOption(authSummaryCmd)
+}
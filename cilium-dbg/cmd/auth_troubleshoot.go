@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var authTroubleshootCmd = func() *cobra.Command {
+	var (
+		peerNode    string
+		port        int
+		adminSocket string
+		caFile      string
+		certFile    string
+		keyFile     string
+		sni         string
+		timeout     time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "troubleshoot",
+		Short: "Troubleshoot mutual authentication towards a peer node",
+		Long: `Checks, in order, the prerequisites for a successful mutual
+authentication handshake: reachability of the certificate provider socket,
+local certificate issuance, trust bundle freshness, TCP reachability of the
+peer's handshake port, and finally a full test handshake.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			stdout := cmd.OutOrStdout()
+
+			ok := checkProviderSocket(stdout, adminSocket, timeout)
+			ok = checkLocalCertificate(stdout, certFile) && ok
+			ok = checkTrustBundle(stdout, caFile) && ok
+
+			target := net.JoinHostPort(peerNode, strconv.Itoa(port))
+			if !checkTCPReachability(stdout, target, timeout) {
+				fmt.Fprintf(stdout, "\nDiagnosis: the handshake port of %s is not reachable, check the node IP, firewalls and that mutual auth is enabled on the peer\n", peerNode)
+				return
+			}
+			ok = checkTestHandshake(stdout, target, caFile, certFile, keyFile, sni, timeout) && ok
+
+			if ok {
+				fmt.Fprintf(stdout, "\nDiagnosis: all checks passed, mutual authentication towards %s should work\n", peerNode)
+			} else {
+				fmt.Fprintf(stdout, "\nDiagnosis: one or more checks failed, see above for details\n")
+			}
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&peerNode, "peer-node", "", "IP address of the peer node to troubleshoot against")
+	flags.IntVar(&port, "port", 4250, "Port of the mutual auth listener on the peer node")
+	flags.StringVar(&adminSocket, "spire-admin-socket", "/run/spire/sockets/admin.sock", "Path of the SPIRE admin agent Unix socket")
+	flags.StringVar(&caFile, "ca", "", "Path to the PEM encoded CA bundle of the trust domain (optional)")
+	flags.StringVar(&certFile, "cert", "", "Path to a PEM encoded local certificate to validate issuance (optional)")
+	flags.StringVar(&keyFile, "key", "", "Path to the PEM encoded private key of the local certificate (optional)")
+	flags.StringVar(&sni, "sni", "", "SNI to present during the test handshake, in the form <numeric-identity>.<trust-domain>")
+	flags.DurationVar(&timeout, "timeout", 5*time.Second, "Timeout of each individual check")
+	cmd.MarkFlagRequired("peer-node")
+
+	return cmd
+}()
+
+func init() {
+	AuthCmd.AddCommand(authTroubleshootCmd)
+}
+
+func checkProviderSocket(stdout io.Writer, socket string, timeout time.Duration) bool {
+	fmt.Fprintf(stdout, "🔌 Certificate provider socket %s... ", socket)
+	if _, err := os.Stat(socket); errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(stdout, "not found\n   The SPIRE agent does not appear to be running on this node\n")
+		return false
+	}
+	conn, err := net.DialTimeout("unix", socket, timeout)
+	if err != nil {
+		fmt.Fprintf(stdout, "not accepting connections: %s\n", err)
+		return false
+	}
+	conn.Close()
+	fmt.Fprintf(stdout, "reachable\n")
+	return true
+}
+
+func checkLocalCertificate(stdout io.Writer, certFile string) bool {
+	fmt.Fprintf(stdout, "📜 Local certificate issuance... ")
+	if certFile == "" {
+		fmt.Fprintf(stdout, "skipped (no --cert given)\n")
+		return true
+	}
+	cert, err := parseFirstCertificate(certFile)
+	if err != nil {
+		fmt.Fprintf(stdout, "failed: %s\n", err)
+		return false
+	}
+	now := time.Now()
+	if now.Before(cert.NotBefore) {
+		fmt.Fprintf(stdout, "certificate is not yet valid (not before %s), check for node clock skew\n", cert.NotBefore)
+		return false
+	}
+	if now.After(cert.NotAfter) {
+		fmt.Fprintf(stdout, "certificate expired at %s, issuance appears stalled\n", cert.NotAfter)
+		return false
+	}
+	fmt.Fprintf(stdout, "valid for another %s\n", cert.NotAfter.Sub(now).Round(time.Second))
+	return true
+}
+
+func checkTrustBundle(stdout io.Writer, caFile string) bool {
+	fmt.Fprintf(stdout, "🔏 Trust bundle freshness... ")
+	if caFile == "" {
+		fmt.Fprintf(stdout, "skipped (no --ca given)\n")
+		return true
+	}
+	caBytes, err := os.ReadFile(caFile)
+	if err != nil {
+		fmt.Fprintf(stdout, "failed to read bundle: %s\n", err)
+		return false
+	}
+
+	now := time.Now()
+	valid := 0
+	earliestExpiry := time.Time{}
+	for block, rest := pem.Decode(caBytes); block != nil; block, rest = pem.Decode(rest) {
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			continue
+		}
+		if now.After(cert.NotAfter) {
+			continue
+		}
+		valid++
+		if earliestExpiry.IsZero() || cert.NotAfter.Before(earliestExpiry) {
+			earliestExpiry = cert.NotAfter
+		}
+	}
+	if valid == 0 {
+		fmt.Fprintf(stdout, "no unexpired CA certificates in the bundle, the trust bundle is stale\n")
+		return false
+	}
+	fmt.Fprintf(stdout, "%d unexpired CA certificate(s), earliest expiry in %s\n", valid, earliestExpiry.Sub(now).Round(time.Second))
+	return true
+}
+
+func checkTCPReachability(stdout io.Writer, target string, timeout time.Duration) bool {
+	fmt.Fprintf(stdout, "🌍 TCP reachability of %s... ", target)
+	conn, err := net.DialTimeout("tcp", target, timeout)
+	if err != nil {
+		fmt.Fprintf(stdout, "failed: %s\n", err)
+		return false
+	}
+	conn.Close()
+	fmt.Fprintf(stdout, "reachable\n")
+	return true
+}
+
+func checkTestHandshake(stdout io.Writer, target, caFile, certFile, keyFile, sni string, timeout time.Duration) bool {
+	fmt.Fprintf(stdout, "🤝 Test handshake with %s... ", target)
+	if caFile == "" || certFile == "" || keyFile == "" || sni == "" {
+		fmt.Fprintf(stdout, "skipped (requires --ca, --cert, --key and --sni)\n")
+		return true
+	}
+
+	tlsConfig, err := authTLSClientConfig(caFile, certFile, keyFile, sni)
+	if err != nil {
+		fmt.Fprintf(stdout, "failed to build TLS config: %s\n", err)
+		return false
+	}
+
+	dialer := net.Dialer{Timeout: timeout}
+	start := time.Now()
+	conn, err := tls.DialWithDialer(&dialer, "tcp", target, tlsConfig)
+	if err != nil {
+		fmt.Fprintf(stdout, "failed: %s\n", err)
+		return false
+	}
+	conn.Close()
+	fmt.Fprintf(stdout, "succeeded in %s\n", time.Since(start).Round(time.Millisecond))
+	return true
+}
+
+func parseFirstCertificate(path string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in %s", path)
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
@@ -41,19 +41,20 @@ const (
 )
 
 // Launch starts the cilium-health server and returns a handle to obtain its status
-func Launch(spec *healthApi.Spec, initialized <-chan struct{}) (*CiliumHealth, error) {
+func Launch(spec *healthApi.Spec, mutualAuthPort int, initialized <-chan struct{}) (*CiliumHealth, error) {
 	var (
 		err error
 		ch  = &CiliumHealth{}
 	)
 
 	config := server.Config{
-		CiliumURI:     ciliumPkg.DefaultSockPath(),
-		Debug:         option.Config.Opts.IsEnabled(option.Debug),
-		ProbeInterval: serverProbeInterval,
-		ProbeDeadline: serverProbeDeadline,
-		HTTPPathPort:  option.Config.ClusterHealthPort,
-		HealthAPISpec: spec,
+		CiliumURI:      ciliumPkg.DefaultSockPath(),
+		Debug:          option.Config.Opts.IsEnabled(option.Debug),
+		ProbeInterval:  serverProbeInterval,
+		ProbeDeadline:  serverProbeDeadline,
+		HTTPPathPort:   option.Config.ClusterHealthPort,
+		MutualAuthPort: mutualAuthPort,
+		HealthAPISpec:  spec,
 	}
 
 	ch.server, err = server.NewServer(config)
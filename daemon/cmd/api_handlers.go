@@ -48,6 +48,7 @@ type handlersOut struct {
 	PolicyGetIPHandler                policy.GetIPHandler
 	PolicyGetPolicyHandler            policy.GetPolicyHandler
 	PolicyGetPolicySelectorsHandler   policy.GetPolicySelectorsHandler
+	PolicyPostAuthHandler             policy.PostAuthHandler
 	PolicyPutPolicyHandler            policy.PutPolicyHandler
 }
 
@@ -136,6 +137,9 @@ func ciliumAPIHandlers(dp promise.Promise[*Daemon], cfg *option.DaemonConfig, _
 	out.PolicyGetFqdnCacheIDHandler = wrapAPIHandler(dp, getFqdnCacheIDHandler)
 	out.PolicyGetFqdnNamesHandler = wrapAPIHandler(dp, getFqdnNamesHandler)
 
+	// /auth/
+	out.PolicyPostAuthHandler = wrapAPIHandler(dp, postAuthHandler)
+
 	// /ip/
 	out.PolicyGetIPHandler = wrapAPIHandler(dp, getIPHandler)
 
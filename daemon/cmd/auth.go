@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+
+	"github.com/cilium/cilium/api/v1/models"
+	. "github.com/cilium/cilium/api/v1/server/restapi/policy"
+	"github.com/cilium/cilium/pkg/identity"
+	"github.com/cilium/cilium/pkg/logging/logfields"
+)
+
+func postAuthHandler(d *Daemon, params PostAuthParams) middleware.Responder {
+	log.WithField(logfields.Params, logfields.Repr(params)).Debug("POST /auth request")
+
+	if d.authManager == nil {
+		return NewPostAuthInvalid().WithPayload(models.Error("authentication processing is disabled"))
+	}
+
+	req := params.Request
+	if req.LocalIdentity == 0 || req.RemoteIdentity == 0 || req.RemoteNodeIP == "" {
+		return NewPostAuthInvalid().WithPayload(models.Error("local-identity, remote-identity and remote-node-ip are required"))
+	}
+
+	expiration, err := d.authManager.AuthenticateOnDemand(
+		identity.NumericIdentity(req.LocalIdentity),
+		identity.NumericIdentity(req.RemoteIdentity),
+		req.RemoteNodeIP)
+	if err != nil {
+		return NewPostAuthFailure().WithPayload(models.Error(err.Error()))
+	}
+
+	return NewPostAuthOK().WithPayload(&models.AuthResponse{
+		Expiration: strfmt.DateTime(expiration),
+	})
+}
@@ -1700,6 +1700,7 @@ type daemonParams struct {
 	DB                     *statedb.DB
 	APILimiterSet          *rate.APILimiterSet
 	AuthManager            *auth.AuthManager
+	MutualAuthConfig       auth.MutualAuthConfig
 	Settings               cellSettings
 	Devices                statedb.Table[*datapathTables.Device]
 	NodeAddrs              statedb.Table[datapathTables.NodeAddress]
@@ -1963,7 +1964,7 @@ func startDaemon(d *Daemon, restoredEndpoints *endpointRestoreState, cleaner *da
 
 	bootstrapStats.healthCheck.Start()
 	if option.Config.EnableHealthChecking {
-		d.initHealth(params.HealthAPISpec, cleaner, params.Sysctl)
+		d.initHealth(params.HealthAPISpec, cleaner, params.Sysctl, params.MutualAuthConfig.MutualAuthListenerPort)
 	}
 	bootstrapStats.healthCheck.End(true)
 
@@ -28,10 +28,10 @@ const (
 	successfulPingTimeout = 3 * time.Minute
 )
 
-func (d *Daemon) initHealth(spec *healthApi.Spec, cleaner *daemonCleanup, sysctl sysctl.Sysctl) {
+func (d *Daemon) initHealth(spec *healthApi.Spec, cleaner *daemonCleanup, sysctl sysctl.Sysctl, mutualAuthPort int) {
 	// Launch cilium-health in the same process (and namespace) as cilium.
 	log.Info("Launching Cilium health daemon")
-	if ch, err := health.Launch(spec, d.loader.HostDatapathInitialized()); err != nil {
+	if ch, err := health.Launch(spec, mutualAuthPort, d.loader.HostDatapathInitialized()); err != nil {
 		log.WithError(err).Fatal("Failed to launch cilium-health")
 	} else {
 		d.ciliumHealth = ch
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package observe
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	flowpb "github.com/cilium/cilium/api/v1/flow"
+)
+
+// authFilterOpts holds the authentication filters. They are applied on the
+// client side, as the flow filter protocol does not carry authentication
+// fields.
+var authFilterOpts struct {
+	authTypes    []string
+	authStatuses []string
+}
+
+const (
+	authStatusSuccess = "success"
+	authStatusFailed  = "failed"
+)
+
+var authStatuses = []string{authStatusSuccess, authStatusFailed}
+
+// authTypeNames returns the valid --auth-type values, derived from the
+// flow protocol enum (e.g. "disabled", "spire", "test-always-fail").
+func authTypeNames() []string {
+	names := make([]string, 0, len(flowpb.AuthType_name))
+	for i := int32(0); int(i) < len(flowpb.AuthType_name); i++ {
+		names = append(names, authTypeName(flowpb.AuthType(i)))
+	}
+	return names
+}
+
+func authTypeName(t flowpb.AuthType) string {
+	return strings.ToLower(strings.ReplaceAll(t.String(), "_", "-"))
+}
+
+// validateAuthFilters checks the given --auth-type and --auth-status values.
+func validateAuthFilters() error {
+	for _, t := range authFilterOpts.authTypes {
+		if !slices.Contains(authTypeNames(), t) {
+			return fmt.Errorf("invalid --auth-type value %q, expected one of %s", t, strings.Join(authTypeNames(), ", "))
+		}
+	}
+	for _, s := range authFilterOpts.authStatuses {
+		if !slices.Contains(authStatuses, s) {
+			return fmt.Errorf("invalid --auth-status value %q, expected one of %s", s, strings.Join(authStatuses, ", "))
+		}
+	}
+	return nil
+}
+
+// matchAuthFilters reports whether the given flow passes the authentication
+// filters.
+func matchAuthFilters(f *flowpb.Flow) bool {
+	if len(authFilterOpts.authTypes) > 0 &&
+		!slices.Contains(authFilterOpts.authTypes, authTypeName(f.GetAuthType())) {
+		return false
+	}
+
+	for _, status := range authFilterOpts.authStatuses {
+		switch status {
+		case authStatusFailed:
+			if f.GetVerdict() != flowpb.Verdict_DROPPED || f.GetDropReasonDesc() != flowpb.DropReason_AUTH_REQUIRED {
+				return false
+			}
+		case authStatusSuccess:
+			if f.GetAuthType() == flowpb.AuthType_DISABLED || f.GetVerdict() != flowpb.Verdict_FORWARDED {
+				return false
+			}
+		}
+	}
+	return true
+}
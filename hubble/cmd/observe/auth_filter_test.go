@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package observe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	flowpb "github.com/cilium/cilium/api/v1/flow"
+)
+
+func TestValidateAuthFilters(t *testing.T) {
+	defer func() {
+		authFilterOpts.authTypes = nil
+		authFilterOpts.authStatuses = nil
+	}()
+
+	authFilterOpts.authTypes = []string{"spire"}
+	authFilterOpts.authStatuses = []string{"failed"}
+	assert.NoError(t, validateAuthFilters())
+
+	authFilterOpts.authTypes = []string{"bogus"}
+	assert.Error(t, validateAuthFilters())
+
+	authFilterOpts.authTypes = nil
+	authFilterOpts.authStatuses = []string{"bogus"}
+	assert.Error(t, validateAuthFilters())
+}
+
+func TestMatchAuthFilters(t *testing.T) {
+	defer func() {
+		authFilterOpts.authTypes = nil
+		authFilterOpts.authStatuses = nil
+	}()
+
+	spireForwarded := &flowpb.Flow{
+		AuthType: flowpb.AuthType_SPIRE,
+		Verdict:  flowpb.Verdict_FORWARDED,
+	}
+	authDropped := &flowpb.Flow{
+		Verdict:        flowpb.Verdict_DROPPED,
+		DropReasonDesc: flowpb.DropReason_AUTH_REQUIRED,
+	}
+	unauthenticated := &flowpb.Flow{
+		Verdict: flowpb.Verdict_FORWARDED,
+	}
+
+	authFilterOpts.authTypes = []string{"spire"}
+	authFilterOpts.authStatuses = nil
+	assert.True(t, matchAuthFilters(spireForwarded))
+	assert.False(t, matchAuthFilters(unauthenticated))
+
+	authFilterOpts.authTypes = nil
+	authFilterOpts.authStatuses = []string{"failed"}
+	assert.True(t, matchAuthFilters(authDropped))
+	assert.False(t, matchAuthFilters(spireForwarded))
+
+	authFilterOpts.authStatuses = []string{"success"}
+	assert.True(t, matchAuthFilters(spireForwarded))
+	assert.False(t, matchAuthFilters(authDropped))
+	assert.False(t, matchAuthFilters(unauthenticated))
+}
@@ -389,6 +389,12 @@ func newFlowsCmdHelper(usage cmdUsage, vp *viper.Viper, ofilter *flowFilter) *co
 	filterFlags.Var(filterVar(
 		"drop-reason-desc", ofilter,
 		`Show only flows which match this drop reason describe (e.g. "POLICY_DENIED", "UNSUPPORTED_L3_PROTOCOL")`))
+	filterFlags.StringSliceVar(&authFilterOpts.authTypes,
+		"auth-type", nil,
+		fmt.Sprintf("Show only flows with this authentication type [%s] (applied client-side)", strings.Join(authTypeNames(), ", ")))
+	filterFlags.StringSliceVar(&authFilterOpts.authStatuses,
+		"auth-status", nil,
+		fmt.Sprintf("Show only flows with this authentication status [%s] (applied client-side)", strings.Join(authStatuses, ", ")))
 	filterFlags.Var(filterVar(
 		"http-status", ofilter,
 		`Show only flows which match this HTTP status code prefix (e.g. "404", "5+")`))
@@ -648,6 +654,9 @@ func handleFlowArgs(writer io.Writer, ofilter *flowFilter, debug bool) (err erro
 	if ofilter.blacklisting {
 		return errors.New("trailing --not found in the arguments")
 	}
+	if err := validateAuthFilters(); err != nil {
+		return err
+	}
 
 	// initialize the printer with any options that were passed in
 	var opts = []hubprinter.Option{
@@ -852,6 +861,10 @@ func getFlows(ctx context.Context, client observerpb.ObserverClient, req *observ
 			return err
 		}
 
+		if flow := getFlowResponse.GetFlow(); flow != nil && !matchAuthFilters(flow) {
+			continue
+		}
+
 		if err = printer.WriteGetFlowsResponse(getFlowResponse); err != nil {
 			return err
 		}
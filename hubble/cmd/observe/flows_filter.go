@@ -365,6 +365,8 @@ var agentEventSubtypes = map[string]monitorAPI.AgentNotification{
 	"ipcache-deleted":             monitorAPI.AgentNotifyIPCacheDeleted,
 	"service-upserted":            monitorAPI.AgentNotifyServiceUpserted,
 	"service-deleted":             monitorAPI.AgentNotifyServiceDeleted,
+	"auth-succeeded":              monitorAPI.AgentNotifyAuthSuccess,
+	"auth-failed":                 monitorAPI.AgentNotifyAuthFail,
 }
 
 func (of *flowFilter) set(f *filterTracker, name, val string, track bool) error {
@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cilium/hive/cell"
+	"github.com/cilium/workerpool"
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cilium/cilium/operator/auth/spire"
+	k8sClient "github.com/cilium/cilium/pkg/k8s/client"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// trustBundleKey is the key under which the PEM encoded trust bundle is
+// stored in the distributed ConfigMap.
+const trustBundleKey = "ca.crt"
+
+type bundleParams struct {
+	cell.In
+
+	Logger    logrus.FieldLogger
+	Lifecycle cell.Lifecycle
+	K8sClient k8sClient.Clientset
+
+	Cfg      Config
+	SpireCfg spire.ClientConfig
+}
+
+// trustBundleDistributor periodically fetches the trust bundle from the
+// SPIRE agent and publishes it into a ConfigMap, so agents and other
+// consumers can pick it up without talking to SPIRE themselves.
+type trustBundleDistributor struct {
+	logger    logrus.FieldLogger
+	k8sClient k8sClient.Clientset
+	wg        *workerpool.WorkerPool
+	cfg       Config
+	spireCfg  spire.ClientConfig
+}
+
+func registerTrustBundleDistributor(p bundleParams) {
+	if !p.Cfg.Enabled || !p.Cfg.TrustBundleDistribution {
+		return
+	}
+
+	d := &trustBundleDistributor{
+		logger:    p.Logger,
+		k8sClient: p.K8sClient,
+		wg:        workerpool.New(1),
+		cfg:       p.Cfg,
+		spireCfg:  p.SpireCfg,
+	}
+	p.Lifecycle.Append(cell.Hook{
+		OnStart: func(ctx cell.HookContext) error {
+			return d.wg.Submit("trust-bundle-distributor", func(ctx context.Context) error {
+				return d.run(ctx)
+			})
+		},
+		OnStop: func(_ cell.HookContext) error {
+			return d.wg.Close()
+		},
+	})
+}
+
+func (d *trustBundleDistributor) run(ctx context.Context) error {
+	ticker := time.NewTicker(d.cfg.TrustBundleRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.publish(ctx); err != nil {
+			d.logger.WithError(err).Warning("Failed to publish trust bundle")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// publish fetches the current trust bundle from the SPIRE agent and upserts
+// it into the configured ConfigMap.
+func (d *trustBundleDistributor) publish(ctx context.Context) error {
+	bundles, err := workloadapi.FetchX509Bundles(ctx,
+		workloadapi.WithAddr(fmt.Sprintf("unix://%s", d.spireCfg.SpireAgentSocketPath)))
+	if err != nil {
+		return fmt.Errorf("failed to fetch trust bundle from SPIRE agent: %w", err)
+	}
+
+	var pem []byte
+	for _, bundle := range bundles.Bundles() {
+		data, err := bundle.Marshal()
+		if err != nil {
+			return fmt.Errorf("failed to marshal trust bundle of %s: %w", bundle.TrustDomain(), err)
+		}
+		pem = append(pem, data...)
+	}
+	if len(pem) == 0 {
+		return fmt.Errorf("SPIRE agent returned an empty trust bundle")
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      d.cfg.TrustBundleConfigMapName,
+			Namespace: d.cfg.TrustBundleConfigMapNamespace,
+		},
+		Data: map[string]string{
+			trustBundleKey: string(pem),
+		},
+	}
+
+	configMaps := d.k8sClient.CoreV1().ConfigMaps(d.cfg.TrustBundleConfigMapNamespace)
+	existing, err := configMaps.Get(ctx, d.cfg.TrustBundleConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get trust bundle ConfigMap: %w", err)
+		}
+		if _, err := configMaps.Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create trust bundle ConfigMap: %w", err)
+		}
+		d.logger.
+			WithField("name", d.cfg.TrustBundleConfigMapName).
+			WithField("namespace", d.cfg.TrustBundleConfigMapNamespace).
+			Info("Created trust bundle ConfigMap")
+		return nil
+	}
+
+	if existing.Data[trustBundleKey] == string(pem) {
+		return nil
+	}
+
+	existing.Data = cm.Data
+	if _, err := configMaps.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update trust bundle ConfigMap: %w", err)
+	}
+	d.logger.
+		WithField("name", d.cfg.TrustBundleConfigMapName).
+		WithField("namespace", d.cfg.TrustBundleConfigMapNamespace).
+		Info("Updated trust bundle ConfigMap")
+	return nil
+}
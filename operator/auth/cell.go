@@ -8,10 +8,13 @@ import (
 	"github.com/spf13/pflag"
 
 	"github.com/cilium/cilium/operator/auth/spire"
+	"github.com/cilium/cilium/pkg/metrics"
+	"github.com/cilium/cilium/pkg/time"
 )
 
 const (
-	mutualAuthEnabled = "mesh-auth-mutual-enabled"
+	mutualAuthEnabled    = "mesh-auth-mutual-enabled"
+	spireEntryGCInterval = "mesh-auth-spire-entry-gc-interval"
 )
 
 var Cell = cell.Module(
@@ -19,19 +22,53 @@ var Cell = cell.Module(
 	"Cilium Mutual Authentication Identity management",
 	spire.Cell,
 	cell.Config(defaultConfig),
+	metrics.Metric(NewMetrics),
 	cell.Invoke(registerIdentityWatcher),
+	cell.Invoke(registerTrustBundleDistributor),
 )
 
 // Config contains the configuration for the identity-gc.
 type Config struct {
-	Enabled bool `mapstructure:"mesh-auth-mutual-enabled"`
+	Enabled              bool          `mapstructure:"mesh-auth-mutual-enabled"`
+	SpireEntryGCInterval time.Duration `mapstructure:"mesh-auth-spire-entry-gc-interval"`
+	NodeIdentities       bool          `mapstructure:"mesh-auth-node-identities"`
+
+	// TenantTrustDomains maps Kubernetes namespaces to the SPIFFE trust
+	// domain identities of that namespace are issued under. Identities of
+	// unmapped namespaces stay in the default trust domain.
+	TenantTrustDomains map[string]string `mapstructure:"mesh-auth-tenant-trust-domains"`
+
+	TrustBundleDistribution       bool          `mapstructure:"mesh-auth-trust-bundle-distribution"`
+	TrustBundleConfigMapName      string        `mapstructure:"mesh-auth-trust-bundle-configmap-name"`
+	TrustBundleConfigMapNamespace string        `mapstructure:"mesh-auth-trust-bundle-configmap-namespace"`
+	TrustBundleRefreshInterval    time.Duration `mapstructure:"mesh-auth-trust-bundle-refresh-interval"`
 }
 
 // Flags implements cell.Flagger interface.
 func (cfg Config) Flags(flags *pflag.FlagSet) {
 	flags.Bool(mutualAuthEnabled, cfg.Enabled, "Enable mutual authentication in Cilium")
+	flags.Duration(spireEntryGCInterval, cfg.SpireEntryGCInterval,
+		"Interval in which SPIRE entries without a matching Cilium identity are garbage collected (0 to disable)")
+	flags.Bool("mesh-auth-node-identities", cfg.NodeIdentities,
+		"Issue SPIFFE identities for the reserved host and remote-node identities so policies can require authenticated node-to-node traffic")
+	flags.StringToString("mesh-auth-tenant-trust-domains", cfg.TenantTrustDomains,
+		"Namespace to SPIFFE trust domain mappings ('<namespace>=<trust-domain>') under which identities of that namespace are issued. Requires a SPIRE server authoritative for the listed trust domains")
+	flags.Bool("mesh-auth-trust-bundle-distribution", cfg.TrustBundleDistribution,
+		"Distribute the SPIFFE trust bundle to agents via a ConfigMap")
+	flags.String("mesh-auth-trust-bundle-configmap-name", cfg.TrustBundleConfigMapName,
+		"Name of the ConfigMap the trust bundle is distributed in")
+	flags.String("mesh-auth-trust-bundle-configmap-namespace", cfg.TrustBundleConfigMapNamespace,
+		"Namespace of the ConfigMap the trust bundle is distributed in")
+	flags.Duration("mesh-auth-trust-bundle-refresh-interval", cfg.TrustBundleRefreshInterval,
+		"Interval in which the trust bundle is refreshed from the SPIRE agent")
 }
 
 var defaultConfig = Config{
-	Enabled: false,
+	Enabled:              false,
+	SpireEntryGCInterval: 15 * time.Minute,
+
+	TrustBundleDistribution:       false,
+	TrustBundleConfigMapName:      "cilium-trust-bundle",
+	TrustBundleConfigMapNamespace: "kube-system",
+	TrustBundleRefreshInterval:    10 * time.Minute,
 }
@@ -7,7 +7,14 @@ import "context"
 
 // Provider is the interface that manages the identity operations.
 type Provider interface {
-	Upsert(ctx context.Context, id string) error
+	// Upsert registers the given identity with the provider. A non-empty
+	// trustDomain issues the identity under that trust domain instead of
+	// the default one.
+	Upsert(ctx context.Context, id string, trustDomain string) error
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context) ([]string, error)
+
+	// ListIdentities returns the names of the Cilium identities currently
+	// registered with the provider.
+	ListIdentities(ctx context.Context) ([]string, error)
 }
@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"github.com/cilium/cilium/pkg/metrics"
+	"github.com/cilium/cilium/pkg/metrics/metric"
+)
+
+const (
+	// LabelOperation marks the type of SPIRE entry operation
+	LabelOperation = "operation"
+
+	// LabelOutcome indicates whether the outcome of the operation was successful or not
+	LabelOutcome = "outcome"
+
+	// LabelValueOperationUpsert is used for entry create/update operations
+	LabelValueOperationUpsert = "upsert"
+
+	// LabelValueOperationDelete is used for entry delete operations
+	LabelValueOperationDelete = "delete"
+
+	// LabelValueOutcomeSuccess is used as a successful outcome of an operation
+	LabelValueOutcomeSuccess = "success"
+
+	// LabelValueOutcomeFail is used as an unsuccessful outcome of an operation
+	LabelValueOutcomeFail = "fail"
+)
+
+// Metrics contains the metrics of the identity watcher reflecting the health
+// of the SPIRE server connection and the registration operations.
+type Metrics struct {
+	SpireServerReachable metric.Gauge
+	SpireEntryOperations metric.Vec[metric.Counter]
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		SpireServerReachable: metric.NewGauge(metric.GaugeOpts{
+			Namespace: metrics.CiliumOperatorNamespace,
+			Name:      "spire_server_reachable",
+			Help:      "Whether the SPIRE server is reachable from the operator (1 if reachable, 0 otherwise)",
+		}),
+
+		SpireEntryOperations: metric.NewCounterVec(metric.CounterOpts{
+			Namespace: metrics.CiliumOperatorNamespace,
+			Name:      "spire_entry_operations_total",
+			Help:      "The number of SPIRE entry operations performed by the identity watcher",
+		}, []string{LabelOperation, LabelOutcome}),
+	}
+}
@@ -19,6 +19,7 @@ import (
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -27,12 +28,22 @@ import (
 	k8sClient "github.com/cilium/cilium/pkg/k8s/client"
 	"github.com/cilium/cilium/pkg/lock"
 	"github.com/cilium/cilium/pkg/logging/logfields"
+	"github.com/cilium/cilium/pkg/metrics"
+	"github.com/cilium/cilium/pkg/metrics/metric"
+	"github.com/cilium/cilium/pkg/rate"
 )
 
 const (
-	notFoundError   = "NotFound"
 	defaultParentID = "/cilium-operator"
 	pathPrefix      = "/identity"
+
+	// defaultEntryBatchSize bounds the number of entries sent to the SPIRE
+	// server in a single batch API call.
+	defaultEntryBatchSize = 64
+
+	// entryQueueSize bounds the number of upserts and deletes queued while
+	// waiting for the SPIRE server to accept them.
+	entryQueueSize = 8192
 )
 
 var defaultSelectors = []*types.Selector{
@@ -42,11 +53,33 @@ var defaultSelectors = []*types.Selector{
 	},
 }
 
+// parseSelectors converts the configured <type>:<value> pairs into SPIRE
+// selectors. An empty list falls back to the default selectors.
+func parseSelectors(selectors []string) ([]*types.Selector, error) {
+	if len(selectors) == 0 {
+		return defaultSelectors, nil
+	}
+
+	parsed := make([]*types.Selector, 0, len(selectors))
+	for _, s := range selectors {
+		selectorType, selectorValue, found := strings.Cut(s, ":")
+		if !found || selectorType == "" || selectorValue == "" {
+			return nil, fmt.Errorf("invalid selector %q, expected format <type>:<value>", s)
+		}
+		parsed = append(parsed, &types.Selector{
+			Type:  selectorType,
+			Value: selectorValue,
+		})
+	}
+	return parsed, nil
+}
+
 // Cell is the cell for the SPIRE client.
 var Cell = cell.Module(
 	"spire-client",
 	"Spire Server API Client",
 	cell.Config(ClientConfig{}),
+	metrics.Metric(NewMetrics),
 	cell.Provide(NewClient),
 )
 
@@ -64,6 +97,14 @@ type ClientConfig struct {
 	SpireServerAddress           string        `mapstructure:"mesh-auth-spire-server-address"`
 	SpireServerConnectionTimeout time.Duration `mapstructure:"mesh-auth-spire-server-connection-timeout"`
 	SpiffeTrustDomain            string        `mapstructure:"mesh-auth-spiffe-trust-domain"`
+	SpireEntrySelectors          []string      `mapstructure:"mesh-auth-spire-entry-selectors"`
+	SpireServerExternal          bool          `mapstructure:"mesh-auth-external-spire-server"`
+	SpireParentID                string        `mapstructure:"mesh-auth-spire-parent-id"`
+	SpireEntryTTL                time.Duration `mapstructure:"mesh-auth-spire-entry-ttl"`
+	SpireEntryDNSNames           []string      `mapstructure:"mesh-auth-spire-entry-dns-names"`
+	SpireEntryFederatesWith      []string      `mapstructure:"mesh-auth-spire-entry-federates-with"`
+	SpireEntryBatchSize          int           `mapstructure:"mesh-auth-spire-entry-batch-size"`
+	SpireEntryRateLimit          int64         `mapstructure:"mesh-auth-spire-entry-rate-limit"`
 }
 
 // Flags adds the flags used by ClientConfig.
@@ -88,12 +129,90 @@ func (cfg ClientConfig) Flags(flags *pflag.FlagSet) {
 		"mesh-auth-spiffe-trust-domain",
 		"spiffe.cilium",
 		"The trust domain for the SPIFFE identity.")
+	flags.StringSliceVar(&cfg.SpireEntrySelectors,
+		"mesh-auth-spire-entry-selectors",
+		[]string{"cilium:mutual-auth"},
+		"Selectors (<type>:<value>) set on the SPIRE entries registered for Cilium identities.")
+	flags.BoolVar(&cfg.SpireServerExternal,
+		"mesh-auth-external-spire-server",
+		false,
+		"Connect to an externally managed SPIRE server. The server address is used as-is without Kubernetes service resolution.")
+	flags.StringVar(&cfg.SpireParentID,
+		"mesh-auth-spire-parent-id",
+		defaultParentID,
+		"SPIFFE path of the parent ID under which entries for Cilium identities are registered.")
+	flags.DurationVar(&cfg.SpireEntryTTL,
+		"mesh-auth-spire-entry-ttl",
+		0,
+		"TTL of the X509-SVIDs issued for the SPIRE entries registered for Cilium identities (0 for the server default).")
+	flags.StringSliceVar(&cfg.SpireEntryDNSNames,
+		"mesh-auth-spire-entry-dns-names",
+		nil,
+		"DNS names set on the SPIRE entries registered for Cilium identities.")
+	flags.StringSliceVar(&cfg.SpireEntryFederatesWith,
+		"mesh-auth-spire-entry-federates-with",
+		nil,
+		"Trust domains the SPIRE entries registered for Cilium identities federate with.")
+	flags.IntVar(&cfg.SpireEntryBatchSize,
+		"mesh-auth-spire-entry-batch-size",
+		defaultEntryBatchSize,
+		"Maximum number of SPIRE entries created or updated in a single batch API call.")
+	flags.Int64Var(&cfg.SpireEntryRateLimit,
+		"mesh-auth-spire-entry-rate-limit",
+		20,
+		"Maximum number of SPIRE entry batch API calls per second (0 to disable rate limiting).")
 }
 
 type params struct {
 	cell.In
 
 	K8sClient k8sClient.Clientset
+	Metrics   *Metrics
+}
+
+// entryRequest is a queued entry registration or deletion. The result of the
+// batch API call the request ended up in is delivered on done.
+type entryRequest struct {
+	id string
+	// trustDomain is the trust domain the entry is registered under, ""
+	// for the default trust domain. It is unused for deletions.
+	trustDomain string
+	done        chan error
+}
+
+// backlogTracker tracks the enqueue times of the pending requests on a batch
+// queue, to expose the age of the oldest pending item. Monitoring can use
+// this to detect a stalled registration pipeline even when the backlog size
+// itself stays small.
+type backlogTracker struct {
+	mu       lock.Mutex
+	enqueued []time.Time
+}
+
+func (b *backlogTracker) push(t time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.enqueued = append(b.enqueued, t)
+}
+
+func (b *backlogTracker) pop(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if n > len(b.enqueued) {
+		n = len(b.enqueued)
+	}
+	b.enqueued = b.enqueued[n:]
+}
+
+// oldestAge returns the age of the oldest pending item in seconds, or 0 if
+// nothing is pending.
+func (b *backlogTracker) oldestAge(now time.Time) float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.enqueued) == 0 {
+		return 0
+	}
+	return now.Sub(b.enqueued[0]).Seconds()
 }
 
 type Client struct {
@@ -102,6 +221,15 @@ type Client struct {
 	entry      entryv1.EntryClient
 	entryMutex lock.RWMutex
 	k8sClient  k8sClient.Clientset
+	selectors  []*types.Selector
+	metrics    *Metrics
+
+	limiter       *rate.Limiter
+	upserts       chan entryRequest
+	deletes       chan entryRequest
+	upsertTracker backlogTracker
+	deleteTracker backlogTracker
+	cancel        context.CancelFunc
 }
 
 // NewClient creates a new SPIRE client.
@@ -110,20 +238,36 @@ func NewClient(params params, lc cell.Lifecycle, cfg ClientConfig, log logrus.Fi
 	if !cfg.MutualAuthEnabled {
 		return &noopClient{}
 	}
+	selectors, err := parseSelectors(cfg.SpireEntrySelectors)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid SPIRE entry selectors")
+	}
 	client := &Client{
 		k8sClient: params.K8sClient,
 		cfg:       cfg,
 		log:       log.WithField(logfields.LogSubsys, "spire-client"),
+		selectors: selectors,
+		metrics:   params.Metrics,
+		upserts:   make(chan entryRequest, entryQueueSize),
+		deletes:   make(chan entryRequest, entryQueueSize),
+	}
+	if cfg.SpireEntryRateLimit > 0 {
+		client.limiter = rate.NewLimiter(time.Second, cfg.SpireEntryRateLimit)
 	}
 
 	lc.Append(cell.Hook{
 		OnStart: client.onStart,
-		OnStop:  func(_ cell.HookContext) error { return nil },
+		OnStop:  client.onStop,
 	})
 	return client
 }
 
 func (c *Client) onStart(_ cell.HookContext) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	go c.processBatches(ctx, c.upserts, &c.upsertTracker, c.metrics.EntryUpsertBacklog, c.upsertBatch)
+	go c.processBatches(ctx, c.deletes, &c.deleteTracker, c.metrics.EntryDeleteBacklog, c.deleteBatch)
+	go c.refreshBacklogAges(ctx)
 	go func() {
 		c.log.Info("Initializing SPIRE client")
 		attempts := 0
@@ -145,16 +289,28 @@ func (c *Client) onStart(_ cell.HookContext) error {
 	return nil
 }
 
+func (c *Client) onStop(_ cell.HookContext) error {
+	c.cancel()
+	if c.limiter != nil {
+		c.limiter.Stop()
+	}
+	return nil
+}
+
 func (c *Client) connect(ctx context.Context) (*grpc.ClientConn, error) {
 	timeoutCtx, cancelFunc := context.WithTimeout(ctx, c.cfg.SpireServerConnectionTimeout)
 	defer cancelFunc()
 
-	resolvedTarget, err := resolvedK8sService(ctx, c.k8sClient, c.cfg.SpireServerAddress)
-	if err != nil {
-		c.log.WithError(err).
-			WithField(logfields.URL, c.cfg.SpireServerAddress).
-			Warning("Unable to resolve SPIRE server address, using original value")
-		resolvedTarget = &c.cfg.SpireServerAddress
+	resolvedTarget := &c.cfg.SpireServerAddress
+	if !c.cfg.SpireServerExternal {
+		var err error
+		resolvedTarget, err = resolvedK8sService(ctx, c.k8sClient, c.cfg.SpireServerAddress)
+		if err != nil {
+			c.log.WithError(err).
+				WithField(logfields.URL, c.cfg.SpireServerAddress).
+				Warning("Unable to resolve SPIRE server address, using original value")
+			resolvedTarget = &c.cfg.SpireServerAddress
+		}
 	}
 
 	// This is blocking till the cilium-operator is registered in SPIRE.
@@ -192,77 +348,289 @@ func (c *Client) connect(ctx context.Context) (*grpc.ClientConn, error) {
 }
 
 // Upsert creates or updates the SPIFFE ID for the given ID.
-// The SPIFFE ID is in the form of spiffe://<trust-domain>/identity/<id>.
-func (c *Client) Upsert(ctx context.Context, id string) error {
+// The SPIFFE ID is in the form of spiffe://<trust-domain>/identity/<id>,
+// where the trust domain is the configured default unless a tenant trust
+// domain is passed. Requests are queued and flushed to the SPIRE server in
+// rate-limited batches, so a flood of new identities does not overwhelm the
+// server.
+func (c *Client) Upsert(ctx context.Context, id string, trustDomain string) error {
+	return c.enqueue(ctx, c.upserts, &c.upsertTracker, c.metrics.EntryUpsertBacklog, id, trustDomain)
+}
+
+// enqueue queues the given ID on the given batch queue and waits for the
+// result of the batch API call it ended up in.
+func (c *Client) enqueue(ctx context.Context, queue chan entryRequest, tracker *backlogTracker, backlog metric.Gauge, id, trustDomain string) error {
+	req := entryRequest{id: id, trustDomain: trustDomain, done: make(chan error, 1)}
+	select {
+	case queue <- req:
+	default:
+		return fmt.Errorf("SPIRE entry backlog is full, retry later")
+	}
+	tracker.push(time.Now())
+	backlog.Set(float64(len(queue)))
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-req.done:
+		return err
+	}
+}
+
+// processBatches consumes queued entry requests and flushes them to the
+// SPIRE server in batches, respecting the configured rate limit.
+func (c *Client) processBatches(ctx context.Context, queue chan entryRequest, tracker *backlogTracker, backlog metric.Gauge, flush func(ctx context.Context, reqs []entryRequest) []error) {
+	for {
+		var batch []entryRequest
+		select {
+		case <-ctx.Done():
+			return
+		case req := <-queue:
+			batch = append(batch, req)
+		}
+
+	drain:
+		for len(batch) < c.batchSize() {
+			select {
+			case req := <-queue:
+				batch = append(batch, req)
+			default:
+				break drain
+			}
+		}
+		tracker.pop(len(batch))
+		backlog.Set(float64(len(queue)))
+
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx); err != nil {
+				for _, req := range batch {
+					req.done <- err
+				}
+				return
+			}
+		}
+
+		for i, err := range flush(ctx, batch) {
+			batch[i].done <- err
+		}
+	}
+}
+
+// upsertBatch registers the given requests with the SPIRE server in a single
+// batch create call, falling back to a batch update for the entries which
+// already exist. The returned errors are index-aligned with the input.
+func (c *Client) upsertBatch(ctx context.Context, reqs []entryRequest) []error {
+	errs := make([]error, len(reqs))
+
 	c.entryMutex.RLock()
 	defer c.entryMutex.RUnlock()
 	if c.entry == nil {
-		return fmt.Errorf("unable to connect to SPIRE server %s", c.cfg.SpireServerAddress)
+		err := fmt.Errorf("unable to connect to SPIRE server %s", c.cfg.SpireServerAddress)
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
 	}
 
-	entries, err := c.listEntries(ctx, id)
-	if err != nil && !strings.Contains(err.Error(), notFoundError) {
-		return err
+	desired := make([]*types.Entry, len(reqs))
+	for i, req := range reqs {
+		desired[i] = c.desiredEntry(req.id, req.trustDomain)
 	}
 
-	desired := []*types.Entry{
-		{
-			SpiffeId: &types.SPIFFEID{
-				TrustDomain: c.cfg.SpiffeTrustDomain,
-				Path:        toPath(id),
-			},
-			ParentId: &types.SPIFFEID{
-				TrustDomain: c.cfg.SpiffeTrustDomain,
-				Path:        defaultParentID,
-			},
-			Selectors: defaultSelectors,
-		},
+	resp, err := c.entry.BatchCreateEntry(ctx, &entryv1.BatchCreateEntryRequest{Entries: desired})
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
 	}
 
-	if entries == nil || len(entries.Entries) == 0 {
-		_, err = c.entry.BatchCreateEntry(ctx, &entryv1.BatchCreateEntryRequest{Entries: desired})
-		return err
+	var updates []*types.Entry
+	var updateIdx []int
+	for i, r := range resp.Results {
+		if i >= len(reqs) || r.Status == nil {
+			continue
+		}
+		switch codes.Code(r.Status.Code) {
+		case codes.OK:
+		case codes.AlreadyExists:
+			entry := desired[i]
+			if r.Entry != nil {
+				entry.Id = r.Entry.Id
+			}
+			updates = append(updates, entry)
+			updateIdx = append(updateIdx, i)
+		default:
+			errs[i] = fmt.Errorf("failed to create entry for %s: %s", reqs[i].id, r.Status.Message)
+		}
+	}
+	if len(updates) == 0 {
+		return errs
 	}
 
-	_, err = c.entry.BatchUpdateEntry(ctx, &entryv1.BatchUpdateEntryRequest{
-		Entries: desired,
-	})
-	return err
+	updateResp, err := c.entry.BatchUpdateEntry(ctx, &entryv1.BatchUpdateEntryRequest{Entries: updates})
+	if err != nil {
+		for _, i := range updateIdx {
+			errs[i] = err
+		}
+		return errs
+	}
+	for j, r := range updateResp.Results {
+		if j >= len(updateIdx) || r.Status == nil {
+			continue
+		}
+		if codes.Code(r.Status.Code) != codes.OK {
+			i := updateIdx[j]
+			errs[i] = fmt.Errorf("failed to update entry for %s: %s", reqs[i].id, r.Status.Message)
+		}
+	}
+	return errs
+}
+
+// desiredEntry returns the SPIRE entry registered for the given identity.
+// A non-empty trustDomain issues the SVID under that trust domain; the
+// parent entry always lives in the default trust domain the operator is
+// registered in.
+func (c *Client) desiredEntry(id, trustDomain string) *types.Entry {
+	if trustDomain == "" {
+		trustDomain = c.cfg.SpiffeTrustDomain
+	}
+	return &types.Entry{
+		SpiffeId: &types.SPIFFEID{
+			TrustDomain: trustDomain,
+			Path:        toPath(id),
+		},
+		ParentId: &types.SPIFFEID{
+			TrustDomain: c.cfg.SpiffeTrustDomain,
+			Path:        c.parentID(),
+		},
+		Selectors:     c.selectors,
+		X509SvidTtl:   int32(c.cfg.SpireEntryTTL.Seconds()),
+		DnsNames:      c.cfg.SpireEntryDNSNames,
+		FederatesWith: c.cfg.SpireEntryFederatesWith,
+	}
+}
+
+// batchSize returns the configured batch size, guarding against a
+// non-positive configuration.
+func (c *Client) batchSize() int {
+	if c.cfg.SpireEntryBatchSize > 0 {
+		return c.cfg.SpireEntryBatchSize
+	}
+	return defaultEntryBatchSize
 }
 
 // Delete deletes the SPIFFE ID for the given ID.
 // The SPIFFE ID is in the form of spiffe://<trust-domain>/identity/<id>.
+// Like Upsert, requests are queued and flushed in rate-limited batches.
 func (c *Client) Delete(ctx context.Context, id string) error {
+	if len(id) == 0 {
+		return nil
+	}
+	return c.enqueue(ctx, c.deletes, &c.deleteTracker, c.metrics.EntryDeleteBacklog, id, "")
+}
+
+// backlogAgeRefreshInterval is the interval in which the age of the oldest
+// pending backlog item gets recomputed.
+const backlogAgeRefreshInterval = 10 * time.Second
+
+// refreshBacklogAges periodically updates the oldest-age backlog metrics.
+// The age of a pending item grows without any queue activity, so it cannot
+// be maintained from the enqueue/flush paths alone.
+func (c *Client) refreshBacklogAges(ctx context.Context) {
+	ticker := time.NewTicker(backlogAgeRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now()
+		c.metrics.EntryUpsertBacklogOldestAge.Set(c.upsertTracker.oldestAge(now))
+		c.metrics.EntryDeleteBacklogOldestAge.Set(c.deleteTracker.oldestAge(now))
+	}
+}
+
+// deleteBatch removes the entries registered for the given requests with a
+// single batch delete call. IDs without a registered entry are treated as
+// already deleted. The returned errors are index-aligned with the input.
+func (c *Client) deleteBatch(ctx context.Context, reqs []entryRequest) []error {
+	errs := make([]error, len(reqs))
+
 	c.entryMutex.RLock()
 	defer c.entryMutex.RUnlock()
 	if c.entry == nil {
-		return fmt.Errorf("unable to connect to SPIRE server %s", c.cfg.SpireServerAddress)
+		err := fmt.Errorf("unable to connect to SPIRE server %s", c.cfg.SpireServerAddress)
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
 	}
 
-	if len(id) == 0 {
-		return nil
+	entries, err := c.entry.ListEntries(ctx, &entryv1.ListEntriesRequest{
+		Filter: &entryv1.ListEntriesRequest_Filter{
+			ByParentId: &types.SPIFFEID{
+				TrustDomain: c.cfg.SpiffeTrustDomain,
+				Path:        c.parentID(),
+			},
+			BySelectors: &types.SelectorMatch{
+				Selectors: c.selectors,
+				Match:     types.SelectorMatch_MATCH_EXACT,
+			},
+		},
+	})
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
 	}
 
-	entries, err := c.listEntries(ctx, id)
-	if err != nil {
-		if strings.Contains(err.Error(), notFoundError) {
-			return nil
+	entriesByPath := map[string][]string{}
+	for _, e := range entries.Entries {
+		if e.SpiffeId == nil {
+			continue
 		}
-		return err
+		entriesByPath[e.SpiffeId.Path] = append(entriesByPath[e.SpiffeId.Path], e.Id)
 	}
-	if len(entries.Entries) == 0 {
-		return nil
+
+	var entryIDs []string
+	entryIdx := map[string]int{}
+	for i, req := range reqs {
+		for _, entryID := range entriesByPath[toPath(req.id)] {
+			entryIDs = append(entryIDs, entryID)
+			entryIdx[entryID] = i
+		}
 	}
-	var ids = make([]string, 0, len(entries.Entries))
-	for _, e := range entries.Entries {
-		ids = append(ids, e.Id)
+	if len(entryIDs) == 0 {
+		return errs
 	}
 
-	_, err = c.entry.BatchDeleteEntry(ctx, &entryv1.BatchDeleteEntryRequest{
-		Ids: ids,
+	resp, err := c.entry.BatchDeleteEntry(ctx, &entryv1.BatchDeleteEntryRequest{
+		Ids: entryIDs,
 	})
-
-	return err
+	if err != nil {
+		for _, i := range entryIdx {
+			errs[i] = err
+		}
+		return errs
+	}
+	for _, r := range resp.Results {
+		if r.Status == nil {
+			continue
+		}
+		switch codes.Code(r.Status.Code) {
+		case codes.OK, codes.NotFound:
+		default:
+			if i, ok := entryIdx[r.Id]; ok {
+				errs[i] = fmt.Errorf("failed to delete entry for %s: %s", reqs[i].id, r.Status.Message)
+			}
+		}
+	}
+	return errs
 }
 
 func (c *Client) List(ctx context.Context) ([]string, error) {
@@ -272,10 +640,10 @@ func (c *Client) List(ctx context.Context) ([]string, error) {
 		Filter: &entryv1.ListEntriesRequest_Filter{
 			ByParentId: &types.SPIFFEID{
 				TrustDomain: c.cfg.SpiffeTrustDomain,
-				Path:        defaultParentID,
+				Path:        c.parentID(),
 			},
 			BySelectors: &types.SelectorMatch{
-				Selectors: defaultSelectors,
+				Selectors: c.selectors,
 				Match:     types.SelectorMatch_MATCH_EXACT,
 			},
 		},
@@ -293,25 +661,39 @@ func (c *Client) List(ctx context.Context) ([]string, error) {
 	return ids, nil
 }
 
-// listEntries returns the list of entries for the given ID.
-// The maximum number of entries returned is 1, so page token can be ignored.
-func (c *Client) listEntries(ctx context.Context, id string) (*entryv1.ListEntriesResponse, error) {
-	return c.entry.ListEntries(ctx, &entryv1.ListEntriesRequest{
+// ListIdentities returns the names of the Cilium identities which currently
+// have an entry registered with the SPIRE server.
+func (c *Client) ListIdentities(ctx context.Context) ([]string, error) {
+	c.entryMutex.RLock()
+	defer c.entryMutex.RUnlock()
+	if c.entry == nil {
+		return nil, fmt.Errorf("unable to connect to SPIRE server %s", c.cfg.SpireServerAddress)
+	}
+
+	entries, err := c.entry.ListEntries(ctx, &entryv1.ListEntriesRequest{
 		Filter: &entryv1.ListEntriesRequest_Filter{
-			BySpiffeId: &types.SPIFFEID{
-				TrustDomain: c.cfg.SpiffeTrustDomain,
-				Path:        toPath(id),
-			},
 			ByParentId: &types.SPIFFEID{
 				TrustDomain: c.cfg.SpiffeTrustDomain,
-				Path:        defaultParentID,
+				Path:        c.parentID(),
 			},
 			BySelectors: &types.SelectorMatch{
-				Selectors: defaultSelectors,
+				Selectors: c.selectors,
 				Match:     types.SelectorMatch_MATCH_EXACT,
 			},
 		},
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	var ids = make([]string, 0, len(entries.Entries))
+	for _, e := range entries.Entries {
+		if e.SpiffeId == nil {
+			continue
+		}
+		ids = append(ids, strings.TrimPrefix(e.SpiffeId.Path, pathPrefix+"/"))
+	}
+	return ids, nil
 }
 
 // resolvedK8sService resolves the given address to the IP address.
@@ -338,6 +720,16 @@ func resolvedK8sService(ctx context.Context, client k8sClient.Clientset, address
 	return &res, nil
 }
 
+// parentID returns the SPIFFE path of the parent entry under which Cilium
+// identity entries are registered. It allows bringing your own SPIRE server
+// with a different registration hierarchy.
+func (c *Client) parentID() string {
+	if c.cfg.SpireParentID != "" {
+		return c.cfg.SpireParentID
+	}
+	return defaultParentID
+}
+
 func toPath(id string) string {
 	return fmt.Sprintf("%s/%s", pathPrefix, id)
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -59,7 +60,7 @@ func (m mockEntryClient) SyncAuthorizedEntries(ctx context.Context, opts ...grpc
 	panic("implement me")
 }
 
-func TestClient_Upsert(t *testing.T) {
+func TestClient_upsertBatch(t *testing.T) {
 	cfg := ClientConfig{
 		SpiffeTrustDomain: "dummy.trusted.domain",
 	}
@@ -67,90 +68,48 @@ func TestClient_Upsert(t *testing.T) {
 		entry entryv1.EntryClient
 	}
 	type args struct {
-		id string
+		reqs []entryRequest
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		wantErr bool
+		name     string
+		fields   fields
+		args     args
+		wantErrs []bool
 	}{
 		{
-			name:    "client not initialized",
-			wantErr: true,
-		},
-		{
-			name: "unable to list entry due to unknown error",
+			name: "client not initialized",
 			args: args{
-				id: "dummy-id",
-			},
-			fields: fields{
-				entry: mockEntryClient{
-					ListEntriesFunc: func(ctx context.Context, in *entryv1.ListEntriesRequest, opts ...grpc.CallOption) (*entryv1.ListEntriesResponse, error) {
-						require.Equal(t, in, &entryv1.ListEntriesRequest{
-							Filter: &entryv1.ListEntriesRequest_Filter{
-								BySpiffeId: &types.SPIFFEID{
-									TrustDomain: "dummy.trusted.domain",
-									Path:        "/identity/dummy-id",
-								},
-								ByParentId: &types.SPIFFEID{
-									TrustDomain: "dummy.trusted.domain",
-									Path:        "/cilium-operator",
-								},
-								BySelectors: &types.SelectorMatch{
-									Selectors: []*types.Selector{
-										{
-											Type:  "cilium",
-											Value: "mutual-auth",
-										},
-									},
-									Match: types.SelectorMatch_MATCH_EXACT,
-								},
-							},
-						})
-						return nil, fmt.Errorf("something is wrong")
-					},
-				},
+				reqs: []entryRequest{{id: "dummy-id"}},
 			},
-			wantErr: true,
+			wantErrs: []bool{true},
 		},
 		{
-			name: "entry does not exist with not found error",
+			name: "create entries in the default and a tenant trust domain",
 			args: args{
-				id: "dummy-id",
+				reqs: []entryRequest{
+					{id: "dummy-id"},
+					{id: "tenant-id", trustDomain: "tenant-a.trusted.domain"},
+				},
 			},
 			fields: fields{
 				entry: mockEntryClient{
-					ListEntriesFunc: func(ctx context.Context, in *entryv1.ListEntriesRequest, opts ...grpc.CallOption) (*entryv1.ListEntriesResponse, error) {
-						require.Equal(t, in, &entryv1.ListEntriesRequest{
-							Filter: &entryv1.ListEntriesRequest_Filter{
-								BySpiffeId: &types.SPIFFEID{
+					BatchCreateEntryFunc: func(ctx context.Context, in *entryv1.BatchCreateEntryRequest, opts ...grpc.CallOption) (*entryv1.BatchCreateEntryResponse, error) {
+						require.ElementsMatch(t, in.Entries, []*types.Entry{
+							{
+								SpiffeId: &types.SPIFFEID{
 									TrustDomain: "dummy.trusted.domain",
 									Path:        "/identity/dummy-id",
 								},
-								ByParentId: &types.SPIFFEID{
+								ParentId: &types.SPIFFEID{
 									TrustDomain: "dummy.trusted.domain",
 									Path:        "/cilium-operator",
 								},
-								BySelectors: &types.SelectorMatch{
-									Selectors: []*types.Selector{
-										{
-											Type:  "cilium",
-											Value: "mutual-auth",
-										},
-									},
-									Match: types.SelectorMatch_MATCH_EXACT,
-								},
+								Selectors: defaultSelectors,
 							},
-						})
-						return nil, fmt.Errorf("NotFound")
-					},
-					BatchCreateEntryFunc: func(ctx context.Context, in *entryv1.BatchCreateEntryRequest, opts ...grpc.CallOption) (*entryv1.BatchCreateEntryResponse, error) {
-						require.ElementsMatch(t, in.Entries, []*types.Entry{
 							{
 								SpiffeId: &types.SPIFFEID{
-									TrustDomain: "dummy.trusted.domain",
-									Path:        "/identity/dummy-id",
+									TrustDomain: "tenant-a.trusted.domain",
+									Path:        "/identity/tenant-id",
 								},
 								ParentId: &types.SPIFFEID{
 									TrustDomain: "dummy.trusted.domain",
@@ -159,47 +118,38 @@ func TestClient_Upsert(t *testing.T) {
 								Selectors: defaultSelectors,
 							},
 						})
-						return &entryv1.BatchCreateEntryResponse{}, nil
+						return &entryv1.BatchCreateEntryResponse{
+							Results: []*entryv1.BatchCreateEntryResponse_Result{
+								{Status: &types.Status{Code: int32(codes.OK)}},
+								{Status: &types.Status{Code: int32(codes.OK)}},
+							},
+						}, nil
 					},
 				},
 			},
+			wantErrs: []bool{false, false},
 		},
 		{
-			name: "entry exists",
+			name: "existing entry falls back to an update",
 			args: args{
-				id: "dummy-id",
+				reqs: []entryRequest{{id: "dummy-id"}},
 			},
 			fields: fields{
 				entry: mockEntryClient{
-					ListEntriesFunc: func(ctx context.Context, in *entryv1.ListEntriesRequest, opts ...grpc.CallOption) (*entryv1.ListEntriesResponse, error) {
-						require.Equal(t, in, &entryv1.ListEntriesRequest{
-							Filter: &entryv1.ListEntriesRequest_Filter{
-								BySpiffeId: &types.SPIFFEID{
-									TrustDomain: "dummy.trusted.domain",
-									Path:        "/identity/dummy-id",
-								},
-								ByParentId: &types.SPIFFEID{
-									TrustDomain: "dummy.trusted.domain",
-									Path:        "/cilium-operator",
-								},
-								BySelectors: &types.SelectorMatch{
-									Selectors: []*types.Selector{
-										{
-											Type:  "cilium",
-											Value: "mutual-auth",
-										},
-									},
-									Match: types.SelectorMatch_MATCH_EXACT,
+					BatchCreateEntryFunc: func(ctx context.Context, in *entryv1.BatchCreateEntryRequest, opts ...grpc.CallOption) (*entryv1.BatchCreateEntryResponse, error) {
+						return &entryv1.BatchCreateEntryResponse{
+							Results: []*entryv1.BatchCreateEntryResponse_Result{
+								{
+									Status: &types.Status{Code: int32(codes.AlreadyExists)},
+									Entry:  &types.Entry{Id: "auto-generated-dummy-id"},
 								},
 							},
-						})
-						return &entryv1.ListEntriesResponse{
-							Entries: []*types.Entry{{}},
 						}, nil
 					},
 					BatchUpdateEntryFunc: func(ctx context.Context, in *entryv1.BatchUpdateEntryRequest, opts ...grpc.CallOption) (*entryv1.BatchUpdateEntryResponse, error) {
 						require.ElementsMatch(t, in.Entries, []*types.Entry{
 							{
+								Id: "auto-generated-dummy-id",
 								SpiffeId: &types.SPIFFEID{
 									TrustDomain: "dummy.trusted.domain",
 									Path:        "/identity/dummy-id",
@@ -211,26 +161,58 @@ func TestClient_Upsert(t *testing.T) {
 								Selectors: defaultSelectors,
 							},
 						})
-						return &entryv1.BatchUpdateEntryResponse{}, nil
+						return &entryv1.BatchUpdateEntryResponse{
+							Results: []*entryv1.BatchUpdateEntryResponse_Result{
+								{Status: &types.Status{Code: int32(codes.OK)}},
+							},
+						}, nil
+					},
+				},
+			},
+			wantErrs: []bool{false},
+		},
+		{
+			name: "per-entry errors are index-aligned",
+			args: args{
+				reqs: []entryRequest{
+					{id: "good-id"},
+					{id: "bad-id"},
+				},
+			},
+			fields: fields{
+				entry: mockEntryClient{
+					BatchCreateEntryFunc: func(ctx context.Context, in *entryv1.BatchCreateEntryRequest, opts ...grpc.CallOption) (*entryv1.BatchCreateEntryResponse, error) {
+						return &entryv1.BatchCreateEntryResponse{
+							Results: []*entryv1.BatchCreateEntryResponse_Result{
+								{Status: &types.Status{Code: int32(codes.OK)}},
+								{Status: &types.Status{Code: int32(codes.Internal), Message: "something is wrong"}},
+							},
+						}, nil
 					},
 				},
 			},
+			wantErrs: []bool{false, true},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := &Client{
-				cfg:   cfg,
-				entry: tt.fields.entry,
+				cfg:       cfg,
+				entry:     tt.fields.entry,
+				selectors: defaultSelectors,
 			}
-			if err := c.Upsert(context.Background(), tt.args.id); (err != nil) != tt.wantErr {
-				t.Errorf("Upsert() error = %v, wantErr %v", err, tt.wantErr)
+			errs := c.upsertBatch(context.Background(), tt.args.reqs)
+			require.Len(t, errs, len(tt.wantErrs))
+			for i, wantErr := range tt.wantErrs {
+				if (errs[i] != nil) != wantErr {
+					t.Errorf("upsertBatch() error[%d] = %v, wantErr %v", i, errs[i], wantErr)
+				}
 			}
 		})
 	}
 }
 
-func TestClient_Delete(t *testing.T) {
+func TestClient_deleteBatch(t *testing.T) {
 	cfg := ClientConfig{
 		SpiffeTrustDomain: "dummy.trusted.domain",
 	}
@@ -238,153 +220,76 @@ func TestClient_Delete(t *testing.T) {
 		entry entryv1.EntryClient
 	}
 	type args struct {
-		id string
+		reqs []entryRequest
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		wantErr bool
+		name     string
+		fields   fields
+		args     args
+		wantErrs []bool
 	}{
 		{
-			name:    "client not initialized",
-			wantErr: true,
-		},
-		{
-			name: "unable to list entries due to unknown error",
+			name: "client not initialized",
 			args: args{
-				id: "dummy-id",
-			},
-			fields: fields{
-				entry: mockEntryClient{
-					ListEntriesFunc: func(ctx context.Context, in *entryv1.ListEntriesRequest, opts ...grpc.CallOption) (*entryv1.ListEntriesResponse, error) {
-						require.Equal(t, in, &entryv1.ListEntriesRequest{
-							Filter: &entryv1.ListEntriesRequest_Filter{
-								BySpiffeId: &types.SPIFFEID{
-									TrustDomain: "dummy.trusted.domain",
-									Path:        "/identity/dummy-id",
-								},
-								ByParentId: &types.SPIFFEID{
-									TrustDomain: "dummy.trusted.domain",
-									Path:        "/cilium-operator",
-								},
-								BySelectors: &types.SelectorMatch{
-									Selectors: []*types.Selector{
-										{
-											Type:  "cilium",
-											Value: "mutual-auth",
-										},
-									},
-									Match: types.SelectorMatch_MATCH_EXACT,
-								},
-							},
-						})
-						return nil, fmt.Errorf("something is wrong")
-					},
-				},
+				reqs: []entryRequest{{id: "dummy-id"}},
 			},
-			wantErr: true,
+			wantErrs: []bool{true},
 		},
 		{
-			name: "unable to list entries due to not found error",
+			name: "unable to list entries due to unknown error",
 			args: args{
-				id: "dummy-id",
+				reqs: []entryRequest{{id: "dummy-id"}},
 			},
 			fields: fields{
 				entry: mockEntryClient{
 					ListEntriesFunc: func(ctx context.Context, in *entryv1.ListEntriesRequest, opts ...grpc.CallOption) (*entryv1.ListEntriesResponse, error) {
 						require.Equal(t, in, &entryv1.ListEntriesRequest{
 							Filter: &entryv1.ListEntriesRequest_Filter{
-								BySpiffeId: &types.SPIFFEID{
-									TrustDomain: "dummy.trusted.domain",
-									Path:        "/identity/dummy-id",
-								},
 								ByParentId: &types.SPIFFEID{
 									TrustDomain: "dummy.trusted.domain",
 									Path:        "/cilium-operator",
 								},
 								BySelectors: &types.SelectorMatch{
-									Selectors: []*types.Selector{
-										{
-											Type:  "cilium",
-											Value: "mutual-auth",
-										},
-									},
-									Match: types.SelectorMatch_MATCH_EXACT,
+									Selectors: defaultSelectors,
+									Match:     types.SelectorMatch_MATCH_EXACT,
 								},
 							},
 						})
-						return nil, fmt.Errorf("NotFound")
+						return nil, fmt.Errorf("something is wrong")
 					},
 				},
 			},
+			wantErrs: []bool{true},
 		},
 		{
 			name: "entry does not exist",
 			args: args{
-				id: "dummy-id",
+				reqs: []entryRequest{{id: "dummy-id"}},
 			},
 			fields: fields{
 				entry: mockEntryClient{
 					ListEntriesFunc: func(ctx context.Context, in *entryv1.ListEntriesRequest, opts ...grpc.CallOption) (*entryv1.ListEntriesResponse, error) {
-						require.Equal(t, in, &entryv1.ListEntriesRequest{
-							Filter: &entryv1.ListEntriesRequest_Filter{
-								BySpiffeId: &types.SPIFFEID{
-									TrustDomain: "dummy.trusted.domain",
-									Path:        "/identity/dummy-id",
-								},
-								ByParentId: &types.SPIFFEID{
-									TrustDomain: "dummy.trusted.domain",
-									Path:        "/cilium-operator",
-								},
-								BySelectors: &types.SelectorMatch{
-									Selectors: []*types.Selector{
-										{
-											Type:  "cilium",
-											Value: "mutual-auth",
-										},
-									},
-									Match: types.SelectorMatch_MATCH_EXACT,
-								},
-							},
-						})
 						return &entryv1.ListEntriesResponse{}, nil
 					},
 				},
 			},
+			wantErrs: []bool{false},
 		},
 		{
 			name: "entry exists",
 			args: args{
-				id: "dummy-id",
+				reqs: []entryRequest{{id: "dummy-id"}},
 			},
 			fields: fields{
 				entry: mockEntryClient{
 					ListEntriesFunc: func(ctx context.Context, in *entryv1.ListEntriesRequest, opts ...grpc.CallOption) (*entryv1.ListEntriesResponse, error) {
-						require.Equal(t, in, &entryv1.ListEntriesRequest{
-							Filter: &entryv1.ListEntriesRequest_Filter{
-								BySpiffeId: &types.SPIFFEID{
-									TrustDomain: "dummy.trusted.domain",
-									Path:        "/identity/dummy-id",
-								},
-								ByParentId: &types.SPIFFEID{
-									TrustDomain: "dummy.trusted.domain",
-									Path:        "/cilium-operator",
-								},
-								BySelectors: &types.SelectorMatch{
-									Selectors: []*types.Selector{
-										{
-											Type:  "cilium",
-											Value: "mutual-auth",
-										},
-									},
-									Match: types.SelectorMatch_MATCH_EXACT,
-								},
-							},
-						})
 						return &entryv1.ListEntriesResponse{
 							Entries: []*types.Entry{{
 								Id: "auto-generated-dummy-id",
+								SpiffeId: &types.SPIFFEID{
+									TrustDomain: "dummy.trusted.domain",
+									Path:        "/identity/dummy-id",
+								},
 							}},
 						}, nil
 					},
@@ -392,20 +297,30 @@ func TestClient_Delete(t *testing.T) {
 						require.Equal(t, in, &entryv1.BatchDeleteEntryRequest{
 							Ids: []string{"auto-generated-dummy-id"},
 						})
-						return &entryv1.BatchDeleteEntryResponse{}, nil
+						return &entryv1.BatchDeleteEntryResponse{
+							Results: []*entryv1.BatchDeleteEntryResponse_Result{
+								{Id: "auto-generated-dummy-id", Status: &types.Status{Code: int32(codes.OK)}},
+							},
+						}, nil
 					},
 				},
 			},
+			wantErrs: []bool{false},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := &Client{
-				cfg:   cfg,
-				entry: tt.fields.entry,
+				cfg:       cfg,
+				entry:     tt.fields.entry,
+				selectors: defaultSelectors,
 			}
-			if err := c.Delete(context.Background(), tt.args.id); (err != nil) != tt.wantErr {
-				t.Errorf("Delete() error = %v, wantErr %v", err, tt.wantErr)
+			errs := c.deleteBatch(context.Background(), tt.args.reqs)
+			require.Len(t, errs, len(tt.wantErrs))
+			for i, wantErr := range tt.wantErrs {
+				if (errs[i] != nil) != wantErr {
+					t.Errorf("deleteBatch() error[%d] = %v, wantErr %v", i, errs[i], wantErr)
+				}
 			}
 		})
 	}
@@ -12,16 +12,18 @@ import (
 // NewFakeClient creates a new fake SPIRE client.
 func NewFakeClient() identity.Provider {
 	return fakeClient{
-		ids: map[string]struct{}{},
+		ids: map[string]string{},
 	}
 }
 
 type fakeClient struct {
-	ids map[string]struct{}
+	// ids maps the registered identities to the trust domain they were
+	// registered under, "" for the default trust domain.
+	ids map[string]string
 }
 
-func (n fakeClient) Upsert(_ context.Context, id string) error {
-	n.ids[id] = struct{}{}
+func (n fakeClient) Upsert(_ context.Context, id string, trustDomain string) error {
+	n.ids[id] = trustDomain
 	return nil
 }
 
@@ -37,3 +39,7 @@ func (n fakeClient) List(_ context.Context) ([]string, error) {
 	}
 	return ids, nil
 }
+
+func (n fakeClient) ListIdentities(_ context.Context) ([]string, error) {
+	return n.List(context.Background())
+}
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package spire
+
+import (
+	"github.com/cilium/cilium/pkg/metrics"
+	"github.com/cilium/cilium/pkg/metrics/metric"
+)
+
+// Metrics contains the metrics of the SPIRE client reflecting the state of
+// the entry registration pipeline.
+type Metrics struct {
+	EntryUpsertBacklog          metric.Gauge
+	EntryDeleteBacklog          metric.Gauge
+	EntryUpsertBacklogOldestAge metric.Gauge
+	EntryDeleteBacklogOldestAge metric.Gauge
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		EntryUpsertBacklog: metric.NewGauge(metric.GaugeOpts{
+			Namespace: metrics.CiliumOperatorNamespace,
+			Name:      "spire_entry_upsert_backlog",
+			Help:      "The number of SPIRE entry upserts queued but not yet sent to the SPIRE server",
+		}),
+
+		EntryDeleteBacklog: metric.NewGauge(metric.GaugeOpts{
+			Namespace: metrics.CiliumOperatorNamespace,
+			Name:      "spire_entry_delete_backlog",
+			Help:      "The number of SPIRE entry deletions queued but not yet sent to the SPIRE server",
+		}),
+
+		EntryUpsertBacklogOldestAge: metric.NewGauge(metric.GaugeOpts{
+			Namespace: metrics.CiliumOperatorNamespace,
+			Name:      "spire_entry_upsert_backlog_oldest_age_seconds",
+			Help:      "The age in seconds of the oldest SPIRE entry upsert still queued (0 if the backlog is empty)",
+		}),
+
+		EntryDeleteBacklogOldestAge: metric.NewGauge(metric.GaugeOpts{
+			Namespace: metrics.CiliumOperatorNamespace,
+			Name:      "spire_entry_delete_backlog_oldest_age_seconds",
+			Help:      "The age in seconds of the oldest SPIRE entry deletion still queued (0 if the backlog is empty)",
+		}),
+	}
+}
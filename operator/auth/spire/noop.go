@@ -8,7 +8,7 @@ import "context"
 type noopClient struct {
 }
 
-func (n noopClient) Upsert(_ context.Context, _ string) error {
+func (n noopClient) Upsert(_ context.Context, _ string, _ string) error {
 	return nil
 }
 
@@ -19,3 +19,7 @@ func (n noopClient) Delete(_ context.Context, _ string) error {
 func (n noopClient) List(_ context.Context) ([]string, error) {
 	return nil, nil
 }
+
+func (n noopClient) ListIdentities(_ context.Context) ([]string, error) {
+	return nil, nil
+}
@@ -5,14 +5,36 @@ package auth
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/cilium/hive/cell"
 	"github.com/cilium/workerpool"
 	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
 
 	"github.com/cilium/cilium/operator/auth/identity"
+	ciliumidentity "github.com/cilium/cilium/pkg/identity"
+	k8sConst "github.com/cilium/cilium/pkg/k8s/apis/cilium.io"
 	ciliumv2 "github.com/cilium/cilium/pkg/k8s/apis/cilium.io/v2"
+	k8sClient "github.com/cilium/cilium/pkg/k8s/client"
 	"github.com/cilium/cilium/pkg/k8s/resource"
+	"github.com/cilium/cilium/pkg/labels"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+const (
+	// AnnotationRegistrationStatus reflects the SPIRE registration state of a
+	// CiliumIdentity ("pending", "registered" or "failed").
+	AnnotationRegistrationStatus = "mesh-auth.cilium.io/registration-status"
+
+	// AnnotationRegistrationReason carries the reason of a failed SPIRE
+	// registration.
+	AnnotationRegistrationReason = "mesh-auth.cilium.io/registration-reason"
+
+	registrationStatusPending    = "pending"
+	registrationStatusRegistered = "registered"
+	registrationStatusFailed     = "failed"
 )
 
 // params contains all the dependencies for the identity-gc.
@@ -24,6 +46,8 @@ type params struct {
 	Lifecycle      cell.Lifecycle
 	IdentityClient identity.Provider
 	Identity       resource.Resource[*ciliumv2.CiliumIdentity]
+	Clientset      k8sClient.Clientset
+	Metrics        *Metrics
 
 	Cfg Config
 }
@@ -35,8 +59,10 @@ type IdentityWatcher struct {
 
 	identityClient identity.Provider
 	identity       resource.Resource[*ciliumv2.CiliumIdentity]
+	clientset      k8sClient.Clientset
 	wg             *workerpool.WorkerPool
 	cfg            Config
+	metrics        *Metrics
 }
 
 func registerIdentityWatcher(p params) {
@@ -47,13 +73,34 @@ func registerIdentityWatcher(p params) {
 		logger:         p.Logger,
 		identityClient: p.IdentityClient,
 		identity:       p.Identity,
-		wg:             workerpool.New(1),
+		clientset:      p.Clientset,
+		wg:             workerpool.New(3),
 		cfg:            p.Cfg,
+		metrics:        p.Metrics,
 	}
 	p.Lifecycle.Append(cell.Hook{
 		OnStart: func(ctx cell.HookContext) error {
-			return iw.wg.Submit("identity-watcher", func(ctx context.Context) error {
+			if err := iw.wg.Submit("identity-watcher", func(ctx context.Context) error {
 				return iw.run(ctx)
+			}); err != nil {
+				return err
+			}
+			if p.Cfg.NodeIdentities {
+				if err := iw.wg.Submit("node-identity-registration", func(ctx context.Context) error {
+					return iw.registerNodeIdentities(ctx)
+				}); err != nil {
+					return err
+				}
+			}
+			if p.Cfg.SpireEntryGCInterval > 0 {
+				if err := iw.wg.Submit("identity-entry-gc", func(ctx context.Context) error {
+					return iw.gc(ctx)
+				}); err != nil {
+					return err
+				}
+			}
+			return iw.wg.Submit("spire-health-monitor", func(ctx context.Context) error {
+				return iw.monitorHealth(ctx)
 			})
 		},
 		OnStop: func(_ cell.HookContext) error {
@@ -67,13 +114,188 @@ func (iw *IdentityWatcher) run(ctx context.Context) error {
 		var err error
 		switch e.Kind {
 		case resource.Upsert:
-			err = iw.identityClient.Upsert(ctx, e.Object.GetName())
+			iw.markRegistrationPending(ctx, e.Object)
+			err = iw.identityClient.Upsert(ctx, e.Object.GetName(), iw.trustDomainFor(e.Object))
 			iw.logger.WithError(err).WithField("identity", e.Object.GetName()).Info("Upsert identity")
+			iw.observeOperation(LabelValueOperationUpsert, err)
+			iw.updateRegistrationStatus(ctx, e.Object, err)
 		case resource.Delete:
 			err = iw.identityClient.Delete(ctx, e.Object.GetName())
 			iw.logger.WithError(err).WithField("identity", e.Object.GetName()).Info("Delete identity")
+			iw.observeOperation(LabelValueOperationDelete, err)
 		}
 		e.Done(err)
 	}
 	return nil
 }
+
+// trustDomainFor returns the tenant trust domain the given identity is
+// issued under, or "" for the default trust domain. The tenant is derived
+// from the namespace label of the identity, so all workload identities of a
+// mapped namespace end up in the trust domain of that tenant.
+func (iw *IdentityWatcher) trustDomainFor(id *ciliumv2.CiliumIdentity) string {
+	if len(iw.cfg.TenantTrustDomains) == 0 {
+		return ""
+	}
+	namespace := id.SecurityLabels[labels.LabelSourceK8s+":"+k8sConst.PodNamespaceLabel]
+	return iw.cfg.TenantTrustDomains[namespace]
+}
+
+// nodeIdentities are the reserved identities covering node-to-node traffic.
+var nodeIdentities = []ciliumidentity.NumericIdentity{
+	ciliumidentity.ReservedIdentityHost,
+	ciliumidentity.ReservedIdentityRemoteNode,
+}
+
+// registerNodeIdentities issues SPIFFE identities for the reserved host and
+// remote-node identities. Unlike workload identities these are not backed by
+// CiliumIdentity objects, so they are registered once at startup instead of
+// through the identity watcher.
+func (iw *IdentityWatcher) registerNodeIdentities(ctx context.Context) error {
+	for _, id := range nodeIdentities {
+		err := iw.identityClient.Upsert(ctx, id.StringID(), "")
+		iw.logger.WithError(err).WithField("identity", id).Info("Upsert node identity")
+		iw.observeOperation(LabelValueOperationUpsert, err)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markRegistrationPending annotates an identity seen for the first time as
+// pending, so users can tell it has not been registered in SPIRE yet.
+func (iw *IdentityWatcher) markRegistrationPending(ctx context.Context, id *ciliumv2.CiliumIdentity) {
+	if _, ok := id.Annotations[AnnotationRegistrationStatus]; ok {
+		return
+	}
+	iw.patchRegistrationAnnotations(ctx, id.GetName(), registrationStatusPending, "")
+}
+
+// updateRegistrationStatus reflects the outcome of a SPIRE registration in
+// the annotations of the identity, so users can see why mutual auth for a
+// workload is not working without digging through operator logs.
+func (iw *IdentityWatcher) updateRegistrationStatus(ctx context.Context, id *ciliumv2.CiliumIdentity, upsertErr error) {
+	status := registrationStatusRegistered
+	reason := ""
+	if upsertErr != nil {
+		status = registrationStatusFailed
+		reason = upsertErr.Error()
+	}
+	if id.Annotations[AnnotationRegistrationStatus] == status &&
+		id.Annotations[AnnotationRegistrationReason] == reason {
+		return
+	}
+	iw.patchRegistrationAnnotations(ctx, id.GetName(), status, reason)
+}
+
+func (iw *IdentityWatcher) patchRegistrationAnnotations(ctx context.Context, name, status, reason string) {
+	annotations := map[string]any{
+		AnnotationRegistrationStatus: status,
+	}
+	if reason != "" {
+		annotations[AnnotationRegistrationReason] = reason
+	} else {
+		// A nil value removes the annotation from a JSON merge patch.
+		annotations[AnnotationRegistrationReason] = nil
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		iw.logger.WithError(err).WithField("identity", name).Warning("Failed to marshal registration status patch")
+		return
+	}
+
+	_, err = iw.clientset.CiliumV2().CiliumIdentities().Patch(ctx, name, k8stypes.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		iw.logger.WithError(err).WithField("identity", name).Warning("Failed to update registration status annotations")
+	}
+}
+
+// spireHealthInterval is the interval in which the connection to the SPIRE
+// server gets probed.
+const spireHealthInterval = 1 * time.Minute
+
+func (iw *IdentityWatcher) observeOperation(operation string, err error) {
+	outcome := LabelValueOutcomeSuccess
+	if err != nil {
+		outcome = LabelValueOutcomeFail
+	}
+	iw.metrics.SpireEntryOperations.WithLabelValues(operation, outcome).Inc()
+}
+
+// monitorHealth periodically probes the SPIRE server connection and reflects
+// its state in the spire_server_reachable metric.
+func (iw *IdentityWatcher) monitorHealth(ctx context.Context) error {
+	ticker := time.NewTicker(spireHealthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		if _, err := iw.identityClient.ListIdentities(ctx); err != nil {
+			iw.logger.WithError(err).Warning("SPIRE server is not reachable")
+			iw.metrics.SpireServerReachable.Set(0)
+		} else {
+			iw.metrics.SpireServerReachable.Set(1)
+		}
+	}
+}
+
+// gc periodically removes SPIRE entries which no longer have a matching
+// Cilium identity. Such stale entries can accumulate when identities get
+// deleted while the operator is down.
+func (iw *IdentityWatcher) gc(ctx context.Context) error {
+	store, err := iw.identity.Store(ctx)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(iw.cfg.SpireEntryGCInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		registered, err := iw.identityClient.ListIdentities(ctx)
+		if err != nil {
+			iw.logger.WithError(err).Warning("Failed to list identities registered in SPIRE")
+			continue
+		}
+
+		live := map[string]struct{}{}
+		for _, id := range store.List() {
+			live[id.GetName()] = struct{}{}
+		}
+		if iw.cfg.NodeIdentities {
+			// Node identities have no CiliumIdentity object and must not be
+			// treated as stale.
+			for _, id := range nodeIdentities {
+				live[id.StringID()] = struct{}{}
+			}
+		}
+
+		for _, id := range registered {
+			if _, ok := live[id]; ok {
+				continue
+			}
+			if err := iw.identityClient.Delete(ctx, id); err != nil {
+				iw.logger.WithError(err).WithField("identity", id).Warning("Failed to delete stale SPIRE entry")
+				continue
+			}
+			iw.logger.WithField("identity", id).Info("Deleted stale SPIRE entry")
+		}
+	}
+}
@@ -214,10 +214,10 @@ func setupCiliumIdentities(clientset k8sClient.Clientset) error {
 }
 
 func setupAuthIdentities(client authIdentity.Provider) error {
-	if err := client.Upsert(context.Background(), "88888"); err != nil {
+	if err := client.Upsert(context.Background(), "88888", ""); err != nil {
 		return err
 	}
-	if err := client.Upsert(context.Background(), "99999"); err != nil {
+	if err := client.Upsert(context.Background(), "99999", ""); err != nil {
 		return err
 	}
 	return nil
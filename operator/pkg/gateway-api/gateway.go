@@ -44,15 +44,20 @@ type gatewayReconciler struct {
 
 	logger        *slog.Logger
 	installedCRDs []schema.GroupVersionKind
+
+	// requireBackendMutualAuth gates the Programmed condition of a Gateway
+	// on established mutual authentication to its backend pods.
+	requireBackendMutualAuth bool
 }
 
-func newGatewayReconciler(mgr ctrl.Manager, translator translation.Translator, logger *slog.Logger, installedCRDs []schema.GroupVersionKind) *gatewayReconciler {
+func newGatewayReconciler(mgr ctrl.Manager, translator translation.Translator, logger *slog.Logger, installedCRDs []schema.GroupVersionKind, requireBackendMutualAuth bool) *gatewayReconciler {
 	return &gatewayReconciler{
-		Client:        mgr.GetClient(),
-		Scheme:        mgr.GetScheme(),
-		translator:    translator,
-		logger:        logger,
-		installedCRDs: installedCRDs,
+		Client:                   mgr.GetClient(),
+		Scheme:                   mgr.GetScheme(),
+		translator:               translator,
+		logger:                   logger,
+		installedCRDs:            installedCRDs,
+		requireBackendMutualAuth: requireBackendMutualAuth,
 	}
 }
 
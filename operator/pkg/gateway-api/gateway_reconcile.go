@@ -182,6 +182,25 @@ func (r *gatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return r.handleReconcileErrorWithStatus(ctx, err, original, gw)
 	}
 
+	// Step 5: Optionally gate the Gateway on established mutual
+	// authentication to its backends
+	if r.requireBackendMutualAuth {
+		authenticated, msg, err := r.checkBackendsMutualAuth(ctx, httpListeners, tlsPassthroughListeners)
+		if err != nil {
+			scopedLog.ErrorContext(ctx, "Unable to check backend mutual authentication", logfields.Error, err)
+			return r.handleReconcileErrorWithStatus(ctx, err, original, gw)
+		}
+		setGatewayBackendsAuthenticated(gw, authenticated, msg)
+		if !authenticated {
+			scopedLog.InfoContext(ctx, "Backends are not yet mutually authenticated, requeueing", "status", msg)
+			setGatewayProgrammed(gw, false, msg, gatewayv1.GatewayReasonPending)
+			if err := r.updateStatus(ctx, original, gw); err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to update Gateway status: %w", err)
+			}
+			return ctrl.Result{RequeueAfter: backendAuthRequeueInterval}, nil
+		}
+	}
+
 	setGatewayProgrammed(gw, true, "Gateway successfully reconciled", gatewayv1.GatewayReasonProgrammed)
 	if err := r.updateStatus(ctx, original, gw); err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to update Gateway status: %w", err)
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package gateway_api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/cilium/cilium/operator/pkg/model"
+	ciliumv2 "github.com/cilium/cilium/pkg/k8s/apis/cilium.io/v2"
+)
+
+// conditionBackendsAuthenticated is the Cilium-specific Gateway status
+// condition reporting whether mutual authentication to the pods backing the
+// Gateway's routes has been established. The condition is based on the auth
+// status the agents sync into the CiliumEndpoint objects.
+const conditionBackendsAuthenticated = "cilium.io/BackendsMutualAuthenticated"
+
+const (
+	reasonBackendsAuthenticated         = "BackendsMutualAuthenticated"
+	reasonBackendsAuthenticationPending = "BackendsMutualAuthenticationPending"
+)
+
+// backendAuthRequeueInterval is the interval at which a Gateway with not yet
+// authenticated backends is re-checked. Backend authentication converges
+// without a Gateway event once the agents established it, so the reconciler
+// has to poll.
+const backendAuthRequeueInterval = 30 * time.Second
+
+// setGatewayBackendsAuthenticated inserts or updates the Cilium-specific
+// BackendsMutualAuthenticated condition for the provided Gateway resource.
+func setGatewayBackendsAuthenticated(gw *gatewayv1.Gateway, authenticated bool, msg string) *gatewayv1.Gateway {
+	status := metav1.ConditionTrue
+	reason := reasonBackendsAuthenticated
+	if !authenticated {
+		status = metav1.ConditionFalse
+		reason = reasonBackendsAuthenticationPending
+	}
+	gw.Status.Conditions = merge(gw.Status.Conditions, metav1.Condition{
+		Type:               conditionBackendsAuthenticated,
+		Status:             status,
+		Reason:             reason,
+		Message:            msg,
+		ObservedGeneration: gw.GetGeneration(),
+		LastTransitionTime: metav1.NewTime(time.Now()),
+	})
+	return gw
+}
+
+// gatewayBackends returns the distinct backend services referenced by the
+// routes of the given listeners.
+func gatewayBackends(httpListeners []model.HTTPListener, tlsPassthroughListeners []model.TLSPassthroughListener) []client.ObjectKey {
+	seen := map[client.ObjectKey]struct{}{}
+	var backends []client.ObjectKey
+
+	add := func(backend model.Backend) {
+		key := client.ObjectKey{Namespace: backend.Namespace, Name: backend.Name}
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			backends = append(backends, key)
+		}
+	}
+
+	for _, listener := range httpListeners {
+		for _, route := range listener.Routes {
+			for _, backend := range route.Backends {
+				add(backend)
+			}
+		}
+	}
+	for _, listener := range tlsPassthroughListeners {
+		for _, route := range listener.Routes {
+			for _, backend := range route.Backends {
+				add(backend)
+			}
+		}
+	}
+
+	return backends
+}
+
+// checkBackendsMutualAuth reports whether every pod backing the Gateway's
+// backend services holds an established mutual authentication with at least
+// one peer identity. Pods whose CiliumEndpoint does not report an auth status
+// yet are treated as not authenticated.
+func (r *gatewayReconciler) checkBackendsMutualAuth(ctx context.Context, httpListeners []model.HTTPListener, tlsPassthroughListeners []model.TLSPassthroughListener) (bool, string, error) {
+	var total, authenticated int
+
+	for _, backend := range gatewayBackends(httpListeners, tlsPassthroughListeners) {
+		eps := &corev1.Endpoints{}
+		if err := r.Client.Get(ctx, backend, eps); err != nil {
+			if k8serrors.IsNotFound(err) {
+				return false, fmt.Sprintf("Endpoints of backend %s not found", backend), nil
+			}
+			return false, "", err
+		}
+
+		for _, subset := range eps.Subsets {
+			for _, addr := range subset.Addresses {
+				if addr.TargetRef == nil || addr.TargetRef.Kind != "Pod" {
+					continue
+				}
+				total++
+
+				cep := &ciliumv2.CiliumEndpoint{}
+				key := client.ObjectKey{Namespace: backend.Namespace, Name: addr.TargetRef.Name}
+				if err := r.Client.Get(ctx, key, cep); err != nil {
+					if k8serrors.IsNotFound(err) {
+						continue
+					}
+					return false, "", err
+				}
+				if cep.Status.Auth != nil && cep.Status.Auth.AuthenticatedPeers > 0 {
+					authenticated++
+				}
+			}
+		}
+	}
+
+	if total == 0 {
+		return false, "No backend pods available yet", nil
+	}
+	if authenticated < total {
+		return false, fmt.Sprintf("%d of %d backend pods are mutually authenticated", authenticated, total), nil
+	}
+	return true, fmt.Sprintf("All %d backend pods are mutually authenticated", total), nil
+}
@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package gateway_api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cilium/hive/hivetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/cilium/cilium/operator/pkg/model"
+	ciliumv2 "github.com/cilium/cilium/pkg/k8s/apis/cilium.io/v2"
+)
+
+func backendEndpoints(podNames ...string) *corev1.Endpoints {
+	var addresses []corev1.EndpointAddress
+	for _, name := range podNames {
+		addresses = append(addresses, corev1.EndpointAddress{
+			IP: "10.0.0.1",
+			TargetRef: &corev1.ObjectReference{
+				Kind:      "Pod",
+				Name:      name,
+				Namespace: "default",
+			},
+		})
+	}
+	return &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dummy-backend",
+			Namespace: "default",
+		},
+		Subsets: []corev1.EndpointSubset{
+			{Addresses: addresses},
+		},
+	}
+}
+
+func backendCiliumEndpoint(podName string, authenticatedPeers int) *ciliumv2.CiliumEndpoint {
+	cep := &ciliumv2.CiliumEndpoint{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      podName,
+			Namespace: "default",
+		},
+	}
+	if authenticatedPeers >= 0 {
+		cep.Status.Auth = &ciliumv2.EndpointAuthStatus{
+			AuthenticatedPeers: authenticatedPeers,
+		}
+	}
+	return cep
+}
+
+func Test_gatewayReconciler_checkBackendsMutualAuth(t *testing.T) {
+	listeners := []model.HTTPListener{
+		{
+			Routes: []model.HTTPRoute{
+				{
+					Backends: []model.Backend{
+						{Name: "dummy-backend", Namespace: "default"},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name              string
+		objects           []client.Object
+		wantAuthenticated bool
+		wantMsg           string
+	}{
+		{
+			name:              "missing backend Endpoints",
+			objects:           nil,
+			wantAuthenticated: false,
+			wantMsg:           "Endpoints of backend default/dummy-backend not found",
+		},
+		{
+			name:              "no backend pods",
+			objects:           []client.Object{backendEndpoints()},
+			wantAuthenticated: false,
+			wantMsg:           "No backend pods available yet",
+		},
+		{
+			name:              "backend pod without CiliumEndpoint",
+			objects:           []client.Object{backendEndpoints("pod-1")},
+			wantAuthenticated: false,
+			wantMsg:           "0 of 1 backend pods are mutually authenticated",
+		},
+		{
+			name: "backend pod without auth status",
+			objects: []client.Object{
+				backendEndpoints("pod-1"),
+				backendCiliumEndpoint("pod-1", -1),
+			},
+			wantAuthenticated: false,
+			wantMsg:           "0 of 1 backend pods are mutually authenticated",
+		},
+		{
+			name: "partially authenticated backend pods",
+			objects: []client.Object{
+				backendEndpoints("pod-1", "pod-2"),
+				backendCiliumEndpoint("pod-1", 1),
+				backendCiliumEndpoint("pod-2", 0),
+			},
+			wantAuthenticated: false,
+			wantMsg:           "1 of 2 backend pods are mutually authenticated",
+		},
+		{
+			name: "all backend pods authenticated",
+			objects: []client.Object{
+				backendEndpoints("pod-1", "pod-2"),
+				backendCiliumEndpoint("pod-1", 1),
+				backendCiliumEndpoint("pod-2", 2),
+			},
+			wantAuthenticated: true,
+			wantMsg:           "All 2 backend pods are mutually authenticated",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().
+				WithScheme(testScheme()).
+				WithObjects(tt.objects...).
+				Build()
+
+			r := &gatewayReconciler{
+				Client:                   c,
+				logger:                   hivetest.Logger(t),
+				requireBackendMutualAuth: true,
+			}
+
+			authenticated, msg, err := r.checkBackendsMutualAuth(context.Background(), listeners, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAuthenticated, authenticated)
+			assert.Equal(t, tt.wantMsg, msg)
+		})
+	}
+}
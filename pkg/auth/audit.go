@@ -0,0 +1,188 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/syslog"
+	"os"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/cilium/cilium/pkg/lock"
+	monitorAPI "github.com/cilium/cilium/pkg/monitor/api"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// Security teams need a durable trail of who authenticated to whom. The
+// auditor turns every handshake - successful or not - into a structured
+// record and fans it out to the configured sinks. Failures to write an audit
+// record never fail the handshake, they are logged and the authentication
+// result stands.
+
+const (
+	auditResultSuccess = "success"
+	auditResultFailure = "failure"
+)
+
+// authAuditRecord is the structured record emitted for every handshake.
+type authAuditRecord struct {
+	Timestamp       time.Time     `json:"timestamp"`
+	Role            string        `json:"role"`
+	Result          string        `json:"result"`
+	Error           string        `json:"error,omitempty"`
+	LocalIdentity   uint32        `json:"local_identity,omitempty"`
+	RemoteIdentity  uint32        `json:"remote_identity,omitempty"`
+	RemoteNodeIP    string        `json:"remote_node_ip,omitempty"`
+	SNI             string        `json:"sni,omitempty"`
+	Latency         time.Duration `json:"latency_ns"`
+	LocalCertSerial string        `json:"local_cert_serial,omitempty"`
+	PeerCertSerial  string        `json:"peer_cert_serial,omitempty"`
+	Expiration      time.Time     `json:"expiration,omitempty"`
+}
+
+// auditSink writes audit records to one output.
+type auditSink interface {
+	name() string
+	submit(record *authAuditRecord) error
+}
+
+// authAuditor fans out audit records to the configured sinks. A nil auditor
+// discards all records.
+type authAuditor struct {
+	log   logrus.FieldLogger
+	sinks []auditSink
+}
+
+func newAuthAuditor(log logrus.FieldLogger, cfg MutualAuthConfig, notifier authNotifier) *authAuditor {
+	var sinks []auditSink
+	if cfg.MutualAuthAuditFile != "" {
+		sinks = append(sinks, &fileAuditSink{path: cfg.MutualAuthAuditFile})
+	}
+	if cfg.MutualAuthAuditSyslog {
+		sinks = append(sinks, &syslogAuditSink{})
+	}
+	if cfg.MutualAuthAuditMonitor && notifier != nil {
+		sinks = append(sinks, &monitorAuditSink{notifier: notifier})
+	}
+	if len(sinks) == 0 {
+		return nil
+	}
+
+	return &authAuditor{
+		log:   log,
+		sinks: sinks,
+	}
+}
+
+// record submits the given record to all configured sinks.
+func (a *authAuditor) record(record *authAuditRecord) {
+	if a == nil {
+		return
+	}
+
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now()
+	}
+
+	for _, sink := range a.sinks {
+		if err := sink.submit(record); err != nil {
+			a.log.
+				WithError(err).
+				WithField("sink", sink.name()).
+				Warning("Failed to write authentication audit record")
+		}
+	}
+}
+
+// fileAuditSink appends one JSON record per line to a file. The file is
+// opened lazily and kept open, log rotation has to truncate or copy it.
+type fileAuditSink struct {
+	path string
+
+	mutex lock.Mutex
+	file  *os.File
+}
+
+func (s *fileAuditSink) name() string { return "file" }
+
+func (s *fileAuditSink) submit(record *authAuditRecord) error {
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.file == nil {
+		f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("failed to open audit log: %w", err)
+		}
+		s.file = f
+	}
+
+	if _, err := s.file.Write(append(encoded, '\n')); err != nil {
+		// Reopen on the next record, the file may have been rotated away.
+		s.file.Close()
+		s.file = nil
+		return fmt.Errorf("failed to append audit record: %w", err)
+	}
+	return nil
+}
+
+// syslogAuditSink writes records to the local syslog daemon with the
+// AUTHPRIV facility.
+type syslogAuditSink struct {
+	mutex  lock.Mutex
+	writer *syslog.Writer
+}
+
+func (s *syslogAuditSink) name() string { return "syslog" }
+
+func (s *syslogAuditSink) submit(record *authAuditRecord) error {
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.writer == nil {
+		w, err := syslog.New(syslog.LOG_AUTHPRIV|syslog.LOG_INFO, "cilium-mesh-auth")
+		if err != nil {
+			return fmt.Errorf("failed to connect to syslog: %w", err)
+		}
+		s.writer = w
+	}
+
+	if err := s.writer.Info(string(encoded)); err != nil {
+		s.writer.Close()
+		s.writer = nil
+		return fmt.Errorf("failed to write audit record to syslog: %w", err)
+	}
+	return nil
+}
+
+// monitorAuditSink publishes records on the monitor event bus, where Hubble
+// and other monitor listeners consume them.
+type monitorAuditSink struct {
+	notifier authNotifier
+}
+
+func (s *monitorAuditSink) name() string { return "monitor" }
+
+func (s *monitorAuditSink) submit(record *authAuditRecord) error {
+	typ := monitorAPI.AgentNotifyAuthSuccess
+	if record.Result == auditResultFailure {
+		typ = monitorAPI.AgentNotifyAuthFail
+	}
+	return s.notifier.SendEvent(monitorAPI.MessageTypeAgent, monitorAPI.AgentNotifyMessage{
+		Type:         typ,
+		Notification: record,
+	})
+}
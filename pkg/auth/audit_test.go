@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	monitorAPI "github.com/cilium/cilium/pkg/monitor/api"
+)
+
+func Test_newAuthAuditor(t *testing.T) {
+	log := logrus.New()
+
+	assert.Nil(t, newAuthAuditor(log, MutualAuthConfig{}, nil))
+	assert.Nil(t, newAuthAuditor(log, MutualAuthConfig{MutualAuthAuditMonitor: true}, nil),
+		"monitor sink without a notifier must not enable the auditor")
+
+	auditor := newAuthAuditor(log, MutualAuthConfig{
+		MutualAuthAuditFile:    "/tmp/audit.log",
+		MutualAuthAuditSyslog:  true,
+		MutualAuthAuditMonitor: true,
+	}, &fakeAuthNotifier{})
+	assert.NotNil(t, auditor)
+	assert.Len(t, auditor.sinks, 3)
+}
+
+func Test_authAuditor_record_nil(t *testing.T) {
+	var auditor *authAuditor
+	auditor.record(&authAuditRecord{Result: auditResultSuccess}) // must not panic
+}
+
+func Test_fileAuditSink_submit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink := &fileAuditSink{path: path}
+
+	assert.NoError(t, sink.submit(&authAuditRecord{
+		Role:           receiptRoleInitiator,
+		Result:         auditResultSuccess,
+		LocalIdentity:  1000,
+		RemoteIdentity: 2000,
+		RemoteNodeIP:   "172.18.0.2",
+		SNI:            "2000.test.cilium.io",
+	}))
+	assert.NoError(t, sink.submit(&authAuditRecord{
+		Role:   receiptRoleListener,
+		Result: auditResultFailure,
+		Error:  "handshake failed",
+	}))
+
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	var records []authAuditRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record authAuditRecord
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	assert.NoError(t, scanner.Err())
+
+	assert.Len(t, records, 2)
+	assert.Equal(t, auditResultSuccess, records[0].Result)
+	assert.Equal(t, uint32(1000), records[0].LocalIdentity)
+	assert.Equal(t, uint32(2000), records[0].RemoteIdentity)
+	assert.Equal(t, "172.18.0.2", records[0].RemoteNodeIP)
+	assert.Equal(t, "2000.test.cilium.io", records[0].SNI)
+	assert.Equal(t, auditResultFailure, records[1].Result)
+	assert.Equal(t, "handshake failed", records[1].Error)
+}
+
+func Test_monitorAuditSink_submit(t *testing.T) {
+	notifier := &fakeAuthNotifier{}
+	sink := &monitorAuditSink{notifier: notifier}
+
+	assert.NoError(t, sink.submit(&authAuditRecord{Result: auditResultSuccess}))
+	assert.NoError(t, sink.submit(&authAuditRecord{Result: auditResultFailure, Error: "handshake failed"}))
+
+	assert.Len(t, notifier.events, 2)
+	assert.Equal(t, monitorAPI.AgentNotifyAuthSuccess, notifier.events[0].Type)
+	assert.Equal(t, monitorAPI.AgentNotifyAuthFail, notifier.events[1].Type)
+}
@@ -10,11 +10,12 @@ import (
 
 	"github.com/cilium/ebpf"
 	"github.com/sirupsen/logrus"
+	"k8s.io/utils/clock"
 
 	"github.com/cilium/cilium/pkg/lock"
 	"github.com/cilium/cilium/pkg/maps/authmap"
 	"github.com/cilium/cilium/pkg/metrics"
-	"github.com/cilium/cilium/pkg/time"
+	"github.com/cilium/cilium/pkg/metrics/metric"
 )
 
 type authMapCache struct {
@@ -23,6 +24,9 @@ type authMapCache struct {
 	cacheEntries      map[authKey]authInfoCache
 	cacheEntriesMutex lock.RWMutex
 	pressureGauge     *metrics.GaugeWithThreshold
+	// entriesGauge exposes the current number of cached entries.
+	entriesGauge metric.Gauge
+	clock        clock.PassiveClock
 }
 
 func newAuthMapCache(logger logrus.FieldLogger, authMap authMap) *authMapCache {
@@ -36,6 +40,7 @@ func newAuthMapCache(logger logrus.FieldLogger, authMap authMap) *authMapCache {
 		authmap:       authMap,
 		cacheEntries:  map[authKey]authInfoCache{},
 		pressureGauge: pressureGauge,
+		clock:         clock.RealClock{},
 	}
 }
 
@@ -76,7 +81,7 @@ func (r *authMapCache) Update(key authKey, info authInfo) error {
 
 	r.cacheEntries[key] = authInfoCache{
 		authInfo: info,
-		storedAt: time.Now(),
+		storedAt: r.clock.Now(),
 	}
 	r.updatePressureMetric()
 
@@ -139,7 +144,7 @@ func (r *authMapCache) restoreCache() error {
 	for k, v := range all {
 		r.cacheEntries[k] = authInfoCache{
 			authInfo: v,
-			storedAt: time.Now(),
+			storedAt: r.clock.Now(),
 		}
 	}
 
@@ -151,6 +156,9 @@ func (r *authMapCache) restoreCache() error {
 }
 
 func (r *authMapCache) updatePressureMetric() {
+	if r.entriesGauge != nil {
+		r.entriesGauge.Set(float64(len(r.cacheEntries)))
+	}
 	if r.pressureGauge == nil {
 		return
 	}
@@ -9,12 +9,14 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/clock"
 
 	"github.com/cilium/cilium/pkg/policy"
 )
 
 func Test_authMapCache_restoreCache(t *testing.T) {
 	am := authMapCache{
+		clock:  clock.RealClock{},
 		logger: logrus.New(),
 		authmap: &fakeAuthMap{
 			entries: map[authKey]authInfo{
@@ -48,6 +50,7 @@ func Test_authMapCache_restoreCache(t *testing.T) {
 
 func Test_authMapCache_allReturnsCopy(t *testing.T) {
 	am := authMapCache{
+		clock:  clock.RealClock{},
 		logger: logrus.New(),
 		authmap: &fakeAuthMap{
 			entries: map[authKey]authInfo{},
@@ -95,6 +98,7 @@ func Test_authMapCache_Delete(t *testing.T) {
 		},
 	}
 	am := authMapCache{
+		clock:   clock.RealClock{},
 		logger:  logrus.New(),
 		authmap: fakeMap,
 		cacheEntries: map[authKey]authInfoCache{
@@ -173,6 +177,7 @@ func Test_authMapCache_DeleteIf(t *testing.T) {
 		},
 	}
 	am := authMapCache{
+		clock:   clock.RealClock{},
 		logger:  logrus.New(),
 		authmap: fakeMap,
 		cacheEntries: map[authKey]authInfoCache{
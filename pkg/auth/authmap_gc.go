@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"github.com/sirupsen/logrus"
+	"k8s.io/utils/clock"
 
 	datapathTypes "github.com/cilium/cilium/pkg/datapath/types"
 	"github.com/cilium/cilium/pkg/endpoint"
@@ -23,11 +24,32 @@ import (
 	"github.com/cilium/cilium/pkg/time"
 )
 
+// Behaviors of the garbage collector towards the entries of deleted
+// identities, see MeshAuthGCIdentityBehavior: purge them on the next sweep,
+// or let them age out with their expiration. The latter avoids dropping
+// authenticated pairs over short-lived identity churn, at the cost of stale
+// entries occupying the map until they expire.
+const (
+	gcIdentityBehaviorPurge  = "purge"
+	gcIdentityBehaviorExpire = "expire"
+)
+
 type authMapGarbageCollector struct {
 	logger        logrus.FieldLogger
 	authmap       authMap
 	nodeIDHandler datapathTypes.NodeIDHandler
 	policyRepo    policyRepository
+	clock         clock.PassiveClock
+	metrics       *gcMetrics
+
+	// expiredGracePeriod keeps expired entries for the given duration past
+	// their expiration, so pairs re-authenticating right around the expiry
+	// are not dropped by a sweep racing the re-authentication.
+	expiredGracePeriod time.Duration
+
+	// identityDeletionBehavior selects what happens to the entries of
+	// deleted identities, see the gcIdentityBehavior constants.
+	identityDeletionBehavior string
 
 	ciliumNodesMutex      lock.Mutex
 	ciliumNodesDiscovered map[uint16]struct{}
@@ -58,6 +80,7 @@ func newAuthMapGC(logger logrus.FieldLogger, authmap authMap, nodeIDHandler data
 		authmap:       authmap,
 		nodeIDHandler: nodeIDHandler,
 		policyRepo:    policyRepo,
+		clock:         clock.RealClock{},
 
 		ciliumNodesDiscovered: map[uint16]struct{}{
 			0: {}, // Local node 0 is always available
@@ -89,9 +112,73 @@ func (r *authMapGarbageCollector) cleanup(ctx context.Context) error {
 		return err
 	}
 
+	r.observeSweep()
+
 	return nil
 }
 
+// observeSweep counts a completed sweep and samples the auth map pressure
+// and the per-identity-pair expiration gauges.
+func (r *authMapGarbageCollector) observeSweep() {
+	if r.metrics == nil {
+		return
+	}
+	r.metrics.Sweeps.Inc()
+	all, err := r.authmap.All()
+	if err != nil {
+		return
+	}
+	if maxEntries := r.authmap.MaxEntries(); maxEntries > 0 {
+		r.metrics.MapPressure.Set(float64(len(all)) / float64(maxEntries))
+	}
+	r.observeEntryExpirations(all)
+}
+
+// identityPair identifies an authenticated identity pair, regardless of the
+// node and the auth type the pair was authenticated for.
+type identityPair struct {
+	localIdentity  identity.NumericIdentity
+	remoteIdentity identity.NumericIdentity
+}
+
+// observeEntryExpirations resamples the per-identity-pair expiration gauge
+// from the auth map entries. Entries of the same pair towards different
+// nodes or auth types collapse into the soonest expiration, the one which
+// triggers the next re-authentication of the pair.
+func (r *authMapGarbageCollector) observeEntryExpirations(all map[authKey]authInfo) {
+	soonest := map[identityPair]time.Time{}
+	for key, info := range all {
+		pair := identityPair{localIdentity: key.localIdentity, remoteIdentity: key.remoteIdentity}
+		if expiration, ok := soonest[pair]; !ok || info.expiration.Before(expiration) {
+			soonest[pair] = info.expiration
+		}
+	}
+
+	// Resampling from scratch drops the gauges of pairs whose entries got
+	// garbage collected since the last sweep.
+	r.metrics.EntryExpiration.Reset()
+	now := r.clock.Now()
+	for pair, expiration := range soonest {
+		r.metrics.EntryExpiration.WithLabelValues(
+			pair.localIdentity.String(), pair.remoteIdentity.String(),
+		).Set(expiration.Sub(now).Seconds())
+	}
+}
+
+// deleteIfCounted deletes the entries matching the predicate and counts the
+// evictions under the given reason.
+func (r *authMapGarbageCollector) deleteIfCounted(reason string, predicate func(key authKey, info authInfo) bool) error {
+	return r.authmap.DeleteIf(func(key authKey, info authInfo) bool {
+		if !predicate(key, info) {
+			return false
+		}
+		if r.metrics != nil {
+			r.metrics.Evicted.WithLabelValues(reason).Inc()
+		}
+		return true
+	})
+}
+
 // Nodes
 
 func (r *authMapGarbageCollector) subscribeToNodeEvents(nodeManager manager.NodeManager) {
@@ -191,7 +278,7 @@ func (r *authMapGarbageCollector) cleanupMissingNodes() error {
 		return nil
 	}
 
-	err := r.authmap.DeleteIf(func(key authKey, info authInfo) bool {
+	err := r.deleteIfCounted(gcReasonNode, func(key authKey, info authInfo) bool {
 		if _, ok := r.ciliumNodesDiscovered[key.remoteNodeID]; !ok {
 			r.logger.
 				WithField("remote_node_id", key.remoteNodeID).
@@ -211,7 +298,7 @@ func (r *authMapGarbageCollector) cleanupMissingNodes() error {
 }
 
 func (r *authMapGarbageCollector) cleanupDeletedNode(nodeID uint16) error {
-	return r.authmap.DeleteIf(func(key authKey, info authInfo) bool {
+	return r.deleteIfCounted(gcReasonNode, func(key authKey, info authInfo) bool {
 		if key.remoteNodeID == nodeID {
 			r.logger.
 				WithField("node_id", nodeID).
@@ -301,7 +388,7 @@ func (r *authMapGarbageCollector) cleanupMissingIdentities() error {
 		return nil
 	}
 
-	err := r.authmap.DeleteIf(func(key authKey, info authInfo) bool {
+	err := r.deleteIfCounted(gcReasonIdentity, func(key authKey, info authInfo) bool {
 		if _, ok := r.ciliumIdentitiesDiscovered[key.localIdentity]; !ok {
 			r.logger.
 				WithField("local_identity", key.localIdentity).
@@ -327,6 +414,15 @@ func (r *authMapGarbageCollector) cleanupMissingIdentities() error {
 }
 
 func (r *authMapGarbageCollector) cleanupDeletedIdentities() error {
+	if r.identityDeletionBehavior == gcIdentityBehaviorExpire {
+		// The entries of deleted identities age out with their expiration
+		// instead of being purged.
+		for id := range r.ciliumIdentitiesDeleted {
+			delete(r.ciliumIdentitiesDeleted, id)
+		}
+		return nil
+	}
+
 	for id := range r.ciliumIdentitiesDeleted {
 		if err := r.cleanupDeletedIdentity(id); err != nil {
 			// keep entry and try to delete it during the next gc execution
@@ -339,7 +435,7 @@ func (r *authMapGarbageCollector) cleanupDeletedIdentities() error {
 }
 
 func (r *authMapGarbageCollector) cleanupDeletedIdentity(id identity.NumericIdentity) error {
-	return r.authmap.DeleteIf(func(key authKey, info authInfo) bool {
+	return r.deleteIfCounted(gcReasonIdentity, func(key authKey, info authInfo) bool {
 		if key.localIdentity == id || key.remoteIdentity == id {
 			r.logger.
 				WithField(logfields.Identity, id).
@@ -355,7 +451,16 @@ func (r *authMapGarbageCollector) cleanupDeletedIdentity(id identity.NumericIden
 func (r *authMapGarbageCollector) cleanupEntriesWithoutAuthPolicy(_ context.Context) error {
 	r.logger.Debug("Cleaning up entries which no longer require authentication by a policy")
 
-	err := r.authmap.DeleteIf(func(key authKey, info authInfo) bool {
+	// Regenerating endpoints detach and reattach their policies while their
+	// policy maps get rebuilt. Deleting entries against such an intermediate
+	// policy state could drop pairs which are still authenticated once the
+	// rebuild settled, so the cleanup is deferred to the next GC run.
+	if r.endpointsRegenerating() {
+		r.logger.Debug("Skipping policy cleanup - endpoints are regenerating")
+		return nil
+	}
+
+	err := r.deleteIfCounted(gcReasonPolicy, func(key authKey, info authInfo) bool {
 		authTypes := r.policyRepo.GetAuthTypes(key.localIdentity, key.remoteIdentity)
 
 		if _, ok := authTypes[key.authType]; !ok {
@@ -374,15 +479,33 @@ func (r *authMapGarbageCollector) cleanupEntriesWithoutAuthPolicy(_ context.Cont
 	return nil
 }
 
+// endpointsRegenerating returns true if any local endpoint currently rebuilds
+// its policy maps or still restores after an agent restart.
+func (r *authMapGarbageCollector) endpointsRegenerating() bool {
+	r.endpointsCacheMutex.RLock()
+	defer r.endpointsCacheMutex.RUnlock()
+
+	for _, ep := range r.endpointsCache {
+		switch ep.GetState() {
+		case endpoint.StateRestoring, endpoint.StateWaitingToRegenerate, endpoint.StateRegenerating:
+			return true
+		}
+	}
+	return false
+}
+
 // Expired
 
 func (r *authMapGarbageCollector) cleanupExpiredEntries(_ context.Context) error {
-	now := time.Now()
+	now := r.clock.Now()
+	// Expired entries stay for the configured grace period past their
+	// expiration before they are deleted.
+	cutoff := now.Add(-r.expiredGracePeriod)
 	r.logger.
 		WithField("gc_time", now).
 		Debug("Cleaning up expired entries")
-	err := r.authmap.DeleteIf(func(key authKey, info authInfo) bool {
-		if info.expiration.Before(now) {
+	err := r.deleteIfCounted(gcReasonExpired, func(key authKey, info authInfo) bool {
+		if info.expiration.Before(cutoff) {
 			r.logger.
 				WithField("gc_time", now).
 				WithField("expiration", info.expiration).
@@ -425,15 +548,28 @@ func (r *authMapGarbageCollector) EndpointDeleted(ep *endpoint.Endpoint, conf en
 	delete(r.endpointsCache, ep.GetID16())
 	r.endpointsCacheMutex.Unlock()
 
+	// Endpoints deleted without releasing their identity are replaced by the
+	// restore logic, so their entries must stay to keep already authenticated
+	// pairs working across the restart. The periodic GC cleans the entries up
+	// if the endpoint never comes back.
+	if conf.NoIdentityRelease {
+		return
+	}
+
 	// when an endpoint got removed clean the authmap entries
 	if err := r.cleanupEndpoints(context.Background()); err != nil {
 		r.logger.WithError(err).Warning("failed to cleanup auth map entries related to endpoint entries")
 	}
 }
 
-// EndpointRestored implements endpointmanager.Subscriber.
+// EndpointRestored implements endpointmanager.Subscriber. Restored endpoints
+// keep their security identity, so they are tracked like created endpoints to
+// prevent the GC from purging the entries of pairs which got authenticated
+// before the restart.
 func (r *authMapGarbageCollector) EndpointRestored(ep *endpoint.Endpoint) {
-	// No-op
+	r.endpointsCacheMutex.Lock()
+	r.endpointsCache[ep.GetID16()] = ep
+	r.endpointsCacheMutex.Unlock()
 }
 
 func (r *authMapGarbageCollector) cleanupEndpoints(_ context.Context) error {
@@ -466,7 +602,7 @@ func (r *authMapGarbageCollector) cleanupEndpoints(_ context.Context) error {
 }
 
 func (r *authMapGarbageCollector) cleanupDeletedEndpointIdentity(id identity.NumericIdentity) error {
-	return r.authmap.DeleteIf(func(key authKey, info authInfo) bool {
+	return r.deleteIfCounted(gcReasonEndpoint, func(key authKey, info authInfo) bool {
 		if key.localIdentity == id || (key.remoteNodeID == 0 && key.remoteIdentity == id) {
 			r.logger.
 				WithField(logfields.Identity, id).
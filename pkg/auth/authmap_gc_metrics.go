@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"github.com/cilium/cilium/pkg/metrics"
+	"github.com/cilium/cilium/pkg/metrics/metric"
+)
+
+// Reasons the auth map garbage collector deletes entries for, used as the
+// "reason" label of the eviction counter.
+const (
+	gcReasonExpired  = "expired"
+	gcReasonNode     = "node"
+	gcReasonIdentity = "identity"
+	gcReasonEndpoint = "endpoint"
+	gcReasonPolicy   = "policy"
+)
+
+// gcMetrics exposes the behavior of the auth map garbage collector: sweep
+// and per-reason eviction counters and the fill ratio of the BPF auth map,
+// so the map size and GC interval can be tuned on large clusters.
+type gcMetrics struct {
+	// Sweeps counts the completed garbage collection sweeps.
+	Sweeps metric.Counter
+
+	// Evicted counts the entries deleted by the garbage collector by the
+	// reason they were deleted for.
+	Evicted metric.Vec[metric.Counter]
+
+	// MapPressure is the fill ratio of the BPF auth map (0.0 to 1.0),
+	// sampled after each sweep.
+	MapPressure metric.Gauge
+
+	// EntryExpiration is the remaining validity in seconds of the
+	// soonest-expiring auth map entry per authenticated identity pair,
+	// sampled after each sweep. Negative values belong to expired entries
+	// which are only kept by the expired grace period.
+	EntryExpiration metric.DeletableVec[metric.Gauge]
+}
+
+func newGCMetrics() *gcMetrics {
+	return &gcMetrics{
+		Sweeps: metric.NewCounter(metric.CounterOpts{
+			ConfigName: metrics.Namespace + "_auth_map_gc_sweeps_total",
+			Namespace:  metrics.Namespace,
+			Subsystem:  "auth_map_gc",
+			Name:       "sweeps_total",
+			Help:       "Number of completed auth map garbage collection sweeps",
+		}),
+		Evicted: metric.NewCounterVec(metric.CounterOpts{
+			ConfigName: metrics.Namespace + "_auth_map_gc_evicted_entries_total",
+			Namespace:  metrics.Namespace,
+			Subsystem:  "auth_map_gc",
+			Name:       "evicted_entries_total",
+			Help:       "Number of auth map entries deleted by the garbage collector by reason",
+		}, []string{"reason"}),
+		MapPressure: metric.NewGauge(metric.GaugeOpts{
+			ConfigName: metrics.Namespace + "_auth_map_pressure",
+			Namespace:  metrics.Namespace,
+			Subsystem:  "auth_map",
+			Name:       "pressure",
+			Help:       "Fill ratio of the BPF auth map, sampled after each garbage collection sweep",
+		}),
+		EntryExpiration: metric.NewGaugeVec(metric.GaugeOpts{
+			ConfigName: metrics.Namespace + "_auth_map_entry_expiration_seconds",
+			Namespace:  metrics.Namespace,
+			Subsystem:  "auth_map",
+			Name:       "entry_expiration_seconds",
+			Help:       "Remaining validity in seconds of the soonest-expiring auth map entry per authenticated identity pair, sampled after each garbage collection sweep",
+		}, []string{"local_identity", "remote_identity"}),
+	}
+}
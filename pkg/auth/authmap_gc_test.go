@@ -9,9 +9,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	clocktesting "k8s.io/utils/clock/testing"
 
 	"github.com/cilium/cilium/pkg/endpoint"
 	"github.com/cilium/cilium/pkg/identity"
@@ -208,6 +210,45 @@ func Test_authMapGarbageCollector_cleanupPolicies(t *testing.T) {
 	assert.Contains(t, authMap.entries, authKey{localIdentity: 1, remoteIdentity: 2, remoteNodeID: 0, authType: policy.AuthTypeSpire})
 }
 
+func Test_authMapGarbageCollector_cleanupPoliciesDeferredDuringRegeneration(t *testing.T) {
+	ctx := context.TODO()
+
+	authMap := &fakeAuthMap{
+		entries: map[authKey]authInfo{
+			{localIdentity: 1, remoteIdentity: 2, remoteNodeID: 0, authType: policy.AuthTypeSpire}: {expiration: time.Now().Add(5 * time.Minute)},
+			{localIdentity: 1, remoteIdentity: 3, remoteNodeID: 0, authType: policy.AuthTypeSpire}: {expiration: time.Now().Add(5 * time.Minute)},
+		},
+	}
+	gc := newAuthMapGC(logrus.New(), authMap, nil,
+		&fakePolicyRepository{
+			needsAuth: map[identity.NumericIdentity]map[identity.NumericIdentity]policy.AuthTypes{
+				1: {
+					2: map[policy.AuthType]struct{}{
+						policy.AuthTypeSpire: {},
+					},
+				},
+			},
+		},
+	)
+	gc.endpointsCache = map[uint16]*endpoint.Endpoint{
+		1: testEndpointInState(t, 1, endpoint.StateRegenerating),
+	}
+	gc.endpointsCacheSynced = true
+
+	err := gc.cleanupEntriesWithoutAuthPolicy(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, authMap.entries, 2, "Policy cleanup should be deferred while an endpoint is regenerating")
+
+	gc.endpointsCache = map[uint16]*endpoint.Endpoint{
+		1: testEndpointInState(t, 1, endpoint.StateReady),
+	}
+
+	err = gc.cleanupEntriesWithoutAuthPolicy(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, authMap.entries, 1, "Policy cleanup should resume once the regeneration settled")
+	assert.Contains(t, authMap.entries, authKey{localIdentity: 1, remoteIdentity: 2, remoteNodeID: 0, authType: policy.AuthTypeSpire})
+}
+
 func Test_authMapGarbageCollector_cleanupExpired(t *testing.T) {
 	ctx := context.TODO()
 
@@ -227,6 +268,52 @@ func Test_authMapGarbageCollector_cleanupExpired(t *testing.T) {
 	assert.Contains(t, authMap.entries, authKey{localIdentity: 1, remoteIdentity: 2, remoteNodeID: 0, authType: policy.AuthTypeSpire})
 }
 
+func Test_authMapGarbageCollector_cleanupExpiredGracePeriod(t *testing.T) {
+	ctx := context.TODO()
+
+	authMap := &fakeAuthMap{
+		entries: map[authKey]authInfo{
+			{localIdentity: 1, remoteIdentity: 2, remoteNodeID: 0, authType: policy.AuthTypeSpire}: {expiration: time.Now().Add(5 * time.Minute)},
+			{localIdentity: 1, remoteIdentity: 3, remoteNodeID: 0, authType: policy.AuthTypeSpire}: {expiration: time.Now().Add(-5 * time.Minute)},
+		},
+	}
+	gc := newAuthMapGC(logrus.New(), authMap, nil, nil)
+	gc.expiredGracePeriod = 10 * time.Minute
+
+	err := gc.cleanupExpiredEntries(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, authMap.entries, 2, "Entries expired for less than the grace period should survive the GC run")
+
+	gc.expiredGracePeriod = time.Minute
+
+	err = gc.cleanupExpiredEntries(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, authMap.entries, 1, "Entries expired for longer than the grace period should be deleted")
+	assert.Contains(t, authMap.entries, authKey{localIdentity: 1, remoteIdentity: 2, remoteNodeID: 0, authType: policy.AuthTypeSpire})
+}
+
+func Test_authMapGarbageCollector_identityDeletionBehaviorExpire(t *testing.T) {
+	ctx := context.TODO()
+
+	authMap := &fakeAuthMap{
+		entries: map[authKey]authInfo{
+			{localIdentity: 1, remoteIdentity: 2, remoteNodeID: 0, authType: policy.AuthTypeSpire}: {expiration: time.Now().Add(5 * time.Minute)},
+		},
+	}
+	gc := newAuthMapGC(logrus.New(), authMap, nil, nil)
+	gc.identityDeletionBehavior = gcIdentityBehaviorExpire
+
+	require.NoError(t, gc.handleIdentityChange(ctx, ciliumIdentityEvent(cache.IdentityChangeUpsert, 1)))
+	require.NoError(t, gc.handleIdentityChange(ctx, ciliumIdentityEvent(cache.IdentityChangeUpsert, 2)))
+	require.NoError(t, gc.handleIdentityChange(ctx, ciliumIdentityEvent(cache.IdentityChangeSync, 0)))
+	require.NoError(t, gc.handleIdentityChange(ctx, ciliumIdentityEvent(cache.IdentityChangeDelete, 2)))
+
+	err := gc.cleanupIdentities(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, authMap.entries, 1, "With the expire behavior, entries of deleted identities are left to expire on their own")
+	assert.Empty(t, gc.ciliumIdentitiesDeleted, "Deleted identities should still be dropped from the internal state")
+}
+
 func Test_authMapGarbageCollector_cleanup(t *testing.T) {
 	ctx := context.TODO()
 
@@ -379,6 +466,55 @@ func Test_authMapGarbageCollector_cleanupEndpointsNoopCase(t *testing.T) {
 	assert.Len(t, authMap.entries, 3, "GC runs should not have deleted entries when all secrity IDs were stil in the endpoint map")
 }
 
+func Test_authMapGarbageCollector_endpointsPreservedAcrossRestore(t *testing.T) {
+	authMap := &fakeAuthMap{
+		entries: map[authKey]authInfo{
+			{localIdentity: 2, remoteIdentity: 1, remoteNodeID: 0, authType: policy.AuthTypeSpire}: {expiration: time.Now().Add(5 * time.Minute)},
+		},
+	}
+	gc := newAuthMapGC(logrus.New(), authMap, nil, nil)
+
+	ep := &endpoint.Endpoint{
+		ID: 1,
+		SecurityIdentity: &identity.Identity{
+			ID: 2,
+		},
+	}
+	remoteEP := &endpoint.Endpoint{
+		ID: 2,
+		SecurityIdentity: &identity.Identity{
+			ID: 1,
+		},
+	}
+	gc.endpointsCache = map[uint16]*endpoint.Endpoint{
+		remoteEP.GetID16(): remoteEP,
+	}
+	gc.endpointsCacheSynced = true
+
+	gc.ciliumIdentitiesDiscovered = map[identity.NumericIdentity]struct{}{
+		1: {},
+		2: {},
+	}
+	gc.ciliumIdentitiesSynced = true
+
+	// A restored endpoint is tracked like a created one, so the entries of its
+	// already authenticated pairs survive the restart.
+	gc.EndpointRestored(ep)
+	assert.Contains(t, gc.endpointsCache, ep.GetID16())
+	assert.Len(t, authMap.entries, 1)
+
+	// Deleting an endpoint without releasing its identity is part of the
+	// restore logic and must not purge the entries of the endpoint.
+	gc.EndpointDeleted(ep, endpoint.DeleteConfig{NoIdentityRelease: true})
+	assert.NotContains(t, gc.endpointsCache, ep.GetID16())
+	assert.Len(t, authMap.entries, 1, "Entries should survive an endpoint deletion during restore")
+
+	// A regular endpoint deletion purges the entries of its identity.
+	gc.EndpointRestored(ep)
+	gc.EndpointDeleted(ep, endpoint.DeleteConfig{})
+	assert.Empty(t, authMap.entries, "Entries should be deleted with a regular endpoint deletion")
+}
+
 func Test_authMapGarbageCollector_HandleNodeEventError(t *testing.T) {
 	authMap := &fakeAuthMap{
 		entries:    map[authKey]authInfo{},
@@ -415,6 +551,33 @@ func Test_authMapGarbageCollector_HandleIdentityEventError(t *testing.T) {
 	assert.ErrorContains(t, err, "failed to cleanup deleted identity: failed to delete entry")
 }
 
+func Test_authMapGarbageCollector_entryExpirationMetric(t *testing.T) {
+	now := time.Now()
+
+	authMap := &fakeAuthMap{
+		entries: map[authKey]authInfo{
+			{localIdentity: 1000, remoteIdentity: 2000, remoteNodeID: 0, authType: policy.AuthTypeSpire}: {expiration: now.Add(10 * time.Minute)},
+			{localIdentity: 1000, remoteIdentity: 2000, remoteNodeID: 1, authType: policy.AuthTypeSpire}: {expiration: now.Add(5 * time.Minute)},
+			{localIdentity: 1000, remoteIdentity: 3000, remoteNodeID: 0, authType: policy.AuthTypeSpire}: {expiration: now.Add(20 * time.Minute)},
+		},
+	}
+	gc := newAuthMapGC(logrus.New(), authMap, nil, nil)
+	gc.metrics = newGCMetrics()
+	gc.clock = clocktesting.NewFakePassiveClock(now)
+
+	gc.observeSweep()
+
+	assert.Equal(t, (5 * time.Minute).Seconds(), testutil.ToFloat64(gc.metrics.EntryExpiration.WithLabelValues("1000", "2000")),
+		"The soonest expiration across the entries of a pair should be exported")
+	assert.Equal(t, (20 * time.Minute).Seconds(), testutil.ToFloat64(gc.metrics.EntryExpiration.WithLabelValues("1000", "3000")))
+
+	delete(authMap.entries, authKey{localIdentity: 1000, remoteIdentity: 3000, remoteNodeID: 0, authType: policy.AuthTypeSpire})
+	gc.observeSweep()
+
+	assert.Equal(t, 1, testutil.CollectAndCount(gc.metrics.EntryExpiration),
+		"Gauges of pairs without remaining entries should be dropped by the next sweep")
+}
+
 func ciliumNodeEvent(nodeInternalIP string) nodeTypes.Node {
 	return nodeTypes.Node{
 		Name:    "test-node",
@@ -450,3 +613,17 @@ func (r *fakePolicyRepository) GetAuthTypes(localID, remoteID identity.NumericId
 
 	return nil
 }
+
+// Fake policyRepoGetter
+
+type fakePolicyRepoGetter struct{}
+
+func (f *fakePolicyRepoGetter) GetPolicyRepository() *policy.Repository {
+	return policy.NewPolicyRepository(nil, nil, nil, nil)
+}
+
+// testEndpointInState builds a fake endpoint in the given state for
+// regeneration coordination tests.
+func testEndpointInState(t *testing.T, id uint16, state endpoint.State) *endpoint.Endpoint {
+	return endpoint.NewTestEndpointWithState(t, nil, &fakePolicyRepoGetter{}, nil, nil, nil, id, state)
+}
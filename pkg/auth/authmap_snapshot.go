@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/cilium/cilium/pkg/identity"
+	"github.com/cilium/cilium/pkg/policy"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// authMapSnapshotter persists the authentication state to a file on shutdown
+// and restores it on startup. This keeps established authentications alive
+// across agent upgrades where the BPF auth map gets recreated, avoiding an
+// authentication storm when many nodes get upgraded in a rolling fashion.
+type authMapSnapshotter struct {
+	logger  logrus.FieldLogger
+	authmap authMap
+	path    string
+}
+
+// snapshotEntry is the serialized form of an auth map entry.
+type snapshotEntry struct {
+	LocalIdentity  uint32    `json:"local_identity"`
+	RemoteIdentity uint32    `json:"remote_identity"`
+	RemoteNodeID   uint16    `json:"remote_node_id"`
+	AuthType       uint8     `json:"auth_type"`
+	Expiration     time.Time `json:"expiration"`
+}
+
+func newAuthMapSnapshotter(logger logrus.FieldLogger, authmap authMap, path string) *authMapSnapshotter {
+	return &authMapSnapshotter{
+		logger:  logger,
+		authmap: authmap,
+		path:    path,
+	}
+}
+
+// snapshot writes all current auth map entries to the configured state file.
+func (s *authMapSnapshotter) snapshot() error {
+	all, err := s.authmap.All()
+	if err != nil {
+		return fmt.Errorf("failed to get all auth map entries: %w", err)
+	}
+
+	entries := make([]snapshotEntry, 0, len(all))
+	for k, v := range all {
+		entries = append(entries, snapshotEntry{
+			LocalIdentity:  k.localIdentity.Uint32(),
+			RemoteIdentity: k.remoteIdentity.Uint32(),
+			RemoteNodeID:   k.remoteNodeID,
+			AuthType:       uint8(k.authType),
+			Expiration:     v.expiration,
+		})
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal auth state: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write auth state file: %w", err)
+	}
+
+	s.logger.
+		WithField("path", s.path).
+		WithField("entries", len(entries)).
+		Info("Persisted authentication state")
+	return nil
+}
+
+// restore loads the auth map entries from the configured state file and
+// removes the file afterwards. Entries which already expired are skipped.
+// A missing state file is not an error - there is simply nothing to restore.
+func (s *authMapSnapshotter) restore() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			s.logger.
+				WithField("path", s.path).
+				Debug("No authentication state file present - nothing to restore")
+			return nil
+		}
+		return fmt.Errorf("failed to read auth state file: %w", err)
+	}
+
+	var entries []snapshotEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to unmarshal auth state: %w", err)
+	}
+
+	now := time.Now()
+	restored := 0
+	for _, e := range entries {
+		if e.Expiration.Before(now) {
+			continue
+		}
+		key := authKey{
+			localIdentity:  identity.NumericIdentity(e.LocalIdentity),
+			remoteIdentity: identity.NumericIdentity(e.RemoteIdentity),
+			remoteNodeID:   e.RemoteNodeID,
+			authType:       policy.AuthType(e.AuthType),
+		}
+		if err := s.authmap.Update(key, authInfo{expiration: e.Expiration}); err != nil {
+			return fmt.Errorf("failed to restore auth map entry: %w", err)
+		}
+		restored++
+	}
+
+	if err := os.Remove(s.path); err != nil {
+		s.logger.
+			WithError(err).
+			WithField("path", s.path).
+			Warning("Failed to remove authentication state file after restore")
+	}
+
+	s.logger.
+		WithField("path", s.path).
+		WithField("entries", restored).
+		Info("Restored authentication state")
+	return nil
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cilium/cilium/pkg/policy"
+)
+
+func Test_authMapSnapshotter_snapshotAndRestore(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "auth-state.json")
+
+	validKey := authKey{
+		localIdentity:  1000,
+		remoteIdentity: 2000,
+		remoteNodeID:   10,
+		authType:       policy.AuthTypeSpire,
+	}
+	expiredKey := authKey{
+		localIdentity:  1001,
+		remoteIdentity: 2001,
+		remoteNodeID:   10,
+		authType:       policy.AuthTypeSpire,
+	}
+
+	source := &fakeAuthMap{
+		entries: map[authKey]authInfo{
+			validKey:   {expiration: time.Now().Add(10 * time.Minute)},
+			expiredKey: {expiration: time.Now().Add(-10 * time.Minute)},
+		},
+	}
+
+	snapshotter := newAuthMapSnapshotter(logrus.New(), source, stateFile)
+	assert.NoError(t, snapshotter.snapshot())
+
+	target := &fakeAuthMap{
+		entries: map[authKey]authInfo{},
+	}
+	restorer := newAuthMapSnapshotter(logrus.New(), target, stateFile)
+	assert.NoError(t, restorer.restore())
+
+	// expired entries are not restored
+	assert.Len(t, target.entries, 1)
+	info, ok := target.entries[validKey]
+	assert.True(t, ok)
+	assert.False(t, info.expiration.Before(time.Now()))
+
+	// the state file is consumed by the restore
+	assert.NoError(t, restorer.restore())
+	assert.Len(t, target.entries, 1)
+}
+
+func Test_authMapSnapshotter_restoreWithoutStateFile(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "auth-state.json")
+
+	target := &fakeAuthMap{
+		entries: map[authKey]authInfo{},
+	}
+	snapshotter := newAuthMapSnapshotter(logrus.New(), target, stateFile)
+
+	assert.NoError(t, snapshotter.restore())
+	assert.Len(t, target.entries, 0)
+}
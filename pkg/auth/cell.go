@@ -12,15 +12,22 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
 
+	"github.com/cilium/cilium/pkg/auth/filecerts"
 	"github.com/cilium/cilium/pkg/auth/spire"
+	"github.com/cilium/cilium/pkg/auth/vaultcerts"
+	cmtypes "github.com/cilium/cilium/pkg/clustermesh/types"
 	"github.com/cilium/cilium/pkg/datapath/types"
 	"github.com/cilium/cilium/pkg/endpointmanager"
 	"github.com/cilium/cilium/pkg/identity/cache"
+	k8sClient "github.com/cilium/cilium/pkg/k8s/client"
 	"github.com/cilium/cilium/pkg/maps/authmap"
+	"github.com/cilium/cilium/pkg/metrics"
+	monitoragent "github.com/cilium/cilium/pkg/monitor/agent"
 	nodeManager "github.com/cilium/cilium/pkg/node/manager"
 	"github.com/cilium/cilium/pkg/policy"
 	"github.com/cilium/cilium/pkg/signal"
 	"github.com/cilium/cilium/pkg/time"
+	wireguardAgent "github.com/cilium/cilium/pkg/wireguard/agent"
 )
 
 // Cell provides AuthManager which is responsible for request authentication.
@@ -33,38 +40,115 @@ var Cell = cell.Module(
 	"Authenticates requests as demanded by policy",
 
 	spire.Cell,
+	filecerts.Cell,
+	vaultcerts.Cell,
+
+	// Select the certificate provider implementation backing the mutual auth
+	// handshakes.
+	cell.Provide(selectCertificateProvider),
 
 	// The auth manager is the main entry point which gets registered to signal map and receives auth requests.
 	// In addition, it handles re-authentication and auth map garbage collection.
 	cell.Provide(registerAuthManager),
+	// Expose the datapath filter configuration of the listener port to the
+	// iptables manager.
+	cell.Provide(newMutualAuthPortFilter),
+	// Expose the network-layer auth verdict to the L7 proxy integration.
+	cell.Provide(newProxyAuthMetadataProvider),
 	cell.ProvidePrivate(
 		// Null auth handler provides support for auth type "null" - which always succeeds.
 		newMutualAuthHandler,
+		// JWT auth handler provides support for auth type "jwt" - a JWT-SVID
+		// exchange over HTTP instead of a mutual TLS handshake.
+		newJWTAuthHandler,
 		// Always fail auth handler provides support for auth type "always-fail" - which always fails.
 		newAlwaysFailAuthHandler,
 	),
+	metrics.Metric(newListenerMetrics),
+	metrics.Metric(newClockSkewMetrics),
+	metrics.Metric(newHandshakeMetrics),
+	metrics.Metric(newGCMetrics),
+	// Fail fast on inconsistent configuration before any auth component starts.
+	cell.Invoke(validateConfig),
+	// React to runtime configuration changes from the dynamic config sources.
+	cell.Invoke(registerDynamicConfigWatcher),
 	cell.Config(config{
 		MeshAuthEnabled:               true,
+		MeshAuthCertProvider:          certProviderSpire,
 		MeshAuthQueueSize:             1024,
 		MeshAuthGCInterval:            5 * time.Minute,
+		MeshAuthGCIdentityBehavior:    gcIdentityBehaviorPurge,
 		MeshAuthSignalBackoffDuration: 1 * time.Second, // this default is based on the default TCP retransmission timeout
+		MeshAuthDryRunGrantLifetime:   1 * time.Minute,
+		MeshAuthRetryBackoffMin:       1 * time.Second,
+		MeshAuthRetryBackoffMax:       1 * time.Minute,
+		MeshAuthReauthCheckInterval:   1 * time.Minute,
 	}),
 	cell.Config(MutualAuthConfig{}),
+	cell.Config(JWTAuthConfig{}),
 )
 
 type config struct {
-	MeshAuthEnabled               bool
-	MeshAuthQueueSize             int
-	MeshAuthGCInterval            time.Duration
-	MeshAuthSignalBackoffDuration time.Duration
+	MeshAuthEnabled                bool
+	MeshAuthCertProvider           string
+	MeshAuthCertProviderFallbacks  []string
+	MeshAuthCertCache              bool
+	MeshAuthCertPrefetchInterval   time.Duration
+	MeshAuthQueueSize              int
+	MeshAuthMaxConcurrent          int
+	MeshAuthGCInterval             time.Duration
+	MeshAuthGCExpiredGracePeriod   time.Duration
+	MeshAuthGCIdentityBehavior     string
+	MeshAuthSignalBackoffDuration  time.Duration
+	MeshAuthFaultInjectionRate     float64
+	MeshAuthFaultInjectionDelay    time.Duration
+	MeshAuthDryRun                 bool
+	MeshAuthDryRunGrantLifetime    time.Duration
+	MeshAuthStateFile              string
+	MeshAuthFailureCacheTTL        time.Duration
+	MeshAuthRetryAttempts          int
+	MeshAuthRetryBackoffMin        time.Duration
+	MeshAuthRetryBackoffMax        time.Duration
+	MeshAuthNotReadyGracePeriod    time.Duration
+	MeshAuthReauthBeforeExpiry     time.Duration
+	MeshAuthReauthCheckInterval    time.Duration
+	MeshAuthRestoredReauthRate     int
+	MeshAuthEndpointStatusInterval time.Duration
+	MeshAuthWireguardGateInterval  time.Duration
+	MeshAuthNodeStatusInterval     time.Duration
 }
 
 func (r config) Flags(flags *pflag.FlagSet) {
 	flags.Bool("mesh-auth-enabled", r.MeshAuthEnabled, "Enable authentication processing & garbage collection (beta)")
+	flags.String("mesh-auth-cert-provider", r.MeshAuthCertProvider, "Certificate provider backing the mutual auth handshakes (spire, file or vault)")
+	flags.StringSlice("mesh-auth-cert-provider-fallbacks", r.MeshAuthCertProviderFallbacks, "Ordered list of certificate providers tried when the primary provider is unavailable at handshake time (e.g. 'file' behind 'spire')")
+	flags.Bool("mesh-auth-cert-cache", r.MeshAuthCertCache, "Cache the certificates minted for local identities between handshakes, dropped on rotation or expiry")
+	flags.Duration("mesh-auth-cert-prefetch-interval", r.MeshAuthCertPrefetchInterval, "Interval in which certificates for local endpoint identities are prefetched into the certificate cache, 0 to disable prefetching")
 	flags.Int("mesh-auth-queue-size", r.MeshAuthQueueSize, "Queue size for the auth manager")
+	flags.Int("mesh-auth-max-concurrent", r.MeshAuthMaxConcurrent, "Maximum number of concurrently running authentication handshakes, 0 for no limit")
 	flags.Duration("mesh-auth-gc-interval", r.MeshAuthGCInterval, "Interval in which auth entries are attempted to be garbage collected")
+	flags.Duration("mesh-auth-gc-expired-grace-period", r.MeshAuthGCExpiredGracePeriod, "Grace period for which expired auth map entries are kept before the garbage collector deletes them")
+	flags.String("mesh-auth-gc-identity-behavior", r.MeshAuthGCIdentityBehavior, "Behavior of the auth map garbage collector towards entries of deleted identities (purge them on the next sweep, or expire to let them age out)")
 	flags.Duration("mesh-auth-signal-backoff-duration", r.MeshAuthSignalBackoffDuration, "Time to wait betweeen two authentication required signals in case of a cache mismatch")
 	flags.MarkHidden("mesh-auth-signal-backoff-duration")
+	flags.Float64("mesh-auth-fault-injection-rate", r.MeshAuthFaultInjectionRate, "Rate (0.0-1.0) of authentications to fail artificially for chaos testing")
+	flags.MarkHidden("mesh-auth-fault-injection-rate")
+	flags.Duration("mesh-auth-fault-injection-delay", r.MeshAuthFaultInjectionDelay, "Artificial delay applied before an injected authentication failure")
+	flags.MarkHidden("mesh-auth-fault-injection-delay")
+	flags.Bool("mesh-auth-dry-run", r.MeshAuthDryRun, "Log and count failed authentications instead of dropping traffic, granting temporary auth entries for the failed identity pairs")
+	flags.Duration("mesh-auth-dry-run-grant-lifetime", r.MeshAuthDryRunGrantLifetime, "Lifetime of the temporary auth map entries granted for failed authentications in dry-run mode")
+	flags.String("mesh-auth-state-file", r.MeshAuthStateFile, "File used to persist authentication state across agent restarts and upgrades (empty to disable)")
+	flags.Duration("mesh-auth-failure-cache-ttl", r.MeshAuthFailureCacheTTL, "Duration for which failed authentications are answered from a negative cache instead of retrying the handshake, 0 to disable")
+	flags.Int("mesh-auth-retry-attempts", r.MeshAuthRetryAttempts, "Number of times a failed authentication is retried with exponential backoff, 0 to disable")
+	flags.Duration("mesh-auth-retry-backoff-min", r.MeshAuthRetryBackoffMin, "Minimum backoff between retries of a failed authentication")
+	flags.Duration("mesh-auth-retry-backoff-max", r.MeshAuthRetryBackoffMax, "Maximum backoff between retries of a failed authentication")
+	flags.Duration("mesh-auth-not-ready-grace-period", r.MeshAuthNotReadyGracePeriod, "Lifetime of the temporary auth map entries granted while the certificate provider is not ready yet, 0 to disable the readiness gating")
+	flags.Duration("mesh-auth-reauth-before-expiry", r.MeshAuthReauthBeforeExpiry, "Lead time before the expiration of an auth entry at which the entry is proactively re-authenticated, 0 to disable")
+	flags.Duration("mesh-auth-reauth-check-interval", r.MeshAuthReauthCheckInterval, "Interval in which auth entries are checked for upcoming expiration")
+	flags.Int("mesh-auth-restored-reauth-rate", r.MeshAuthRestoredReauthRate, "Number of restored auth map entries re-authenticated per second after an agent restart, 0 to disable the reconciliation pass")
+	flags.Duration("mesh-auth-endpoint-status-interval", r.MeshAuthEndpointStatusInterval, "Interval in which the authentication status of the endpoints is summarized into the CiliumEndpoint objects, 0 to disable")
+	flags.Duration("mesh-auth-wireguard-gate-interval", r.MeshAuthWireguardGateInterval, "Interval in which WireGuard peers are installed or removed based on the mutual authentication state of their node, 0 to disable the gating")
+	flags.Duration("mesh-auth-node-status-interval", r.MeshAuthNodeStatusInterval, "Interval in which the per-peer authentication health of the node is published into its CiliumNode object, 0 to disable")
 }
 
 type authManagerParams struct {
@@ -81,10 +165,22 @@ type authManagerParams struct {
 
 	SignalManager   signal.SignalManager
 	NodeIDHandler   types.NodeIDHandler
+	ClusterInfo     cmtypes.ClusterInfo
 	IdentityChanges stream.Observable[cache.IdentityChange]
 	NodeManager     nodeManager.NodeManager
 	EndpointManager endpointmanager.EndpointManager
 	PolicyRepo      *policy.Repository
+
+	// WireGuardAgent is nil unless WireGuard is enabled.
+	WireGuardAgent *wireguardAgent.Agent `optional:"true"`
+
+	// MonitorAgent publishes authentication events to the monitor listeners.
+	MonitorAgent monitoragent.Agent `optional:"true"`
+
+	HandshakeMetrics *handshakeMetrics
+	GCMetrics        *gcMetrics
+
+	Clientset k8sClient.Clientset
 }
 
 func registerAuthManager(params authManagerParams) (*AuthManager, error) {
@@ -97,22 +193,84 @@ func registerAuthManager(params authManagerParams) (*AuthManager, error) {
 
 	mapWriter := newAuthMapWriter(params.Logger, params.AuthMap)
 	mapCache := newAuthMapCache(params.Logger, mapWriter)
+	mapCache.entriesGauge = params.HandshakeMetrics.CachedAuthEntries
 
 	mgr, err := newAuthManager(params.Logger, params.AuthHandlers, mapCache, params.NodeIDHandler, params.Config.MeshAuthSignalBackoffDuration)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create auth manager: %w", err)
 	}
+	mgr.faultInjector = newFaultInjector(params.Logger, params.Config)
+	mgr.failureCacheTTL = params.Config.MeshAuthFailureCacheTTL
+	mgr.dryRun = params.Config.MeshAuthDryRun
+	mgr.dryRunGrantLifetime = params.Config.MeshAuthDryRunGrantLifetime
+	mgr.dryRunWouldFail = params.HandshakeMetrics.DryRunWouldFail
+	if mgr.dryRun {
+		params.Logger.Warning("Mesh authentication runs in dry-run mode, failed authentications are logged but do not drop traffic")
+	}
+	if params.Config.MeshAuthMaxConcurrent > 0 {
+		mgr.concurrencySem = make(chan struct{}, params.Config.MeshAuthMaxConcurrent)
+	}
+	mgr.coalescedAuths = params.HandshakeMetrics.CoalescedAuthentications
+	mgr.queuedAuths = params.HandshakeMetrics.QueuedAuthentications
+	mgr.notReadyGracePeriod = params.Config.MeshAuthNotReadyGracePeriod
+	mgr.restoredReauthRate = params.Config.MeshAuthRestoredReauthRate
+	mgr.retryAttempts = params.Config.MeshAuthRetryAttempts
+	mgr.retryBackoff = newRetryBackoff(params.Config)
+	mgr.reauthBeforeExpiry = params.Config.MeshAuthReauthBeforeExpiry
+	if params.MonitorAgent != nil {
+		mgr.monitorNotifier = params.MonitorAgent
+	}
+	if params.ClusterInfo.ID != 0 {
+		// In a ClusterMesh, auth requests may target nodes of other
+		// clusters; track them so their node IDs resolve to a routable
+		// address.
+		meshNodes := newMeshNodeCache(params.Logger, params.ClusterInfo.Name, params.NodeIDHandler)
+		mgr.meshNodes = meshNodes
+		params.Lifecycle.Append(cell.Hook{
+			OnStart: func(hookContext cell.HookContext) error {
+				meshNodes.subscribeToNodeEvents(params.NodeManager)
+				return nil
+			},
+			OnStop: func(hookContext cell.HookContext) error {
+				params.NodeManager.Unsubscribe(meshNodes)
+				return nil
+			},
+		})
+	}
 
 	mapGC := newAuthMapGC(params.Logger, mapCache, params.NodeIDHandler, params.PolicyRepo)
+	mapGC.metrics = params.GCMetrics
+	mapGC.expiredGracePeriod = params.Config.MeshAuthGCExpiredGracePeriod
+	mapGC.identityDeletionBehavior = params.Config.MeshAuthGCIdentityBehavior
 
 	// Register auth components to lifecycle hooks & jobs
 
+	var snapshotter *authMapSnapshotter
+	if params.Config.MeshAuthStateFile != "" {
+		snapshotter = newAuthMapSnapshotter(params.Logger, mapCache, params.Config.MeshAuthStateFile)
+	}
+
 	params.Lifecycle.Append(cell.Hook{
 		OnStart: func(hookContext cell.HookContext) error {
 			if err := mapCache.restoreCache(); err != nil {
 				return fmt.Errorf("failed to restore auth map cache: %w", err)
 			}
 
+			if snapshotter != nil {
+				if err := snapshotter.restore(); err != nil {
+					return fmt.Errorf("failed to restore auth state snapshot: %w", err)
+				}
+			}
+
+			return nil
+		},
+		OnStop: func(hookContext cell.HookContext) error {
+			if snapshotter != nil {
+				if err := snapshotter.snapshot(); err != nil {
+					return fmt.Errorf("failed to snapshot auth state: %w", err)
+				}
+			}
+
 			return nil
 		},
 	})
@@ -122,6 +280,44 @@ func registerAuthManager(params authManagerParams) (*AuthManager, error) {
 	}
 	registerReAuthenticationJob(params.JobGroup, mgr, params.AuthHandlers)
 	registerGCJobs(params.JobGroup, params.Lifecycle, mapGC, params.Config, params.NodeManager, params.EndpointManager, params.IdentityChanges)
+	if params.Config.MeshAuthEndpointStatusInterval > 0 {
+		statusSync := &endpointAuthStatusSync{
+			logger:          params.Logger,
+			manager:         mgr,
+			endpointManager: params.EndpointManager,
+		}
+		params.JobGroup.Add(job.Timer("auth-endpoint-status-sync", statusSync.sync, params.Config.MeshAuthEndpointStatusInterval))
+	}
+	if params.Config.MeshAuthNodeStatusInterval > 0 && params.Clientset.IsEnabled() {
+		nodeStatusSync := &nodeAuthStatusSync{
+			logger:    params.Logger,
+			manager:   mgr,
+			clientset: params.Clientset,
+		}
+		params.JobGroup.Add(job.Timer("auth-node-status-sync", nodeStatusSync.sync, params.Config.MeshAuthNodeStatusInterval))
+	}
+	if params.Config.MeshAuthWireguardGateInterval > 0 {
+		if params.WireGuardAgent == nil {
+			return nil, fmt.Errorf("WireGuard peer gating requires WireGuard to be enabled")
+		}
+		gate := &wireguardAuthGate{
+			logger:  params.Logger,
+			manager: mgr,
+			wgAgent: params.WireGuardAgent,
+		}
+		params.JobGroup.Add(job.Timer("auth-wireguard-gating", gate.sync, params.Config.MeshAuthWireguardGateInterval))
+	}
+	if params.Config.MeshAuthRetryAttempts > 0 {
+		params.JobGroup.Add(job.Timer("auth-retry-failed", mgr.retryFailedAuthentications, params.Config.MeshAuthRetryBackoffMin))
+	}
+	if params.Config.MeshAuthReauthBeforeExpiry > 0 {
+		params.JobGroup.Add(job.Timer("auth-reauth-expiring", mgr.reauthExpiringEntries, params.Config.MeshAuthReauthCheckInterval))
+	}
+	if params.Config.MeshAuthRestoredReauthRate > 0 {
+		// Runs once after start, over the entries restored by the OnStart
+		// hook above.
+		params.JobGroup.Add(job.OneShot("auth-restored-reauthentication", mgr.reconcileRestoredEntries))
+	}
 
 	return mgr, nil
 }
@@ -0,0 +1,158 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/cilium/cilium/pkg/auth/certs"
+	"github.com/cilium/cilium/pkg/identity"
+	"github.com/cilium/cilium/pkg/lock"
+	"github.com/cilium/cilium/pkg/logging/logfields"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// certificateCache wraps a certificate provider with a per-identity cache of
+// the minted certificates. GetCertificateForIdentity sits in the hot path of
+// every handshake, and with the SPIRE provider each call is a delegated API
+// round trip; the cache answers repeat handshakes for the same identity
+// locally. Entries are dropped when the certificate rotates or expires, and
+// the prefetch pass warms the cache for all local endpoint identities before
+// their first handshake needs them.
+type certificateCache struct {
+	certs.CertificateProvider
+
+	log       logrus.FieldLogger
+	endpoints endpointGetter
+
+	mutex lock.Mutex
+	cache map[identity.NumericIdentity]*tls.Certificate
+
+	// rotated forwards the rotation events of the wrapped provider to the
+	// downstream subscriber, after the rotated identity has been dropped
+	// from the cache. Consuming the provider channel directly would steal
+	// the events from the re-authentication job.
+	subscribeOnce sync.Once
+	rotated       chan certs.CertificateRotationEvent
+}
+
+func newCertificateCache(log logrus.FieldLogger, provider certs.CertificateProvider, endpoints endpointGetter) *certificateCache {
+	return &certificateCache{
+		CertificateProvider: provider,
+		log:                 log,
+		endpoints:           endpoints,
+		cache:               map[identity.NumericIdentity]*tls.Certificate{},
+	}
+}
+
+// provider returns the cache as the certificate provider to expose. A
+// wrapped provider tracking per-trust-domain bundles keeps exposing them.
+func (c *certificateCache) provider() certs.CertificateProvider {
+	if _, ok := c.CertificateProvider.(certs.FederatedTrustProvider); ok {
+		return &federatedCertificateCache{certificateCache: c}
+	}
+	return c
+}
+
+// federatedCertificateCache additionally exposes the per-trust-domain
+// bundles of the wrapped provider, which a plain interface embedding would
+// hide from the optional interface assertion in the handshake path.
+type federatedCertificateCache struct {
+	*certificateCache
+}
+
+func (f *federatedCertificateCache) GetTrustBundleForDomain(trustDomain string) (*x509.CertPool, error) {
+	return f.CertificateProvider.(certs.FederatedTrustProvider).GetTrustBundleForDomain(trustDomain)
+}
+
+func (c *certificateCache) GetCertificateForIdentity(id identity.NumericIdentity) (*tls.Certificate, error) {
+	if cert := c.cached(id); cert != nil {
+		return cert, nil
+	}
+
+	cert, err := c.CertificateProvider.GetCertificateForIdentity(id)
+	if err != nil {
+		return nil, err
+	}
+	// Certificates without a parsed leaf cannot be checked for expiry and
+	// are not cached.
+	if cert.Leaf != nil {
+		c.mutex.Lock()
+		c.cache[id] = cert
+		c.mutex.Unlock()
+	}
+	return cert, nil
+}
+
+// cached returns the cached certificate of the given identity, or nil if
+// none is cached or the cached one expired.
+func (c *certificateCache) cached(id identity.NumericIdentity) *tls.Certificate {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	cert, ok := c.cache[id]
+	if !ok {
+		return nil
+	}
+	if !time.Now().Before(cert.Leaf.NotAfter) {
+		delete(c.cache, id)
+		return nil
+	}
+	return cert
+}
+
+func (c *certificateCache) invalidate(id identity.NumericIdentity) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.cache, id)
+}
+
+func (c *certificateCache) SubscribeToRotatedIdentities() <-chan certs.CertificateRotationEvent {
+	c.subscribeOnce.Do(func() {
+		upstream := c.CertificateProvider.SubscribeToRotatedIdentities()
+		if upstream == nil {
+			return
+		}
+		c.rotated = make(chan certs.CertificateRotationEvent)
+		go func() {
+			defer close(c.rotated)
+			for event := range upstream {
+				c.invalidate(event.Identity)
+				c.rotated <- event
+			}
+		}()
+	})
+	return c.rotated
+}
+
+// prefetch warms the cache for the identities of all local endpoints, so
+// their first handshake does not pay for the certificate round trip. Run as
+// a timer job, it also re-fetches entries dropped by rotation or expiry.
+func (c *certificateCache) prefetch(ctx context.Context) error {
+	if c.endpoints == nil {
+		return nil
+	}
+	for _, ep := range c.endpoints.GetEndpoints() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if ep.SecurityIdentity == nil {
+			continue
+		}
+		id := ep.SecurityIdentity.ID
+		if c.cached(id) != nil {
+			continue
+		}
+		if _, err := c.GetCertificateForIdentity(id); err != nil {
+			c.log.WithError(err).WithField(logfields.Identity, id).
+				Debug("Failed to prefetch certificate")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/cilium/cilium/pkg/auth/certs"
+	"github.com/cilium/cilium/pkg/auth/certs/fake"
+	"github.com/cilium/cilium/pkg/identity"
+)
+
+// countingCertProvider counts the certificate requests reaching the wrapped
+// provider.
+type countingCertProvider struct {
+	certs.CertificateProvider
+
+	calls int
+}
+
+func (c *countingCertProvider) GetCertificateForIdentity(id identity.NumericIdentity) (*tls.Certificate, error) {
+	c.calls++
+	return c.CertificateProvider.GetCertificateForIdentity(id)
+}
+
+func Test_certificateCache_GetCertificateForIdentity(t *testing.T) {
+	provider := fake.NewProvider()
+	counting := &countingCertProvider{CertificateProvider: provider}
+	cache := newCertificateCache(logrus.New(), counting, nil)
+
+	rotated := cache.SubscribeToRotatedIdentities()
+	if rotated == nil {
+		t.Fatalf("SubscribeToRotatedIdentities() returned no channel")
+	}
+
+	first, err := cache.GetCertificateForIdentity(id1000)
+	if err != nil {
+		t.Fatalf("GetCertificateForIdentity() error = %v", err)
+	}
+	second, err := cache.GetCertificateForIdentity(id1000)
+	if err != nil {
+		t.Fatalf("GetCertificateForIdentity() error = %v", err)
+	}
+	if first != second {
+		t.Errorf("GetCertificateForIdentity() did not return the cached certificate")
+	}
+	if counting.calls != 1 {
+		t.Errorf("provider was called %d times, want 1", counting.calls)
+	}
+
+	// A rotation event drops the cached entry; the event itself is forwarded
+	// to the subscriber after the invalidation.
+	provider.RotateIdentity(id1000, false)
+	event := <-rotated
+	if event.Identity != id1000 {
+		t.Errorf("forwarded rotation event for identity %s, want %s", event.Identity, id1000)
+	}
+	if _, err := cache.GetCertificateForIdentity(id1000); err != nil {
+		t.Fatalf("GetCertificateForIdentity() after rotation error = %v", err)
+	}
+	if counting.calls != 2 {
+		t.Errorf("provider was called %d times after rotation, want 2", counting.calls)
+	}
+}
+
+func Test_certificateCache_expiry(t *testing.T) {
+	provider := fake.NewProvider()
+	provider.SetCertificateLifetime(-time.Minute) // already expired when issued
+	counting := &countingCertProvider{CertificateProvider: provider}
+	cache := newCertificateCache(logrus.New(), counting, nil)
+
+	cache.GetCertificateForIdentity(id1000)
+	cache.GetCertificateForIdentity(id1000)
+	if counting.calls != 2 {
+		t.Errorf("provider was called %d times for an expired certificate, want 2", counting.calls)
+	}
+}
+
+func Test_certificateCache_prefetch(t *testing.T) {
+	provider := fake.NewProvider()
+	counting := &countingCertProvider{CertificateProvider: provider}
+	cache := newCertificateCache(logrus.New(), counting, &fakeEndpointGetter{})
+
+	if err := cache.prefetch(context.Background()); err != nil {
+		t.Fatalf("prefetch() error = %v", err)
+	}
+	warmed := counting.calls
+	if warmed == 0 {
+		t.Fatalf("prefetch() did not warm any certificates")
+	}
+
+	// The endpoint identities are answered from the warm cache, and a
+	// repeated pass fetches nothing new.
+	if _, err := cache.GetCertificateForIdentity(id1000); err != nil {
+		t.Fatalf("GetCertificateForIdentity() error = %v", err)
+	}
+	if err := cache.prefetch(context.Background()); err != nil {
+		t.Fatalf("prefetch() error = %v", err)
+	}
+	if counting.calls != warmed {
+		t.Errorf("provider was called %d times after the warm-up, want %d", counting.calls, warmed)
+	}
+}
+
+func Test_certificateCache_provider(t *testing.T) {
+	// The fake provider does not track per-trust-domain bundles, the plain
+	// cache is exposed.
+	plain := newCertificateCache(logrus.New(), fake.NewProvider(), nil).provider()
+	if _, ok := plain.(certs.FederatedTrustProvider); ok {
+		t.Errorf("provider() over a plain provider must not expose per-trust-domain bundles")
+	}
+}
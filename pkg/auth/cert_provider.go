@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"fmt"
+
+	"github.com/cilium/hive/cell"
+	"github.com/cilium/hive/job"
+	"github.com/sirupsen/logrus"
+
+	"github.com/cilium/cilium/pkg/auth/certs"
+	"github.com/cilium/cilium/pkg/auth/filecerts"
+	"github.com/cilium/cilium/pkg/auth/spire"
+	"github.com/cilium/cilium/pkg/auth/vaultcerts"
+	"github.com/cilium/cilium/pkg/endpointmanager"
+)
+
+// Values of the --mesh-auth-cert-provider flag.
+const (
+	certProviderSpire = "spire"
+	certProviderFile  = "file"
+	certProviderVault = "vault"
+)
+
+type certProviderParams struct {
+	cell.In
+
+	Logger   logrus.FieldLogger
+	JobGroup job.Group
+	Config   config
+
+	SpireClient     *spire.SpireDelegateClient
+	FileProvider    *filecerts.Provider
+	VaultProvider   *vaultcerts.Provider
+	EndpointManager endpointmanager.EndpointManager
+}
+
+// providerByName resolves a certificate provider flag value to its
+// implementation.
+func providerByName(name string, params certProviderParams) (certs.CertificateProvider, error) {
+	switch name {
+	case certProviderSpire:
+		if params.SpireClient == nil {
+			return nil, fmt.Errorf("certificate provider %q is selected, but no SPIRE admin socket is configured", certProviderSpire)
+		}
+		return params.SpireClient, nil
+	case certProviderFile:
+		if params.FileProvider == nil {
+			return nil, fmt.Errorf("certificate provider %q is selected, but no certificate directory is configured", certProviderFile)
+		}
+		return params.FileProvider, nil
+	case certProviderVault:
+		if params.VaultProvider == nil {
+			return nil, fmt.Errorf("certificate provider %q is selected, but no Vault address is configured", certProviderVault)
+		}
+		return params.VaultProvider, nil
+	default:
+		return nil, fmt.Errorf("unsupported certificate provider %q", name)
+	}
+}
+
+// selectCertificateProvider selects the certificate provider implementation
+// backing the mutual auth handshakes. The SPIRE delegate client remains the
+// default, the file based provider serves clusters without a SPIRE
+// deployment, e.g. air-gapped ones. Configured fallback providers are
+// chained behind the primary, so a handshake keeps working while the primary
+// provider is unavailable. With the certificate cache enabled, the selected
+// provider is wrapped so repeat handshakes for the same identity are
+// answered without a provider round trip.
+func selectCertificateProvider(params certProviderParams) (certs.CertificateProvider, error) {
+	primaryName := params.Config.MeshAuthCertProvider
+	if primaryName == "" {
+		primaryName = certProviderSpire
+	}
+	if primaryName == certProviderSpire && params.SpireClient == nil {
+		// The mutual auth handler reports degraded health if it is
+		// enabled without a certificate provider.
+		return nil, nil
+	}
+
+	provider, err := providerByName(primaryName, params)
+	if err != nil {
+		return nil, err
+	}
+	switch primaryName {
+	case certProviderFile:
+		params.Logger.Info("Using the file based certificate provider for mutual authentication")
+	case certProviderVault:
+		params.Logger.Info("Using the Vault PKI certificate provider for mutual authentication")
+	}
+
+	if fallbacks := params.Config.MeshAuthCertProviderFallbacks; len(fallbacks) > 0 {
+		chain := []certs.CertificateProvider{provider}
+		seen := map[string]bool{primaryName: true}
+		for _, name := range fallbacks {
+			if seen[name] {
+				return nil, fmt.Errorf("certificate provider %q is listed more than once in the provider chain", name)
+			}
+			seen[name] = true
+			fallback, err := providerByName(name, params)
+			if err != nil {
+				return nil, err
+			}
+			chain = append(chain, fallback)
+		}
+		params.Logger.
+			WithField("providers", append([]string{primaryName}, fallbacks...)).
+			Info("Using a fallback chain of certificate providers for mutual authentication")
+		provider = newFallbackCertificateProvider(params.Logger, chain)
+	}
+
+	if params.Config.MeshAuthCertCache {
+		cache := newCertificateCache(params.Logger, provider, params.EndpointManager)
+		if interval := params.Config.MeshAuthCertPrefetchInterval; interval > 0 {
+			params.JobGroup.Add(job.Timer("auth-cert-prefetch", cache.prefetch, interval))
+		}
+		return cache.provider(), nil
+	}
+	return provider, nil
+}
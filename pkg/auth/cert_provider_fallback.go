@@ -0,0 +1,201 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/cilium/cilium/api/v1/models"
+	"github.com/cilium/cilium/pkg/auth/certs"
+	"github.com/cilium/cilium/pkg/identity"
+	"github.com/cilium/cilium/pkg/logging/logfields"
+)
+
+// fallbackCertificateProvider chains an ordered list of certificate
+// providers. Operations are served by the first provider in the chain able to
+// answer them, so a handshake keeps working from a secondary source (e.g.
+// file based certificates) while the primary provider (e.g. SPIRE) is
+// unavailable. For verification to succeed across the chain, the trust
+// bundles of the providers must cover each other's CAs, e.g. by including
+// the fallback CA in the bundle distributed by the primary provider.
+type fallbackCertificateProvider struct {
+	log   logrus.FieldLogger
+	chain []certs.CertificateProvider
+
+	// rotated merges the rotation events of all providers in the chain,
+	// see SubscribeToRotatedIdentities.
+	subscribeOnce sync.Once
+	rotated       chan certs.CertificateRotationEvent
+}
+
+// newFallbackCertificateProvider chains the given providers in order. The
+// returned provider exposes per-trust-domain bundles if any chain member
+// tracks them.
+func newFallbackCertificateProvider(log logrus.FieldLogger, chain []certs.CertificateProvider) certs.CertificateProvider {
+	provider := &fallbackCertificateProvider{
+		log:   log,
+		chain: chain,
+	}
+	for _, p := range chain {
+		if _, ok := p.(certs.FederatedTrustProvider); ok {
+			return &federatedFallbackCertificateProvider{fallbackCertificateProvider: provider}
+		}
+	}
+	return provider
+}
+
+// federatedFallbackCertificateProvider additionally exposes the
+// per-trust-domain bundles of the chain members tracking them, which the
+// plain chain type would hide from the optional interface assertion in the
+// handshake path.
+type federatedFallbackCertificateProvider struct {
+	*fallbackCertificateProvider
+}
+
+func (f *federatedFallbackCertificateProvider) GetTrustBundleForDomain(trustDomain string) (*x509.CertPool, error) {
+	var errs error
+	for _, p := range f.chain {
+		federated, ok := p.(certs.FederatedTrustProvider)
+		if !ok {
+			continue
+		}
+		bundle, err := federated.GetTrustBundleForDomain(trustDomain)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		return bundle, nil
+	}
+	return nil, errs
+}
+
+func (c *fallbackCertificateProvider) GetTrustBundle() (*x509.CertPool, error) {
+	var errs error
+	for _, p := range c.chain {
+		bundle, err := p.GetTrustBundle()
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		return bundle, nil
+	}
+	return nil, errs
+}
+
+func (c *fallbackCertificateProvider) GetCertificateForIdentity(id identity.NumericIdentity) (*tls.Certificate, error) {
+	var errs error
+	for i, p := range c.chain {
+		cert, err := p.GetCertificateForIdentity(id)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		if i > 0 {
+			c.log.
+				WithField(logfields.Identity, id).
+				Debug("Certificate served by a fallback certificate provider")
+		}
+		return cert, nil
+	}
+	return nil, errs
+}
+
+func (c *fallbackCertificateProvider) ValidateIdentity(id identity.NumericIdentity, cert *x509.Certificate) (bool, error) {
+	var errs error
+	for _, p := range c.chain {
+		valid, err := p.ValidateIdentity(id, cert)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		// The first provider able to evaluate the certificate decides.
+		return valid, nil
+	}
+	return false, errs
+}
+
+// NumericIdentityToSNI returns the encoding of the primary provider. The
+// identity encodings are shared across the chain, a handshake initiated
+// under one provider can be answered from another.
+func (c *fallbackCertificateProvider) NumericIdentityToSNI(id identity.NumericIdentity) string {
+	return c.chain[0].NumericIdentityToSNI(id)
+}
+
+func (c *fallbackCertificateProvider) NumericIdentityToSPIFFEID(id identity.NumericIdentity) string {
+	return c.chain[0].NumericIdentityToSPIFFEID(id)
+}
+
+func (c *fallbackCertificateProvider) SNIToNumericIdentity(sni string) (identity.NumericIdentity, error) {
+	var errs error
+	for _, p := range c.chain {
+		id, err := p.SNIToNumericIdentity(sni)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		return id, nil
+	}
+	return 0, errs
+}
+
+// SubscribeToRotatedIdentities merges the rotation events of all providers
+// in the chain, so certificates rotated by any of them re-trigger the
+// affected handshakes.
+func (c *fallbackCertificateProvider) SubscribeToRotatedIdentities() <-chan certs.CertificateRotationEvent {
+	c.subscribeOnce.Do(func() {
+		var upstreams []<-chan certs.CertificateRotationEvent
+		for _, p := range c.chain {
+			if upstream := p.SubscribeToRotatedIdentities(); upstream != nil {
+				upstreams = append(upstreams, upstream)
+			}
+		}
+		if len(upstreams) == 0 {
+			return
+		}
+
+		c.rotated = make(chan certs.CertificateRotationEvent)
+		var wg sync.WaitGroup
+		for _, upstream := range upstreams {
+			wg.Add(1)
+			go func(upstream <-chan certs.CertificateRotationEvent) {
+				defer wg.Done()
+				for event := range upstream {
+					c.rotated <- event
+				}
+			}(upstream)
+		}
+		go func() {
+			wg.Wait()
+			close(c.rotated)
+		}()
+	})
+	return c.rotated
+}
+
+// Status reports the status of the primary provider, degraded to a warning
+// while a fallback provider stands in for a failed primary.
+func (c *fallbackCertificateProvider) Status() *models.Status {
+	primary := c.chain[0].Status()
+	if primary == nil || primary.State != models.StatusStateFailure {
+		return primary
+	}
+
+	for i, p := range c.chain[1:] {
+		status := p.Status()
+		if status != nil && status.State == models.StatusStateFailure {
+			continue
+		}
+		return &models.Status{
+			State: models.StatusStateWarning,
+			Msg:   fmt.Sprintf("Primary certificate provider unavailable (%s), serving from fallback provider %d", primary.Msg, i+1),
+		}
+	}
+	return primary
+}
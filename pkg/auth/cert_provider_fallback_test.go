@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"crypto/tls"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cilium/cilium/api/v1/models"
+	"github.com/cilium/cilium/pkg/auth/certs"
+	"github.com/cilium/cilium/pkg/identity"
+)
+
+// failingCertProvider simulates an unavailable certificate provider.
+type failingCertProvider struct {
+	certs.CertificateProvider
+}
+
+func (f *failingCertProvider) GetCertificateForIdentity(id identity.NumericIdentity) (*tls.Certificate, error) {
+	return nil, errors.New("provider unavailable")
+}
+
+func (f *failingCertProvider) Status() *models.Status {
+	return &models.Status{State: models.StatusStateFailure, Msg: "provider unavailable"}
+}
+
+func Test_fallbackCertificateProvider(t *testing.T) {
+	certMap, keyMap, caPool := generateTestCertificates(t)
+	working := &fakeCertificateProvider{certMap: certMap, caPool: caPool, privkeyMap: keyMap}
+	failing := &failingCertProvider{CertificateProvider: working}
+
+	provider := newFallbackCertificateProvider(logrus.New(),
+		[]certs.CertificateProvider{failing, working})
+
+	// The certificate is served by the fallback provider while the primary
+	// is unavailable.
+	cert, err := provider.GetCertificateForIdentity(id1000)
+	require.NoError(t, err)
+	assert.NotNil(t, cert)
+
+	// The chain reports a warning instead of the primary's failure.
+	status := provider.Status()
+	require.NotNil(t, status)
+	assert.Equal(t, models.StatusStateWarning, status.State)
+
+	// With all providers unavailable, the error and the primary's failure
+	// surface.
+	provider = newFallbackCertificateProvider(logrus.New(),
+		[]certs.CertificateProvider{failing, failing})
+	_, err = provider.GetCertificateForIdentity(id1000)
+	assert.Error(t, err)
+	status = provider.Status()
+	require.NotNil(t, status)
+	assert.Equal(t, models.StatusStateFailure, status.State)
+}
+
+func Test_fallbackCertificateProvider_healthyPrimary(t *testing.T) {
+	certMap, keyMap, caPool := generateTestCertificates(t)
+	working := &fakeCertificateProvider{certMap: certMap, caPool: caPool, privkeyMap: keyMap}
+
+	provider := newFallbackCertificateProvider(logrus.New(),
+		[]certs.CertificateProvider{working, &failingCertProvider{CertificateProvider: working}})
+
+	cert, err := provider.GetCertificateForIdentity(id1000)
+	require.NoError(t, err)
+	assert.NotNil(t, cert)
+
+	id, err := provider.SNIToNumericIdentity("1000.spiffe.cilium")
+	require.NoError(t, err)
+	assert.Equal(t, id1000, id)
+}
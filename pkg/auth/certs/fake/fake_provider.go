@@ -0,0 +1,227 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package fake
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+
+	"github.com/cilium/hive/cell"
+
+	"github.com/cilium/cilium/api/v1/models"
+	"github.com/cilium/cilium/pkg/auth/certs"
+	"github.com/cilium/cilium/pkg/identity"
+	"github.com/cilium/cilium/pkg/lock"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// Cell provides an in-memory certificate provider which issues certificates
+// from a self-signed CA without requiring a running SPIRE deployment. It is
+// intended for integration tests and downstream projects exercising the auth
+// handlers.
+var Cell = cell.Module(
+	"fake-cert-provider",
+	"Fake Certificate Provider",
+
+	cell.Provide(func() certs.CertificateProvider { return NewProvider() }),
+)
+
+const (
+	fakeTrustDomain = "spiffe.cilium"
+
+	// rotationQueueSize mirrors the default queue size of the SPIRE delegate client.
+	rotationQueueSize = 1024
+)
+
+// Provider is an in-memory implementation of certs.CertificateProvider.
+// Certificates for identities are issued on demand from a CA generated at
+// construction time. Rotation events and failures can be scripted by tests.
+type Provider struct {
+	caCert *x509.Certificate
+	caKey  *ecdsa.PrivateKey
+	caPool *x509.CertPool
+
+	certLifetime time.Duration
+
+	mutex       lock.RWMutex
+	issuedCerts map[identity.NumericIdentity]*tls.Certificate
+	failures    map[identity.NumericIdentity]error
+
+	rotatedIdentitiesChan chan certs.CertificateRotationEvent
+}
+
+// NewProvider creates a fake certificate provider with a freshly generated CA.
+func NewProvider() *Provider {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(fmt.Sprintf("failed to generate fake CA key: %v", err))
+	}
+	caTmpl := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "fake-cilium-ca"},
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		SerialNumber:          big.NewInt(1),
+		BasicConstraintsValid: true,
+	}
+	caCertBytes, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		panic(fmt.Sprintf("failed to sign fake CA certificate: %v", err))
+	}
+	caCert, err := x509.ParseCertificate(caCertBytes)
+	if err != nil {
+		panic(fmt.Sprintf("failed to parse fake CA certificate: %v", err))
+	}
+
+	caPool := x509.NewCertPool()
+	caPool.AddCert(caCert)
+
+	return &Provider{
+		caCert:                caCert,
+		caKey:                 caKey,
+		caPool:                caPool,
+		certLifetime:          1 * time.Hour,
+		issuedCerts:           map[identity.NumericIdentity]*tls.Certificate{},
+		failures:              map[identity.NumericIdentity]error{},
+		rotatedIdentitiesChan: make(chan certs.CertificateRotationEvent, rotationQueueSize),
+	}
+}
+
+func (f *Provider) GetTrustBundle() (*x509.CertPool, error) {
+	return f.caPool, nil
+}
+
+func (f *Provider) GetCertificateForIdentity(id identity.NumericIdentity) (*tls.Certificate, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err, ok := f.failures[id]; ok {
+		return nil, err
+	}
+
+	if cert, ok := f.issuedCerts[id]; ok {
+		return cert, nil
+	}
+
+	cert, err := f.issueCertificate(id)
+	if err != nil {
+		return nil, err
+	}
+	f.issuedCerts[id] = cert
+
+	return cert, nil
+}
+
+func (f *Provider) issueCertificate(id identity.NumericIdentity) (*tls.Certificate, error) {
+	spiffeURI, err := url.Parse(f.spiffeID(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SPIFFE ID: %w", err)
+	}
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate leaf key: %w", err)
+	}
+	leafTmpl := &x509.Certificate{
+		NotAfter:     time.Now().Add(f.certLifetime),
+		URIs:         []*url.URL{spiffeURI},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		SerialNumber: big.NewInt(int64(id)),
+	}
+	leafCertBytes, err := x509.CreateCertificate(rand.Reader, leafTmpl, f.caCert, &leafKey.PublicKey, f.caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign leaf certificate: %w", err)
+	}
+	leafCert, err := x509.ParseCertificate(leafCertBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse leaf certificate: %w", err)
+	}
+
+	return &tls.Certificate{
+		Certificate: [][]byte{leafCertBytes},
+		PrivateKey:  leafKey,
+		Leaf:        leafCert,
+	}, nil
+}
+
+func (f *Provider) ValidateIdentity(id identity.NumericIdentity, cert *x509.Certificate) (bool, error) {
+	if len(cert.URIs) != 1 {
+		return false, fmt.Errorf("SPIFFE IDs must have exactly one URI SAN")
+	}
+	return cert.URIs[0].String() == f.spiffeID(id), nil
+}
+
+func (f *Provider) NumericIdentityToSNI(id identity.NumericIdentity) string {
+	return id.String() + "." + fakeTrustDomain
+}
+
+func (f *Provider) NumericIdentityToSPIFFEID(id identity.NumericIdentity) string {
+	return f.spiffeID(id)
+}
+
+func (f *Provider) SNIToNumericIdentity(sni string) (identity.NumericIdentity, error) {
+	suffix := "." + fakeTrustDomain
+	if !strings.HasSuffix(sni, suffix) {
+		return 0, fmt.Errorf("SNI %s does not belong to our trust domain", sni)
+	}
+	return identity.ParseNumericIdentity(strings.TrimSuffix(sni, suffix))
+}
+
+func (f *Provider) SubscribeToRotatedIdentities() <-chan certs.CertificateRotationEvent {
+	return f.rotatedIdentitiesChan
+}
+
+func (f *Provider) Status() *models.Status {
+	return &models.Status{
+		State: models.StatusStateOk,
+	}
+}
+
+func (f *Provider) spiffeID(id identity.NumericIdentity) string {
+	return "spiffe://" + fakeTrustDomain + "/identity/" + id.String()
+}
+
+// SetCertificateLifetime changes the lifetime of certificates issued after the call.
+func (f *Provider) SetCertificateLifetime(lifetime time.Duration) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.certLifetime = lifetime
+}
+
+// FailIdentity makes certificate requests for the given identity fail with
+// the provided error until ClearFailure is called.
+func (f *Provider) FailIdentity(id identity.NumericIdentity, err error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.failures[id] = err
+}
+
+// ClearFailure removes a previously scripted failure for the given identity.
+func (f *Provider) ClearFailure(id identity.NumericIdentity) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	delete(f.failures, id)
+}
+
+// RotateIdentity drops the cached certificate for the given identity - forcing
+// a re-issuance on next use - and emits a rotation event to subscribers.
+func (f *Provider) RotateIdentity(id identity.NumericIdentity, deleted bool) {
+	f.mutex.Lock()
+	delete(f.issuedCerts, id)
+	f.mutex.Unlock()
+
+	f.rotatedIdentitiesChan <- certs.CertificateRotationEvent{
+		Identity: id,
+		Deleted:  deleted,
+	}
+}
@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+)
+
+// minFIPSRSABits is the minimum RSA modulus size approved by NIST SP 800-57.
+const minFIPSRSABits = 2048
+
+// fipsSignatureAlgorithms are the certificate signature algorithms accepted
+// in FIPS mode. SHA-1 based and other legacy algorithms are rejected, as is
+// Ed25519, which is not supported by BoringCrypto.
+var fipsSignatureAlgorithms = map[x509.SignatureAlgorithm]struct{}{
+	x509.SHA256WithRSA:    {},
+	x509.SHA384WithRSA:    {},
+	x509.SHA512WithRSA:    {},
+	x509.SHA256WithRSAPSS: {},
+	x509.SHA384WithRSAPSS: {},
+	x509.SHA512WithRSAPSS: {},
+	x509.ECDSAWithSHA256:  {},
+	x509.ECDSAWithSHA384:  {},
+	x509.ECDSAWithSHA512:  {},
+}
+
+// checkFIPSCompliance rejects certificates using public keys or signature
+// algorithms outside the FIPS-approved set.
+func checkFIPSCompliance(cert *x509.Certificate) error {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < minFIPSRSABits {
+			return fmt.Errorf("RSA key size %d is below the FIPS minimum of %d bits", key.N.BitLen(), minFIPSRSABits)
+		}
+	case *ecdsa.PublicKey:
+		switch key.Curve {
+		case elliptic.P256(), elliptic.P384(), elliptic.P521():
+		default:
+			return fmt.Errorf("ECDSA curve %s is not FIPS-approved", key.Curve.Params().Name)
+		}
+	default:
+		return fmt.Errorf("public key algorithm %s is not FIPS-approved", cert.PublicKeyAlgorithm)
+	}
+
+	if _, ok := fipsSignatureAlgorithms[cert.SignatureAlgorithm]; !ok {
+		return fmt.Errorf("signature algorithm %s is not FIPS-approved", cert.SignatureAlgorithm)
+	}
+
+	return nil
+}
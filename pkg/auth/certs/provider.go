@@ -22,7 +22,10 @@ type CertificateProvider interface {
 	GetTrustBundle() (*x509.CertPool, error)
 
 	// GetCertificateForIdentity gives the certificate and intermediates required
-	// to send as trust chain for a certain identity as well as a private key
+	// to send as trust chain for a certain identity as well as a private key.
+	// The private key must implement crypto.Signer, but may be opaque (e.g.
+	// backed by a PKCS#11 HSM or a TPM) - consumers must not assume one of the
+	// in-memory key types.
 	GetCertificateForIdentity(id identity.NumericIdentity) (*tls.Certificate, error)
 
 	// ValidateIdentity will check if the SANs or other identity methods are valid
@@ -33,6 +36,9 @@ type CertificateProvider interface {
 	// NumericIdentityToSNI will return the SNI that should be used for a given Cilium Identity
 	NumericIdentityToSNI(id identity.NumericIdentity) string
 
+	// NumericIdentityToSPIFFEID will return the SPIFFE ID for a given Cilium Identity
+	NumericIdentityToSPIFFEID(id identity.NumericIdentity) string
+
 	// SNIToNumericIdentity will return the Cilium Identity for a given SNI
 	SNIToNumericIdentity(sni string) (identity.NumericIdentity, error)
 
@@ -42,3 +48,14 @@ type CertificateProvider interface {
 	// Status will return the status of the certificate provider
 	Status() *models.Status
 }
+
+// FederatedTrustProvider is optionally implemented by certificate providers
+// which track the CA pool of each trust domain separately. Verification code
+// uses it to verify a peer certificate against the bundle of its own trust
+// domain only, as required by SPIFFE federation - the CA of one federated
+// trust domain must not be able to issue identities of another.
+type FederatedTrustProvider interface {
+	// GetTrustBundleForDomain gives the CA pool of the given trust domain,
+	// or an error if the trust domain is unknown.
+	GetTrustBundleForDomain(trustDomain string) (*x509.CertPool, error)
+}
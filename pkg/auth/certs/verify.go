@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package certs
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+)
+
+// VerifyConfig controls how a Verifier validates peer certificate chains.
+type VerifyConfig struct {
+	// KeyUsages are the extended key usages the leaf certificate must be
+	// valid for. If empty, x509.ExtKeyUsageServerAuth is assumed by the
+	// x509 library.
+	KeyUsages []x509.ExtKeyUsage
+
+	// MaxChainDepth limits the length of the verified chains including the
+	// leaf and the root. A value of 0 disables the depth check.
+	MaxChainDepth int
+
+	// StrictSAN requires the leaf certificate to carry exactly one URI SAN,
+	// as mandated by the SPIFFE specification for SVIDs.
+	StrictSAN bool
+
+	// FIPS restricts the presented certificates to FIPS-approved public key
+	// and signature algorithms.
+	FIPS bool
+}
+
+// Verifier validates peer certificate chains against a CA trust bundle.
+// It contains the chain-building and SAN validation logic shared by the
+// consumers of TLS connections between Cilium components.
+type Verifier struct {
+	cfg VerifyConfig
+}
+
+// NewVerifier returns a Verifier validating chains according to the given config.
+func NewVerifier(cfg VerifyConfig) *Verifier {
+	return &Verifier{cfg: cfg}
+}
+
+// VerifyChain builds and verifies a certificate chain presented by a peer
+// against the given CA bundle. CA certificates in the presented chain are
+// used as intermediates. It returns the verified leaf certificate.
+func (v *Verifier) VerifyChain(caBundle *x509.CertPool, chain []*x509.Certificate) (*x509.Certificate, error) {
+	opts := x509.VerifyOptions{
+		Roots:         caBundle,
+		Intermediates: x509.NewCertPool(),
+		KeyUsages:     v.cfg.KeyUsages,
+	}
+
+	var leaf *x509.Certificate
+	for _, cert := range chain {
+		if cert.IsCA {
+			opts.Intermediates.AddCert(cert)
+		} else {
+			leaf = cert
+		}
+	}
+	if leaf == nil {
+		return nil, errors.New("no leaf certificate found")
+	}
+
+	verifiedChains, err := leaf.Verify(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify certificate: %w", err)
+	}
+
+	if v.cfg.MaxChainDepth > 0 {
+		for _, verifiedChain := range verifiedChains {
+			if len(verifiedChain) > v.cfg.MaxChainDepth {
+				return nil, fmt.Errorf("certificate chain length %d exceeds maximum depth %d", len(verifiedChain), v.cfg.MaxChainDepth)
+			}
+		}
+	}
+
+	if v.cfg.FIPS {
+		for _, cert := range chain {
+			if err := checkFIPSCompliance(cert); err != nil {
+				return nil, fmt.Errorf("certificate %q is not FIPS-compliant: %w", cert.Subject, err)
+			}
+		}
+	}
+
+	if v.cfg.StrictSAN && len(leaf.URIs) != 1 {
+		return nil, fmt.Errorf("expected exactly one URI SAN on the leaf certificate, got %d", len(leaf.URIs))
+	}
+
+	return leaf, nil
+}
+
+// VerifyRawChain parses and verifies the raw DER certificates presented
+// during a TLS handshake. It is intended to be used from a tls.Config
+// VerifyPeerCertificate callback where verifiedChains is nil due to
+// InsecureSkipVerify.
+func (v *Verifier) VerifyRawChain(caBundle *x509.CertPool, rawCerts [][]byte) (*x509.Certificate, error) {
+	chain := make([]*x509.Certificate, len(rawCerts))
+	for i, rawCert := range rawCerts {
+		cert, err := x509.ParseCertificate(rawCert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		chain[i] = cert
+	}
+
+	return v.VerifyChain(caBundle, chain)
+}
@@ -0,0 +1,198 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func testCA(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey, *x509.CertPool) {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate CA key: %v", err)
+	}
+	caTmpl := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		SerialNumber:          big.NewInt(1),
+		BasicConstraintsValid: true,
+	}
+	caCertBytes, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to sign CA certificate: %v", err)
+	}
+	caCert, err := x509.ParseCertificate(caCertBytes)
+	if err != nil {
+		t.Fatalf("failed to parse CA certificate: %v", err)
+	}
+
+	caPool := x509.NewCertPool()
+	caPool.AddCert(caCert)
+	return caCert, caKey, caPool
+}
+
+func testLeaf(t *testing.T, caCert *x509.Certificate, caKey *ecdsa.PrivateKey, uris []*url.URL) *x509.Certificate {
+	t.Helper()
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate leaf key: %v", err)
+	}
+	leafTmpl := &x509.Certificate{
+		NotAfter:     time.Now().Add(time.Hour),
+		URIs:         uris,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		SerialNumber: big.NewInt(2),
+	}
+	leafCertBytes, err := x509.CreateCertificate(rand.Reader, leafTmpl, caCert, &leafKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to sign leaf certificate: %v", err)
+	}
+	leafCert, err := x509.ParseCertificate(leafCertBytes)
+	if err != nil {
+		t.Fatalf("failed to parse leaf certificate: %v", err)
+	}
+	return leafCert
+}
+
+func TestVerifierVerifyChain(t *testing.T) {
+	caCert, caKey, caPool := testCA(t)
+	_, _, otherCAPool := testCA(t)
+
+	spiffeURI, err := url.Parse("spiffe://spiffe.cilium/identity/1000")
+	if err != nil {
+		t.Fatalf("failed to parse URI: %v", err)
+	}
+	leaf := testLeaf(t, caCert, caKey, []*url.URL{spiffeURI})
+	leafNoSAN := testLeaf(t, caCert, caKey, nil)
+
+	ed25519Pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate Ed25519 key: %v", err)
+	}
+	ed25519Tmpl := &x509.Certificate{
+		NotAfter:     time.Now().Add(time.Hour),
+		URIs:         []*url.URL{spiffeURI},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		SerialNumber: big.NewInt(3),
+	}
+	ed25519LeafBytes, err := x509.CreateCertificate(rand.Reader, ed25519Tmpl, caCert, ed25519Pub, caKey)
+	if err != nil {
+		t.Fatalf("failed to sign Ed25519 leaf certificate: %v", err)
+	}
+	ed25519Leaf, err := x509.ParseCertificate(ed25519LeafBytes)
+	if err != nil {
+		t.Fatalf("failed to parse Ed25519 leaf certificate: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		cfg     VerifyConfig
+		caPool  *x509.CertPool
+		chain   []*x509.Certificate
+		wantErr bool
+	}{
+		{
+			name:   "valid chain",
+			caPool: caPool,
+			chain:  []*x509.Certificate{leaf},
+		},
+		{
+			name:    "chain signed by other CA",
+			caPool:  otherCAPool,
+			chain:   []*x509.Certificate{leaf},
+			wantErr: true,
+		},
+		{
+			name:    "no leaf certificate",
+			caPool:  caPool,
+			chain:   []*x509.Certificate{caCert},
+			wantErr: true,
+		},
+		{
+			name:    "strict SAN rejects leaf without URI SAN",
+			cfg:     VerifyConfig{StrictSAN: true},
+			caPool:  caPool,
+			chain:   []*x509.Certificate{leafNoSAN},
+			wantErr: true,
+		},
+		{
+			name:   "strict SAN accepts single URI SAN",
+			cfg:    VerifyConfig{StrictSAN: true},
+			caPool: caPool,
+			chain:  []*x509.Certificate{leaf},
+		},
+		{
+			name:    "chain exceeding max depth",
+			cfg:     VerifyConfig{MaxChainDepth: 1},
+			caPool:  caPool,
+			chain:   []*x509.Certificate{leaf},
+			wantErr: true,
+		},
+		{
+			name:   "chain within max depth",
+			cfg:    VerifyConfig{MaxChainDepth: 2},
+			caPool: caPool,
+			chain:  []*x509.Certificate{leaf},
+		},
+		{
+			name:   "FIPS accepts P-256 leaf",
+			cfg:    VerifyConfig{FIPS: true},
+			caPool: caPool,
+			chain:  []*x509.Certificate{leaf},
+		},
+		{
+			name:    "FIPS rejects Ed25519 leaf",
+			cfg:     VerifyConfig{FIPS: true},
+			caPool:  caPool,
+			chain:   []*x509.Certificate{ed25519Leaf},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewVerifier(tt.cfg).VerifyChain(tt.caPool, tt.chain)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verifier.VerifyChain() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got == nil {
+				t.Errorf("Verifier.VerifyChain() returned no leaf certificate")
+			}
+		})
+	}
+}
+
+func TestVerifierVerifyRawChain(t *testing.T) {
+	caCert, caKey, caPool := testCA(t)
+
+	spiffeURI, err := url.Parse("spiffe://spiffe.cilium/identity/1000")
+	if err != nil {
+		t.Fatalf("failed to parse URI: %v", err)
+	}
+	leaf := testLeaf(t, caCert, caKey, []*url.URL{spiffeURI})
+
+	if _, err := NewVerifier(VerifyConfig{}).VerifyRawChain(caPool, [][]byte{leaf.Raw}); err != nil {
+		t.Errorf("Verifier.VerifyRawChain() error = %v", err)
+	}
+
+	if _, err := NewVerifier(VerifyConfig{}).VerifyRawChain(caPool, [][]byte{{0x01, 0x02}}); err == nil {
+		t.Errorf("Verifier.VerifyRawChain() expected error on garbage input")
+	}
+}
@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"crypto/x509"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/cilium/cilium/pkg/metrics"
+	"github.com/cilium/cilium/pkg/metrics/metric"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+type clockSkewMetrics struct {
+	// SkewSuspected counts handshake failures on certificate validity
+	// windows which look like they are caused by node clock skew.
+	SkewSuspected metric.Counter
+}
+
+func newClockSkewMetrics() *clockSkewMetrics {
+	return &clockSkewMetrics{
+		SkewSuspected: metric.NewCounter(metric.CounterOpts{
+			ConfigName: metrics.Namespace + "_mutual_auth_clock_skew_suspected_total",
+			Namespace:  metrics.Namespace,
+			Subsystem:  "mutual_auth",
+			Name:       "clock_skew_suspected_total",
+			Help:       "Number of handshake failures on certificate validity windows suspected to be caused by node clock skew",
+		}),
+	}
+}
+
+// reportPossibleClockSkew inspects a certificate verification error and, if
+// it failed on the validity window, emits a dedicated clock skew warning,
+// metric and health degradation. Without this, skewed node clocks surface as
+// generic verification failures which are hard to diagnose.
+func (m *mutualAuthHandler) reportPossibleClockSkew(err error) {
+	var invalidErr x509.CertificateInvalidError
+	if !errors.As(err, &invalidErr) || invalidErr.Reason != x509.Expired {
+		return
+	}
+
+	now := time.Now()
+	scopedLog := m.log.WithError(err)
+	if cert := invalidErr.Cert; cert != nil {
+		scopedLog = scopedLog.WithFields(logrus.Fields{
+			"notBefore": cert.NotBefore,
+			"notAfter":  cert.NotAfter,
+			"now":       now,
+		})
+		if now.Before(cert.NotBefore) {
+			scopedLog = scopedLog.WithField("suspectedSkew", cert.NotBefore.Sub(now))
+		}
+	}
+	scopedLog.Warning("Clock skew suspected: certificate failed validation on its validity window. " +
+		"Check the clock synchronization of this node and its peers")
+
+	if m.skewMetrics != nil {
+		m.skewMetrics.SkewSuspected.Inc()
+	}
+	if m.health != nil {
+		m.health.Degraded("Clock skew suspected, certificates fail validation on their validity window", err)
+	}
+}
@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"github.com/sirupsen/logrus"
+
+	datapathTypes "github.com/cilium/cilium/pkg/datapath/types"
+	"github.com/cilium/cilium/pkg/lock"
+	"github.com/cilium/cilium/pkg/logging/logfields"
+	"github.com/cilium/cilium/pkg/node/addressing"
+	nodeManager "github.com/cilium/cilium/pkg/node/manager"
+	nodeTypes "github.com/cilium/cilium/pkg/node/types"
+)
+
+// meshNodeCache resolves node IDs of remote-cluster nodes learned via
+// ClusterMesh to a routable node IP. The datapath node handler resolves a
+// node ID to an arbitrary member of the node's IP set, which is good enough
+// within a cluster but may yield an address that is not routable from here
+// for nodes of other clusters. The cache records the node IP preferred by
+// the node object itself at event time instead, so mutual auth handshakes
+// with remote-cluster nodes dial an address the originating cluster
+// advertises for them.
+type meshNodeCache struct {
+	logger        logrus.FieldLogger
+	localCluster  string
+	nodeIDHandler datapathTypes.NodeIDHandler
+
+	mutex        lock.RWMutex
+	nodeIPsByIDs map[uint16]string
+}
+
+func newMeshNodeCache(logger logrus.FieldLogger, localCluster string, nodeIDHandler datapathTypes.NodeIDHandler) *meshNodeCache {
+	return &meshNodeCache{
+		logger:        logger,
+		localCluster:  localCluster,
+		nodeIDHandler: nodeIDHandler,
+		nodeIPsByIDs:  map[uint16]string{},
+	}
+}
+
+func (c *meshNodeCache) subscribeToNodeEvents(nodeManager nodeManager.NodeManager) {
+	nodeManager.Subscribe(c)
+}
+
+// nodeIP returns the recorded node IP of a remote-cluster node, or "" if the
+// node ID does not belong to a node of another cluster.
+func (c *meshNodeCache) nodeIP(nodeID uint16) string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.nodeIPsByIDs[nodeID]
+}
+
+func (c *meshNodeCache) Name() string {
+	return "auth-mesh-node-cache"
+}
+
+func (c *meshNodeCache) NodeAdd(newNode nodeTypes.Node) error {
+	c.recordNode(newNode)
+	return nil
+}
+
+func (c *meshNodeCache) NodeUpdate(oldNode, newNode nodeTypes.Node) error {
+	c.recordNode(newNode)
+	return nil
+}
+
+func (c *meshNodeCache) NodeDelete(deletedNode nodeTypes.Node) error {
+	if !c.isMeshNode(deletedNode) {
+		return nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, nodeID := range c.nodeIDs(deletedNode) {
+		delete(c.nodeIPsByIDs, nodeID)
+	}
+
+	return nil
+}
+
+func (c *meshNodeCache) AllNodeValidateImplementation() {
+}
+
+func (c *meshNodeCache) NodeValidateImplementation(node nodeTypes.Node) error {
+	return nil
+}
+
+func (c *meshNodeCache) NodeConfigurationChanged(config datapathTypes.LocalNodeConfiguration) error {
+	return nil
+}
+
+// isMeshNode reports whether the node was learned from another cluster of a
+// ClusterMesh. Nodes of the local cluster stay with the datapath resolution.
+func (c *meshNodeCache) isMeshNode(node nodeTypes.Node) bool {
+	return node.Cluster != "" && node.Cluster != c.localCluster
+}
+
+func (c *meshNodeCache) recordNode(node nodeTypes.Node) {
+	if !c.isMeshNode(node) {
+		return
+	}
+
+	nodeIP := node.GetNodeIP(false)
+	if nodeIP == nil {
+		nodeIP = node.GetNodeIP(true)
+	}
+	if nodeIP == nil {
+		c.logger.
+			WithField(logfields.NodeName, node.Name).
+			WithField("cluster", node.Cluster).
+			Debug("No routable IP available for mesh node - skipping")
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, nodeID := range c.nodeIDs(node) {
+		c.nodeIPsByIDs[nodeID] = nodeIP.String()
+	}
+}
+
+// nodeIDs returns the node IDs allocated by the datapath for the addresses
+// of the node.
+func (c *meshNodeCache) nodeIDs(node nodeTypes.Node) []uint16 {
+	var nodeIDs []uint16
+
+	for _, addr := range node.IPAddresses {
+		if addr.Type != addressing.NodeInternalIP && addr.Type != addressing.NodeCiliumInternalIP {
+			continue
+		}
+		nodeID, exists := c.nodeIDHandler.GetNodeID(addr.IP)
+		if !exists {
+			// This might be the case at startup, when new nodes aren't yet
+			// known to the nodehandler and therefore no node id has been
+			// assigned to them. The node ID is picked up on the next update.
+			c.logger.
+				WithField(logfields.NodeName, node.Name).
+				WithField(logfields.IPAddr, addr.IP).
+				Debug("No node ID available for node IP - skipping")
+			continue
+		}
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+
+	return nodeIDs
+}
@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"net"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cilium/cilium/pkg/node/addressing"
+	nodeTypes "github.com/cilium/cilium/pkg/node/types"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+func meshNodeEvent(cluster, nodeInternalIP string) nodeTypes.Node {
+	return nodeTypes.Node{
+		Name:    "test-node",
+		Cluster: cluster,
+		IPAddresses: []nodeTypes.Address{
+			{
+				Type: addressing.NodeInternalIP,
+				IP:   net.ParseIP(nodeInternalIP),
+			},
+		},
+	}
+}
+
+func Test_meshNodeCache(t *testing.T) {
+	cache := newMeshNodeCache(logrus.New(), "cluster1",
+		newFakeNodeIDHandler(map[uint16]string{
+			10: "172.18.0.10",
+			20: "172.19.0.20",
+		}),
+	)
+
+	// Nodes of the local cluster stay with the datapath resolution.
+	require.NoError(t, cache.NodeAdd(meshNodeEvent("cluster1", "172.18.0.10")))
+	assert.Empty(t, cache.nodeIP(10))
+
+	require.NoError(t, cache.NodeAdd(meshNodeEvent("cluster2", "172.19.0.20")))
+	assert.Equal(t, "172.19.0.20", cache.nodeIP(20))
+
+	// The recorded address follows node updates and is dropped with the node.
+	require.NoError(t, cache.NodeUpdate(meshNodeEvent("cluster2", "172.19.0.20"), meshNodeEvent("cluster2", "172.19.0.20")))
+	assert.Equal(t, "172.19.0.20", cache.nodeIP(20))
+
+	require.NoError(t, cache.NodeDelete(meshNodeEvent("cluster2", "172.19.0.20")))
+	assert.Empty(t, cache.nodeIP(20))
+
+	// Nodes without an allocated node ID are skipped until the next update.
+	require.NoError(t, cache.NodeAdd(meshNodeEvent("cluster2", "172.19.0.99")))
+	assert.Empty(t, cache.nodeIP(20))
+}
+
+func Test_authManager_authenticate_meshNode(t *testing.T) {
+	authHandlers := []authHandler{newAlwaysPassAuthHandler(logrus.New())}
+	authMap := &fakeAuthMap{
+		entries: map[authKey]authInfo{},
+	}
+
+	am, err := newAuthManager(logrus.New(), authHandlers, authMap,
+		newFakeNodeIDHandler(map[uint16]string{}), // the datapath cannot resolve the node
+		time.Second,
+	)
+	require.NoError(t, err)
+
+	am.meshNodes = newMeshNodeCache(logrus.New(), "cluster1",
+		newFakeNodeIDHandler(map[uint16]string{42: "172.19.0.42"}),
+	)
+	require.NoError(t, am.meshNodes.NodeAdd(meshNodeEvent("cluster2", "172.19.0.42")))
+
+	err = am.authenticate(authKey{
+		localIdentity:  1000,
+		remoteIdentity: 2000,
+		remoteNodeID:   42,
+		authType:       100,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, authMap.entries, 1)
+}
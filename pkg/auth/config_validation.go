@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"crypto/tls"
+	"fmt"
+
+	"github.com/cilium/hive/cell"
+
+	"github.com/cilium/cilium/pkg/auth/spire"
+)
+
+type validateConfigParams struct {
+	cell.In
+
+	Config           config
+	MutualAuthConfig MutualAuthConfig
+	JWTAuthConfig    JWTAuthConfig
+	SpireConfig      spire.SpireDelegateConfig
+}
+
+// validateConfig performs cross-field validation of the auth configuration
+// at startup. Inconsistent combinations abort the agent start instead of
+// surfacing much later as hard-to-debug runtime failures.
+func validateConfig(params validateConfigParams) error {
+	if !params.Config.MeshAuthEnabled {
+		return nil
+	}
+
+	if params.Config.MeshAuthQueueSize <= 0 {
+		return fmt.Errorf("mesh-auth-queue-size must be greater than 0, got %d", params.Config.MeshAuthQueueSize)
+	}
+
+	if params.Config.MeshAuthMaxConcurrent < 0 {
+		return fmt.Errorf("mesh-auth-max-concurrent must not be negative, got %d", params.Config.MeshAuthMaxConcurrent)
+	}
+
+	if params.Config.MeshAuthGCInterval <= 0 {
+		return fmt.Errorf("mesh-auth-gc-interval must be greater than 0, got %s", params.Config.MeshAuthGCInterval)
+	}
+
+	if params.Config.MeshAuthGCExpiredGracePeriod < 0 {
+		return fmt.Errorf("mesh-auth-gc-expired-grace-period must not be negative, got %s", params.Config.MeshAuthGCExpiredGracePeriod)
+	}
+
+	switch params.Config.MeshAuthGCIdentityBehavior {
+	// An empty behavior falls back to the flag default ("purge").
+	case "", gcIdentityBehaviorPurge, gcIdentityBehaviorExpire:
+	default:
+		return fmt.Errorf("mesh-auth-gc-identity-behavior must be %s or %s, got %q",
+			gcIdentityBehaviorPurge, gcIdentityBehaviorExpire, params.Config.MeshAuthGCIdentityBehavior)
+	}
+
+	if params.Config.MeshAuthFaultInjectionRate < 0 || params.Config.MeshAuthFaultInjectionRate > 1 {
+		return fmt.Errorf("mesh-auth-fault-injection-rate must be between 0.0 and 1.0, got %f", params.Config.MeshAuthFaultInjectionRate)
+	}
+
+	if params.Config.MeshAuthDryRun && params.Config.MeshAuthDryRunGrantLifetime <= 0 {
+		return fmt.Errorf("mesh-auth-dry-run-grant-lifetime must be greater than 0 in dry-run mode, got %s", params.Config.MeshAuthDryRunGrantLifetime)
+	}
+
+	if params.MutualAuthConfig.MutualAuthListenerPort != 0 {
+		if params.MutualAuthConfig.MutualAuthListenerPort < 0 || params.MutualAuthConfig.MutualAuthListenerPort > 65535 {
+			return fmt.Errorf("mesh-auth-mutual-listener-port must be a valid port number, got %d", params.MutualAuthConfig.MutualAuthListenerPort)
+		}
+
+		if params.MutualAuthConfig.MutualAuthConnectTimeout <= 0 {
+			return fmt.Errorf("mesh-auth-mutual-connect-timeout must be greater than 0, got %s", params.MutualAuthConfig.MutualAuthConnectTimeout)
+		}
+
+		if params.MutualAuthConfig.MutualAuthHandshakeTimeout < 0 {
+			return fmt.Errorf("mesh-auth-mutual-handshake-timeout must not be negative, got %s", params.MutualAuthConfig.MutualAuthHandshakeTimeout)
+		}
+
+		if params.MutualAuthConfig.MutualAuthListenerRateLimit < 0 {
+			return fmt.Errorf("mesh-auth-mutual-listener-rate-limit must not be negative, got %d", params.MutualAuthConfig.MutualAuthListenerRateLimit)
+		}
+
+		if params.MutualAuthConfig.MutualAuthListenerRateLimit > 0 && params.MutualAuthConfig.MutualAuthListenerBanDuration <= 0 {
+			return fmt.Errorf("mesh-auth-mutual-listener-ban-duration must be greater than 0, got %s", params.MutualAuthConfig.MutualAuthListenerBanDuration)
+		}
+
+		switch params.MutualAuthConfig.MutualAuthNearExpiryAction {
+		// An empty action falls back to the flag default ("warn").
+		case "", nearExpiryActionProceed, nearExpiryActionWarn, nearExpiryActionRefuse:
+		default:
+			return fmt.Errorf("mesh-auth-mutual-near-expiry-action must be one of %s, %s or %s, got %q",
+				nearExpiryActionProceed, nearExpiryActionWarn, nearExpiryActionRefuse, params.MutualAuthConfig.MutualAuthNearExpiryAction)
+		}
+
+		if params.MutualAuthConfig.MutualAuthQuarantineThreshold < 0 {
+			return fmt.Errorf("mesh-auth-mutual-quarantine-threshold must not be negative, got %d", params.MutualAuthConfig.MutualAuthQuarantineThreshold)
+		}
+
+		if params.MutualAuthConfig.MutualAuthQuarantineThreshold > 0 && params.MutualAuthConfig.MutualAuthQuarantineCooldown <= 0 {
+			return fmt.Errorf("mesh-auth-mutual-quarantine-cooldown must be greater than 0, got %s", params.MutualAuthConfig.MutualAuthQuarantineCooldown)
+		}
+
+		if (params.MutualAuthConfig.MutualAuthRevocationCRL || params.MutualAuthConfig.MutualAuthRevocationOCSP) &&
+			params.MutualAuthConfig.MutualAuthRevocationCacheTTL <= 0 {
+			return fmt.Errorf("mesh-auth-mutual-revocation-cache-ttl must be greater than 0, got %s", params.MutualAuthConfig.MutualAuthRevocationCacheTTL)
+		}
+
+		if _, err := parseTLSMinVersion(params.MutualAuthConfig.MutualAuthTLSMinVersion); err != nil {
+			return fmt.Errorf("mesh-auth-mutual-tls-min-version: %w", err)
+		}
+
+		suites, err := parseTLSCipherSuites(params.MutualAuthConfig.MutualAuthTLSCipherSuites)
+		if err != nil {
+			return fmt.Errorf("mesh-auth-mutual-tls-cipher-suites: %w", err)
+		}
+		if params.MutualAuthConfig.MutualAuthFIPSMode {
+			for _, id := range suites {
+				if !fipsApprovedCipherSuite(id) {
+					return fmt.Errorf("mesh-auth-mutual-tls-cipher-suites: cipher suite %s is not FIPS-approved", tls.CipherSuiteName(id))
+				}
+			}
+		}
+
+		if _, err := newStaticAuthEndpointResolver(params.MutualAuthConfig.MutualAuthDialAddressMap); err != nil {
+			return fmt.Errorf("mesh-auth-mutual-dial-address-map: %w", err)
+		}
+
+		if params.SpireConfig.SpireAdminSocketPath == "" {
+			return fmt.Errorf("mutual authentication requires a certificate provider, but no SPIRE admin socket is configured (mesh-auth-spire-admin-socket)")
+		}
+	}
+
+	if params.JWTAuthConfig.JWTAuthListenerPort != 0 {
+		if params.JWTAuthConfig.JWTAuthListenerPort < 0 || params.JWTAuthConfig.JWTAuthListenerPort > 65535 {
+			return fmt.Errorf("mesh-auth-jwt-listener-port must be a valid port number, got %d", params.JWTAuthConfig.JWTAuthListenerPort)
+		}
+
+		if params.JWTAuthConfig.JWTAuthTimeout <= 0 {
+			return fmt.Errorf("mesh-auth-jwt-timeout must be greater than 0, got %s", params.JWTAuthConfig.JWTAuthTimeout)
+		}
+	}
+
+	return nil
+}
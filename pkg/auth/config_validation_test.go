@@ -0,0 +1,204 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cilium/cilium/pkg/auth/spire"
+)
+
+func Test_validateConfig(t *testing.T) {
+	validConfig := config{
+		MeshAuthEnabled:    true,
+		MeshAuthQueueSize:  1024,
+		MeshAuthGCInterval: 5 * time.Minute,
+	}
+
+	tests := []struct {
+		name    string
+		params  validateConfigParams
+		wantErr bool
+	}{
+		{
+			name: "valid config without mutual auth",
+			params: validateConfigParams{
+				Config: validConfig,
+			},
+		},
+		{
+			name: "disabled auth skips validation",
+			params: validateConfigParams{
+				Config: config{MeshAuthEnabled: false},
+			},
+		},
+		{
+			name: "invalid queue size",
+			params: validateConfigParams{
+				Config: config{
+					MeshAuthEnabled:    true,
+					MeshAuthQueueSize:  0,
+					MeshAuthGCInterval: 5 * time.Minute,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid max concurrent",
+			params: validateConfigParams{
+				Config: config{
+					MeshAuthEnabled:       true,
+					MeshAuthQueueSize:     1024,
+					MeshAuthGCInterval:    5 * time.Minute,
+					MeshAuthMaxConcurrent: -1,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid gc interval",
+			params: validateConfigParams{
+				Config: config{
+					MeshAuthEnabled:   true,
+					MeshAuthQueueSize: 1024,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid fault injection rate",
+			params: validateConfigParams{
+				Config: config{
+					MeshAuthEnabled:            true,
+					MeshAuthQueueSize:          1024,
+					MeshAuthGCInterval:         5 * time.Minute,
+					MeshAuthFaultInjectionRate: 1.5,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "dry-run mode without a grant lifetime",
+			params: validateConfigParams{
+				Config: config{
+					MeshAuthEnabled:    true,
+					MeshAuthQueueSize:  1024,
+					MeshAuthGCInterval: 5 * time.Minute,
+					MeshAuthDryRun:     true,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "mutual auth without certificate provider",
+			params: validateConfigParams{
+				Config: validConfig,
+				MutualAuthConfig: MutualAuthConfig{
+					MutualAuthListenerPort:   4250,
+					MutualAuthConnectTimeout: 5 * time.Second,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "mutual auth with invalid listener port",
+			params: validateConfigParams{
+				Config: validConfig,
+				MutualAuthConfig: MutualAuthConfig{
+					MutualAuthListenerPort:   123456,
+					MutualAuthConnectTimeout: 5 * time.Second,
+				},
+				SpireConfig: spire.SpireDelegateConfig{
+					SpireAdminSocketPath: "/run/spire/sockets/admin.sock",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "mutual auth with missing connect timeout",
+			params: validateConfigParams{
+				Config: validConfig,
+				MutualAuthConfig: MutualAuthConfig{
+					MutualAuthListenerPort: 4250,
+				},
+				SpireConfig: spire.SpireDelegateConfig{
+					SpireAdminSocketPath: "/run/spire/sockets/admin.sock",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "mutual auth with invalid near-expiry action",
+			params: validateConfigParams{
+				Config: validConfig,
+				MutualAuthConfig: MutualAuthConfig{
+					MutualAuthListenerPort:     4250,
+					MutualAuthConnectTimeout:   5 * time.Second,
+					MutualAuthNearExpiryAction: "panic",
+				},
+				SpireConfig: spire.SpireDelegateConfig{
+					SpireAdminSocketPath: "/run/spire/sockets/admin.sock",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "mutual auth in FIPS mode with a non-approved cipher suite",
+			params: validateConfigParams{
+				Config: validConfig,
+				MutualAuthConfig: MutualAuthConfig{
+					MutualAuthListenerPort:    4250,
+					MutualAuthConnectTimeout:  5 * time.Second,
+					MutualAuthFIPSMode:        true,
+					MutualAuthTLSCipherSuites: []string{"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256"},
+				},
+				SpireConfig: spire.SpireDelegateConfig{
+					SpireAdminSocketPath: "/run/spire/sockets/admin.sock",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "mutual auth in FIPS mode with approved cipher suites",
+			params: validateConfigParams{
+				Config: validConfig,
+				MutualAuthConfig: MutualAuthConfig{
+					MutualAuthListenerPort:    4250,
+					MutualAuthConnectTimeout:  5 * time.Second,
+					MutualAuthFIPSMode:        true,
+					MutualAuthTLSCipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384"},
+				},
+				SpireConfig: spire.SpireDelegateConfig{
+					SpireAdminSocketPath: "/run/spire/sockets/admin.sock",
+				},
+			},
+		},
+		{
+			name: "valid mutual auth config",
+			params: validateConfigParams{
+				Config: validConfig,
+				MutualAuthConfig: MutualAuthConfig{
+					MutualAuthListenerPort:   4250,
+					MutualAuthConnectTimeout: 5 * time.Second,
+				},
+				SpireConfig: spire.SpireDelegateConfig{
+					SpireAdminSocketPath: "/run/spire/sockets/admin.sock",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateConfig(tt.params)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// authEndpointResolver maps the internal IP of a remote node to the address
+// its mutual auth listener is reachable at. Implementations can return an
+// advertised external IP or a per-node annotated endpoint for topologies
+// where the node IPs are not directly routable, e.g. nodes behind NAT.
+type authEndpointResolver interface {
+	// ResolveAuthEndpoint returns the "host:port" address to dial for the
+	// given remote node IP. ok is false if the resolver has no mapping for
+	// the node, in which case the node IP and the configured listener port
+	// are dialed directly.
+	ResolveAuthEndpoint(remoteNodeIP string) (addr string, ok bool)
+}
+
+// staticAuthEndpointResolver resolves auth endpoints from the static node IP
+// to address map of the MutualAuthDialAddressMap flag.
+type staticAuthEndpointResolver struct {
+	endpoints map[string]string
+}
+
+func newStaticAuthEndpointResolver(entries []string) (*staticAuthEndpointResolver, error) {
+	endpoints := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		nodeIP, addr, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("dial address %q is not of the form <node-ip>=<host:port>", entry)
+		}
+		if net.ParseIP(nodeIP) == nil {
+			return nil, fmt.Errorf("dial address %q does not map a valid node IP", entry)
+		}
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return nil, fmt.Errorf("dial address %q does not map to a valid host:port: %w", entry, err)
+		}
+		endpoints[nodeIP] = addr
+	}
+	return &staticAuthEndpointResolver{endpoints: endpoints}, nil
+}
+
+func (r *staticAuthEndpointResolver) ResolveAuthEndpoint(remoteNodeIP string) (string, bool) {
+	addr, ok := r.endpoints[remoteNodeIP]
+	return addr, ok
+}
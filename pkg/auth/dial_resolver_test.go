@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"testing"
+)
+
+func Test_staticAuthEndpointResolver(t *testing.T) {
+	resolver, err := newStaticAuthEndpointResolver([]string{
+		"10.0.0.1=203.0.113.10:4250",
+		"10.0.0.2=nat-gw.example.com:14250",
+	})
+	if err != nil {
+		t.Fatalf("newStaticAuthEndpointResolver() returned unexpected error: %v", err)
+	}
+
+	if addr, ok := resolver.ResolveAuthEndpoint("10.0.0.1"); !ok || addr != "203.0.113.10:4250" {
+		t.Errorf("ResolveAuthEndpoint(10.0.0.1) = %q, %v, want the mapped address", addr, ok)
+	}
+	if addr, ok := resolver.ResolveAuthEndpoint("10.0.0.2"); !ok || addr != "nat-gw.example.com:14250" {
+		t.Errorf("ResolveAuthEndpoint(10.0.0.2) = %q, %v, want the mapped address", addr, ok)
+	}
+	if _, ok := resolver.ResolveAuthEndpoint("10.0.0.3"); ok {
+		t.Error("ResolveAuthEndpoint() of an unmapped node should report no mapping")
+	}
+}
+
+func Test_newStaticAuthEndpointResolver_invalid(t *testing.T) {
+	invalid := [][]string{
+		{"10.0.0.1"},                      // no mapping
+		{"not-an-ip=203.0.113.10:4250"},   // invalid node IP
+		{"10.0.0.1=203.0.113.10"},         // missing port
+		{"10.0.0.1=", "10.0.0.2=foo:123"}, // empty address
+	}
+	for _, entries := range invalid {
+		if _, err := newStaticAuthEndpointResolver(entries); err == nil {
+			t.Errorf("newStaticAuthEndpointResolver(%v) should be rejected", entries)
+		}
+	}
+}
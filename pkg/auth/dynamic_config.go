@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/cilium/hive/cell"
+	"github.com/cilium/hive/job"
+	"github.com/cilium/statedb"
+	"github.com/sirupsen/logrus"
+
+	"github.com/cilium/cilium/pkg/dynamicconfig"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// The auth settings which may be overridden at runtime through the dynamic
+// configuration sources (ConfigMap, CiliumNodeConfig). More settings can be
+// added here once they are safe to change without restarting the auth
+// components.
+const (
+	signalBackoffDurationKey = "mesh-auth-signal-backoff-duration"
+
+	// Listener settings of the mutual auth handler. Changing them rebinds
+	// the listener socket at runtime, draining in-flight handshakes first.
+	mutualListenerPortKey     = "mesh-auth-mutual-listener-port"
+	mutualListenerDisabledKey = "mesh-auth-mutual-listener-disabled"
+)
+
+type dynamicConfigWatcherParams struct {
+	cell.In
+
+	Logger           logrus.FieldLogger
+	JobGroup         job.Group
+	MutualAuthConfig MutualAuthConfig
+
+	DB                 *statedb.DB                                `optional:"true"`
+	DynamicConfigTable statedb.Table[dynamicconfig.DynamicConfig] `optional:"true"`
+	DynamicConfig      dynamicconfig.Config                       `optional:"true"`
+}
+
+// registerDynamicConfigWatcher watches the dynamic configuration table for
+// runtime changes of auth settings and applies them to the running auth
+// manager without an agent restart.
+func registerDynamicConfigWatcher(params dynamicConfigWatcherParams, mgr *AuthManager) {
+	if mgr == nil || params.DB == nil || params.DynamicConfigTable == nil || !params.DynamicConfig.EnableDynamicConfig {
+		return
+	}
+
+	w := &dynamicConfigWatcher{
+		logger:    params.Logger,
+		db:        params.DB,
+		table:     params.DynamicConfigTable,
+		manager:   mgr,
+		mutualCfg: params.MutualAuthConfig,
+	}
+
+	params.JobGroup.Add(job.OneShot("auth-dynamic-config", func(ctx context.Context, health cell.Health) error {
+		return w.watch(ctx)
+	}))
+}
+
+type dynamicConfigWatcher struct {
+	logger    logrus.FieldLogger
+	db        *statedb.DB
+	table     statedb.Table[dynamicconfig.DynamicConfig]
+	manager   *AuthManager
+	mutualCfg MutualAuthConfig
+}
+
+func (w *dynamicConfigWatcher) watch(ctx context.Context) error {
+	for {
+		txn := w.db.ReadTxn()
+
+		backoffEntry, backoffFound, backoffChannel := dynamicconfig.WatchKey(txn, w.table, signalBackoffDurationKey)
+		if backoffFound {
+			if d, err := time.ParseDuration(backoffEntry.Value); err != nil {
+				w.logInvalidValue(signalBackoffDurationKey, backoffEntry.Value, err)
+			} else {
+				w.manager.SetSignalBackoffDuration(d)
+			}
+		}
+
+		// Listener settings without a runtime override fall back to the
+		// startup configuration, so removing the override reverts the
+		// listener to its configured state.
+		port := w.mutualCfg.MutualAuthListenerPort
+		portEntry, portFound, portChannel := dynamicconfig.WatchKey(txn, w.table, mutualListenerPortKey)
+		if portFound {
+			if p, err := strconv.Atoi(portEntry.Value); err != nil {
+				w.logInvalidValue(mutualListenerPortKey, portEntry.Value, err)
+			} else {
+				port = p
+			}
+		}
+		disabled := w.mutualCfg.MutualAuthListenerDisabled
+		disabledEntry, disabledFound, disabledChannel := dynamicconfig.WatchKey(txn, w.table, mutualListenerDisabledKey)
+		if disabledFound {
+			if d, err := strconv.ParseBool(disabledEntry.Value); err != nil {
+				w.logInvalidValue(mutualListenerDisabledKey, disabledEntry.Value, err)
+			} else {
+				disabled = d
+			}
+		}
+		w.manager.ApplyListenerSettings(port, disabled)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-backoffChannel:
+		case <-portChannel:
+		case <-disabledChannel:
+		}
+	}
+}
+
+func (w *dynamicConfigWatcher) logInvalidValue(key, value string, err error) {
+	w.logger.
+		WithError(err).
+		WithField("key", key).
+		WithField("value", value).
+		Warning("Ignoring invalid dynamic auth configuration value")
+}
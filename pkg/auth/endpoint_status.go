@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/cilium/cilium/pkg/endpointmanager"
+	"github.com/cilium/cilium/pkg/identity"
+	ciliumv2 "github.com/cilium/cilium/pkg/k8s/apis/cilium.io/v2"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// endpointAuthStatusSync periodically summarizes the authentication state of
+// the identities of the local endpoints and pushes it into the endpoints, so
+// it gets synced to the CiliumEndpoint objects and app teams can debug
+// authentication problems of their workloads via kubectl.
+type endpointAuthStatusSync struct {
+	logger          logrus.FieldLogger
+	manager         *AuthManager
+	endpointManager endpointmanager.EndpointManager
+}
+
+func (s *endpointAuthStatusSync) sync(_ context.Context) error {
+	endpoints := s.endpointManager.GetEndpoints()
+
+	ids := make([]identity.NumericIdentity, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if id := ep.GetIdentity(); id != identity.InvalidIdentity {
+			ids = append(ids, id)
+		}
+	}
+
+	statuses, err := s.manager.identityAuthStatuses(ids)
+	if err != nil {
+		return err
+	}
+
+	for _, ep := range endpoints {
+		if status, ok := statuses[ep.GetIdentity()]; ok {
+			ep.SetAuthStatus(status)
+		}
+	}
+
+	return nil
+}
+
+// identityAuthStatuses summarizes the authentication state of the given
+// identities in a single pass over the auth map. Recorded failures of
+// identities no longer in the given set are pruned on the way.
+func (a *AuthManager) identityAuthStatuses(ids []identity.NumericIdentity) (map[identity.NumericIdentity]*ciliumv2.EndpointAuthStatus, error) {
+	all, err := a.authmap.All()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all auth map entries: %w", err)
+	}
+
+	statuses := make(map[identity.NumericIdentity]*ciliumv2.EndpointAuthStatus, len(ids))
+	for _, id := range ids {
+		statuses[id] = &ciliumv2.EndpointAuthStatus{}
+	}
+
+	// Count the distinct remote identities each local identity holds a
+	// non-expired authentication with.
+	peers := make(map[authKey]struct{}, len(all))
+	now := a.clock.Now()
+	for k, info := range all {
+		if _, ok := statuses[k.localIdentity]; !ok || !info.expiration.After(now) {
+			continue
+		}
+		peerKey := authKey{localIdentity: k.localIdentity, remoteIdentity: k.remoteIdentity}
+		if _, ok := peers[peerKey]; !ok {
+			peers[peerKey] = struct{}{}
+			statuses[k.localIdentity].AuthenticatedPeers++
+		}
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	for k := range a.pending {
+		if status, ok := statuses[k.localIdentity]; ok {
+			status.PendingAuthentications++
+		}
+	}
+
+	for id, failure := range a.lastFailures {
+		status, ok := statuses[id]
+		if !ok {
+			delete(a.lastFailures, id)
+			continue
+		}
+		status.LastFailureMsg = failure.message
+		status.LastFailureTimestamp = failure.at.Format(time.RFC3339)
+	}
+
+	return statuses, nil
+}
+
+// recordFailure remembers the most recent authentication failure per local
+// identity for the endpoint authentication status.
+func (a *AuthManager) recordFailure(key authKey, err error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.lastFailures[key.localIdentity] = authFailure{
+		message: err.Error(),
+		at:      a.clock.Now(),
+	}
+}
+
+type authFailure struct {
+	message string
+	at      time.Time
+}
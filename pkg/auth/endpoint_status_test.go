@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cilium/cilium/pkg/identity"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+func Test_authManager_identityAuthStatuses(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	aMap := &fakeAuthMap{
+		entries: map[authKey]authInfo{
+			// Same peer over two nodes counts as one authenticated peer.
+			{localIdentity: 1000, remoteIdentity: 2000, remoteNodeID: 1, authType: 100}: {expiration: future},
+			{localIdentity: 1000, remoteIdentity: 2000, remoteNodeID: 2, authType: 100}: {expiration: future},
+			// Expired entries are not authenticated peers.
+			{localIdentity: 1000, remoteIdentity: 3000, remoteNodeID: 1, authType: 100}: {expiration: time.Now().Add(-time.Hour)},
+			{localIdentity: 2000, remoteIdentity: 4000, remoteNodeID: 1, authType: 100}: {expiration: future},
+		},
+	}
+
+	am, err := newAuthManager(logrus.New(), []authHandler{&fakeAuthHandler{}}, aMap, nil, time.Second)
+	assert.NoError(t, err)
+	assert.NotNil(t, am)
+
+	assert.True(t, am.markPendingAuth(authKey{localIdentity: 1000, remoteIdentity: 3000, remoteNodeID: 1, authType: 100}))
+	am.recordFailure(authKey{localIdentity: 2000, remoteIdentity: 4000, remoteNodeID: 1, authType: 100}, errors.New("handshake failed"))
+	am.recordFailure(authKey{localIdentity: 9000, remoteIdentity: 4000, remoteNodeID: 1, authType: 100}, errors.New("stale failure"))
+
+	statuses, err := am.identityAuthStatuses([]identity.NumericIdentity{1000, 2000, 5000})
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 3)
+
+	assert.Equal(t, 1, statuses[1000].AuthenticatedPeers)
+	assert.Equal(t, 1, statuses[1000].PendingAuthentications)
+	assert.Empty(t, statuses[1000].LastFailureMsg)
+
+	assert.Equal(t, 1, statuses[2000].AuthenticatedPeers)
+	assert.Equal(t, 0, statuses[2000].PendingAuthentications)
+	assert.Equal(t, "handshake failed", statuses[2000].LastFailureMsg)
+	assert.NotEmpty(t, statuses[2000].LastFailureTimestamp)
+
+	assert.Equal(t, 0, statuses[5000].AuthenticatedPeers)
+
+	// Failures of identities without a local endpoint got pruned.
+	assert.NotContains(t, am.lastFailures, identity.NumericIdentity(9000))
+}
@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// faultInjector injects controlled failures into the authentication flow.
+// It is driven by hidden configuration flags and intended to chaos-test the
+// resilience of policies and applications to authentication failures in
+// staging environments. With a rate of 0 no injector gets created.
+type faultInjector struct {
+	logger logrus.FieldLogger
+
+	rate  float64
+	delay time.Duration
+}
+
+func newFaultInjector(logger logrus.FieldLogger, cfg config) *faultInjector {
+	if cfg.MeshAuthFaultInjectionRate <= 0 {
+		return nil
+	}
+
+	logger.
+		WithField("rate", cfg.MeshAuthFaultInjectionRate).
+		WithField("delay", cfg.MeshAuthFaultInjectionDelay).
+		Warning("Authentication fault injection is enabled - this is intended for testing only")
+
+	return &faultInjector{
+		logger: logger,
+		rate:   cfg.MeshAuthFaultInjectionRate,
+		delay:  cfg.MeshAuthFaultInjectionDelay,
+	}
+}
+
+// maybeFail fails the authentication for the given key at the configured
+// rate. If a delay is configured, it is applied before failing to emulate
+// handshake timeouts in addition to hard errors.
+func (f *faultInjector) maybeFail(key authKey) error {
+	if rand.Float64() >= f.rate {
+		return nil
+	}
+
+	if f.delay > 0 {
+		time.Sleep(f.delay)
+	}
+
+	f.logger.
+		WithField("key", key).
+		Debug("Injecting authentication failure")
+
+	return fmt.Errorf("injected authentication failure for key %s", key)
+}
@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// trustDomainFederation is the explicit federation policy between SPIFFE
+// trust domains. With per-tenant trust domains, a handshake between peers
+// of two different trust domains only succeeds when the pair of trust
+// domains is listed in the policy; handshakes within a single trust domain
+// are always allowed.
+type trustDomainFederation struct {
+	pairs map[string]struct{}
+}
+
+// newTrustDomainFederation parses the configured "<td1>=<td2>" pairs.
+// Malformed entries are skipped with a warning, which fails closed: a
+// skipped pair denies the cross trust domain handshakes it was meant to
+// allow.
+func newTrustDomainFederation(logger logrus.FieldLogger, entries []string) *trustDomainFederation {
+	f := &trustDomainFederation{
+		pairs: make(map[string]struct{}, len(entries)),
+	}
+	for _, entry := range entries {
+		first, second, found := strings.Cut(entry, "=")
+		if !found || first == "" || second == "" {
+			logger.WithField("pair", entry).
+				Warning("Skipping malformed federated trust domain pair, expected format <trust-domain>=<trust-domain>")
+			continue
+		}
+		f.pairs[pairKey(first, second)] = struct{}{}
+	}
+	return f
+}
+
+// pairKey returns the order-independent lookup key of a trust domain pair.
+func pairKey(a, b string) string {
+	if b < a {
+		a, b = b, a
+	}
+	return a + "=" + b
+}
+
+func (f *trustDomainFederation) allowed(localTD, peerTD string) bool {
+	if localTD == peerTD {
+		return true
+	}
+	_, ok := f.pairs[pairKey(localTD, peerTD)]
+	return ok
+}
+
+// check rejects a handshake between certificates of two different trust
+// domains unless the pair is federated.
+func (f *trustDomainFederation) check(localCert, peerCert *x509.Certificate) error {
+	localTD, err := certTrustDomain(localCert)
+	if err != nil {
+		return err
+	}
+	peerTD, err := certTrustDomain(peerCert)
+	if err != nil {
+		return err
+	}
+	if !f.allowed(localTD, peerTD) {
+		return fmt.Errorf("trust domains %q and %q are not federated", localTD, peerTD)
+	}
+	return nil
+}
+
+// certTrustDomain returns the trust domain of the SPIFFE ID in the URI SAN
+// of the given certificate.
+func certTrustDomain(cert *x509.Certificate) (string, error) {
+	if cert == nil || len(cert.URIs) != 1 {
+		return "", errors.New("certificate does not carry exactly one URI SAN")
+	}
+	uri := cert.URIs[0]
+	if uri.Scheme != "spiffe" || uri.Host == "" {
+		return "", fmt.Errorf("URI SAN %q is not a SPIFFE ID", uri.String())
+	}
+	return uri.Host, nil
+}
+
+// checkListenerFederation enforces the trust domain federation policy on an
+// inbound handshake. It runs before the binding request is answered, so a
+// refused cross trust domain handshake fails on the initiator side as well.
+// The local trust domain is that of the certificate served for the requested
+// SNI, the peer trust domain that of the client certificate.
+func (m *mutualAuthHandler) checkListenerFederation(tlsConn *tls.Conn) error {
+	state := tlsConn.ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("no peer certificate")
+	}
+	id, err := m.cert.SNIToNumericIdentity(state.ServerName)
+	if err != nil {
+		return fmt.Errorf("failed to get identity for SNI %s: %w", state.ServerName, err)
+	}
+	localCert, err := m.cert.GetCertificateForIdentity(id)
+	if err != nil {
+		return fmt.Errorf("failed to get certificate for local identity %s: %w", id.String(), err)
+	}
+	return m.federation.check(localCert.Leaf, state.PeerCertificates[0])
+}
@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"crypto/x509"
+	"net/url"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_trustDomainFederation_allowed(t *testing.T) {
+	f := newTrustDomainFederation(logrus.New(), []string{
+		"tenant-a.cilium=tenant-b.cilium",
+		"malformed-entry", // skipped with a warning
+	})
+
+	// Handshakes within a single trust domain are always allowed.
+	assert.True(t, f.allowed("tenant-a.cilium", "tenant-a.cilium"))
+	assert.True(t, f.allowed("tenant-c.cilium", "tenant-c.cilium"))
+
+	// Federated pairs are allowed in both directions.
+	assert.True(t, f.allowed("tenant-a.cilium", "tenant-b.cilium"))
+	assert.True(t, f.allowed("tenant-b.cilium", "tenant-a.cilium"))
+
+	// Unlisted cross trust domain pairs are refused.
+	assert.False(t, f.allowed("tenant-a.cilium", "tenant-c.cilium"))
+	assert.False(t, f.allowed("tenant-b.cilium", "tenant-c.cilium"))
+}
+
+func Test_trustDomainFederation_check(t *testing.T) {
+	certFor := func(rawURL string) *x509.Certificate {
+		u, err := url.Parse(rawURL)
+		require.NoError(t, err)
+		return &x509.Certificate{URIs: []*url.URL{u}}
+	}
+
+	f := newTrustDomainFederation(logrus.New(), []string{"tenant-a.cilium=tenant-b.cilium"})
+
+	assert.NoError(t, f.check(
+		certFor("spiffe://tenant-a.cilium/identity/1000"),
+		certFor("spiffe://tenant-b.cilium/identity/2000")))
+
+	assert.ErrorContains(t, f.check(
+		certFor("spiffe://tenant-a.cilium/identity/1000"),
+		certFor("spiffe://tenant-c.cilium/identity/2000")),
+		"not federated")
+
+	// Certificates without a proper SPIFFE URI SAN fail the check.
+	assert.Error(t, f.check(
+		certFor("https://tenant-a.cilium/identity/1000"),
+		certFor("spiffe://tenant-a.cilium/identity/2000")))
+	assert.Error(t, f.check(
+		&x509.Certificate{},
+		certFor("spiffe://tenant-a.cilium/identity/2000")))
+}
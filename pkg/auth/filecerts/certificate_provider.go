@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package filecerts
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cilium/cilium/api/v1/models"
+	"github.com/cilium/cilium/pkg/auth/certs"
+	"github.com/cilium/cilium/pkg/identity"
+)
+
+// This file implements the CertificateProvider interface. The SNI and SPIFFE
+// ID encoding matches the version 1 encoding of the SPIRE provider, so a
+// cluster can be migrated between the two providers.
+
+func (p *Provider) GetTrustBundle() (*x509.CertPool, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if p.trustBundle == nil {
+		return nil, errors.New("trust bundle not yet available")
+	}
+	return p.trustBundle, nil
+}
+
+func (p *Provider) GetCertificateForIdentity(id identity.NumericIdentity) (*tls.Certificate, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	cert, ok := p.certificates[id]
+	if !ok {
+		return nil, fmt.Errorf("no certificate for identity %s", id.String())
+	}
+	return cert, nil
+}
+
+func (p *Provider) ValidateIdentity(id identity.NumericIdentity, cert *x509.Certificate) (bool, error) {
+	// Spec: SVIDs containing more than one URI SAN MUST be rejected
+	if len(cert.URIs) != 1 {
+		return false, errors.New("SPIFFE IDs must have exactly one URI SAN")
+	}
+	return cert.URIs[0].String() == p.spiffeID(id), nil
+}
+
+func (p *Provider) NumericIdentityToSNI(id identity.NumericIdentity) string {
+	return id.String() + "." + p.cfg.FileCertsTrustDomain
+}
+
+func (p *Provider) NumericIdentityToSPIFFEID(id identity.NumericIdentity) string {
+	return p.spiffeID(id)
+}
+
+func (p *Provider) SNIToNumericIdentity(sni string) (identity.NumericIdentity, error) {
+	suffix := "." + p.cfg.FileCertsTrustDomain
+	if !strings.HasSuffix(sni, suffix) {
+		return 0, fmt.Errorf("SNI %s does not belong to our trust domain", sni)
+	}
+
+	// Only the canonical decimal encoding produced by NumericIdentityToSNI is
+	// accepted, identityOfFile applies the same restriction to file names.
+	label := strings.TrimSuffix(sni, suffix)
+	id, ok := identityOfLabel(label)
+	if !ok {
+		return 0, fmt.Errorf("%q is not a canonical decimal identity", label)
+	}
+	return id, nil
+}
+
+// identityOfLabel parses the canonical decimal identity encoding.
+func identityOfLabel(label string) (identity.NumericIdentity, bool) {
+	if label == "" || (len(label) > 1 && label[0] == '0') {
+		return 0, false
+	}
+	for i := 0; i < len(label); i++ {
+		if label[i] < '0' || label[i] > '9' {
+			return 0, false
+		}
+	}
+	id, err := identity.ParseNumericIdentity(label)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+func (p *Provider) SubscribeToRotatedIdentities() <-chan certs.CertificateRotationEvent {
+	return p.rotatedIdentitiesChan
+}
+
+func (p *Provider) Status() *models.Status {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if p.trustBundle == nil {
+		return &models.Status{
+			State: models.StatusStateFailure,
+			Msg:   "Trust bundle not loaded",
+		}
+	}
+	return &models.Status{
+		State: models.StatusStateOk,
+		Msg:   fmt.Sprintf("%d certificates loaded", len(p.certificates)),
+	}
+}
+
+func (p *Provider) spiffeID(id identity.NumericIdentity) string {
+	return "spiffe://" + p.cfg.FileCertsTrustDomain + "/identity/" + id.String()
+}
@@ -0,0 +1,286 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package filecerts
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cilium/hive/cell"
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+
+	"github.com/cilium/cilium/pkg/auth/certs"
+	"github.com/cilium/cilium/pkg/identity"
+	"github.com/cilium/cilium/pkg/lock"
+)
+
+// Cell provides a certificate provider which loads per-identity certificates
+// and the trust bundle from a directory on disk instead of requiring a
+// running SPIRE deployment, e.g. in air-gapped clusters where certificates
+// are distributed by an external process. The directory is watched for
+// changes, so certificate rotation only requires replacing the files.
+var Cell = cell.Module(
+	"file-cert-provider",
+	"File Based Certificate Provider",
+
+	cell.Provide(newFileCertificateProvider),
+	cell.Config(Config{}),
+)
+
+// File layout inside the certificate directory: the trust bundle and one
+// certificate/key pair per numeric identity, all PEM encoded.
+const (
+	caBundleFile  = "ca.crt"
+	certExtension = ".crt"
+	keyExtension  = ".key"
+)
+
+// rotationQueueSize mirrors the default queue size of the SPIRE delegate client.
+const rotationQueueSize = 1024
+
+type Config struct {
+	// FileCertsDirectory is the directory the certificates are loaded from.
+	// An empty value disables the provider.
+	FileCertsDirectory string `mapstructure:"mesh-auth-file-certs-directory"`
+
+	// FileCertsTrustDomain is the trust domain the certificates are issued
+	// under, used for the SNI and SPIFFE ID encoding of identities.
+	FileCertsTrustDomain string `mapstructure:"mesh-auth-file-certs-trust-domain"`
+}
+
+func (cfg Config) Flags(flags *pflag.FlagSet) {
+	flags.StringVar(&cfg.FileCertsDirectory, "mesh-auth-file-certs-directory", "",
+		"Directory from which per-identity certificates ('<identity>.crt'/'<identity>.key') and the trust bundle ('ca.crt') are loaded, empty to disable the file based certificate provider")
+	flags.StringVar(&cfg.FileCertsTrustDomain, "mesh-auth-file-certs-trust-domain", "spiffe.cilium",
+		"Trust domain used for the SNI and SPIFFE ID encoding of the file based certificates")
+}
+
+// Provider implements certs.CertificateProvider on top of a certificate
+// directory on disk.
+type Provider struct {
+	cfg    Config
+	log    logrus.FieldLogger
+	health cell.Health
+
+	mutex        lock.RWMutex
+	trustBundle  *x509.CertPool
+	certificates map[identity.NumericIdentity]*tls.Certificate
+
+	rotatedIdentitiesChan chan certs.CertificateRotationEvent
+
+	cancelWatch context.CancelFunc
+}
+
+func newFileCertificateProvider(lc cell.Lifecycle, health cell.Health, cfg Config, log logrus.FieldLogger) (*Provider, error) {
+	if cfg.FileCertsDirectory == "" {
+		return nil, nil
+	}
+
+	p := &Provider{
+		cfg:                   cfg,
+		log:                   log,
+		health:                health,
+		certificates:          map[identity.NumericIdentity]*tls.Certificate{},
+		rotatedIdentitiesChan: make(chan certs.CertificateRotationEvent, rotationQueueSize),
+	}
+
+	lc.Append(cell.Hook{OnStart: p.onStart, OnStop: p.onStop})
+
+	return p, nil
+}
+
+func (p *Provider) onStart(ctx cell.HookContext) error {
+	if err := p.loadDirectory(); err != nil {
+		return fmt.Errorf("failed to load certificate directory %s: %w", p.cfg.FileCertsDirectory, err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create certificate directory watcher: %w", err)
+	}
+	if err := watcher.Add(p.cfg.FileCertsDirectory); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch certificate directory %s: %w", p.cfg.FileCertsDirectory, err)
+	}
+
+	watchCtx, cancel := context.WithCancel(context.Background())
+	p.cancelWatch = cancel
+	go p.watchDirectory(watchCtx, watcher)
+
+	p.log.WithField("directory", p.cfg.FileCertsDirectory).Info("File based certificate provider is running")
+	return nil
+}
+
+func (p *Provider) onStop(ctx cell.HookContext) error {
+	if p.cancelWatch != nil {
+		p.cancelWatch()
+	}
+	return nil
+}
+
+// loadDirectory loads the trust bundle and all certificate/key pairs present
+// in the certificate directory.
+func (p *Provider) loadDirectory() error {
+	entries, err := os.ReadDir(p.cfg.FileCertsDirectory)
+	if err != nil {
+		return err
+	}
+
+	if err := p.loadTrustBundle(); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		id, ok := identityOfFile(entry.Name())
+		if !ok {
+			continue
+		}
+		if err := p.loadCertificate(id); err != nil {
+			// A partially written pair must not prevent the other
+			// certificates from loading - the watcher picks the pair up once
+			// its second file arrives.
+			p.log.WithError(err).WithField("identity", id).
+				Warning("Failed to load certificate, skipping")
+		}
+	}
+
+	return nil
+}
+
+// identityOfFile returns the numeric identity a certificate or key file
+// belongs to. Only the canonical decimal encoding is accepted, so multiple
+// file names cannot resolve to the same identity.
+func identityOfFile(name string) (identity.NumericIdentity, bool) {
+	label := strings.TrimSuffix(strings.TrimSuffix(name, certExtension), keyExtension)
+	if label == name {
+		return 0, false
+	}
+	return identityOfLabel(label)
+}
+
+func (p *Provider) loadTrustBundle() error {
+	pem, err := os.ReadFile(filepath.Join(p.cfg.FileCertsDirectory, caBundleFile))
+	if err != nil {
+		return fmt.Errorf("failed to read trust bundle: %w", err)
+	}
+
+	// A fresh pool is built on every reload, as consumers detect a trust
+	// bundle rotation by pointer comparison.
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("no CA certificates found in %s", caBundleFile)
+	}
+
+	p.mutex.Lock()
+	p.trustBundle = pool
+	p.mutex.Unlock()
+	return nil
+}
+
+func (p *Provider) loadCertificate(id identity.NumericIdentity) error {
+	certPath := filepath.Join(p.cfg.FileCertsDirectory, id.String()+certExtension)
+	keyPath := filepath.Join(p.cfg.FileCertsDirectory, id.String()+keyExtension)
+
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate pair: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse leaf certificate: %w", err)
+	}
+	cert.Leaf = leaf
+
+	p.mutex.Lock()
+	p.certificates[id] = &cert
+	p.mutex.Unlock()
+	return nil
+}
+
+func (p *Provider) removeCertificate(id identity.NumericIdentity) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if _, ok := p.certificates[id]; !ok {
+		return false
+	}
+	delete(p.certificates, id)
+	return true
+}
+
+// watchDirectory reloads the trust bundle and certificate pairs as their
+// files change and emits the resulting rotation events.
+func (p *Provider) watchDirectory(ctx context.Context, watcher *fsnotify.Watcher) {
+	defer watcher.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			p.log.WithError(err).Error("Certificate directory watcher failed")
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			p.handleFileEvent(event)
+		}
+	}
+}
+
+func (p *Provider) handleFileEvent(event fsnotify.Event) {
+	name := filepath.Base(event.Name)
+
+	if name == caBundleFile {
+		if err := p.loadTrustBundle(); err != nil {
+			p.log.WithError(err).Error("Failed to reload rotated trust bundle")
+		} else {
+			p.log.Debug("Reloaded rotated trust bundle")
+		}
+		return
+	}
+
+	id, ok := identityOfFile(name)
+	if !ok {
+		return
+	}
+
+	if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+		if p.removeCertificate(id) {
+			p.emitRotationEvent(id, true)
+		}
+		return
+	}
+
+	if err := p.loadCertificate(id); err != nil {
+		// Certificate and key arrive as separate files, so the first event
+		// of a new pair regularly sees an incomplete pair.
+		p.log.WithError(err).WithField("identity", id).
+			Debug("Failed to load changed certificate")
+		return
+	}
+	p.emitRotationEvent(id, false)
+}
+
+func (p *Provider) emitRotationEvent(id identity.NumericIdentity, deleted bool) {
+	select {
+	case p.rotatedIdentitiesChan <- certs.CertificateRotationEvent{Identity: id, Deleted: deleted}:
+	default:
+		p.log.WithField("identity", id).
+			Warning("Skipping rotation event, queue is full")
+	}
+}
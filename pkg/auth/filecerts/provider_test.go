@@ -0,0 +1,184 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package filecerts
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cilium/cilium/pkg/auth/certs"
+	"github.com/cilium/cilium/pkg/identity"
+)
+
+func Test_Provider_loadDirectory(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	ca.writeBundle(t, dir)
+	ca.writeCertificate(t, dir, 1234)
+
+	// Files not following the naming scheme must be ignored.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o600))
+
+	p := newTestProvider(t, dir)
+	require.NoError(t, p.loadDirectory())
+
+	bundle, err := p.GetTrustBundle()
+	require.NoError(t, err)
+	assert.NotNil(t, bundle)
+
+	cert, err := p.GetCertificateForIdentity(identity.NumericIdentity(1234))
+	require.NoError(t, err)
+	require.NotNil(t, cert.Leaf)
+	assert.Equal(t, "spiffe://test.cilium.io/identity/1234", cert.Leaf.URIs[0].String())
+
+	_, err = p.GetCertificateForIdentity(identity.NumericIdentity(9999))
+	assert.ErrorContains(t, err, "no certificate for identity")
+}
+
+func Test_Provider_handleFileEvent(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	ca.writeBundle(t, dir)
+	ca.writeCertificate(t, dir, 1234)
+
+	p := newTestProvider(t, dir)
+	require.NoError(t, p.loadDirectory())
+
+	// A replaced certificate pair triggers a rotation event.
+	ca.writeCertificate(t, dir, 1234)
+	p.handleFileEvent(fsnotify.Event{Name: filepath.Join(dir, "1234.crt"), Op: fsnotify.Write})
+	event := <-p.SubscribeToRotatedIdentities()
+	assert.Equal(t, identity.NumericIdentity(1234), event.Identity)
+	assert.False(t, event.Deleted)
+
+	// A new certificate pair becomes available once both files arrived.
+	ca.writeCertificate(t, dir, 2000)
+	p.handleFileEvent(fsnotify.Event{Name: filepath.Join(dir, "2000.crt"), Op: fsnotify.Create})
+	event = <-p.SubscribeToRotatedIdentities()
+	assert.Equal(t, identity.NumericIdentity(2000), event.Identity)
+	_, err := p.GetCertificateForIdentity(identity.NumericIdentity(2000))
+	assert.NoError(t, err)
+
+	// A removed certificate pair triggers a deletion event.
+	p.handleFileEvent(fsnotify.Event{Name: filepath.Join(dir, "2000.crt"), Op: fsnotify.Remove})
+	event = <-p.SubscribeToRotatedIdentities()
+	assert.Equal(t, identity.NumericIdentity(2000), event.Identity)
+	assert.True(t, event.Deleted)
+	_, err = p.GetCertificateForIdentity(identity.NumericIdentity(2000))
+	assert.Error(t, err)
+
+	// A rotated trust bundle is reloaded into a fresh pool.
+	before, err := p.GetTrustBundle()
+	require.NoError(t, err)
+	ca.writeBundle(t, dir)
+	p.handleFileEvent(fsnotify.Event{Name: filepath.Join(dir, caBundleFile), Op: fsnotify.Write})
+	after, err := p.GetTrustBundle()
+	require.NoError(t, err)
+	assert.NotSame(t, before, after)
+}
+
+func Test_Provider_SNIEncoding(t *testing.T) {
+	p := newTestProvider(t, t.TempDir())
+
+	assert.Equal(t, "1234.test.cilium.io", p.NumericIdentityToSNI(identity.NumericIdentity(1234)))
+	assert.Equal(t, "spiffe://test.cilium.io/identity/1234", p.NumericIdentityToSPIFFEID(identity.NumericIdentity(1234)))
+
+	id, err := p.SNIToNumericIdentity("1234.test.cilium.io")
+	require.NoError(t, err)
+	assert.Equal(t, identity.NumericIdentity(1234), id)
+
+	_, err = p.SNIToNumericIdentity("1234.other.cilium.io")
+	assert.ErrorContains(t, err, "does not belong to our trust domain")
+
+	_, err = p.SNIToNumericIdentity("0x10.test.cilium.io")
+	assert.ErrorContains(t, err, "not a canonical decimal identity")
+}
+
+func newTestProvider(t *testing.T, dir string) *Provider {
+	t.Helper()
+	return &Provider{
+		cfg: Config{
+			FileCertsDirectory:   dir,
+			FileCertsTrustDomain: "test.cilium.io",
+		},
+		log:                   logrus.New(),
+		certificates:          map[identity.NumericIdentity]*tls.Certificate{},
+		rotatedIdentitiesChan: make(chan certs.CertificateRotationEvent, rotationQueueSize),
+	}
+}
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		SerialNumber:          big.NewInt(1),
+		BasicConstraintsValid: true,
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(certBytes)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) writeBundle(t *testing.T, dir string) {
+	t.Helper()
+	bundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, caBundleFile), bundle, 0o600))
+}
+
+func (ca *testCA) writeCertificate(t *testing.T, dir string, id identity.NumericIdentity) {
+	t.Helper()
+
+	spiffeURI, err := url.Parse("spiffe://test.cilium.io/identity/" + id.String())
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		NotAfter:     time.Now().Add(time.Hour),
+		URIs:         []*url.URL{spiffeURI},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		SerialNumber: big.NewInt(int64(id)),
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, id.String()+certExtension), certPEM, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, id.String()+keyExtension), keyPEM, 0o600))
+}
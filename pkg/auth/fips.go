@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"crypto/tls"
+)
+
+// fipsCurvePreferences restricts the TLS 1.3 key exchange to the NIST
+// curves. The default preference list additionally contains X25519, which is
+// not FIPS-approved.
+var fipsCurvePreferences = []tls.CurveID{
+	tls.CurveP256,
+	tls.CurveP384,
+	tls.CurveP521,
+}
+
+// fipsApprovedTLS12CipherSuites are the TLS 1.2 cipher suites approved by
+// NIST SP 800-52r2: ECDHE key exchange with AES-GCM. The TLS 1.3 suites are
+// not configurable and already restricted by the crypto module in FIPS
+// builds.
+var fipsApprovedTLS12CipherSuites = map[uint16]struct{}{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256: {},
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384: {},
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256:   {},
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384:   {},
+}
+
+// fipsApprovedCipherSuite reports whether the TLS 1.2 cipher suite is
+// FIPS-approved.
+func fipsApprovedCipherSuite(id uint16) bool {
+	_, ok := fipsApprovedTLS12CipherSuites[id]
+	return ok
+}
@@ -0,0 +1,11 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+//go:build !boringcrypto
+
+package auth
+
+// boringCryptoEnabled reports whether the binary was built against the
+// BoringCrypto module, which is the FIPS 140 validated implementation of
+// Go's crypto primitives.
+const boringCryptoEnabled = false
@@ -0,0 +1,378 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/cilium/cilium/pkg/identity"
+	"github.com/cilium/cilium/pkg/lock"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// The negotiated auth protocol runs a small gRPC service over the
+// established mTLS connection instead of the bare binding exchange. Both
+// peers state their protocol version and the auth extensions they support,
+// and the listener answers with the negotiated expiration explicitly, so
+// the protocol can evolve (channel binding variants, datapath keys, ...)
+// without breaking agents that only speak the plain exchange. Support is
+// advertised through TLS ALPN: if the peer does not offer the protocol,
+// both sides fall back to the plain exchange.
+const (
+	// authProtocolALPN is the ALPN protocol name under which the gRPC-based
+	// auth protocol is advertised.
+	authProtocolALPN = "cilium-auth/1"
+
+	// authProtocolVersion is the protocol version spoken by this agent.
+	authProtocolVersion = 1
+
+	authProtocolMethod = "/cilium.auth.v1.MutualAuth/Authenticate"
+)
+
+// Auth extensions negotiated between the peers. An extension only takes
+// effect if both sides announce it.
+const (
+	// authExtensionSessionKeys negotiates the derivation of datapath session
+	// keys from the handshake, see sessionKeyManager.
+	authExtensionSessionKeys = "datapath-session-keys"
+)
+
+// authProtocolHello is the request of the Authenticate RPC, sent by the
+// initiator. It carries the handshake binding claims together with the
+// initiator's protocol version and supported extensions.
+type authProtocolHello struct {
+	ProtocolVersion uint32   `json:"protocol_version"`
+	Nonce           []byte   `json:"nonce"`
+	LocalIdentity   uint32   `json:"local_identity"`
+	RemoteIdentity  uint32   `json:"remote_identity"`
+	Extensions      []string `json:"extensions,omitempty"`
+}
+
+// authProtocolResult is the response of the Authenticate RPC. It carries the
+// session-bound binding confirmation, the extensions both sides support and
+// the expiration the listener grants for the authentication.
+type authProtocolResult struct {
+	ProtocolVersion uint32   `json:"protocol_version"`
+	Confirmation    []byte   `json:"confirmation"`
+	Extensions      []string `json:"extensions,omitempty"`
+	ExpirationUnix  int64    `json:"expiration_unix"`
+}
+
+// authProtocolCodec is the gRPC codec of the auth protocol. The messages are
+// encoded as JSON, consistent with the plain binding exchange, so the
+// protocol needs no generated code.
+type authProtocolCodec struct{}
+
+func (authProtocolCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (authProtocolCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (authProtocolCodec) Name() string                       { return "json" }
+
+type authProtocolService interface {
+	Authenticate(ctx context.Context, hello *authProtocolHello) (*authProtocolResult, error)
+}
+
+func authProtocolAuthenticateHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	hello := new(authProtocolHello)
+	if err := dec(hello); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(authProtocolService).Authenticate(ctx, hello)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: authProtocolMethod,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(authProtocolService).Authenticate(ctx, req.(*authProtocolHello))
+	}
+	return interceptor(ctx, hello, info, handler)
+}
+
+var authProtocolServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cilium.auth.v1.MutualAuth",
+	HandlerType: (*authProtocolService)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Authenticate",
+			Handler:    authProtocolAuthenticateHandler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// supportedAuthExtensions returns the auth extensions this handler supports.
+func (m *mutualAuthHandler) supportedAuthExtensions() []string {
+	var extensions []string
+	if m.sessionKeys != nil {
+		extensions = append(extensions, authExtensionSessionKeys)
+	}
+	return extensions
+}
+
+// negotiatedAuthProtocol reports whether the peers agreed on the gRPC-based
+// auth protocol during the TLS handshake.
+func negotiatedAuthProtocol(tlsConn *tls.Conn) bool {
+	return tlsConn.ConnectionState().NegotiatedProtocol == authProtocolALPN
+}
+
+// negotiateAuthProtocol performs the initiator side of the gRPC-based auth
+// protocol over the established mTLS connection.
+func (m *mutualAuthHandler) negotiateAuthProtocol(ctx context.Context, tlsConn *tls.Conn, ar *authRequest) (*authProtocolResult, error) {
+	var dialed bool
+	var dialMutex lock.Mutex
+
+	// The gRPC channel runs over the already established mTLS connection,
+	// so the transport carries no credentials of its own.
+	cc, err := grpc.NewClient("passthrough:///mutual-auth",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			dialMutex.Lock()
+			defer dialMutex.Unlock()
+			if dialed {
+				return nil, errors.New("mutual auth connection is already consumed")
+			}
+			dialed = true
+			return tlsConn, nil
+		}),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(authProtocolCodec{})),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up auth protocol channel: %w", err)
+	}
+	defer cc.Close()
+
+	nonce := make([]byte, bindingNonceLen)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate handshake nonce: %w", err)
+	}
+
+	hello := &authProtocolHello{
+		ProtocolVersion: authProtocolVersion,
+		Nonce:           nonce,
+		LocalIdentity:   ar.localIdentity.Uint32(),
+		RemoteIdentity:  ar.remoteIdentity.Uint32(),
+		Extensions:      m.supportedAuthExtensions(),
+	}
+	result := new(authProtocolResult)
+	if err := cc.Invoke(ctx, authProtocolMethod, hello, result); err != nil {
+		return nil, fmt.Errorf("authenticate RPC failed: %w", err)
+	}
+
+	if result.ProtocolVersion != authProtocolVersion {
+		return nil, fmt.Errorf("peer answered with unsupported auth protocol version %d", result.ProtocolVersion)
+	}
+
+	want, err := bindingConfirmation(tlsConn, &bindingRequest{
+		Nonce:          nonce,
+		LocalIdentity:  hello.LocalIdentity,
+		RemoteIdentity: hello.RemoteIdentity,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to export handshake binding confirmation: %w", err)
+	}
+	if subtle.ConstantTimeCompare(want, result.Confirmation) != 1 {
+		return nil, errors.New("handshake binding confirmation mismatch")
+	}
+
+	return result, nil
+}
+
+// authProtocolServer serves the Authenticate RPC for a single inbound
+// connection.
+type authProtocolServer struct {
+	handler *mutualAuthHandler
+	tlsConn *tls.Conn
+
+	// done is closed once the Authenticate RPC has been handled; req,
+	// extensions and err hold the outcome.
+	done       chan struct{}
+	req        *bindingRequest
+	extensions []string
+	err        error
+
+	// completed guards against a peer invoking the RPC more than once on
+	// the same connection: only the first invocation is served and done is
+	// closed exactly once.
+	completedMutex lock.Mutex
+	completed      bool
+}
+
+func (s *authProtocolServer) Authenticate(ctx context.Context, hello *authProtocolHello) (*authProtocolResult, error) {
+	s.completedMutex.Lock()
+	if s.completed {
+		s.completedMutex.Unlock()
+		return nil, errors.New("authenticate RPC already handled on this connection")
+	}
+	s.completed = true
+	s.completedMutex.Unlock()
+
+	result, err := s.authenticate(ctx, hello)
+	s.err = err
+	close(s.done)
+	return result, err
+}
+
+func (s *authProtocolServer) authenticate(_ context.Context, hello *authProtocolHello) (*authProtocolResult, error) {
+	if hello.ProtocolVersion != authProtocolVersion {
+		return nil, fmt.Errorf("unsupported auth protocol version %d", hello.ProtocolVersion)
+	}
+
+	req := &bindingRequest{
+		Nonce:          hello.Nonce,
+		LocalIdentity:  hello.LocalIdentity,
+		RemoteIdentity: hello.RemoteIdentity,
+	}
+	if err := s.handler.verifyBindingClaims(s.tlsConn, req); err != nil {
+		return nil, err
+	}
+
+	confirmation, err := bindingConfirmation(s.tlsConn, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export handshake binding confirmation: %w", err)
+	}
+
+	// The granted expiration is the lower expiration of the two certificates
+	// involved, stated explicitly instead of being derived by both sides on
+	// their own. The peer certificate is guaranteed to be present, the
+	// binding claims have been validated against it.
+	expiration := s.tlsConn.ConnectionState().PeerCertificates[0].NotAfter
+	if cert, err := s.handler.cert.GetCertificateForIdentity(identity.NumericIdentity(hello.RemoteIdentity)); err == nil &&
+		cert.Leaf != nil && cert.Leaf.NotAfter.Before(expiration) {
+		expiration = cert.Leaf.NotAfter
+	}
+
+	var extensions []string
+	for _, extension := range s.handler.supportedAuthExtensions() {
+		if slices.Contains(hello.Extensions, extension) {
+			extensions = append(extensions, extension)
+		}
+	}
+
+	s.req = req
+	s.extensions = extensions
+
+	return &authProtocolResult{
+		ProtocolVersion: authProtocolVersion,
+		Confirmation:    confirmation,
+		Extensions:      extensions,
+		ExpirationUnix:  expiration.Unix(),
+	}, nil
+}
+
+// serveAuthProtocol performs the listener side of the gRPC-based auth
+// protocol over the established mTLS connection. On success, the verified
+// binding request and the negotiated extensions are returned.
+func (m *mutualAuthHandler) serveAuthProtocol(ctx context.Context, tlsConn *tls.Conn) (*bindingRequest, []string, error) {
+	service := &authProtocolServer{
+		handler: m,
+		tlsConn: tlsConn,
+		done:    make(chan struct{}),
+	}
+
+	srv := grpc.NewServer(grpc.ForceServerCodec(authProtocolCodec{}))
+	srv.RegisterService(&authProtocolServiceDesc, service)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(newSingleConnListener(tlsConn))
+	}()
+
+	select {
+	case <-service.done:
+	case err := <-serveErr:
+		srv.Stop()
+		if err == nil {
+			err = errors.New("connection closed before the authenticate RPC")
+		}
+		return nil, nil, err
+	case <-ctx.Done():
+		srv.Stop()
+		return nil, nil, ctx.Err()
+	}
+
+	// Let the server flush the RPC response before tearing the connection
+	// down; the context deadline bounds a peer which keeps the connection
+	// open.
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		srv.Stop()
+	}
+
+	if service.err != nil {
+		return nil, nil, service.err
+	}
+	if service.req == nil {
+		return nil, nil, errors.New("authenticate RPC failed")
+	}
+	return service.req, service.extensions, nil
+}
+
+// singleConnListener hands the single already-accepted connection to the
+// gRPC server and blocks further accepts until the listener is closed.
+type singleConnListener struct {
+	conn   chan net.Conn
+	closed chan struct{}
+
+	closeOnce func()
+}
+
+func newSingleConnListener(conn net.Conn) *singleConnListener {
+	l := &singleConnListener{
+		conn:   make(chan net.Conn, 1),
+		closed: make(chan struct{}),
+	}
+	l.conn <- conn
+	l.closeOnce = sync.OnceFunc(func() { close(l.closed) })
+	return l
+}
+
+func (l *singleConnListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conn:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *singleConnListener) Close() error {
+	l.closeOnce()
+	return nil
+}
+
+func (l *singleConnListener) Addr() net.Addr {
+	return &net.TCPAddr{}
+}
+
+// boundNegotiatedExpiration folds the expiration granted by the listener
+// into the locally derived one. The negotiated expiration can only lower the
+// local expiration, never extend it.
+func boundNegotiatedExpiration(local time.Time, negotiatedUnix int64) time.Time {
+	if negotiatedUnix <= 0 {
+		return local
+	}
+	if negotiated := time.Unix(negotiatedUnix, 0); negotiated.Before(local) {
+		return negotiated
+	}
+	return local
+}
@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func Test_mutualAuthHandler_protocolNegotiation(t *testing.T) {
+	certMap, keyMap, caPool := generateTestCertificates(t)
+
+	mAuthHandler := &mutualAuthHandler{
+		cfg: MutualAuthConfig{
+			MutualAuthListenerPort:        getRandomOpenPort(t),
+			MutualAuthConnectTimeout:      5 * time.Second,
+			MutualAuthProtocolNegotiation: true,
+		},
+		log:             logrus.New(),
+		cert:            &fakeCertificateProvider{certMap: certMap, caPool: caPool, privkeyMap: keyMap},
+		endpointManager: &fakeEndpointGetter{},
+	}
+	mAuthHandler.onStart(context.Background())
+	defer mAuthHandler.onStop(context.Background())
+
+	resp, err := mAuthHandler.authenticate(&authRequest{
+		localIdentity:  id1000,
+		remoteIdentity: id1001,
+		remoteNodeIP:   GetLoopBackIP(t),
+	})
+	if err != nil {
+		t.Fatalf("authenticate() over the negotiated protocol failed: %v", err)
+	}
+	if resp.expirationTime.IsZero() {
+		t.Errorf("authenticate() over the negotiated protocol returned no expiration")
+	}
+	want := certMap["spiffe://spiffe.cilium/identity/1001"].NotAfter
+	if resp.expirationTime.After(want) {
+		t.Errorf("negotiated expiration %s extends the certificate expiration %s", resp.expirationTime, want)
+	}
+}
+
+func Test_authProtocolServer_repeatedAuthenticate(t *testing.T) {
+	service := &authProtocolServer{
+		handler: &mutualAuthHandler{log: logrus.New()},
+		done:    make(chan struct{}),
+	}
+
+	// An invocation with an unsupported version fails, but completes the
+	// handshake handling.
+	if _, err := service.Authenticate(context.Background(), &authProtocolHello{ProtocolVersion: 99}); err == nil {
+		t.Fatalf("Authenticate() with an unsupported protocol version must fail")
+	}
+
+	// A repeat invocation on the same connection must be rejected instead
+	// of completing the handshake again.
+	if _, err := service.Authenticate(context.Background(), &authProtocolHello{ProtocolVersion: authProtocolVersion}); err == nil {
+		t.Errorf("repeated Authenticate() on the same connection must fail")
+	}
+}
+
+func Test_boundNegotiatedExpiration(t *testing.T) {
+	local := time.Now().Add(time.Hour)
+
+	if got := boundNegotiatedExpiration(local, 0); !got.Equal(local) {
+		t.Errorf("boundNegotiatedExpiration() without a negotiated expiration = %s, want %s", got, local)
+	}
+	earlier := local.Add(-time.Minute)
+	if got := boundNegotiatedExpiration(local, earlier.Unix()); !got.Equal(time.Unix(earlier.Unix(), 0)) {
+		t.Errorf("boundNegotiatedExpiration() with an earlier negotiated expiration = %s, want %s", got, earlier)
+	}
+	later := local.Add(time.Minute)
+	if got := boundNegotiatedExpiration(local, later.Unix()); !got.Equal(local) {
+		t.Errorf("boundNegotiatedExpiration() must not extend the local expiration, got %s", got)
+	}
+}
@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/cilium/cilium/pkg/identity"
+)
+
+const (
+	// bindingLabel is the label under which the keying material for the
+	// handshake binding is exported from the TLS session.
+	bindingLabel = "cilium mutual auth binding"
+
+	bindingNonceLen        = 32
+	bindingConfirmationLen = 32
+)
+
+// bindingRequest is sent by the initiator over the established TLS channel.
+// It binds the handshake result to a fresh nonce and the identity pair being
+// authorized, so a captured transcript or a confused-deputy connection cannot
+// be replayed to authorize a different pair.
+type bindingRequest struct {
+	Nonce          []byte `json:"nonce"`
+	LocalIdentity  uint32 `json:"local_identity"`
+	RemoteIdentity uint32 `json:"remote_identity"`
+}
+
+// bindingResponse carries the listener's confirmation, keying material
+// exported from this very TLS session over the nonce and identity pair. It
+// cannot be produced without possession of the session keys.
+type bindingResponse struct {
+	Confirmation []byte `json:"confirmation"`
+}
+
+// bindingConfirmation exports the session-bound confirmation value for the
+// given binding request.
+func bindingConfirmation(tlsConn *tls.Conn, req *bindingRequest) ([]byte, error) {
+	context := make([]byte, 0, len(req.Nonce)+8)
+	context = append(context, req.Nonce...)
+	context = binary.BigEndian.AppendUint32(context, req.LocalIdentity)
+	context = binary.BigEndian.AppendUint32(context, req.RemoteIdentity)
+
+	state := tlsConn.ConnectionState()
+	return state.ExportKeyingMaterial(bindingLabel, context, bindingConfirmationLen)
+}
+
+// sendBindingRequest performs the initiator side of the handshake binding:
+// it sends the nonce and identity pair and verifies the confirmation
+// returned by the listener.
+func (m *mutualAuthHandler) sendBindingRequest(tlsConn *tls.Conn, ar *authRequest) error {
+	nonce := make([]byte, bindingNonceLen)
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate handshake nonce: %w", err)
+	}
+
+	req := &bindingRequest{
+		Nonce:          nonce,
+		LocalIdentity:  ar.localIdentity.Uint32(),
+		RemoteIdentity: ar.remoteIdentity.Uint32(),
+	}
+	if err := json.NewEncoder(tlsConn).Encode(req); err != nil {
+		return fmt.Errorf("failed to send handshake binding request: %w", err)
+	}
+
+	var resp bindingResponse
+	if err := json.NewDecoder(tlsConn).Decode(&resp); err != nil {
+		return fmt.Errorf("failed to read handshake binding response: %w", err)
+	}
+
+	want, err := bindingConfirmation(tlsConn, req)
+	if err != nil {
+		return fmt.Errorf("failed to export handshake binding confirmation: %w", err)
+	}
+	if subtle.ConstantTimeCompare(want, resp.Confirmation) != 1 {
+		return errors.New("handshake binding confirmation mismatch")
+	}
+
+	return nil
+}
+
+// verifyBindingClaims checks that the identity pair claimed in a binding
+// request matches the certificates exchanged during the TLS handshake.
+func (m *mutualAuthHandler) verifyBindingClaims(tlsConn *tls.Conn, req *bindingRequest) error {
+	if len(req.Nonce) != bindingNonceLen {
+		return fmt.Errorf("unexpected handshake nonce length %d", len(req.Nonce))
+	}
+
+	state := tlsConn.ConnectionState()
+
+	// The remote identity the initiator claims to authenticate must be the
+	// one the served certificate was selected for.
+	sniID, err := m.cert.SNIToNumericIdentity(state.ServerName)
+	if err != nil {
+		return fmt.Errorf("failed to get identity for SNI %s: %w", state.ServerName, err)
+	}
+	if identity.NumericIdentity(req.RemoteIdentity) != sniID {
+		return fmt.Errorf("claimed remote identity %d does not match SNI identity %s", req.RemoteIdentity, sniID.String())
+	}
+
+	// The local identity the initiator claims must match its certificate.
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("no peer certificate available")
+	}
+	if valid, err := m.cert.ValidateIdentity(identity.NumericIdentity(req.LocalIdentity), state.PeerCertificates[0]); err != nil {
+		return fmt.Errorf("failed to validate claimed local identity %d: %w", req.LocalIdentity, err)
+	} else if !valid {
+		return fmt.Errorf("claimed local identity %d does not match the peer certificate", req.LocalIdentity)
+	}
+
+	// The claimed identity must additionally be hosted on the node the
+	// connection originates from, see verifyInitiatorPlacement.
+	if err := m.verifyInitiatorPlacement(identity.NumericIdentity(req.LocalIdentity), remoteIPOf(tlsConn)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// answerBindingRequest performs the listener side of the handshake binding:
+// it checks that the claimed identity pair matches the certificates exchanged
+// during the TLS handshake and answers with the session-bound confirmation.
+// On success, the verified binding request is returned.
+func (m *mutualAuthHandler) answerBindingRequest(tlsConn *tls.Conn) (*bindingRequest, error) {
+	var req bindingRequest
+	if err := json.NewDecoder(tlsConn).Decode(&req); err != nil {
+		return nil, fmt.Errorf("failed to read handshake binding request: %w", err)
+	}
+	if err := m.verifyBindingClaims(tlsConn, &req); err != nil {
+		return nil, err
+	}
+
+	confirmation, err := bindingConfirmation(tlsConn, &req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export handshake binding confirmation: %w", err)
+	}
+
+	if err := json.NewEncoder(tlsConn).Encode(&bindingResponse{Confirmation: confirmation}); err != nil {
+		return nil, fmt.Errorf("failed to send handshake binding response: %w", err)
+	}
+
+	return &req, nil
+}
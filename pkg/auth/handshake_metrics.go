@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"github.com/cilium/cilium/pkg/metrics"
+	"github.com/cilium/cilium/pkg/metrics/metric"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// Stages of a handshake a failure is attributed to. The "role" label of the
+// handshake metrics reuses the receipt roles of the two sides of a handshake.
+const (
+	handshakeStageSetup     = "setup"
+	handshakeStageDial      = "dial"
+	handshakeStageHandshake = "tls-handshake"
+	handshakeStageBinding   = "binding"
+)
+
+// handshakeMetrics exposes the performance of the mutual auth handshakes:
+// attempt, success and per-stage failure counters for both roles, handshake
+// latency and the current number of cached auth map entries.
+type handshakeMetrics struct {
+	// Attempts counts the started handshakes by role.
+	Attempts metric.Vec[metric.Counter]
+
+	// Successes counts the completed handshakes by role.
+	Successes metric.Vec[metric.Counter]
+
+	// Failures counts the failed handshakes by role and the stage the
+	// handshake failed in.
+	Failures metric.Vec[metric.Counter]
+
+	// Timeouts counts the handshakes which failed because a deadline
+	// expired, telling wedged peers apart from actual handshake failures.
+	Timeouts metric.Vec[metric.Counter]
+
+	// Resumptions counts the handshakes which resumed a cached TLS session
+	// instead of performing a full certificate exchange.
+	Resumptions metric.Vec[metric.Counter]
+
+	// Duration observes the latency of completed and failed handshakes by
+	// role.
+	Duration metric.Vec[metric.Observer]
+
+	// CachedAuthEntries is the current number of entries in the auth map
+	// cache.
+	CachedAuthEntries metric.Gauge
+
+	// DryRunWouldFail counts the authentications which failed but were
+	// granted anyway because the agent runs in dry-run mode.
+	DryRunWouldFail metric.Counter
+
+	// CoalescedAuthentications counts the authentications answered by an
+	// already in-flight handshake of an identical request instead of
+	// starting a handshake of their own.
+	CoalescedAuthentications metric.Counter
+
+	// QueuedAuthentications is the current number of authentications
+	// waiting for a free concurrency slot, see mesh-auth-max-concurrent.
+	QueuedAuthentications metric.Gauge
+}
+
+func newHandshakeMetrics() *handshakeMetrics {
+	return &handshakeMetrics{
+		Attempts: metric.NewCounterVec(metric.CounterOpts{
+			ConfigName: metrics.Namespace + "_mutual_auth_handshakes_total",
+			Namespace:  metrics.Namespace,
+			Subsystem:  "mutual_auth",
+			Name:       "handshakes_total",
+			Help:       "Number of started mutual authentication handshakes",
+		}, []string{"role"}),
+		Successes: metric.NewCounterVec(metric.CounterOpts{
+			ConfigName: metrics.Namespace + "_mutual_auth_handshake_successes_total",
+			Namespace:  metrics.Namespace,
+			Subsystem:  "mutual_auth",
+			Name:       "handshake_successes_total",
+			Help:       "Number of completed mutual authentication handshakes",
+		}, []string{"role"}),
+		Failures: metric.NewCounterVec(metric.CounterOpts{
+			ConfigName: metrics.Namespace + "_mutual_auth_handshake_failures_total",
+			Namespace:  metrics.Namespace,
+			Subsystem:  "mutual_auth",
+			Name:       "handshake_failures_total",
+			Help:       "Number of failed mutual authentication handshakes by the stage they failed in",
+		}, []string{"role", "stage"}),
+		Timeouts: metric.NewCounterVec(metric.CounterOpts{
+			ConfigName: metrics.Namespace + "_mutual_auth_handshake_timeouts_total",
+			Namespace:  metrics.Namespace,
+			Subsystem:  "mutual_auth",
+			Name:       "handshake_timeouts_total",
+			Help:       "Number of mutual authentication handshakes which failed because a deadline expired",
+		}, []string{"role"}),
+		Resumptions: metric.NewCounterVec(metric.CounterOpts{
+			ConfigName: metrics.Namespace + "_mutual_auth_handshake_resumptions_total",
+			Namespace:  metrics.Namespace,
+			Subsystem:  "mutual_auth",
+			Name:       "handshake_resumptions_total",
+			Help:       "Number of mutual authentication handshakes which resumed a cached TLS session",
+		}, []string{"role"}),
+		Duration: metric.NewHistogramVec(metric.HistogramOpts{
+			ConfigName: metrics.Namespace + "_mutual_auth_handshake_duration_seconds",
+			Namespace:  metrics.Namespace,
+			Subsystem:  "mutual_auth",
+			Name:       "handshake_duration_seconds",
+			Help:       "Duration of mutual authentication handshakes in seconds",
+		}, []string{"role"}),
+		CachedAuthEntries: metric.NewGauge(metric.GaugeOpts{
+			ConfigName: metrics.Namespace + "_mutual_auth_cached_entries",
+			Namespace:  metrics.Namespace,
+			Subsystem:  "mutual_auth",
+			Name:       "cached_entries",
+			Help:       "Current number of entries in the auth map cache",
+		}),
+		DryRunWouldFail: metric.NewCounter(metric.CounterOpts{
+			ConfigName: metrics.Namespace + "_auth_dry_run_would_fail_total",
+			Namespace:  metrics.Namespace,
+			Subsystem:  "auth",
+			Name:       "dry_run_would_fail_total",
+			Help:       "Number of authentications which would have failed but were granted in dry-run mode",
+		}),
+		CoalescedAuthentications: metric.NewCounter(metric.CounterOpts{
+			ConfigName: metrics.Namespace + "_auth_coalesced_authentications_total",
+			Namespace:  metrics.Namespace,
+			Subsystem:  "auth",
+			Name:       "coalesced_authentications_total",
+			Help:       "Number of authentications answered by an already in-flight handshake of an identical request",
+		}),
+		QueuedAuthentications: metric.NewGauge(metric.GaugeOpts{
+			ConfigName: metrics.Namespace + "_auth_queued_authentications",
+			Namespace:  metrics.Namespace,
+			Subsystem:  "auth",
+			Name:       "queued_authentications",
+			Help:       "Current number of authentications waiting for a free concurrency slot",
+		}),
+	}
+}
+
+// observeAttempt counts a started handshake.
+func (m *handshakeMetrics) observeAttempt(role string) {
+	if m == nil {
+		return
+	}
+	m.Attempts.WithLabelValues(role).Inc()
+}
+
+// observeTimeout counts a handshake which failed on an expired deadline.
+func (m *handshakeMetrics) observeTimeout(role string) {
+	if m == nil {
+		return
+	}
+	m.Timeouts.WithLabelValues(role).Inc()
+}
+
+// observeResumption counts a handshake which resumed a cached TLS session.
+func (m *handshakeMetrics) observeResumption(role string) {
+	if m == nil {
+		return
+	}
+	m.Resumptions.WithLabelValues(role).Inc()
+}
+
+// observeResult counts the outcome of a handshake and observes its latency.
+// A failure is attributed to the given stage of the handshake.
+func (m *handshakeMetrics) observeResult(role, failedStage string, start time.Time, failed bool) {
+	if m == nil {
+		return
+	}
+	m.Duration.WithLabelValues(role).Observe(time.Since(start).Seconds())
+	if failed {
+		m.Failures.WithLabelValues(role, failedStage).Inc()
+	} else {
+		m.Successes.WithLabelValues(role).Inc()
+	}
+}
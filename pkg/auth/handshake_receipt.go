@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/cilium/cilium/pkg/identity"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+const (
+	receiptRoleInitiator = "initiator"
+	receiptRoleListener  = "listener"
+)
+
+// handshakeReceipt records the outcome of a successful handshake. Both sides
+// of the handshake emit one, signed with the key of their local certificate,
+// giving verifiable evidence of who authenticated to whom and when.
+type handshakeReceipt struct {
+	Role            string    `json:"role"`
+	LocalIdentity   uint32    `json:"local_identity"`
+	RemoteIdentity  uint32    `json:"remote_identity"`
+	LocalAddr       string    `json:"local_addr"`
+	RemoteAddr      string    `json:"remote_addr"`
+	Timestamp       time.Time `json:"timestamp"`
+	LocalCertSerial string    `json:"local_cert_serial"`
+	PeerCertSerial  string    `json:"peer_cert_serial"`
+}
+
+// signedHandshakeReceipt wraps a receipt with the signature computed over its
+// JSON encoding, so it can be verified against the certificate of the
+// emitting identity.
+type signedHandshakeReceipt struct {
+	Receipt   handshakeReceipt `json:"receipt"`
+	Signature []byte           `json:"signature"`
+}
+
+// emitHandshakeReceipt signs and logs a receipt of the successful handshake
+// on the given connection. Failures to produce a receipt do not fail the
+// handshake, they are logged and the authentication result stands.
+func (m *mutualAuthHandler) emitHandshakeReceipt(tlsConn *tls.Conn, role string, localID, remoteID identity.NumericIdentity) {
+	if !m.cfg.MutualAuthReceiptsEnabled {
+		return
+	}
+
+	receipt, err := m.signHandshakeReceipt(tlsConn, role, localID, remoteID)
+	if err != nil {
+		m.log.WithError(err).Warning("Failed to produce handshake receipt")
+		return
+	}
+
+	encoded, err := json.Marshal(receipt)
+	if err != nil {
+		m.log.WithError(err).Warning("Failed to encode handshake receipt")
+		return
+	}
+
+	m.log.WithField("receipt", string(encoded)).Info("Mutual auth handshake receipt")
+}
+
+func (m *mutualAuthHandler) signHandshakeReceipt(tlsConn *tls.Conn, role string, localID, remoteID identity.NumericIdentity) (*signedHandshakeReceipt, error) {
+	localCert, err := m.cert.GetCertificateForIdentity(localID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get certificate for local identity %s: %w", localID.String(), err)
+	}
+
+	state := tlsConn.ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		return nil, errors.New("no peer certificate available")
+	}
+
+	receipt := handshakeReceipt{
+		Role:            role,
+		LocalIdentity:   localID.Uint32(),
+		RemoteIdentity:  remoteID.Uint32(),
+		LocalAddr:       tlsConn.LocalAddr().String(),
+		RemoteAddr:      tlsConn.RemoteAddr().String(),
+		Timestamp:       time.Now(),
+		LocalCertSerial: localCert.Leaf.SerialNumber.String(),
+		PeerCertSerial:  state.PeerCertificates[0].SerialNumber.String(),
+	}
+
+	payload, err := json.Marshal(receipt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode receipt: %w", err)
+	}
+
+	signature, err := signReceiptPayload(localCert, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign receipt: %w", err)
+	}
+
+	return &signedHandshakeReceipt{
+		Receipt:   receipt,
+		Signature: signature,
+	}, nil
+}
+
+// signReceiptPayload signs the given payload with the private key of the
+// certificate, using a SHA-256 digest for all key types except Ed25519 which
+// signs the payload directly.
+func signReceiptPayload(cert *tls.Certificate, payload []byte) ([]byte, error) {
+	signer, ok := cert.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("private key of type %T cannot sign", cert.PrivateKey)
+	}
+
+	// Detected via the public key so opaque Ed25519 signers (e.g. HSM- or
+	// TPM-backed keys) take this path too.
+	if _, ok := signer.Public().(ed25519.PublicKey); ok {
+		return signer.Sign(rand.Reader, payload, crypto.Hash(0))
+	}
+
+	digest := sha256.Sum256(payload)
+	return signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/cilium/cilium/pkg/lock"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// sessionsPerNode bounds the number of TLS sessions cached per remote node.
+// Each identity pair handshakes with its own SNI and therefore occupies its
+// own session slot, so this is the number of recently authenticated pairs
+// per node that can resume.
+const sessionsPerNode = 16
+
+// handshakeSessionCache pools TLS session tickets per remote node, so that
+// repeated outbound handshakes to the same node resume the TLS session
+// instead of paying for a fresh certificate exchange every time. Only the
+// session tickets are pooled, not live connections: the listener answers a
+// single binding request per connection, so connections cannot be reused.
+//
+// Sessions are keyed by SNI within a node, so a resumed session always
+// belongs to the identity pair it was fully verified for. A trust bundle
+// rotation drops all cached sessions, so no session outlives the trust it
+// was established against.
+type handshakeSessionCache struct {
+	logger logrus.FieldLogger
+
+	maxNodes    int
+	idleTimeout time.Duration
+
+	mutex lock.Mutex
+	nodes map[string]*handshakeSessionNode
+	// bundle is the trust bundle the cached sessions were verified against.
+	bundle *x509.CertPool
+}
+
+type handshakeSessionNode struct {
+	sessions tls.ClientSessionCache
+	lastUsed time.Time
+}
+
+func newHandshakeSessionCache(logger logrus.FieldLogger, maxNodes int, idleTimeout time.Duration) *handshakeSessionCache {
+	return &handshakeSessionCache{
+		logger:      logger,
+		maxNodes:    maxNodes,
+		idleTimeout: idleTimeout,
+		nodes:       map[string]*handshakeSessionNode{},
+	}
+}
+
+// sessionsFor returns the TLS session cache of the given remote node,
+// creating it if needed. Sessions established against a different trust
+// bundle than the given one are dropped first.
+func (c *handshakeSessionCache) sessionsFor(nodeIP string, bundle *x509.CertPool, now time.Time) tls.ClientSessionCache {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.bundle != bundle {
+		if c.bundle != nil && len(c.nodes) > 0 {
+			c.logger.Debug("Trust bundle rotated, dropping cached TLS sessions")
+		}
+		c.nodes = map[string]*handshakeSessionNode{}
+		c.bundle = bundle
+	}
+
+	c.evictIdleLocked(now)
+
+	node, ok := c.nodes[nodeIP]
+	if !ok {
+		if len(c.nodes) >= c.maxNodes {
+			c.evictOldestLocked()
+		}
+		node = &handshakeSessionNode{sessions: tls.NewLRUClientSessionCache(sessionsPerNode)}
+		c.nodes[nodeIP] = node
+	}
+	node.lastUsed = now
+
+	return node.sessions
+}
+
+// evictIdleLocked drops the sessions of nodes no handshake used for longer
+// than the idle timeout.
+func (c *handshakeSessionCache) evictIdleLocked(now time.Time) {
+	if c.idleTimeout <= 0 {
+		return
+	}
+	for nodeIP, node := range c.nodes {
+		if now.Sub(node.lastUsed) > c.idleTimeout {
+			delete(c.nodes, nodeIP)
+		}
+	}
+}
+
+// evictOldestLocked drops the sessions of the least recently used node to
+// make room for a new one.
+func (c *handshakeSessionCache) evictOldestLocked() {
+	var oldestIP string
+	var oldest time.Time
+	for nodeIP, node := range c.nodes {
+		if oldestIP == "" || node.lastUsed.Before(oldest) {
+			oldestIP = nodeIP
+			oldest = node.lastUsed
+		}
+	}
+	if oldestIP != "" {
+		delete(c.nodes, oldestIP)
+	}
+}
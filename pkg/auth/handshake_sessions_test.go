@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_handshakeSessionCache_sessionsFor(t *testing.T) {
+	bundle := x509.NewCertPool()
+	base := time.Now()
+
+	c := newHandshakeSessionCache(logrus.New(), 2, 10*time.Minute)
+
+	first := c.sessionsFor("172.18.0.1", bundle, base)
+	assert.Same(t, first, c.sessionsFor("172.18.0.1", bundle, base.Add(time.Second)),
+		"repeated handshakes to the same node should reuse its session cache")
+
+	c.sessionsFor("172.18.0.2", bundle, base.Add(2*time.Second))
+	assert.Len(t, c.nodes, 2)
+
+	// Exceeding the pool size evicts the least recently used node.
+	c.sessionsFor("172.18.0.3", bundle, base.Add(3*time.Second))
+	assert.Len(t, c.nodes, 2)
+	assert.NotContains(t, c.nodes, "172.18.0.1",
+		"the least recently used node should be evicted when the pool is full")
+
+	// Nodes unused for longer than the idle timeout are dropped.
+	c.sessionsFor("172.18.0.3", bundle, base.Add(15*time.Minute))
+	assert.Len(t, c.nodes, 1)
+	assert.Contains(t, c.nodes, "172.18.0.3")
+
+	// A trust bundle rotation drops all cached sessions.
+	rotated := x509.NewCertPool()
+	c.sessionsFor("172.18.0.4", rotated, base.Add(16*time.Minute))
+	assert.Len(t, c.nodes, 1)
+	assert.Contains(t, c.nodes, "172.18.0.4",
+		"sessions established against the previous trust bundle should be dropped")
+}
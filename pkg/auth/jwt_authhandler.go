@@ -0,0 +1,475 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/cilium/hive/cell"
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+
+	"github.com/cilium/cilium/api/v1/models"
+	"github.com/cilium/cilium/pkg/auth/certs"
+	"github.com/cilium/cilium/pkg/endpointmanager"
+	"github.com/cilium/cilium/pkg/identity"
+	"github.com/cilium/cilium/pkg/policy"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// The JWT auth handler implements auth type "jwt" with a SPIFFE JWT-SVID
+// exchange over a plain HTTP endpoint instead of a mutual TLS handshake.
+// Both sides mint a short-lived JWT signed with the private key of their
+// X.509 SVID and carrying the certificate chain in the x5c header, so the
+// peer can verify the token against the trust bundle of the issuer's trust
+// domain. The response token echoes the request token's ID, binding the two
+// directions of the exchange together. This avoids opening a dedicated mTLS
+// port in environments where extra listening ports are prohibited - the
+// endpoint speaks ordinary HTTP and never grants anything beyond the
+// datapath auth entry.
+
+const (
+	// jwtAuthPath is the HTTP path of the JWT-SVID exchange endpoint.
+	jwtAuthPath = "/auth/jwt"
+
+	// jwtTokenLifetime bounds the validity of the exchanged tokens. The
+	// tokens only prove the exchange itself, the resulting auth entry
+	// expiration is taken from the certificates instead.
+	jwtTokenLifetime = 1 * time.Minute
+
+	// jwtMaxRequestSize bounds the accepted request body size.
+	jwtMaxRequestSize = 64 << 10
+)
+
+type JWTAuthConfig struct {
+	// JWTAuthListenerPort is the port of the HTTP endpoint answering JWT
+	// auth exchanges. 0 disables the JWT auth handler.
+	JWTAuthListenerPort int `mapstructure:"mesh-auth-jwt-listener-port"`
+
+	// JWTAuthTimeout bounds a complete JWT auth exchange with a remote node.
+	JWTAuthTimeout time.Duration `mapstructure:"mesh-auth-jwt-timeout"`
+}
+
+func (cfg JWTAuthConfig) Flags(flags *pflag.FlagSet) {
+	flags.IntVar(&cfg.JWTAuthListenerPort, "mesh-auth-jwt-listener-port", 0,
+		"Port on which the Cilium Agent answers JWT-SVID authentication exchanges, 0 to disable the JWT auth handler")
+	flags.DurationVar(&cfg.JWTAuthTimeout, "mesh-auth-jwt-timeout", 10*time.Second,
+		"Timeout for completing a JWT-SVID authentication exchange with a remote node")
+}
+
+type jwtAuthParams struct {
+	cell.In
+
+	Health cell.Health
+
+	CertificateProvider certs.CertificateProvider
+
+	EndpointManager endpointmanager.EndpointManager
+}
+
+func newJWTAuthHandler(logger logrus.FieldLogger, lc cell.Lifecycle, cfg JWTAuthConfig, params jwtAuthParams) authHandlerResult {
+	if cfg.JWTAuthListenerPort == 0 {
+		return authHandlerResult{}
+	}
+	if params.CertificateProvider == nil {
+		logger.Error("No certificate provider configured, but one is required. Please check if the spire flags are configured.")
+		params.Health.Degraded("JWT auth handler is disabled", errors.New("no certificate provider configured"))
+		return authHandlerResult{}
+	}
+
+	jwtHandler := &jwtAuthHandler{
+		cfg:             cfg,
+		log:             logger,
+		health:          params.Health,
+		cert:            params.CertificateProvider,
+		endpointManager: params.EndpointManager,
+	}
+
+	lc.Append(cell.Hook{OnStart: jwtHandler.onStart, OnStop: jwtHandler.onStop})
+
+	return authHandlerResult{
+		AuthHandler: jwtHandler,
+	}
+}
+
+type jwtAuthHandler struct {
+	cfg    JWTAuthConfig
+	log    logrus.FieldLogger
+	health cell.Health
+
+	cert certs.CertificateProvider
+
+	endpointManager endpointGetter
+
+	server *http.Server
+}
+
+// jwtAuthRequest is the body POSTed to the JWT auth endpoint. The identity
+// pair mirrors the mutual auth binding request: LocalIdentity is the
+// initiator's identity, RemoteIdentity the identity of the answering node the
+// initiator wants to authenticate with.
+type jwtAuthRequest struct {
+	Token          string `json:"token"`
+	LocalIdentity  uint32 `json:"local_identity"`
+	RemoteIdentity uint32 `json:"remote_identity"`
+}
+
+// jwtAuthResponse carries the answering node's token back to the initiator.
+type jwtAuthResponse struct {
+	Token string `json:"token"`
+}
+
+func (j *jwtAuthHandler) authType() policy.AuthType {
+	return policy.AuthTypeJWT
+}
+
+func (j *jwtAuthHandler) subscribeToRotatedIdentities() <-chan certs.CertificateRotationEvent {
+	// The certificate provider exposes a single rotation channel, which the
+	// mutual auth handler already consumes. Subscribing here as well would
+	// split the events between the two observers.
+	return nil
+}
+
+func (j *jwtAuthHandler) certProviderStatus() *models.Status {
+	return j.cert.Status()
+}
+
+func (j *jwtAuthHandler) authenticate(ar *authRequest) (*authResponse, error) {
+	if ar == nil {
+		return nil, errors.New("authRequest is nil")
+	}
+
+	token, tokenID, localLeaf, err := j.mintToken(ar.localIdentity, j.cert.NumericIdentityToSPIFFEID(ar.remoteIdentity), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to mint JWT for local identity %s: %w", ar.localIdentity.String(), err)
+	}
+
+	body, err := json.Marshal(&jwtAuthRequest{
+		Token:          token,
+		LocalIdentity:  ar.localIdentity.Uint32(),
+		RemoteIdentity: ar.remoteIdentity.Uint32(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JWT auth request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), j.cfg.JWTAuthTimeout)
+	defer cancel()
+
+	url := "http://" + net.JoinHostPort(ar.remoteNodeIP, strconv.Itoa(j.cfg.JWTAuthListenerPort)) + jwtAuthPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWT auth request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to perform JWT auth exchange with %s: %w", ar.remoteNodeIP, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWT auth exchange with %s failed with status %s", ar.remoteNodeIP, resp.Status)
+	}
+
+	var exchangeResp jwtAuthResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, jwtMaxRequestSize)).Decode(&exchangeResp); err != nil {
+		return nil, fmt.Errorf("failed to decode JWT auth response: %w", err)
+	}
+
+	remoteLeaf, claims, err := j.validateToken(exchangeResp.Token, j.cert.NumericIdentityToSPIFFEID(ar.localIdentity), ar.remoteIdentity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate JWT of remote identity %s: %w", ar.remoteIdentity.String(), err)
+	}
+	// The response token must be bound to this very exchange, a captured
+	// response cannot be replayed to answer a different request.
+	if reqID, _ := claims["req_jti"].(string); reqID != tokenID {
+		return nil, errors.New("JWT auth response is not bound to the request token")
+	}
+
+	// As with the mutual handshake, the auth entry expires with the
+	// shorter-lived of the two certificates.
+	expirationTime := localLeaf.NotAfter
+	if remoteLeaf.NotAfter.Before(expirationTime) {
+		expirationTime = remoteLeaf.NotAfter
+	}
+
+	return &authResponse{
+		expirationTime: expirationTime,
+	}, nil
+}
+
+// handleExchange answers the listener side of the JWT-SVID exchange.
+func (j *jwtAuthHandler) handleExchange(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req jwtAuthRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, jwtMaxRequestSize)).Decode(&req); err != nil {
+		http.Error(w, "malformed request", http.StatusBadRequest)
+		return
+	}
+
+	// From the listener's perspective, the local identity is the one the
+	// initiator wants to authenticate with.
+	localID := identity.NumericIdentity(req.RemoteIdentity)
+	remoteID := identity.NumericIdentity(req.LocalIdentity)
+
+	// As with the mutual auth listener, only identities present on this node
+	// are answered for.
+	if !j.hasLocalIdentity(localID) {
+		j.log.WithField("identity", localID.String()).Debug("Refusing JWT auth exchange for identity not present on this node")
+		http.Error(w, "identity not present on this node", http.StatusForbidden)
+		return
+	}
+
+	_, claims, err := j.validateToken(req.Token, j.cert.NumericIdentityToSPIFFEID(localID), remoteID)
+	if err != nil {
+		j.log.WithError(err).Debug("Failed to validate JWT auth request token")
+		http.Error(w, "token validation failed", http.StatusForbidden)
+		return
+	}
+
+	reqID, _ := claims["jti"].(string)
+	token, _, _, err := j.mintToken(localID, j.cert.NumericIdentityToSPIFFEID(remoteID), reqID)
+	if err != nil {
+		j.log.WithError(err).Error("Failed to mint JWT auth response token")
+		http.Error(w, "failed to mint response token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(&jwtAuthResponse{Token: token}); err != nil {
+		j.log.WithError(err).Debug("Failed to send JWT auth response")
+	}
+}
+
+// hasLocalIdentity returns true if an endpoint with the given security
+// identity is present on this node.
+func (j *jwtAuthHandler) hasLocalIdentity(id identity.NumericIdentity) bool {
+	if j.endpointManager == nil {
+		return false
+	}
+	for _, ep := range j.endpointManager.GetEndpoints() {
+		if ep.SecurityIdentity != nil && ep.SecurityIdentity.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// mintToken mints a short-lived JWT for the given identity, signed with the
+// private key of its X.509 SVID and carrying the certificate chain in the
+// x5c header. reqID, if not empty, is echoed in the req_jti claim to bind a
+// response token to the request it answers.
+func (j *jwtAuthHandler) mintToken(id identity.NumericIdentity, audience, reqID string) (token, tokenID string, leaf *x509.Certificate, err error) {
+	cert, err := j.cert.GetCertificateForIdentity(id)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to get certificate for identity %s: %w", id.String(), err)
+	}
+
+	method, err := jwtSigningMethod(cert.PrivateKey)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if isOpaqueSigner(cert.PrivateKey) {
+		// HSM- or TPM-backed keys only implement crypto.Signer, route the
+		// signing operation through the signer instead of the raw key.
+		method = &opaqueSigningMethod{SigningMethod: method}
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", "", nil, fmt.Errorf("failed to generate token ID: %w", err)
+	}
+	tokenID = hex.EncodeToString(nonce)
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub": j.cert.NumericIdentityToSPIFFEID(id),
+		"aud": audience,
+		"iat": now.Unix(),
+		"exp": now.Add(jwtTokenLifetime).Unix(),
+		"jti": tokenID,
+	}
+	if reqID != "" {
+		claims["req_jti"] = reqID
+	}
+
+	t := jwt.NewWithClaims(method, claims)
+	x5c := make([]string, 0, len(cert.Certificate))
+	for _, der := range cert.Certificate {
+		x5c = append(x5c, base64.StdEncoding.EncodeToString(der))
+	}
+	t.Header["x5c"] = x5c
+
+	signed, err := t.SignedString(cert.PrivateKey)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return signed, tokenID, cert.Leaf, nil
+}
+
+// validateToken verifies a received token: the x5c certificate chain against
+// the trust bundle of its trust domain, the signature against the leaf key,
+// the standard claims, the audience and the claimed peer identity against
+// the leaf certificate. The verified leaf and the claims are returned.
+func (j *jwtAuthHandler) validateToken(tokenString, expectedAudience string, claimedID identity.NumericIdentity) (*x509.Certificate, jwt.MapClaims, error) {
+	var leaf *x509.Certificate
+
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"ES256", "ES384", "RS256", "EdDSA"}))
+	claims := jwt.MapClaims{}
+	if _, err := parser.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		chain, err := jwtTokenChain(t)
+		if err != nil {
+			return nil, err
+		}
+		bundle, err := j.trustBundleFor(chain[0])
+		if err != nil {
+			return nil, err
+		}
+		verified, err := certs.NewVerifier(certs.VerifyConfig{StrictSAN: true}).VerifyChain(bundle, chain)
+		if err != nil {
+			return nil, err
+		}
+		leaf = verified
+		return leaf.PublicKey, nil
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	if !claims.VerifyAudience(expectedAudience, true) {
+		return nil, nil, errors.New("token audience does not match the local identity")
+	}
+	if sub, _ := claims["sub"].(string); sub != leaf.URIs[0].String() {
+		return nil, nil, errors.New("token subject does not match the signing certificate")
+	}
+	if valid, err := j.cert.ValidateIdentity(claimedID, leaf); err != nil {
+		return nil, nil, fmt.Errorf("failed to validate claimed identity %s: %w", claimedID.String(), err)
+	} else if !valid {
+		return nil, nil, fmt.Errorf("claimed identity %s does not match the signing certificate", claimedID.String())
+	}
+
+	return leaf, claims, nil
+}
+
+// trustBundleFor returns the CA pool the signing chain of a token is
+// verified against, the per-trust-domain pool with a federated certificate
+// provider.
+func (j *jwtAuthHandler) trustBundleFor(leaf *x509.Certificate) (*x509.CertPool, error) {
+	if federated, ok := j.cert.(certs.FederatedTrustProvider); ok {
+		trustDomain, err := certTrustDomain(leaf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get trust domain of signing certificate: %w", err)
+		}
+		return federated.GetTrustBundleForDomain(trustDomain)
+	}
+	return j.cert.GetTrustBundle()
+}
+
+// jwtTokenChain parses the certificate chain carried in the x5c header of a
+// token.
+func jwtTokenChain(t *jwt.Token) ([]*x509.Certificate, error) {
+	rawChain, ok := t.Header["x5c"].([]interface{})
+	if !ok || len(rawChain) == 0 {
+		return nil, errors.New("token carries no x5c certificate chain")
+	}
+	chain := make([]*x509.Certificate, len(rawChain))
+	for i, raw := range rawChain {
+		encoded, ok := raw.(string)
+		if !ok {
+			return nil, errors.New("malformed x5c certificate chain")
+		}
+		der, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode x5c certificate: %w", err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse x5c certificate: %w", err)
+		}
+		chain[i] = cert
+	}
+	return chain, nil
+}
+
+// jwtSigningMethod returns the JWT signing method matching the key of the
+// X.509 SVID.
+func jwtSigningMethod(key crypto.PrivateKey) (jwt.SigningMethod, error) {
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	switch pub := signer.Public().(type) {
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P256():
+			return jwt.SigningMethodES256, nil
+		case elliptic.P384():
+			return jwt.SigningMethodES384, nil
+		}
+		return nil, fmt.Errorf("unsupported ECDSA curve %s", pub.Curve.Params().Name)
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T", signer.Public())
+}
+
+func (j *jwtAuthHandler) onStart(_ cell.HookContext) error {
+	j.log.Info("Starting JWT auth handler")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(jwtAuthPath, j.handleExchange)
+	j.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: j.cfg.JWTAuthTimeout,
+	}
+
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(j.cfg.JWTAuthListenerPort))
+	if err != nil {
+		return fmt.Errorf("failed to listen on JWT auth port %d: %w", j.cfg.JWTAuthListenerPort, err)
+	}
+
+	go func() {
+		if err := j.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			j.log.WithError(err).Error("JWT auth endpoint failed")
+			j.health.Degraded("JWT auth endpoint failed", err)
+		}
+	}()
+	j.health.OK(fmt.Sprintf("Serving JWT auth endpoint on port %d", j.cfg.JWTAuthListenerPort))
+
+	return nil
+}
+
+func (j *jwtAuthHandler) onStop(_ cell.HookContext) error {
+	j.log.Info("Stopping JWT auth handler")
+	if j.server != nil {
+		return j.server.Close()
+	}
+	return nil
+}
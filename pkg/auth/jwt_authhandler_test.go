@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"crypto"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/cilium/cilium/pkg/auth/certs/fake"
+	"github.com/cilium/cilium/pkg/identity"
+)
+
+func Test_jwtAuthHandler_mintAndValidateToken(t *testing.T) {
+	provider := fake.NewProvider()
+	j := &jwtAuthHandler{
+		cfg:  JWTAuthConfig{JWTAuthListenerPort: 1234, JWTAuthTimeout: 5 * time.Second},
+		log:  logrus.New(),
+		cert: provider,
+	}
+
+	audience := provider.NumericIdentityToSPIFFEID(id1001)
+	token, tokenID, leaf, err := j.mintToken(id1000, audience, "")
+	if err != nil {
+		t.Fatalf("mintToken() error = %v", err)
+	}
+	if tokenID == "" {
+		t.Errorf("mintToken() returned an empty token ID")
+	}
+	if leaf == nil {
+		t.Fatalf("mintToken() returned no leaf certificate")
+	}
+
+	validatedLeaf, claims, err := j.validateToken(token, audience, id1000)
+	if err != nil {
+		t.Fatalf("validateToken() error = %v", err)
+	}
+	if !validatedLeaf.Equal(leaf) {
+		t.Errorf("validateToken() returned a different leaf certificate")
+	}
+	if jti, _ := claims["jti"].(string); jti != tokenID {
+		t.Errorf("validateToken() claims jti = %q, want %q", jti, tokenID)
+	}
+
+	if _, _, err := j.validateToken(token, provider.NumericIdentityToSPIFFEID(idbad1), id1000); err == nil {
+		t.Errorf("validateToken() with a wrong audience must fail")
+	}
+	if _, _, err := j.validateToken(token, audience, id1001); err == nil {
+		t.Errorf("validateToken() with a wrong claimed identity must fail")
+	}
+	if _, _, err := j.validateToken("not-a-token", audience, id1000); err == nil {
+		t.Errorf("validateToken() with a malformed token must fail")
+	}
+}
+
+// opaqueTestSigner hides the concrete key type behind a plain crypto.Signer,
+// as an HSM- or TPM-backed key would.
+type opaqueTestSigner struct {
+	signer crypto.Signer
+}
+
+func (s *opaqueTestSigner) Public() crypto.PublicKey {
+	return s.signer.Public()
+}
+
+func (s *opaqueTestSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return s.signer.Sign(rand, digest, opts)
+}
+
+// opaqueKeyProvider serves the certificates of the wrapped provider with
+// their private keys replaced by opaque signers.
+type opaqueKeyProvider struct {
+	*fake.Provider
+}
+
+func (p *opaqueKeyProvider) GetCertificateForIdentity(id identity.NumericIdentity) (*tls.Certificate, error) {
+	cert, err := p.Provider.GetCertificateForIdentity(id)
+	if err != nil {
+		return nil, err
+	}
+
+	opaque := *cert
+	opaque.PrivateKey = &opaqueTestSigner{signer: cert.PrivateKey.(crypto.Signer)}
+	return &opaque, nil
+}
+
+func Test_jwtAuthHandler_mintToken_opaqueKey(t *testing.T) {
+	provider := &opaqueKeyProvider{Provider: fake.NewProvider()}
+	j := &jwtAuthHandler{
+		cfg:  JWTAuthConfig{JWTAuthListenerPort: 1234, JWTAuthTimeout: 5 * time.Second},
+		log:  logrus.New(),
+		cert: provider,
+	}
+
+	audience := provider.NumericIdentityToSPIFFEID(id1001)
+	token, _, _, err := j.mintToken(id1000, audience, "")
+	if err != nil {
+		t.Fatalf("mintToken() error = %v", err)
+	}
+
+	if _, _, err := j.validateToken(token, audience, id1000); err != nil {
+		t.Fatalf("validateToken() error = %v", err)
+	}
+}
+
+func Test_jwtAuthHandler_authenticate(t *testing.T) {
+	provider := fake.NewProvider()
+
+	listener := &jwtAuthHandler{
+		log:             logrus.New(),
+		cert:            provider,
+		endpointManager: &fakeEndpointGetter{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(jwtAuthPath, listener.handleExchange)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	host, portStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to get test server address: %v", err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		t.Fatalf("failed to parse test server port: %v", err)
+	}
+
+	initiator := &jwtAuthHandler{
+		cfg:  JWTAuthConfig{JWTAuthListenerPort: port, JWTAuthTimeout: 5 * time.Second},
+		log:  logrus.New(),
+		cert: provider,
+	}
+
+	resp, err := initiator.authenticate(&authRequest{
+		localIdentity:  id1000,
+		remoteIdentity: id1001,
+		remoteNodeIP:   host,
+	})
+	if err != nil {
+		t.Fatalf("authenticate() error = %v", err)
+	}
+	if !resp.expirationTime.After(time.Now()) {
+		t.Errorf("authenticate() returned an already expired expiration time %v", resp.expirationTime)
+	}
+
+	// An identity without a local endpoint on the answering node is refused.
+	if _, err := initiator.authenticate(&authRequest{
+		localIdentity:  id1000,
+		remoteIdentity: 7777,
+		remoteNodeIP:   host,
+	}); err == nil {
+		t.Errorf("authenticate() against an identity not present on the remote node must fail")
+	}
+}
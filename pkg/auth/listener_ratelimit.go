@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"net"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/cilium/cilium/pkg/lock"
+	"github.com/cilium/cilium/pkg/logging/logfields"
+	"github.com/cilium/cilium/pkg/metrics"
+	"github.com/cilium/cilium/pkg/metrics/metric"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+type listenerMetrics struct {
+	// RateLimitedConnections counts inbound handshake connections which got
+	// rejected by the per-source rate limiter.
+	RateLimitedConnections metric.Counter
+
+	// BannedSources counts the temporary bans issued to handshake sources
+	// exceeding the accept rate limit.
+	BannedSources metric.Counter
+
+	// ConcurrencyLimitedConnections counts inbound handshake connections
+	// which got rejected because the maximum number of concurrently handled
+	// connections was reached.
+	ConcurrencyLimitedConnections metric.Counter
+
+	// RecoveredPanics counts panics recovered in the handshake code paths.
+	RecoveredPanics metric.Counter
+}
+
+func newListenerMetrics() *listenerMetrics {
+	return &listenerMetrics{
+		RateLimitedConnections: metric.NewCounter(metric.CounterOpts{
+			ConfigName: metrics.Namespace + "_mutual_auth_listener_rate_limited_total",
+			Namespace:  metrics.Namespace,
+			Subsystem:  "mutual_auth_listener",
+			Name:       "rate_limited_total",
+			Help:       "Number of handshake connections rejected by the per-source rate limiter",
+		}),
+		BannedSources: metric.NewCounter(metric.CounterOpts{
+			ConfigName: metrics.Namespace + "_mutual_auth_listener_bans_total",
+			Namespace:  metrics.Namespace,
+			Subsystem:  "mutual_auth_listener",
+			Name:       "bans_total",
+			Help:       "Number of temporary bans issued to handshake sources exceeding the accept rate limit",
+		}),
+		ConcurrencyLimitedConnections: metric.NewCounter(metric.CounterOpts{
+			ConfigName: metrics.Namespace + "_mutual_auth_listener_concurrency_limited_total",
+			Namespace:  metrics.Namespace,
+			Subsystem:  "mutual_auth_listener",
+			Name:       "concurrency_limited_total",
+			Help:       "Number of handshake connections rejected because the concurrent connection limit was reached",
+		}),
+		RecoveredPanics: metric.NewCounter(metric.CounterOpts{
+			ConfigName: metrics.Namespace + "_mutual_auth_handler_panics_total",
+			Namespace:  metrics.Namespace,
+			Subsystem:  "mutual_auth",
+			Name:       "handler_panics_total",
+			Help:       "Number of panics recovered in the mutual auth handshake code paths",
+		}),
+	}
+}
+
+// handshakeRateLimiter limits the rate at which inbound handshake connections
+// are accepted per source IP with a token bucket, so short legitimate bursts
+// (e.g. after a policy change affecting many identity pairs) pass while a
+// sustained flood does not. A source draining its bucket gets banned for a
+// fixed duration, so a single misbehaving peer cannot consume the handshake
+// capacity of the listener.
+type handshakeRateLimiter struct {
+	log     logrus.FieldLogger
+	metrics *listenerMetrics
+
+	rate        int // token refill rate per source and second
+	burst       int // bucket capacity per source
+	banDuration time.Duration
+
+	mutex     lock.Mutex
+	sources   map[string]*handshakeSource
+	lastPrune time.Time
+}
+
+type handshakeSource struct {
+	tokens      float64
+	lastRefill  time.Time
+	bannedUntil time.Time
+}
+
+func newHandshakeRateLimiter(log logrus.FieldLogger, rate, burst int, banDuration time.Duration, metrics *listenerMetrics) *handshakeRateLimiter {
+	if burst <= 0 {
+		burst = rate
+	}
+	return &handshakeRateLimiter{
+		log:         log,
+		metrics:     metrics,
+		rate:        rate,
+		burst:       burst,
+		banDuration: banDuration,
+		sources:     map[string]*handshakeSource{},
+	}
+}
+
+// allow returns true if a connection from the given address may be accepted
+// at the given time.
+func (l *handshakeRateLimiter) allow(addr net.Addr, now time.Time) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return true
+	}
+	ip := tcpAddr.IP.String()
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.pruneStaleSources(now)
+
+	src, ok := l.sources[ip]
+	if !ok {
+		src = &handshakeSource{tokens: float64(l.burst), lastRefill: now}
+		l.sources[ip] = src
+	}
+
+	if now.Before(src.bannedUntil) {
+		l.metrics.RateLimitedConnections.Inc()
+		return false
+	}
+
+	src.tokens = min(float64(l.burst), src.tokens+now.Sub(src.lastRefill).Seconds()*float64(l.rate))
+	src.lastRefill = now
+
+	if src.tokens < 1 {
+		src.bannedUntil = now.Add(l.banDuration)
+		l.metrics.BannedSources.Inc()
+		l.metrics.RateLimitedConnections.Inc()
+		l.log.WithFields(logrus.Fields{
+			logfields.IPAddr:   ip,
+			logfields.Duration: l.banDuration,
+		}).Warning("Temporarily banning handshake source exceeding the accept rate limit")
+		return false
+	}
+
+	src.tokens--
+	return true
+}
+
+// pruneStaleSources drops the state of sources which are neither banned nor
+// missing tokens from their bucket. It runs at most once per second, while
+// the mutex is held.
+func (l *handshakeRateLimiter) pruneStaleSources(now time.Time) {
+	if now.Sub(l.lastPrune) < time.Second {
+		return
+	}
+	l.lastPrune = now
+
+	for ip, src := range l.sources {
+		if now.After(src.bannedUntil) && now.Sub(src.lastRefill).Seconds()*float64(l.rate) >= float64(l.burst) {
+			delete(l.sources, ip)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func Test_handshakeRateLimiter_allow(t *testing.T) {
+	limiter := newHandshakeRateLimiter(logrus.New(), 2, 0, time.Minute, newListenerMetrics())
+
+	addr := &net.TCPAddr{IP: net.ParseIP("172.18.0.2"), Port: 12345}
+	otherAddr := &net.TCPAddr{IP: net.ParseIP("172.18.0.3"), Port: 12345}
+	now := time.Now()
+
+	// The first two connections drain the bucket, the third one exceeds the
+	// limit and bans the source.
+	for i := 0; i < 2; i++ {
+		if !limiter.allow(addr, now) {
+			t.Fatalf("connection %d got rejected, want accepted", i+1)
+		}
+	}
+	if limiter.allow(addr, now) {
+		t.Fatal("connection exceeding the limit got accepted, want rejected")
+	}
+
+	// Other sources are not affected by the ban.
+	if !limiter.allow(otherAddr, now) {
+		t.Fatal("connection from other source got rejected, want accepted")
+	}
+
+	// The source stays banned even after its bucket refilled.
+	if limiter.allow(addr, now.Add(2*time.Second)) {
+		t.Fatal("banned source got accepted, want rejected")
+	}
+
+	// Once the ban expired, the source is accepted again.
+	if !limiter.allow(addr, now.Add(2*time.Minute)) {
+		t.Fatal("source got rejected after ban expiry, want accepted")
+	}
+}
+
+func Test_handshakeRateLimiter_burst(t *testing.T) {
+	limiter := newHandshakeRateLimiter(logrus.New(), 1, 3, time.Minute, newListenerMetrics())
+
+	addr := &net.TCPAddr{IP: net.ParseIP("172.18.0.2"), Port: 12345}
+	now := time.Now()
+
+	// A burst of up to three connections is accepted even though the rate
+	// limit is one connection per second.
+	for i := 0; i < 3; i++ {
+		if !limiter.allow(addr, now) {
+			t.Fatalf("connection %d got rejected, want accepted", i+1)
+		}
+	}
+	if limiter.allow(addr, now) {
+		t.Fatal("connection exceeding the burst got accepted, want rejected")
+	}
+}
+
+func Test_handshakeRateLimiter_pruneStaleSources(t *testing.T) {
+	limiter := newHandshakeRateLimiter(logrus.New(), 1, 0, time.Minute, newListenerMetrics())
+
+	addr := &net.TCPAddr{IP: net.ParseIP("172.18.0.2"), Port: 12345}
+	now := time.Now()
+
+	limiter.allow(addr, now)
+	if len(limiter.sources) != 1 {
+		t.Fatalf("got %d tracked sources, want 1", len(limiter.sources))
+	}
+
+	// An idle, unbanned source gets pruned once its bucket is full again.
+	limiter.pruneStaleSources(now.Add(2 * time.Second))
+	if len(limiter.sources) != 0 {
+		t.Fatalf("got %d tracked sources after pruning, want 0", len(limiter.sources))
+	}
+}
@@ -7,14 +7,19 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/cilium/hive/cell"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+	"k8s.io/utils/clock"
 
 	"github.com/cilium/cilium/api/v1/models"
 	"github.com/cilium/cilium/pkg/auth/certs"
+	"github.com/cilium/cilium/pkg/backoff"
 	"github.com/cilium/cilium/pkg/datapath/types"
 	"github.com/cilium/cilium/pkg/identity"
 	"github.com/cilium/cilium/pkg/lock"
 	"github.com/cilium/cilium/pkg/maps/authmap"
+	"github.com/cilium/cilium/pkg/metrics/metric"
 	"github.com/cilium/cilium/pkg/policy"
 	"github.com/cilium/cilium/pkg/time"
 )
@@ -33,10 +38,93 @@ type AuthManager struct {
 	authHandlers          map[policy.AuthType]authHandler
 	authmap               authMapCacher
 	authSignalBackoffTime time.Duration
+	clock                 clock.PassiveClock
+
+	faultInjector *faultInjector
+
+	// meshNodes resolves node IDs of remote-cluster nodes learned via
+	// ClusterMesh, nil outside of a ClusterMesh.
+	meshNodes *meshNodeCache
 
 	mutex                    lock.Mutex
 	pending                  map[authKey]struct{}
 	handleAuthenticationFunc func(a *AuthManager, k authKey, reAuth bool)
+
+	// inFlight coalesces concurrent handshakes: an authentication finding an
+	// identical request already in flight waits for its result instead of
+	// starting a second handshake. Bursts of datapath signals for the same
+	// identity pair collapse into a single handshake this way.
+	inFlightMutex lock.Mutex
+	inFlight      map[inFlightKey]*inFlightAuthentication
+
+	// concurrencySem bounds the number of concurrently running
+	// authentications, see MeshAuthMaxConcurrent. Requests beyond the bound
+	// wait for a free slot, surfacing as queued authentications in the
+	// metrics. Nil disables the bound.
+	concurrencySem chan struct{}
+
+	coalescedAuths metric.Counter
+	queuedAuths    metric.Gauge
+
+	// failureCacheTTL enables negative caching of failed authentications:
+	// for its duration, new datapath triggers for a failed key are answered
+	// from the cache instead of starting another handshake, protecting the
+	// certificate provider and the network during persistent failures. The
+	// datapath keeps dropping the traffic as auth-failed in the meantime.
+	// A value of 0 disables the cache.
+	failureCacheTTL time.Duration
+	failedAuths     map[authKey]time.Time
+
+	// dryRun makes policies requiring authentication observe-only: a failed
+	// authentication is logged and counted, but the identity pair is granted
+	// a temporary auth map entry of dryRunGrantLifetime anyway, so the
+	// datapath does not drop its traffic. This validates the certificate
+	// provider integration and identity mappings before enforcing.
+	dryRun              bool
+	dryRunGrantLifetime time.Duration
+
+	// dryRunWouldFail counts the authentications which would have failed in
+	// dry-run mode.
+	dryRunWouldFail metric.Counter
+
+	// notReadyGracePeriod gates auth enforcement on subsystem readiness:
+	// while a certificate provider is not ready yet (e.g. right after an
+	// agent restart), datapath authentication requests are granted a
+	// temporary auth map entry of this lifetime instead of being dropped.
+	// The entry expires and triggers a real authentication once the
+	// subsystem is warm. A value of 0 disables the gating.
+	notReadyGracePeriod time.Duration
+
+	// lastFailures remembers the most recent authentication failure per
+	// local identity, surfaced in the endpoint authentication status.
+	lastFailures map[identity.NumericIdentity]authFailure
+
+	// nodeAuthStats remembers the authentication history per remote node,
+	// surfaced in the CiliumNode authentication status.
+	nodeAuthStats map[uint16]nodeAuthStat
+
+	// retryAttempts is the number of times a failed authentication is
+	// retried with exponential backoff before giving up. A value of 0
+	// disables the retry queue.
+	retryAttempts int
+	retryBackoff  backoff.Exponential
+	retries       map[authKey]*authRetryState
+
+	// reauthBeforeExpiry is the lead time before the expiration of an auth
+	// map entry at which the entry is proactively re-authenticated, so the
+	// datapath does not see a gap between the expiry of an entry and the
+	// traffic-triggered re-authentication. A value of 0 disables the
+	// re-auth scheduler.
+	reauthBeforeExpiry time.Duration
+
+	// restoredReauthRate is the number of restored auth map entries
+	// re-authenticated per second in the one-shot reconciliation pass after
+	// an agent restart. A value of 0 disables the pass.
+	restoredReauthRate int
+
+	// monitorNotifier publishes authentication events on the monitor event
+	// bus if set.
+	monitorNotifier authNotifier
 }
 
 // authHandler is responsible to handle authentication for a specific auth type
@@ -57,6 +145,22 @@ type authResponse struct {
 	expirationTime time.Time
 }
 
+// inFlightKey identifies a running handshake for coalescing purposes. Two
+// authentications share a handshake only if they target the same identity
+// pair on the same remote node with the same auth type.
+type inFlightKey struct {
+	authRequest
+	authType policy.AuthType
+}
+
+// inFlightAuthentication is a running handshake whose result can be shared
+// by coalesced authentications. done is closed once resp and err are set.
+type inFlightAuthentication struct {
+	done chan struct{}
+	resp *authResponse
+	err  error
+}
+
 func newAuthManager(logger logrus.FieldLogger, authHandlers []authHandler, authmap authMapCacher, nodeIDHandler types.NodeIDHandler, authSignalBackoffTime time.Duration) (*AuthManager, error) {
 	ahs := map[policy.AuthType]authHandler{}
 	for _, ah := range authHandlers {
@@ -75,8 +179,14 @@ func newAuthManager(logger logrus.FieldLogger, authHandlers []authHandler, authm
 		authmap:                  authmap,
 		nodeIDHandler:            nodeIDHandler,
 		pending:                  make(map[authKey]struct{}),
+		inFlight:                 make(map[inFlightKey]*inFlightAuthentication),
+		failedAuths:              make(map[authKey]time.Time),
+		retries:                  make(map[authKey]*authRetryState),
+		lastFailures:             make(map[identity.NumericIdentity]authFailure),
+		nodeAuthStats:            make(map[uint16]nodeAuthStat),
 		handleAuthenticationFunc: handleAuthentication,
 		authSignalBackoffTime:    authSignalBackoffTime,
+		clock:                    clock.RealClock{},
 	}, nil
 }
 
@@ -89,7 +199,7 @@ func (a *AuthManager) handleAuthRequest(_ context.Context, key signalAuthKey) er
 		authType:       policy.AuthType(key.AuthType),
 	}
 
-	if k.localIdentity.IsReservedIdentity() || k.remoteIdentity.IsReservedIdentity() {
+	if !isAuthenticatable(k.localIdentity) || !isAuthenticatable(k.remoteIdentity) {
 		a.logger.
 			WithField("key", k).
 			Info("Reserved identity, skipping authentication as reserved identities are not compatible with authentication")
@@ -105,11 +215,30 @@ func (a *AuthManager) handleAuthRequest(_ context.Context, key signalAuthKey) er
 	return nil
 }
 
+// isAuthenticatable returns true if the identity can take part in
+// authentication. Besides workload identities this covers the reserved host
+// and remote-node identities, for which SPIFFE identities can be issued to
+// authenticate node-to-node traffic. All other reserved identities (world,
+// health, ...) have no certificates and cannot be authenticated.
+func isAuthenticatable(id identity.NumericIdentity) bool {
+	switch id {
+	case identity.ReservedIdentityHost, identity.ReservedIdentityRemoteNode:
+		return true
+	}
+
+	return !id.IsReservedIdentity()
+}
+
 func (a *AuthManager) handleCertificateRotationEvent(_ context.Context, event certs.CertificateRotationEvent) error {
 	a.logger.
 		WithField("identity", event.Identity).
 		Debug("Handle certificate rotation event")
 
+	// A rotated certificate may well be what resolves a failing identity
+	// pair, so cached failures involving the identity must not keep
+	// suppressing handshake attempts for the rest of their TTL.
+	a.invalidateCachedFailures(event.Identity)
+
 	all, err := a.authmap.All()
 	if err != nil {
 		return fmt.Errorf("failed to get all auth map entries: %w", err)
@@ -152,24 +281,242 @@ func handleAuthentication(a *AuthManager, k authKey, reAuth bool) {
 			// it will authenticate again, this is to make sure that
 			// we re-authenticate if the authmap was updated by an
 			// external source.
-			if i, err := a.authmap.GetCacheInfo(key); err == nil && i.expiration.After(time.Now()) && time.Now().Before(i.storedAt.Add(a.authSignalBackoffTime)) {
+			backoffTime := a.signalBackoffDuration()
+			if i, err := a.authmap.GetCacheInfo(key); err == nil && i.expiration.After(a.clock.Now()) && a.clock.Now().Before(i.storedAt.Add(backoffTime)) {
 				a.logger.
 					WithField("key", key).
 					WithField("storedAt", i.storedAt).
-					Debugf("Already authenticated in the past %s, skipping authentication", a.authSignalBackoffTime.String())
+					Debugf("Already authenticated in the past %s, skipping authentication", backoffTime.String())
 				return
 			}
 		}
 
-		if err := a.authenticate(key); err != nil {
+		if !reAuth && a.gateOnReadiness(key) {
+			return
+		}
+
+		if !reAuth && a.isFailureCached(key) {
+			a.logger.
+				WithField("key", key).
+				Debug("Recently failed to authenticate, answering from negative cache")
+			return
+		}
+
+		release := a.acquireAuthSlot()
+		err := a.authenticate(key)
+		release()
+		if err != nil {
+			if a.dryRunGrant(key, err) {
+				// The failure still surfaces in the endpoint and node
+				// authentication status, it just does not drop traffic.
+				a.recordFailure(key, err)
+				a.recordNodeResult(key, err)
+				a.notifyAuthResult(key, err)
+				return
+			}
+			a.cacheFailure(key)
+			a.scheduleRetry(key)
+			a.recordFailure(key, err)
+			a.recordNodeResult(key, err)
+			a.notifyAuthResult(key, err)
 			a.logger.
 				WithError(err).
 				WithField("key", key).
 				Warning("Failed to authenticate request")
+			return
 		}
+
+		a.clearRetry(key)
+		a.recordNodeResult(key, nil)
+		a.notifyAuthResult(key, nil)
 	}(k)
 }
 
+func (a *AuthManager) signalBackoffDuration() time.Duration {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.authSignalBackoffTime
+}
+
+// SetSignalBackoffDuration updates the signal backoff duration at runtime.
+// It is called by the dynamic configuration watcher.
+func (a *AuthManager) SetSignalBackoffDuration(d time.Duration) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.authSignalBackoffTime != d {
+		a.logger.
+			WithField("duration", d).
+			Info("Updating authentication signal backoff duration")
+		a.authSignalBackoffTime = d
+	}
+}
+
+// listenerReconfigurer is implemented by auth handlers whose listener can be
+// enabled, disabled or rebound to a new port at runtime.
+type listenerReconfigurer interface {
+	applyListenerSettings(port int, disabled bool)
+}
+
+// ApplyListenerSettings forwards runtime listener settings to the auth
+// handlers supporting runtime reconfiguration. It is called by the dynamic
+// configuration watcher.
+func (a *AuthManager) ApplyListenerSettings(port int, disabled bool) {
+	for _, handler := range a.authHandlers {
+		if r, ok := handler.(listenerReconfigurer); ok {
+			r.applyListenerSettings(port, disabled)
+		}
+	}
+}
+
+// reconcileRestoredEntries re-authenticates the auth map entries restored
+// after an agent restart in a single rate-limited pass. Certificates may
+// have rotated while the agent was down and those rotation events were never
+// seen, so the restored entries are proactively re-issued instead of relying
+// on traffic-triggered drops to resurface them one by one.
+func (a *AuthManager) reconcileRestoredEntries(ctx context.Context, _ cell.Health) error {
+	all, err := a.authmap.All()
+	if err != nil {
+		return fmt.Errorf("failed to get all auth map entries: %w", err)
+	}
+	if len(all) == 0 {
+		return nil
+	}
+
+	a.logger.
+		WithField("entries", len(all)).
+		WithField("rate", a.restoredReauthRate).
+		Info("Re-authenticating restored auth map entries")
+
+	limiter := rate.NewLimiter(rate.Limit(a.restoredReauthRate), 1)
+	for k := range all {
+		if err := limiter.Wait(ctx); err != nil {
+			return nil // context cancelled during shutdown
+		}
+		a.handleAuthenticationFunc(a, k, true)
+	}
+
+	return nil
+}
+
+// authSubsystemReady returns true once all certificate providers backing the
+// registered auth handlers report an Ok status.
+func (a *AuthManager) authSubsystemReady() bool {
+	for _, h := range a.authHandlers {
+		if status := h.certProviderStatus(); status != nil && status.State != models.StatusStateOk {
+			return false
+		}
+	}
+	return true
+}
+
+// gateOnReadiness answers an authentication request with a temporary grace
+// entry while the auth subsystem is not ready yet, so agent restarts do not
+// cause a burst of auth-failed drops before the certificate provider is
+// warm. It returns true if the request was answered this way.
+func (a *AuthManager) gateOnReadiness(key authKey) bool {
+	if a.notReadyGracePeriod <= 0 || a.authSubsystemReady() {
+		return false
+	}
+
+	expiration := a.clock.Now().Add(a.notReadyGracePeriod)
+	if err := a.updateAuthMap(key, expiration); err != nil {
+		a.logger.
+			WithError(err).
+			WithField("key", key).
+			Warning("Failed to write grace entry for not yet ready auth subsystem")
+		return false
+	}
+
+	a.logger.
+		WithField("key", key).
+		WithField("expiration", expiration).
+		Info("Auth subsystem is not ready yet, granting temporary grace entry")
+	return true
+}
+
+// dryRunGrant answers a failed authentication in dry-run mode: the would-be
+// failure is logged and counted, but the identity pair is granted a temporary
+// auth map entry so the datapath does not drop its traffic. It returns true
+// if the failure was absorbed this way.
+func (a *AuthManager) dryRunGrant(key authKey, authErr error) bool {
+	if !a.dryRun {
+		return false
+	}
+
+	expiration := a.clock.Now().Add(a.dryRunGrantLifetime)
+	if err := a.updateAuthMap(key, expiration); err != nil {
+		a.logger.
+			WithError(err).
+			WithField("key", key).
+			Warning("Dry-run mode: failed to write grant entry for failed authentication")
+		return false
+	}
+
+	if a.dryRunWouldFail != nil {
+		a.dryRunWouldFail.Inc()
+	}
+	a.logger.
+		WithError(authErr).
+		WithField("key", key).
+		WithField("expiration", expiration).
+		Warning("Dry-run mode: authentication would have failed, granting traffic anyway")
+	return true
+}
+
+// isFailureCached returns true if the key failed to authenticate within the
+// last failureCacheTTL and the retry should be suppressed.
+func (a *AuthManager) isFailureCached(key authKey) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.failureCacheTTL <= 0 {
+		return false
+	}
+
+	expiry, ok := a.failedAuths[key]
+	if !ok {
+		return false
+	}
+	if a.clock.Now().After(expiry) {
+		delete(a.failedAuths, key)
+		return false
+	}
+	return true
+}
+
+// cacheFailure records a failed authentication for the given key. Expired
+// entries of other keys are pruned on the way, keeping the map bounded.
+func (a *AuthManager) cacheFailure(key authKey) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.failureCacheTTL <= 0 {
+		return
+	}
+
+	now := a.clock.Now()
+	for k, expiry := range a.failedAuths {
+		if now.After(expiry) {
+			delete(a.failedAuths, k)
+		}
+	}
+	a.failedAuths[key] = now.Add(a.failureCacheTTL)
+}
+
+// invalidateCachedFailures drops all cached authentication failures in which
+// the given identity takes part, on either side of the handshake.
+func (a *AuthManager) invalidateCachedFailures(id identity.NumericIdentity) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	for k := range a.failedAuths {
+		if k.localIdentity == id || k.remoteIdentity == id {
+			delete(a.failedAuths, k)
+		}
+	}
+}
+
 // markPendingAuth checks if there is a pending authentication for the given key.
 // If an auth is already pending returns false, otherwise marks the key as pending
 // and returns true.
@@ -201,6 +548,12 @@ func (a *AuthManager) authenticate(key authKey) error {
 		WithField("key", key).
 		Debug("Policy is requiring authentication")
 
+	if a.faultInjector != nil {
+		if err := a.faultInjector.maybeFail(key); err != nil {
+			return err
+		}
+	}
+
 	// Authenticate according to the requested auth type
 	h, ok := a.authHandlers[key.authType]
 	if !ok {
@@ -208,6 +561,14 @@ func (a *AuthManager) authenticate(key authKey) error {
 	}
 
 	nodeIP := a.nodeIDHandler.GetNodeIP(key.remoteNodeID)
+	if a.meshNodes != nil {
+		// Remote-cluster nodes advertise the address to dial themselves;
+		// the datapath may only know an address of theirs that is not
+		// routable from this cluster.
+		if meshIP := a.meshNodes.nodeIP(key.remoteNodeID); meshIP != "" {
+			nodeIP = meshIP
+		}
+	}
 	if nodeIP == "" {
 		return fmt.Errorf("remote node IP not available for node ID %d", key.remoteNodeID)
 	}
@@ -218,7 +579,7 @@ func (a *AuthManager) authenticate(key authKey) error {
 		remoteNodeIP:   nodeIP,
 	}
 
-	authResp, err := h.authenticate(authReq)
+	authResp, err := a.coalescedAuthenticate(h, authReq)
 	if err != nil {
 		return fmt.Errorf("failed to authenticate with auth type %s: %w", key.authType, err)
 	}
@@ -235,6 +596,117 @@ func (a *AuthManager) authenticate(key authKey) error {
 	return nil
 }
 
+// coalescedAuthenticate runs the handshake for the given request, sharing the
+// result of an already in-flight handshake of an identical request instead of
+// starting a second one. Many endpoints hitting the same remote identity at
+// once trigger authentication for several keys that resolve to the same
+// handshake; only one of them talks to the certificate provider and the
+// network.
+func (a *AuthManager) coalescedAuthenticate(h authHandler, authReq *authRequest) (*authResponse, error) {
+	flightKey := inFlightKey{
+		authRequest: *authReq,
+		authType:    h.authType(),
+	}
+
+	a.inFlightMutex.Lock()
+	if flight, ok := a.inFlight[flightKey]; ok {
+		a.inFlightMutex.Unlock()
+		if a.coalescedAuths != nil {
+			a.coalescedAuths.Inc()
+		}
+		a.logger.
+			WithField("request", flightKey).
+			Debug("Identical handshake already in flight, waiting for its result")
+		<-flight.done
+		return flight.resp, flight.err
+	}
+	flight := &inFlightAuthentication{done: make(chan struct{})}
+	a.inFlight[flightKey] = flight
+	a.inFlightMutex.Unlock()
+
+	flight.resp, flight.err = h.authenticate(authReq)
+
+	a.inFlightMutex.Lock()
+	delete(a.inFlight, flightKey)
+	a.inFlightMutex.Unlock()
+	close(flight.done)
+
+	return flight.resp, flight.err
+}
+
+// acquireAuthSlot blocks until a concurrency slot is free and returns the
+// function releasing the slot again. Waiting authentications are surfaced in
+// the queued authentications gauge.
+func (a *AuthManager) acquireAuthSlot() (release func()) {
+	if a.concurrencySem == nil {
+		return func() {}
+	}
+
+	if a.queuedAuths != nil {
+		a.queuedAuths.Inc()
+	}
+	a.concurrencySem <- struct{}{}
+	if a.queuedAuths != nil {
+		a.queuedAuths.Dec()
+	}
+
+	return func() { <-a.concurrencySem }
+}
+
+// AuthenticateOnDemand runs the authentication of the given identity pair
+// against the given remote node on behalf of the agent API, independently of
+// the datapath triggers. The negotiated expiration is returned, but no auth
+// map entry is installed - entries remain driven by the datapath signals, so
+// an operator probing authentications cannot grant datapath access.
+func (a *AuthManager) AuthenticateOnDemand(localIdentity, remoteIdentity identity.NumericIdentity, remoteNodeIP string) (time.Time, error) {
+	if !isAuthenticatable(localIdentity) || !isAuthenticatable(remoteIdentity) {
+		return time.Time{}, fmt.Errorf("reserved identities are not compatible with authentication")
+	}
+
+	h, err := a.onDemandAuthHandler()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	authResp, err := h.authenticate(&authRequest{
+		localIdentity:  localIdentity,
+		remoteIdentity: remoteIdentity,
+		remoteNodeIP:   remoteNodeIP,
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return authResp.expirationTime, nil
+}
+
+// onDemandAuthHandler picks the auth handler backing AuthenticateOnDemand.
+// The spire handler is preferred when registered; otherwise the single other
+// configured handler is used. The always-fail handler only exists for testing
+// policies and is never picked implicitly.
+func (a *AuthManager) onDemandAuthHandler() (authHandler, error) {
+	if h, ok := a.authHandlers[policy.AuthTypeSpire]; ok {
+		return h, nil
+	}
+
+	var candidates []authHandler
+	for authType, h := range a.authHandlers {
+		if authType == policy.AuthTypeAlwaysFail {
+			continue
+		}
+		candidates = append(candidates, h)
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil, fmt.Errorf("no handler registered for on-demand authentication")
+	case 1:
+		return candidates[0], nil
+	default:
+		return nil, fmt.Errorf("multiple auth handlers registered - unable to pick one for on-demand authentication")
+	}
+}
+
 func (a *AuthManager) updateAuthMap(key authKey, expirationTime time.Time) error {
 	val := authInfo{
 		expiration: expirationTime,
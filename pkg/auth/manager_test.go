@@ -8,12 +8,15 @@ import (
 	"errors"
 	"maps"
 	"net"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/cilium/ebpf"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	clocktesting "k8s.io/utils/clock/testing"
 
 	"github.com/cilium/cilium/api/v1/models"
 	"github.com/cilium/cilium/pkg/auth/certs"
@@ -132,6 +135,24 @@ func Test_authManager_handleAuthRequest(t *testing.T) {
 	assert.True(t, handleAuthCalled)
 }
 
+func Test_authManager_handleAuthRequest_nodeIdentities(t *testing.T) {
+	authHandlers := []authHandler{newAlwaysPassAuthHandler(logrus.New())}
+
+	am, err := newAuthManager(logrus.New(), authHandlers, nil, nil, time.Second)
+	assert.NoError(t, err)
+	assert.NotNil(t, am)
+
+	handleAuthCalled := false
+	am.handleAuthenticationFunc = func(_ *AuthManager, k authKey, reAuth bool) {
+		handleAuthCalled = true
+		assert.Equal(t, authKey{localIdentity: identity.ReservedIdentityHost, remoteIdentity: identity.ReservedIdentityRemoteNode, remoteNodeID: 0, authType: 100}, k)
+	}
+
+	err = am.handleAuthRequest(context.Background(), signalAuthKey{LocalIdentity: identity.ReservedIdentityHost.Uint32(), RemoteIdentity: identity.ReservedIdentityRemoteNode.Uint32(), RemoteNodeID: 0, AuthType: 100, Pad: 0})
+	assert.NoError(t, err)
+	assert.True(t, handleAuthCalled)
+}
+
 func Test_authManager_handleAuthRequest_reservedRemoteIdentity(t *testing.T) {
 	authHandlers := []authHandler{newAlwaysPassAuthHandler(logrus.New())}
 
@@ -286,6 +307,7 @@ type fakeAuthMap struct {
 	entries    map[authKey]authInfo
 	failDelete bool
 	failGet    bool
+	failUpdate bool
 }
 
 func (r *fakeAuthMap) Delete(key authKey) error {
@@ -341,6 +363,10 @@ func (r *fakeAuthMap) Get(key authKey) (authInfo, error) {
 }
 
 func (r *fakeAuthMap) Update(key authKey, info authInfo) error {
+	if r.failUpdate {
+		return errors.New("failed to update entry")
+	}
+
 	r.entries[authKey{
 		localIdentity:  key.localIdentity,
 		remoteIdentity: key.remoteIdentity,
@@ -359,3 +385,357 @@ func assertErrorString(errString string) assert.ErrorAssertionFunc {
 		return assert.EqualError(t, err, errString, msgAndArgs)
 	}
 }
+
+func Test_authManager_authenticateOnDemand(t *testing.T) {
+	// A single registered handler serves the on-demand API even when it is
+	// not the spire one.
+	am, err := newAuthManager(logrus.New(), []authHandler{newAlwaysPassAuthHandler(logrus.New())}, nil, nil, time.Second)
+	assert.NoError(t, err)
+
+	expiration, err := am.AuthenticateOnDemand(1000, 2000, "172.18.0.2")
+	assert.NoError(t, err)
+	assert.False(t, expiration.IsZero())
+
+	// Reserved identities are rejected.
+	_, err = am.AuthenticateOnDemand(identity.ReservedIdentityWorld, 2000, "172.18.0.2")
+	assert.ErrorContains(t, err, "reserved identities")
+
+	// The always-fail handler only exists for testing policies and is never
+	// picked implicitly.
+	am, err = newAuthManager(logrus.New(), []authHandler{&alwaysFailAuthHandler{}}, nil, nil, time.Second)
+	assert.NoError(t, err)
+	_, err = am.AuthenticateOnDemand(1000, 2000, "172.18.0.2")
+	assert.ErrorContains(t, err, "no handler registered for on-demand authentication")
+
+	// With several non-spire handlers registered the choice is ambiguous.
+	am, err = newAuthManager(logrus.New(), []authHandler{newAlwaysPassAuthHandler(logrus.New()), &fakeAuthHandler{}}, nil, nil, time.Second)
+	assert.NoError(t, err)
+	_, err = am.AuthenticateOnDemand(1000, 2000, "172.18.0.2")
+	assert.ErrorContains(t, err, "multiple auth handlers registered")
+}
+
+func Test_authManager_negativeFailureCache(t *testing.T) {
+	authHandlers := []authHandler{newAlwaysPassAuthHandler(logrus.New())}
+
+	am, err := newAuthManager(logrus.New(), authHandlers, nil, nil, time.Second)
+	assert.NoError(t, err)
+	assert.NotNil(t, am)
+
+	am.failureCacheTTL = 5 * time.Second
+	fakeClock := clocktesting.NewFakePassiveClock(time.Now())
+	am.clock = fakeClock
+
+	key := authKey{localIdentity: 1000, remoteIdentity: 2000, remoteNodeID: 1, authType: 100}
+
+	assert.False(t, am.isFailureCached(key), "unknown key must not be cached")
+
+	am.cacheFailure(key)
+	assert.True(t, am.isFailureCached(key), "failed key must be cached within the TTL")
+
+	fakeClock.SetTime(fakeClock.Now().Add(10 * time.Second))
+	assert.False(t, am.isFailureCached(key), "failed key must expire after the TTL")
+
+	// With a disabled cache, failures are not recorded at all.
+	am.failureCacheTTL = 0
+	am.cacheFailure(key)
+	assert.False(t, am.isFailureCached(key))
+}
+
+func Test_authManager_invalidateCachedFailures(t *testing.T) {
+	authHandlers := []authHandler{newAlwaysPassAuthHandler(logrus.New())}
+
+	am, err := newAuthManager(logrus.New(), authHandlers, nil, nil, time.Second)
+	assert.NoError(t, err)
+	assert.NotNil(t, am)
+
+	am.failureCacheTTL = 5 * time.Second
+
+	localKey := authKey{localIdentity: 1000, remoteIdentity: 2000, remoteNodeID: 1, authType: 100}
+	remoteKey := authKey{localIdentity: 3000, remoteIdentity: 1000, remoteNodeID: 1, authType: 100}
+	otherKey := authKey{localIdentity: 4000, remoteIdentity: 5000, remoteNodeID: 1, authType: 100}
+	am.cacheFailure(localKey)
+	am.cacheFailure(remoteKey)
+	am.cacheFailure(otherKey)
+
+	am.invalidateCachedFailures(1000)
+
+	assert.False(t, am.isFailureCached(localKey), "cached failure of the rotated local identity must be dropped")
+	assert.False(t, am.isFailureCached(remoteKey), "cached failure of the rotated remote identity must be dropped")
+	assert.True(t, am.isFailureCached(otherKey), "cached failure of an unrelated identity pair must remain")
+}
+
+// Fake AuthHandler with a certificate provider which is not ready
+type notReadyAuthHandler struct {
+	fakeAuthHandler
+}
+
+func (r *notReadyAuthHandler) certProviderStatus() *models.Status {
+	return &models.Status{State: models.StatusStateFailure}
+}
+
+func Test_authManager_authSubsystemReady(t *testing.T) {
+	am, err := newAuthManager(logrus.New(), []authHandler{&fakeAuthHandler{}}, nil, nil, time.Second)
+	assert.NoError(t, err)
+	assert.True(t, am.authSubsystemReady(), "handler without certificate provider status must count as ready")
+
+	am, err = newAuthManager(logrus.New(), []authHandler{&notReadyAuthHandler{}}, nil, nil, time.Second)
+	assert.NoError(t, err)
+	assert.False(t, am.authSubsystemReady())
+}
+
+func Test_authManager_gateOnReadiness(t *testing.T) {
+	aMap := &fakeAuthMap{entries: map[authKey]authInfo{}}
+
+	am, err := newAuthManager(logrus.New(), []authHandler{&notReadyAuthHandler{}}, aMap, nil, time.Second)
+	assert.NoError(t, err)
+	assert.NotNil(t, am)
+
+	key := authKey{localIdentity: 1000, remoteIdentity: 2000, remoteNodeID: 1, authType: 100}
+
+	// Disabled gating never answers requests, even when not ready.
+	assert.False(t, am.gateOnReadiness(key))
+	assert.Empty(t, aMap.entries)
+
+	// With gating enabled, a not yet ready subsystem grants a grace entry.
+	am.notReadyGracePeriod = time.Minute
+	assert.True(t, am.gateOnReadiness(key))
+	assert.Len(t, aMap.entries, 1)
+}
+
+func Test_authManager_dryRunGrant(t *testing.T) {
+	aMap := &fakeAuthMap{entries: map[authKey]authInfo{}}
+
+	am, err := newAuthManager(logrus.New(), []authHandler{&fakeAuthHandler{}}, aMap, nil, time.Second)
+	assert.NoError(t, err)
+	assert.NotNil(t, am)
+
+	key := authKey{localIdentity: 1000, remoteIdentity: 2000, remoteNodeID: 1, authType: 100}
+	authErr := errors.New("handshake failed")
+
+	// Outside of dry-run mode, failures are not absorbed.
+	assert.False(t, am.dryRunGrant(key, authErr))
+	assert.Empty(t, aMap.entries)
+
+	// In dry-run mode, the failed pair is granted a temporary entry.
+	am.dryRun = true
+	am.dryRunGrantLifetime = time.Minute
+	assert.True(t, am.dryRunGrant(key, authErr))
+	assert.Len(t, aMap.entries, 1)
+
+	// A grant entry which cannot be written falls back to enforcing.
+	aMap.failUpdate = true
+	delete(aMap.entries, key)
+	assert.False(t, am.dryRunGrant(key, authErr))
+	assert.Empty(t, aMap.entries)
+}
+
+// blockingAuthHandler signals each started handshake and blocks it until
+// release is closed.
+type blockingAuthHandler struct {
+	fakeAuthHandler
+
+	started chan struct{}
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (r *blockingAuthHandler) authenticate(authReq *authRequest) (*authResponse, error) {
+	r.calls.Add(1)
+	r.started <- struct{}{}
+	<-r.release
+
+	return &authResponse{expirationTime: time.Now().Add(time.Hour)}, nil
+}
+
+func Test_authManager_coalescedAuthenticate(t *testing.T) {
+	handler := &blockingAuthHandler{started: make(chan struct{}, 2), release: make(chan struct{})}
+
+	am, err := newAuthManager(logrus.New(), []authHandler{handler}, nil, nil, time.Second)
+	assert.NoError(t, err)
+	assert.NotNil(t, am)
+	am.coalescedAuths = newHandshakeMetrics().CoalescedAuthentications
+
+	req := &authRequest{localIdentity: 1000, remoteIdentity: 2000, remoteNodeIP: "172.18.0.2"}
+
+	results := make(chan error, 2)
+	go func() {
+		_, err := am.coalescedAuthenticate(handler, req)
+		results <- err
+	}()
+	// Once the handshake runs it is registered as in flight.
+	<-handler.started
+
+	go func() {
+		_, err := am.coalescedAuthenticate(handler, req)
+		results <- err
+	}()
+	// The second request must attach to the in-flight handshake instead of
+	// starting one of its own.
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(am.coalescedAuths) == 1
+	}, time.Second, time.Millisecond)
+
+	close(handler.release)
+	assert.NoError(t, <-results)
+	assert.NoError(t, <-results)
+	assert.EqualValues(t, 1, handler.calls.Load())
+}
+
+func Test_authManager_acquireAuthSlot(t *testing.T) {
+	am, err := newAuthManager(logrus.New(), []authHandler{&fakeAuthHandler{}}, nil, nil, time.Second)
+	assert.NoError(t, err)
+	assert.NotNil(t, am)
+
+	// Without a bound, slots are granted immediately.
+	release := am.acquireAuthSlot()
+	release()
+
+	// With a bound of one, the second acquisition has to wait for the
+	// first slot to be released.
+	am.concurrencySem = make(chan struct{}, 1)
+	release = am.acquireAuthSlot()
+
+	acquired := make(chan struct{})
+	go func() {
+		release := am.acquireAuthSlot()
+		close(acquired)
+		release()
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second slot acquired while the first one is still held")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	release()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("second slot not acquired after the first one was released")
+	}
+}
+
+func Test_authManager_reconcileRestoredEntries(t *testing.T) {
+	aMap := &fakeAuthMap{
+		entries: map[authKey]authInfo{
+			{localIdentity: 1000, remoteIdentity: 2000, remoteNodeID: 1, authType: 100}: {expiration: time.Now()},
+			{localIdentity: 2000, remoteIdentity: 3000, remoteNodeID: 1, authType: 100}: {expiration: time.Now()},
+		},
+	}
+
+	am, err := newAuthManager(logrus.New(), []authHandler{&fakeAuthHandler{}}, aMap, nil, time.Second)
+	assert.NoError(t, err)
+	assert.NotNil(t, am)
+	am.restoredReauthRate = 1000
+
+	reauthed := map[authKey]bool{}
+	am.handleAuthenticationFunc = func(_ *AuthManager, k authKey, reAuth bool) {
+		assert.True(t, reAuth)
+		reauthed[k] = true
+	}
+
+	err = am.reconcileRestoredEntries(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Len(t, reauthed, 2)
+}
+
+func Test_authManager_retryQueue(t *testing.T) {
+	am, err := newAuthManager(logrus.New(), []authHandler{newAlwaysPassAuthHandler(logrus.New())}, nil, nil, time.Second)
+	assert.NoError(t, err)
+	assert.NotNil(t, am)
+
+	fakeClock := clocktesting.NewFakePassiveClock(time.Now())
+	am.clock = fakeClock
+	am.retryAttempts = 2
+	am.retryBackoff = newRetryBackoff(config{
+		MeshAuthRetryBackoffMin: time.Second,
+		MeshAuthRetryBackoffMax: time.Minute,
+	})
+
+	key := authKey{localIdentity: 1000, remoteIdentity: 2000, remoteNodeID: 1, authType: 100}
+
+	// A failed authentication is queued and becomes due once its backoff
+	// expired.
+	am.scheduleRetry(key)
+	assert.Empty(t, am.dueRetries(), "retry must not be due before its backoff expired")
+	fakeClock.SetTime(fakeClock.Now().Add(3 * time.Minute))
+	assert.Equal(t, []authKey{key}, am.dueRetries())
+
+	// A successful authentication drops the key from the queue.
+	am.clearRetry(key)
+	assert.Empty(t, am.dueRetries())
+
+	// The queue gives up after the configured number of attempts.
+	am.scheduleRetry(key)
+	am.scheduleRetry(key)
+	am.scheduleRetry(key)
+	fakeClock.SetTime(fakeClock.Now().Add(3 * time.Minute))
+	assert.Empty(t, am.dueRetries(), "exhausted key must be dropped from the queue")
+
+	// A disabled queue never records failures.
+	am.retryAttempts = 0
+	am.scheduleRetry(key)
+	fakeClock.SetTime(fakeClock.Now().Add(3 * time.Minute))
+	assert.Empty(t, am.dueRetries())
+}
+
+func Test_authManager_retryFailedAuthentications(t *testing.T) {
+	am, err := newAuthManager(logrus.New(), []authHandler{newAlwaysPassAuthHandler(logrus.New())}, nil, nil, time.Second)
+	assert.NoError(t, err)
+
+	fakeClock := clocktesting.NewFakePassiveClock(time.Now())
+	am.clock = fakeClock
+	am.retryAttempts = 3
+	am.retryBackoff = newRetryBackoff(config{
+		MeshAuthRetryBackoffMin: time.Second,
+		MeshAuthRetryBackoffMax: time.Minute,
+	})
+
+	var retried []authKey
+	am.handleAuthenticationFunc = func(a *AuthManager, k authKey, reAuth bool) {
+		assert.True(t, reAuth, "retries must bypass the negative cache")
+		retried = append(retried, k)
+	}
+
+	key := authKey{localIdentity: 1000, remoteIdentity: 2000, remoteNodeID: 1, authType: 100}
+	am.scheduleRetry(key)
+
+	assert.NoError(t, am.retryFailedAuthentications(context.Background()))
+	assert.Empty(t, retried, "retry must not run before its backoff expired")
+
+	fakeClock.SetTime(fakeClock.Now().Add(3 * time.Minute))
+	assert.NoError(t, am.retryFailedAuthentications(context.Background()))
+	assert.Equal(t, []authKey{key}, retried)
+}
+
+func Test_authManager_reauthExpiringEntries(t *testing.T) {
+	now := time.Now()
+	expiringKey := authKey{localIdentity: 1000, remoteIdentity: 2000, remoteNodeID: 1, authType: 100}
+	aMap := &fakeAuthMap{
+		entries: map[authKey]authInfo{
+			expiringKey: {expiration: now.Add(30 * time.Second)},
+			{localIdentity: 2000, remoteIdentity: 3000, remoteNodeID: 1, authType: 100}: {expiration: now.Add(time.Hour)},
+			{localIdentity: 3000, remoteIdentity: 4000, remoteNodeID: 1, authType: 100}: {expiration: now.Add(-time.Minute)},
+		},
+	}
+
+	am, err := newAuthManager(logrus.New(), []authHandler{newAlwaysPassAuthHandler(logrus.New())}, aMap, nil, time.Second)
+	assert.NoError(t, err)
+
+	fakeClock := clocktesting.NewFakePassiveClock(now)
+	am.clock = fakeClock
+	am.reauthBeforeExpiry = time.Minute
+
+	var reauthed []authKey
+	am.handleAuthenticationFunc = func(a *AuthManager, k authKey, reAuth bool) {
+		assert.True(t, reAuth, "proactive re-authentication must bypass the signal backoff")
+		reauthed = append(reauthed, k)
+	}
+
+	// Only the entry expiring within the lead time is re-authenticated -
+	// neither the one with plenty of lifetime left nor the already expired
+	// one.
+	assert.NoError(t, am.reauthExpiringEntries(context.Background()))
+	assert.Equal(t, []authKey{expiringKey}, reauthed)
+}
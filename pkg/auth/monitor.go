@@ -0,0 +1,37 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	monitorAPI "github.com/cilium/cilium/pkg/monitor/api"
+)
+
+// authNotifier is the subset of the monitor agent used to publish
+// authentication events to its listeners.
+type authNotifier interface {
+	SendEvent(typ int, event interface{}) error
+}
+
+// notifyAuthResult publishes the outcome of an authentication attempt on the
+// monitor event bus. Runtime security tools like Tetragon consume these
+// events to correlate identity authentication with process-level telemetry.
+func (a *AuthManager) notifyAuthResult(key authKey, authErr error) {
+	if a.monitorNotifier == nil {
+		return
+	}
+
+	msg := monitorAPI.AuthMessage(
+		key.localIdentity.Uint32(),
+		key.remoteIdentity.Uint32(),
+		a.nodeIDHandler.GetNodeIP(key.remoteNodeID),
+		key.authType.String(),
+		authErr,
+	)
+	if err := a.monitorNotifier.SendEvent(monitorAPI.MessageTypeAgent, msg); err != nil {
+		a.logger.
+			WithError(err).
+			WithField("key", key).
+			Debug("Failed to publish authentication event to the monitor")
+	}
+}
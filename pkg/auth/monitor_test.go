@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	monitorAPI "github.com/cilium/cilium/pkg/monitor/api"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// Fake monitor notifier which records the published events
+type fakeAuthNotifier struct {
+	events []monitorAPI.AgentNotifyMessage
+}
+
+func (f *fakeAuthNotifier) SendEvent(typ int, event interface{}) error {
+	f.events = append(f.events, event.(monitorAPI.AgentNotifyMessage))
+	return nil
+}
+
+func Test_authManager_notifyAuthResult(t *testing.T) {
+	am, err := newAuthManager(logrus.New(), []authHandler{&fakeAuthHandler{}}, &fakeAuthMap{},
+		newFakeNodeIDHandler(map[uint16]string{10: "172.18.0.2"}), time.Second)
+	assert.NoError(t, err)
+	assert.NotNil(t, am)
+
+	notifier := &fakeAuthNotifier{}
+	am.monitorNotifier = notifier
+
+	key := authKey{localIdentity: 1000, remoteIdentity: 2000, remoteNodeID: 10, authType: 255}
+	am.notifyAuthResult(key, nil)
+	am.notifyAuthResult(key, errors.New("handshake failed"))
+
+	assert.Len(t, notifier.events, 2)
+
+	assert.Equal(t, monitorAPI.AgentNotifyAuthSuccess, notifier.events[0].Type)
+	success := notifier.events[0].Notification.(monitorAPI.AuthNotification)
+	assert.Equal(t, uint32(1000), success.LocalIdentity)
+	assert.Equal(t, uint32(2000), success.RemoteIdentity)
+	assert.Equal(t, "172.18.0.2", success.RemoteNodeIP)
+	assert.Empty(t, success.Error)
+
+	assert.Equal(t, monitorAPI.AgentNotifyAuthFail, notifier.events[1].Type)
+	fail := notifier.events[1].Notification.(monitorAPI.AuthNotification)
+	assert.Equal(t, "handshake failed", fail.Error)
+}
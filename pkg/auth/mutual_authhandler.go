@@ -10,18 +10,26 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"runtime/debug"
+	"slices"
 	"strconv"
+	"sync"
 
 	"github.com/cilium/hive/cell"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/cilium/cilium/api/v1/models"
 	"github.com/cilium/cilium/pkg/auth/certs"
+	"github.com/cilium/cilium/pkg/datapath/types"
 	"github.com/cilium/cilium/pkg/endpoint"
 	"github.com/cilium/cilium/pkg/endpointmanager"
 	"github.com/cilium/cilium/pkg/identity"
+	"github.com/cilium/cilium/pkg/ipcache"
+	"github.com/cilium/cilium/pkg/lock"
 	"github.com/cilium/cilium/pkg/logging/logfields"
+	monitoragent "github.com/cilium/cilium/pkg/monitor/agent"
 	"github.com/cilium/cilium/pkg/policy"
 	"github.com/cilium/cilium/pkg/time"
 )
@@ -30,12 +38,64 @@ type endpointGetter interface {
 	GetEndpoints() []*endpoint.Endpoint
 }
 
+// remoteIdentityLookup abstracts the ipcache lookups used to cross-check the
+// identity claimed by a dialing agent against the node hosting it. It is
+// satisfied by *ipcache.IPCache.
+type remoteIdentityLookup interface {
+	LookupByIdentity(id identity.NumericIdentity) (ips []string)
+	GetHostIPCache(ip string) (net.IP, uint8)
+}
+
+// handshakeDialer abstracts the dialing of the outgoing handshake connection.
+// It is satisfied by *net.Dialer and can be replaced to tunnel handshakes
+// through custom transports (in-memory pipes in tests, proxies, VRFs).
+type handshakeDialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// handshakeListenConfig abstracts the creation of the handshake listener
+// socket. It is satisfied by *net.ListenConfig.
+type handshakeListenConfig interface {
+	Listen(ctx context.Context, network, address string) (net.Listener, error)
+}
+
 type mutualAuthParams struct {
 	cell.In
 
+	Health cell.Health
+
 	CertificateProvider certs.CertificateProvider
 
 	EndpointManager endpointmanager.EndpointManager
+
+	NodeIDHandler types.NodeIDHandler
+
+	ListenerMetrics  *listenerMetrics
+	ClockSkewMetrics *clockSkewMetrics
+	HandshakeMetrics *handshakeMetrics
+
+	// Dialer and ListenConfig are optional overrides for the handshake
+	// transport. If not provided, the handler falls back to the net package
+	// defaults.
+	Dialer       handshakeDialer       `optional:"true"`
+	ListenConfig handshakeListenConfig `optional:"true"`
+
+	// MonitorAgent carries audit records to the monitor listeners.
+	MonitorAgent monitoragent.Agent `optional:"true"`
+
+	// SessionKeyProgrammer receives the datapath keying material derived
+	// from the handshakes, see sessionKeyStore.
+	SessionKeyProgrammer types.AuthSessionKeyProgrammer `optional:"true"`
+
+	// IPCache is used to cross-check the identity claimed by a dialing agent
+	// against the node its connection originates from, see
+	// MutualAuthValidateClientIdentity.
+	IPCache *ipcache.IPCache `optional:"true"`
+
+	// EndpointResolver overrides the address authenticate() dials for a
+	// remote node, see authEndpointResolver. It takes precedence over the
+	// static MutualAuthDialAddressMap.
+	EndpointResolver authEndpointResolver `optional:"true"`
 }
 
 func newMutualAuthHandler(logger logrus.FieldLogger, lc cell.Lifecycle, cfg MutualAuthConfig, params mutualAuthParams) authHandlerResult {
@@ -43,15 +103,95 @@ func newMutualAuthHandler(logger logrus.FieldLogger, lc cell.Lifecycle, cfg Mutu
 		logger.Info("Mutual authentication handler is disabled as no port is configured")
 		return authHandlerResult{}
 	}
+	if cfg.MutualAuthListenerDisabled && cfg.MutualAuthClientDisabled {
+		logger.Info("Mutual authentication handler is disabled as both listener and client are disabled")
+		return authHandlerResult{}
+	}
+	switch cfg.MutualAuthListenerAddressFamily {
+	case "", addressFamilyDual, addressFamilyIPv4, addressFamilyIPv6, addressFamilySeparate:
+	default:
+		logger.WithField(logfields.Family, cfg.MutualAuthListenerAddressFamily).
+			Error("Unsupported mutual auth listener address family, must be dual, ipv4, ipv6 or separate")
+		params.Health.Degraded("Mutual auth handler is disabled",
+			fmt.Errorf("unsupported listener address family %q", cfg.MutualAuthListenerAddressFamily))
+		return authHandlerResult{}
+	}
 	if params.CertificateProvider == nil {
-		logger.Fatal("No certificate provider configured, but one is required. Please check if the spire flags are configured.")
+		// Do not take down the whole agent over a mutual auth
+		// misconfiguration - disable the handler and report degraded health
+		// instead.
+		logger.Error("No certificate provider configured, but one is required. Please check if the spire flags are configured.")
+		params.Health.Degraded("Mutual auth handler is disabled", errors.New("no certificate provider configured"))
+		return authHandlerResult{}
+	}
+	tlsMinVersion, err := parseTLSMinVersion(cfg.MutualAuthTLSMinVersion)
+	if err != nil {
+		logger.WithError(err).Error("Unsupported minimum TLS version for the mutual auth handshake")
+		params.Health.Degraded("Mutual auth handler is disabled", err)
+		return authHandlerResult{}
+	}
+	tlsCipherSuites, err := parseTLSCipherSuites(cfg.MutualAuthTLSCipherSuites)
+	if err != nil {
+		logger.WithError(err).Error("Unsupported TLS cipher suites for the mutual auth handshake")
+		params.Health.Degraded("Mutual auth handler is disabled", err)
+		return authHandlerResult{}
+	}
+	endpointResolver := params.EndpointResolver
+	if endpointResolver == nil && len(cfg.MutualAuthDialAddressMap) > 0 {
+		staticResolver, err := newStaticAuthEndpointResolver(cfg.MutualAuthDialAddressMap)
+		if err != nil {
+			logger.WithError(err).Error("Invalid mutual auth dial address map")
+			params.Health.Degraded("Mutual auth handler is disabled", err)
+			return authHandlerResult{}
+		}
+		endpointResolver = staticResolver
 	}
 
 	mAuthHandler := &mutualAuthHandler{
-		cfg:             cfg,
-		log:             logger,
-		cert:            params.CertificateProvider,
-		endpointManager: params.EndpointManager,
+		cfg:              cfg,
+		log:              logger,
+		health:           params.Health,
+		listenerMetrics:  params.ListenerMetrics,
+		skewMetrics:      params.ClockSkewMetrics,
+		handshakeMetrics: params.HandshakeMetrics,
+		cert:             params.CertificateProvider,
+		endpointManager:  params.EndpointManager,
+		nodeIDHandler:    params.NodeIDHandler,
+		dialer:           params.Dialer,
+		listenConfig:     params.ListenConfig,
+		keyProgrammer:    params.SessionKeyProgrammer,
+		endpointResolver: endpointResolver,
+		tlsMinVersion:    tlsMinVersion,
+		tlsCipherSuites:  tlsCipherSuites,
+		sniFailures:      newSNIFailureTracker(logger, params.Health),
+	}
+	mAuthHandler.auditor = newAuthAuditor(logger, cfg, params.MonitorAgent)
+	if cfg.MutualAuthListenerRateLimit > 0 {
+		mAuthHandler.rateLimiter = newHandshakeRateLimiter(logger,
+			cfg.MutualAuthListenerRateLimit, cfg.MutualAuthListenerRateBurst,
+			cfg.MutualAuthListenerBanDuration, params.ListenerMetrics)
+	}
+	if cfg.MutualAuthQuarantineThreshold > 0 {
+		mAuthHandler.quarantine = newPeerQuarantine(logger,
+			cfg.MutualAuthQuarantineThreshold, cfg.MutualAuthQuarantineCooldown)
+	}
+	if cfg.MutualAuthSessionCacheSize > 0 {
+		mAuthHandler.sessionCache = newHandshakeSessionCache(logger,
+			cfg.MutualAuthSessionCacheSize, cfg.MutualAuthSessionCacheIdleTimeout)
+	}
+	if len(cfg.MutualAuthFederatedTrustDomains) > 0 {
+		mAuthHandler.federation = newTrustDomainFederation(logger, cfg.MutualAuthFederatedTrustDomains)
+	}
+	if cfg.MutualAuthRevocationCRL || cfg.MutualAuthRevocationOCSP {
+		mAuthHandler.revocation = newRevocationChecker(logger, cfg)
+	}
+	if cfg.MutualAuthValidateClientIdentity {
+		if params.IPCache == nil {
+			logger.Error("Client identity validation requires the ipcache, but it is not available")
+			params.Health.Degraded("Mutual auth handler is disabled", errors.New("client identity validation requires the ipcache"))
+			return authHandlerResult{}
+		}
+		mAuthHandler.remoteIdentities = params.IPCache
 	}
 
 	lc.Append(cell.Hook{OnStart: mAuthHandler.onStart, OnStop: mAuthHandler.onStop})
@@ -64,6 +204,155 @@ func newMutualAuthHandler(logger logrus.FieldLogger, lc cell.Lifecycle, cfg Mutu
 type MutualAuthConfig struct {
 	MutualAuthListenerPort   int           `mapstructure:"mesh-auth-mutual-listener-port"`
 	MutualAuthConnectTimeout time.Duration `mapstructure:"mesh-auth-mutual-connect-timeout"`
+
+	// MutualAuthListenerAddress is the address the listener binds to, e.g.
+	// the cilium_host IP of the node. Empty binds to all interfaces.
+	// MutualAuthListenerAddressFamily selects the address family of the
+	// listener socket: "dual" for a single dual-stack socket, "ipv4" or
+	// "ipv6" for a single-family socket (e.g. on IPv6-only clusters), or
+	// "separate" for one IPv4 and one IPv6 socket on hosts where dual-stack
+	// sockets are unavailable.
+	MutualAuthListenerAddress       string `mapstructure:"mesh-auth-mutual-listener-address"`
+	MutualAuthListenerAddressFamily string `mapstructure:"mesh-auth-mutual-listener-address-family"`
+
+	// MutualAuthListenerDisabled and MutualAuthClientDisabled allow turning
+	// off the server and client side of the handshake independently, e.g. on
+	// nodes which only ever initiate or only ever answer handshakes.
+	MutualAuthListenerDisabled bool `mapstructure:"mesh-auth-mutual-listener-disabled"`
+	MutualAuthClientDisabled   bool `mapstructure:"mesh-auth-mutual-client-disabled"`
+
+	// MutualAuthValidateSourceIP rejects inbound handshake connections whose
+	// source IP is not a known cluster node, before the TLS handshake starts.
+	MutualAuthValidateSourceIP bool `mapstructure:"mesh-auth-mutual-listener-validate-source"`
+
+	// MutualAuthValidateClientIdentity makes the listener cross-check the
+	// identity claimed by a dialing agent against the ipcache: an endpoint
+	// carrying the claimed identity must be hosted on the node the
+	// connection originates from. This rejects compromised nodes presenting
+	// a valid certificate for an identity none of their endpoints hold.
+	MutualAuthValidateClientIdentity bool `mapstructure:"mesh-auth-mutual-validate-client-identity"`
+
+	// MutualAuthReceiptsEnabled makes both sides of a successful handshake
+	// emit a signed receipt into the log for audit purposes.
+	MutualAuthReceiptsEnabled bool `mapstructure:"mesh-auth-mutual-receipts"`
+
+	// MutualAuthAuditFile, MutualAuthAuditSyslog and MutualAuthAuditMonitor
+	// enable the structured audit log of authentication events, written to
+	// the given file, to syslog and to the monitor event bus respectively.
+	// An empty file path and false disable the respective sink.
+	MutualAuthAuditFile    string `mapstructure:"mesh-auth-audit-file"`
+	MutualAuthAuditSyslog  bool   `mapstructure:"mesh-auth-audit-syslog"`
+	MutualAuthAuditMonitor bool   `mapstructure:"mesh-auth-audit-monitor"`
+
+	// MutualAuthHandshakeTimeout bounds the duration of the TLS handshake on
+	// both sides: inbound so slow clients cannot hold sockets and goroutines
+	// open indefinitely, outbound so a wedged remote node cannot stall auth
+	// requests.
+	MutualAuthHandshakeTimeout time.Duration `mapstructure:"mesh-auth-mutual-handshake-timeout"`
+
+	// MutualAuthListenerRateLimit is the number of inbound handshake
+	// connections accepted per source IP and second, with bursts of up to
+	// MutualAuthListenerRateBurst connections. A source exceeding it gets
+	// banned for MutualAuthListenerBanDuration. A rate limit of 0 disables
+	// the limiter, a burst of 0 defaults to the rate limit.
+	MutualAuthListenerRateLimit   int           `mapstructure:"mesh-auth-mutual-listener-rate-limit"`
+	MutualAuthListenerRateBurst   int           `mapstructure:"mesh-auth-mutual-listener-rate-burst"`
+	MutualAuthListenerBanDuration time.Duration `mapstructure:"mesh-auth-mutual-listener-ban-duration"`
+
+	// MutualAuthListenerMaxConnections caps the number of concurrently
+	// handled inbound handshake connections, bounding the goroutines and
+	// memory a connection burst can consume. 0 disables the cap.
+	MutualAuthListenerMaxConnections int `mapstructure:"mesh-auth-mutual-listener-max-connections"`
+
+	// MutualAuthDatapathFilter installs a datapath filter which restricts
+	// the listener port to traffic from cluster nodes, making the port
+	// invisible to pods and external scanners.
+	MutualAuthDatapathFilter bool `mapstructure:"mesh-auth-mutual-listener-datapath-filter"`
+
+	// MutualAuthNearExpiryThreshold makes the handler act when the local
+	// certificate is within this duration of its expiry and rotation has not
+	// arrived yet. MutualAuthNearExpiryAction selects the action: "proceed"
+	// silently, "warn" but proceed, or "refuse" the handshake instead of
+	// installing auth entries that expire almost immediately. A threshold of
+	// 0 disables the check.
+	MutualAuthNearExpiryThreshold time.Duration `mapstructure:"mesh-auth-mutual-near-expiry-threshold"`
+	MutualAuthNearExpiryAction    string        `mapstructure:"mesh-auth-mutual-near-expiry-action"`
+
+	// MutualAuthQuarantineThreshold is the number of certificate validation
+	// failures after which handshakes with a remote node are refused for
+	// MutualAuthQuarantineCooldown. 0 disables the quarantine.
+	MutualAuthQuarantineThreshold int           `mapstructure:"mesh-auth-mutual-quarantine-threshold"`
+	MutualAuthQuarantineCooldown  time.Duration `mapstructure:"mesh-auth-mutual-quarantine-cooldown"`
+
+	// MutualAuthSessionCacheSize is the number of remote nodes for which TLS
+	// sessions of outbound handshakes are cached, so repeated handshakes to
+	// the same node resume the session instead of performing a fresh
+	// certificate exchange. Sessions of nodes unused for longer than
+	// MutualAuthSessionCacheIdleTimeout are dropped. 0 disables the cache.
+	MutualAuthSessionCacheSize        int           `mapstructure:"mesh-auth-mutual-session-cache-size"`
+	MutualAuthSessionCacheIdleTimeout time.Duration `mapstructure:"mesh-auth-mutual-session-cache-idle-timeout"`
+
+	// MutualAuthSessionTicketsDisabled stops the listener from issuing TLS
+	// session tickets, forcing every inbound handshake through a full
+	// certificate exchange regardless of the peers' session caches.
+	MutualAuthSessionTicketsDisabled bool `mapstructure:"mesh-auth-mutual-session-tickets-disabled"`
+
+	// MutualAuthProtocolNegotiation runs the exchange after the mTLS
+	// handshake as a gRPC service over the established connection, in which
+	// the peers negotiate protocol version, supported auth extensions and
+	// the granted expiration explicitly. Support is advertised via TLS ALPN,
+	// so peers without it transparently fall back to the plain exchange.
+	MutualAuthProtocolNegotiation bool `mapstructure:"mesh-auth-mutual-protocol-negotiation"`
+
+	// MutualAuthSessionKeys derives per-identity-pair keying material from
+	// the TLS session of successful handshakes, which the datapath can use
+	// to seed encryption keys or key rotation hints tied to the
+	// authenticated identities.
+	MutualAuthSessionKeys bool `mapstructure:"mesh-auth-mutual-session-keys"`
+
+	// MutualAuthFederatedTrustDomains lists the pairs of SPIFFE trust
+	// domains between which cross trust domain handshakes are allowed, in
+	// the form "<trust-domain>=<trust-domain>". When set, handshakes between
+	// peers of two different trust domains are refused unless the pair is
+	// listed, which is the federation policy for per-tenant trust domains.
+	MutualAuthFederatedTrustDomains []string `mapstructure:"mesh-auth-federated-trust-domains"`
+
+	// MutualAuthRevocationCRL and MutualAuthRevocationOCSP enable revocation
+	// checking of peer certificates against the CRL distribution points and
+	// OCSP responders listed in the certificate, so a compromised workload
+	// identity can be revoked without waiting for its certificate to expire.
+	// With OCSP enabled the listener additionally staples a cached OCSP
+	// response for its own certificate into inbound handshakes. Lookups which
+	// cannot be completed are logged and ignored unless
+	// MutualAuthRevocationHardFail is set; results are cached per certificate
+	// for MutualAuthRevocationCacheTTL.
+	MutualAuthRevocationCRL      bool          `mapstructure:"mesh-auth-mutual-revocation-crl"`
+	MutualAuthRevocationOCSP     bool          `mapstructure:"mesh-auth-mutual-revocation-ocsp"`
+	MutualAuthRevocationHardFail bool          `mapstructure:"mesh-auth-mutual-revocation-hard-fail"`
+	MutualAuthRevocationCacheTTL time.Duration `mapstructure:"mesh-auth-mutual-revocation-cache-ttl"`
+
+	// MutualAuthDialAddressMap statically maps node IPs to the "host:port"
+	// address their mutual auth listener is reachable at, in the form
+	// "<node-ip>=<host:port>". It serves topologies where the node IPs are
+	// not directly routable, e.g. nodes behind NAT advertising an external
+	// IP. Nodes without a mapping are dialed directly.
+	MutualAuthDialAddressMap []string `mapstructure:"mesh-auth-mutual-dial-address-map"`
+
+	// MutualAuthTLSMinVersion is the minimum TLS version accepted for the
+	// handshake ("1.2" or "1.3"). The handshake defaults to TLS 1.3 only;
+	// lowering the minimum serves compliance regimes which mandate specific
+	// TLS 1.2 cipher suites.
+	MutualAuthTLSMinVersion string `mapstructure:"mesh-auth-mutual-tls-min-version"`
+
+	// MutualAuthTLSCipherSuites restricts the TLS 1.2 cipher suites offered
+	// and accepted in the handshake, by their IANA names. The TLS 1.3
+	// suites are not configurable in Go and remain unaffected.
+	MutualAuthTLSCipherSuites []string `mapstructure:"mesh-auth-mutual-tls-cipher-suites"`
+
+	// MutualAuthFIPSMode restricts the handshake to FIPS-approved algorithms
+	// and rejects peer certificates outside the approved set. For full FIPS
+	// compliance the agent additionally has to be built with BoringCrypto.
+	MutualAuthFIPSMode bool `mapstructure:"mesh-auth-mutual-fips-mode"`
 }
 
 func (cfg MutualAuthConfig) Flags(flags *pflag.FlagSet) {
@@ -71,41 +360,334 @@ func (cfg MutualAuthConfig) Flags(flags *pflag.FlagSet) {
 		"Port on which the Cilium Agent will perform mutual authentication handshakes between other Agents")
 	flags.DurationVar(&cfg.MutualAuthConnectTimeout, "mesh-auth-mutual-connect-timeout", 5*time.Second,
 		"Timeout for connecting to the remote node TCP socket")
+	flags.StringVar(&cfg.MutualAuthListenerAddress, "mesh-auth-mutual-listener-address", "",
+		"Address the mutual authentication listener binds to, empty to bind to all interfaces")
+	flags.StringVar(&cfg.MutualAuthListenerAddressFamily, "mesh-auth-mutual-listener-address-family", addressFamilyDual,
+		"Address family of the mutual authentication listener (dual, ipv4, ipv6 or separate for one socket per family)")
+	flags.DurationVar(&cfg.MutualAuthHandshakeTimeout, "mesh-auth-mutual-handshake-timeout", 10*time.Second,
+		"Timeout for completing a mutual authentication TLS handshake, applied to both inbound and outbound handshakes")
+	flags.BoolVar(&cfg.MutualAuthReceiptsEnabled, "mesh-auth-mutual-receipts", false,
+		"Emit signed receipts of successful mutual authentication handshakes for audit purposes")
+	flags.StringVar(&cfg.MutualAuthAuditFile, "mesh-auth-audit-file", "",
+		"File to append structured audit records of mutual authentication handshakes to, empty to disable")
+	flags.BoolVar(&cfg.MutualAuthAuditSyslog, "mesh-auth-audit-syslog", false,
+		"Write structured audit records of mutual authentication handshakes to syslog")
+	flags.BoolVar(&cfg.MutualAuthAuditMonitor, "mesh-auth-audit-monitor", false,
+		"Publish structured audit records of mutual authentication handshakes on the monitor event bus")
+	flags.BoolVar(&cfg.MutualAuthListenerDisabled, "mesh-auth-mutual-listener-disabled", false,
+		"Disable the listener side of the mutual authentication handshake")
+	flags.MarkHidden("mesh-auth-mutual-listener-disabled")
+	flags.BoolVar(&cfg.MutualAuthClientDisabled, "mesh-auth-mutual-client-disabled", false,
+		"Disable the client side of the mutual authentication handshake")
+	flags.MarkHidden("mesh-auth-mutual-client-disabled")
+	flags.BoolVar(&cfg.MutualAuthValidateSourceIP, "mesh-auth-mutual-listener-validate-source", false,
+		"Reject mutual authentication handshakes originating from IPs which are not known cluster nodes")
+	flags.BoolVar(&cfg.MutualAuthValidateClientIdentity, "mesh-auth-mutual-validate-client-identity", false,
+		"Reject mutual authentication handshakes claiming an identity which is not hosted on the node the connection originates from")
+	flags.IntVar(&cfg.MutualAuthListenerRateLimit, "mesh-auth-mutual-listener-rate-limit", 0,
+		"Number of mutual authentication handshakes accepted per source IP and second, 0 to disable")
+	flags.IntVar(&cfg.MutualAuthListenerRateBurst, "mesh-auth-mutual-listener-rate-burst", 0,
+		"Maximum burst of mutual authentication handshakes accepted from a single source IP, 0 to default to the per-second rate")
+	flags.DurationVar(&cfg.MutualAuthListenerBanDuration, "mesh-auth-mutual-listener-ban-duration", 1*time.Minute,
+		"Duration for which a source IP exceeding the handshake rate limit is banned")
+	flags.IntVar(&cfg.MutualAuthListenerMaxConnections, "mesh-auth-mutual-listener-max-connections", 100,
+		"Maximum number of concurrently handled mutual authentication handshake connections, 0 to disable the cap")
+	flags.BoolVar(&cfg.MutualAuthDatapathFilter, "mesh-auth-mutual-listener-datapath-filter", false,
+		"Restrict the mutual authentication listener port to traffic from cluster nodes in the datapath")
+	flags.DurationVar(&cfg.MutualAuthNearExpiryThreshold, "mesh-auth-mutual-near-expiry-threshold", 0,
+		"Remaining local certificate lifetime below which the near-expiry action is taken, 0 to disable")
+	flags.StringVar(&cfg.MutualAuthNearExpiryAction, "mesh-auth-mutual-near-expiry-action", nearExpiryActionWarn,
+		"Action to take when the local certificate is close to expiry (proceed, warn or refuse)")
+	flags.IntVar(&cfg.MutualAuthQuarantineThreshold, "mesh-auth-mutual-quarantine-threshold", 0,
+		"Number of certificate validation failures after which a remote node is quarantined, 0 to disable")
+	flags.DurationVar(&cfg.MutualAuthQuarantineCooldown, "mesh-auth-mutual-quarantine-cooldown", 5*time.Minute,
+		"Duration for which handshakes with a quarantined remote node are refused")
+	flags.IntVar(&cfg.MutualAuthSessionCacheSize, "mesh-auth-mutual-session-cache-size", 0,
+		"Number of remote nodes for which TLS sessions of outbound mutual authentication handshakes are cached for resumption, 0 to disable")
+	flags.DurationVar(&cfg.MutualAuthSessionCacheIdleTimeout, "mesh-auth-mutual-session-cache-idle-timeout", 10*time.Minute,
+		"Duration after which the cached TLS sessions of an unused remote node are dropped")
+	flags.BoolVar(&cfg.MutualAuthSessionTicketsDisabled, "mesh-auth-mutual-session-tickets-disabled", false,
+		"Disable TLS session tickets on the mutual authentication listener, forcing full handshakes")
+	flags.BoolVar(&cfg.MutualAuthProtocolNegotiation, "mesh-auth-mutual-protocol-negotiation", false,
+		"Negotiate the exchange after the mutual authentication handshake over a gRPC service on the established connection, falling back to the plain exchange with peers without support")
+	flags.BoolVar(&cfg.MutualAuthSessionKeys, "mesh-auth-mutual-session-keys", false,
+		"Derive per-identity-pair datapath keying material from the mutual authentication handshakes")
+	flags.StringSliceVar(&cfg.MutualAuthFederatedTrustDomains, "mesh-auth-federated-trust-domains", nil,
+		"Pairs of SPIFFE trust domains ('<trust-domain>=<trust-domain>') between which cross trust domain handshakes are allowed, refusing all other cross trust domain handshakes")
+	flags.BoolVar(&cfg.MutualAuthRevocationCRL, "mesh-auth-mutual-revocation-crl", false,
+		"Check peer certificates of mutual authentication handshakes against the CRL distribution points listed in the certificate")
+	flags.BoolVar(&cfg.MutualAuthRevocationOCSP, "mesh-auth-mutual-revocation-ocsp", false,
+		"Check peer certificates of mutual authentication handshakes against the OCSP responders listed in the certificate, and staple OCSP responses on the listener side")
+	flags.BoolVar(&cfg.MutualAuthRevocationHardFail, "mesh-auth-mutual-revocation-hard-fail", false,
+		"Refuse mutual authentication handshakes when the revocation status of the peer certificate cannot be determined")
+	flags.DurationVar(&cfg.MutualAuthRevocationCacheTTL, "mesh-auth-mutual-revocation-cache-ttl", 1*time.Hour,
+		"Duration for which certificate revocation lookup results are cached")
+	flags.StringSliceVar(&cfg.MutualAuthDialAddressMap, "mesh-auth-mutual-dial-address-map", nil,
+		"Static map of node IPs to the addresses ('<node-ip>=<host:port>') their mutual authentication listener is reachable at, for nodes whose IPs are not directly routable")
+	flags.StringVar(&cfg.MutualAuthTLSMinVersion, "mesh-auth-mutual-tls-min-version", tlsVersion13,
+		"Minimum TLS version of the mutual authentication handshake (1.2 or 1.3)")
+	flags.StringSliceVar(&cfg.MutualAuthTLSCipherSuites, "mesh-auth-mutual-tls-cipher-suites", nil,
+		"TLS 1.2 cipher suites allowed in the mutual authentication handshake by IANA name, empty for the Go defaults (the TLS 1.3 suites are not configurable)")
+	flags.BoolVar(&cfg.MutualAuthFIPSMode, "mesh-auth-mutual-fips-mode", false,
+		"Restrict the mutual authentication handshake to FIPS-approved algorithms and certificates")
+}
+
+// newMutualAuthPortFilter exposes the datapath filter configuration of the
+// mutual auth listener port, consumed by the iptables manager.
+func newMutualAuthPortFilter(cfg MutualAuthConfig) types.MutualAuthPortFilter {
+	if !cfg.MutualAuthDatapathFilter || cfg.MutualAuthListenerPort <= 0 || cfg.MutualAuthListenerPort > 65535 {
+		return types.MutualAuthPortFilter{}
+	}
+	return types.MutualAuthPortFilter{Port: uint16(cfg.MutualAuthListenerPort)}
 }
 
 type mutualAuthHandler struct {
 	cell.In
 
-	cfg MutualAuthConfig
-	log logrus.FieldLogger
+	cfg    MutualAuthConfig
+	log    logrus.FieldLogger
+	health cell.Health
 
 	cert certs.CertificateProvider
 
+	// listenerCtrlMutex serializes listener start, stop and runtime rebind.
+	// started tracks whether onStart ran: settings applied before that are
+	// only recorded, the start hook brings the listener up with them.
+	// listenerStopped is closed once the current listener generation and its
+	// inbound handshakes have fully wound down.
+	listenerCtrlMutex  lock.Mutex
+	started            bool
 	cancelSocketListen context.CancelFunc
+	listenerStopped    chan struct{}
+
+	// Runtime overrides of the listener settings from the dynamic
+	// configuration sources, guarded by listenerSettingsMutex. A port of 0
+	// and a nil disabled flag fall back to the startup configuration.
+	listenerSettingsMutex   lock.RWMutex
+	runtimeListenerPort     int
+	runtimeListenerDisabled *bool
+
+	// connectionsCtx outlives the listener socket during shutdown, so that
+	// in-flight handshakes can be drained before they get cancelled.
+	connectionsCtx    context.Context
+	cancelConnections context.CancelFunc
+	handshakes        sync.WaitGroup
 
 	endpointManager endpointGetter
+
+	nodeIDHandler types.NodeIDHandler
+
+	// remoteIdentities cross-checks the identity a dialing agent claims
+	// against the node its connection originates from, see
+	// verifyInitiatorPlacement. Nil disables the check.
+	remoteIdentities remoteIdentityLookup
+
+	sessionKeys   *sessionKeyStore
+	keyProgrammer types.AuthSessionKeyProgrammer
+	sessionCache  *handshakeSessionCache
+
+	// tlsMinVersion and tlsCipherSuites are the parsed TLS parameters of
+	// the handshake, see MutualAuthTLSMinVersion. A zero tlsMinVersion
+	// falls back to TLS 1.3 only.
+	tlsMinVersion   uint16
+	tlsCipherSuites []uint16
+
+	rateLimiter      *handshakeRateLimiter
+	listenerMetrics  *listenerMetrics
+	skewMetrics      *clockSkewMetrics
+	handshakeMetrics *handshakeMetrics
+	sniFailures      *sniFailureTracker
+	quarantine       *peerQuarantine
+	federation       *trustDomainFederation
+	revocation       *revocationChecker
+	auditor          *authAuditor
+
+	dialer       handshakeDialer
+	listenConfig handshakeListenConfig
+
+	// endpointResolver overrides the address outbound handshakes dial for a
+	// remote node, see authEndpointResolver. Nil dials the node IP directly.
+	endpointResolver authEndpointResolver
+
+	verifier *certs.Verifier
+
+	// serverTLSConfig is the tls.Config served to inbound handshake
+	// connections. It is rebuilt and swapped when the trust bundle rotates,
+	// without restarting the listener socket.
+	serverTLSMutex  lock.Mutex
+	serverTLSConfig *tls.Config
+	serverTLSBundle *x509.CertPool
 }
 
-func (m *mutualAuthHandler) authenticate(ar *authRequest) (*authResponse, error) {
+// Actions taken when the local certificate is close to expiry, see
+// MutualAuthNearExpiryAction.
+const (
+	nearExpiryActionProceed = "proceed"
+	nearExpiryActionWarn    = "warn"
+	nearExpiryActionRefuse  = "refuse"
+)
+
+// checkNearExpiry applies the configured near-expiry action when the local
+// certificate expires within the configured threshold, i.e. certificate
+// rotation has not arrived in time.
+func (m *mutualAuthHandler) checkNearExpiry(notAfter time.Time) error {
+	if m.cfg.MutualAuthNearExpiryThreshold <= 0 {
+		return nil
+	}
+	remaining := time.Until(notAfter)
+	if remaining > m.cfg.MutualAuthNearExpiryThreshold {
+		return nil
+	}
+
+	switch m.cfg.MutualAuthNearExpiryAction {
+	case nearExpiryActionRefuse:
+		return fmt.Errorf("local certificate expires in %s and rotation has not arrived, refusing handshake", remaining)
+	case nearExpiryActionProceed:
+	default:
+		m.log.WithFields(logrus.Fields{
+			"notAfter":  notAfter,
+			"remaining": remaining,
+		}).Warning("Local certificate is close to expiry and rotation has not arrived, resulting auth entries will expire shortly")
+	}
+	return nil
+}
+
+// transportDialer returns the dialer used for outgoing handshake connections,
+// falling back to a plain net.Dialer if none got injected.
+func (m *mutualAuthHandler) transportDialer() handshakeDialer {
+	if m.dialer != nil {
+		return m.dialer
+	}
+	return &net.Dialer{}
+}
+
+// transportListenConfig returns the listen config used for the handshake
+// listener, falling back to a plain net.ListenConfig if none got injected.
+func (m *mutualAuthHandler) transportListenConfig() handshakeListenConfig {
+	if m.listenConfig != nil {
+		return m.listenConfig
+	}
+	return &net.ListenConfig{}
+}
+
+// errHandshakeTimeout marks handshake failures caused by an expired
+// deadline, so callers can tell wedged peers apart from actual handshake
+// failures with errors.Is.
+var errHandshakeTimeout = errors.New("handshake timed out")
+
+// wrapTimeout wraps err with errHandshakeTimeout and counts the timeout if
+// it was caused by an expired deadline.
+func (m *mutualAuthHandler) wrapTimeout(role string, err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		m.handshakeMetrics.observeTimeout(role)
+		return fmt.Errorf("%w: %w", errHandshakeTimeout, err)
+	}
+	return err
+}
+
+// recoverHandlerPanic logs and counts a recovered panic in a handshake code
+// path. It keeps the accept loop and the auth workers alive when malformed
+// input triggers a panic in parsing code.
+func (m *mutualAuthHandler) recoverHandlerPanic(path string, r any) {
+	m.log.WithFields(logrus.Fields{
+		"path":  path,
+		"panic": r,
+		"stack": string(debug.Stack()),
+	}).Error("Recovered from panic in mutual auth handler")
+	if m.listenerMetrics != nil {
+		m.listenerMetrics.RecoveredPanics.Inc()
+	}
+}
+
+func (m *mutualAuthHandler) authenticate(ar *authRequest) (resp *authResponse, err error) {
+	start := time.Now()
+	stage := handshakeStageSetup
+	m.handshakeMetrics.observeAttempt(receiptRoleInitiator)
+	defer func() {
+		m.handshakeMetrics.observeResult(receiptRoleInitiator, stage, start, err != nil)
+	}()
+	record := &authAuditRecord{Timestamp: start, Role: receiptRoleInitiator}
+	defer func() {
+		record.Latency = time.Since(start)
+		if err != nil {
+			record.Result = auditResultFailure
+			record.Error = err.Error()
+		} else {
+			record.Result = auditResultSuccess
+		}
+		m.auditor.record(record)
+	}()
+	defer func() {
+		if r := recover(); r != nil {
+			m.recoverHandlerPanic("authenticate", r)
+			resp, err = nil, fmt.Errorf("panic during mutual auth handshake: %v", r)
+		}
+	}()
+
 	if ar == nil {
 		return nil, errors.New("authRequest is nil")
 	}
-	clientCert, err := m.cert.GetCertificateForIdentity(ar.localIdentity)
+	record.LocalIdentity = ar.localIdentity.Uint32()
+	record.RemoteIdentity = ar.remoteIdentity.Uint32()
+	record.RemoteNodeIP = ar.remoteNodeIP
+	if m.cfg.MutualAuthClientDisabled {
+		return nil, errors.New("client side of the mutual auth handler is disabled")
+	}
+
+	m.handshakes.Add(1)
+	defer m.handshakes.Done()
+
+	ctx, span := startHandshakeSpan(context.Background(), "mutualauth.authenticate",
+		attribute.Int64("auth.local_identity", int64(ar.localIdentity)),
+		attribute.Int64("auth.remote_identity", int64(ar.remoteIdentity)),
+		attribute.String("auth.remote_node_ip", ar.remoteNodeIP),
+		attribute.String("auth.role", receiptRoleInitiator),
+	)
+	defer func() { endHandshakeSpan(span, err) }()
+
+	if m.quarantine != nil && m.quarantine.isQuarantined(ar.remoteNodeIP, time.Now()) {
+		return nil, fmt.Errorf("remote node %s is quarantined after repeated certificate validation failures", ar.remoteNodeIP)
+	}
+
+	clientCert, err := traceProviderCall(ctx, "certprovider.GetCertificateForIdentity", func() (*tls.Certificate, error) {
+		return m.cert.GetCertificateForIdentity(ar.localIdentity)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get certificate for local identity %s: %w", ar.localIdentity.String(), err)
 	}
+	record.LocalCertSerial = clientCert.Leaf.SerialNumber.String()
+	if err := m.checkNearExpiry(clientCert.Leaf.NotAfter); err != nil {
+		return nil, err
+	}
 
-	caBundle, err := m.cert.GetTrustBundle()
+	caBundle, err := traceProviderCall(ctx, "certprovider.GetTrustBundle", m.cert.GetTrustBundle)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get CA bundle: %w", err)
 	}
 
 	// set up TCP connection
-	conn, err := net.DialTimeout("tcp",
-		net.JoinHostPort(ar.remoteNodeIP, strconv.Itoa(m.cfg.MutualAuthListenerPort)),
-		m.cfg.MutualAuthConnectTimeout)
+	stage = handshakeStageDial
+	traceStage(span, handshakeStageDial)
+	dialCtx := ctx
+	if m.cfg.MutualAuthConnectTimeout > 0 {
+		var cancelDial context.CancelFunc
+		dialCtx, cancelDial = context.WithTimeout(dialCtx, m.cfg.MutualAuthConnectTimeout)
+		defer cancelDial()
+	}
+	target := net.JoinHostPort(ar.remoteNodeIP, strconv.Itoa(m.listenerPort()))
+	if m.endpointResolver != nil {
+		if resolved, ok := m.endpointResolver.ResolveAuthEndpoint(ar.remoteNodeIP); ok {
+			m.log.
+				WithField(logfields.IPAddr, ar.remoteNodeIP).
+				WithField(logfields.Address, resolved).
+				Debug("Dialing resolved auth endpoint instead of the node IP")
+			target = resolved
+		}
+	}
+	conn, err := m.transportDialer().DialContext(dialCtx, "tcp", target)
 	if err != nil {
-		return nil, fmt.Errorf("failed to dial %s:%d: %w", ar.remoteNodeIP, m.cfg.MutualAuthListenerPort, err)
+		return nil, fmt.Errorf("failed to dial %s: %w", target, m.wrapTimeout(receiptRoleInitiator, err))
 	}
 	defer conn.Close()
 
@@ -114,12 +696,13 @@ func (m *mutualAuthHandler) authenticate(ar *authRequest) (*authResponse, error)
 	// set up TLS socket
 
 	//nolint:gosec // InsecureSkipVerify is not insecure as we do the verification in VerifyPeerCertificate
-	tlsConn := tls.Client(conn, &tls.Config{
+	tlsConfig := &tls.Config{
 		ServerName: m.cert.NumericIdentityToSNI(ar.remoteIdentity),
 		GetClientCertificate: func(info *tls.CertificateRequestInfo) (*tls.Certificate, error) {
 			return clientCert, nil
 		},
-		MinVersion:         tls.VersionTLS13,
+		MinVersion:         m.tlsConfigMinVersion(),
+		CipherSuites:       m.tlsCipherSuites,
 		InsecureSkipVerify: true, // not insecure as we do the verification in VerifyPeerCertificate
 		VerifyPeerCertificate: func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
 			// verifiedChains will be nil as we set InsecureSkipVerify to true
@@ -137,20 +720,120 @@ func (m *mutualAuthHandler) authenticate(ar *authRequest) (*authResponse, error)
 			if peerExpirationTime != nil && peerExpirationTime.Before(*expirationTime) {
 				expirationTime = peerExpirationTime // send down the lowest expiration time of the two certificates
 			}
-			return err
+			if err != nil {
+				return err
+			}
+			if m.federation != nil && len(chain) > 0 {
+				if err := m.federation.check(clientCert.Leaf, chain[0]); err != nil {
+					return fmt.Errorf("refusing cross trust domain handshake: %w", err)
+				}
+			}
+			return nil
 		},
 		ClientCAs: caBundle,
 		RootCAs:   caBundle,
-	})
+	}
+	if m.cfg.MutualAuthProtocolNegotiation {
+		tlsConfig.NextProtos = []string{authProtocolALPN}
+	}
+	if m.cfg.MutualAuthFIPSMode {
+		tlsConfig.CurvePreferences = fipsCurvePreferences
+	}
+	if m.sessionCache != nil {
+		tlsConfig.ClientSessionCache = m.sessionCache.sessionsFor(ar.remoteNodeIP, caBundle, time.Now())
+	}
+	record.SNI = tlsConfig.ServerName
+	tlsConn := tls.Client(conn, tlsConfig)
 	defer tlsConn.Close()
 
+	// Bound the TLS handshake itself, so a peer which accepted the TCP
+	// connection but stopped responding cannot stall the auth request
+	// indefinitely.
+	stage = handshakeStageHandshake
+	traceStage(span, handshakeStageHandshake)
+	if m.cfg.MutualAuthHandshakeTimeout > 0 {
+		conn.SetDeadline(time.Now().Add(m.cfg.MutualAuthHandshakeTimeout))
+	}
 	if err := tlsConn.Handshake(); err != nil {
-		return nil, fmt.Errorf("failed to perform TLS handshake: %w", err)
+		m.reportPossibleClockSkew(err)
+		if m.quarantine != nil && isCertValidationError(err) {
+			m.quarantine.recordFailure(ar.remoteNodeIP, time.Now())
+		}
+		return nil, fmt.Errorf("failed to perform TLS handshake: %w", m.wrapTimeout(receiptRoleInitiator, err))
+	}
+	if m.cfg.MutualAuthHandshakeTimeout > 0 {
+		conn.SetDeadline(time.Time{})
+	}
+	if m.quarantine != nil {
+		m.quarantine.recordSuccess(ar.remoteNodeIP)
+	}
+	if state := tlsConn.ConnectionState(); len(state.PeerCertificates) > 0 {
+		record.PeerCertSerial = state.PeerCertificates[0].SerialNumber.String()
+	}
+	if m.revocation != nil {
+		state := tlsConn.ConnectionState()
+		if err := m.revocation.checkPeer(state.PeerCertificates, state.OCSPResponse); err != nil {
+			return nil, fmt.Errorf("refusing handshake with revoked peer certificate: %w", err)
+		}
+	}
+
+	// A resumed session reuses the certificates verified during the original
+	// full handshake for the same SNI; VerifyPeerCertificate is not invoked
+	// again. The peer certificate expiration still has to be folded into the
+	// auth entry expiration.
+	if state := tlsConn.ConnectionState(); state.DidResume {
+		m.handshakeMetrics.observeResumption(receiptRoleInitiator)
+		if len(state.PeerCertificates) > 0 {
+			if peerNotAfter := state.PeerCertificates[0].NotAfter; peerNotAfter.Before(*expirationTime) {
+				expirationTime = &peerNotAfter
+			}
+		}
 	}
 
+	// Bind the handshake result to a fresh nonce and the identity pair
+	// being authorized.
+	stage = handshakeStageBinding
+	traceStage(span, handshakeStageBinding)
+	if m.cfg.MutualAuthConnectTimeout > 0 {
+		conn.SetDeadline(time.Now().Add(m.cfg.MutualAuthConnectTimeout))
+	}
+	sessionKeysNegotiated := true
+	if negotiatedAuthProtocol(tlsConn) {
+		negCtx := ctx
+		if m.cfg.MutualAuthConnectTimeout > 0 {
+			var cancelNeg context.CancelFunc
+			negCtx, cancelNeg = context.WithTimeout(negCtx, m.cfg.MutualAuthConnectTimeout)
+			defer cancelNeg()
+		}
+		result, err := m.negotiateAuthProtocol(negCtx, tlsConn, ar)
+		if err != nil {
+			return nil, fmt.Errorf("failed to authenticate over the negotiated auth protocol: %w", err)
+		}
+		if expirationTime != nil {
+			bounded := boundNegotiatedExpiration(*expirationTime, result.ExpirationUnix)
+			expirationTime = &bounded
+		}
+		sessionKeysNegotiated = slices.Contains(result.Extensions, authExtensionSessionKeys)
+	} else if err := m.sendBindingRequest(tlsConn, ar); err != nil {
+		return nil, fmt.Errorf("failed to bind handshake result: %w", err)
+	}
+
+	m.emitHandshakeReceipt(tlsConn, receiptRoleInitiator, ar.localIdentity, ar.remoteIdentity)
+
 	if expirationTime == nil {
 		return nil, fmt.Errorf("failed to get expiration time of peer certificate")
 	}
+	record.Expiration = *expirationTime
+
+	// On the negotiated auth protocol, key derivation is an extension which
+	// only takes effect if both peers announced it.
+	if m.sessionKeys != nil && sessionKeysNegotiated {
+		if err := m.sessionKeys.derive(tlsConn, receiptRoleInitiator, ar.localIdentity, ar.remoteIdentity, *expirationTime); err != nil {
+			// The authentication result stands, only the optional key
+			// material derivation failed.
+			m.log.WithError(err).Warning("Failed to derive datapath session key")
+		}
+	}
 
 	return &authResponse{
 		expirationTime: *expirationTime,
@@ -161,24 +844,212 @@ func (m *mutualAuthHandler) authType() policy.AuthType {
 	return policy.AuthTypeSpire
 }
 
-func (m *mutualAuthHandler) listenForConnections(upstreamCtx context.Context, ready chan<- struct{}) {
-	// set up TCP listener
+// listenRetryInterval is the interval in which a failed listener socket
+// setup is retried.
+const listenRetryInterval = 10 * time.Second
+
+// Address families of the listener socket, see
+// MutualAuthListenerAddressFamily.
+const (
+	addressFamilyDual     = "dual"
+	addressFamilyIPv4     = "ipv4"
+	addressFamilyIPv6     = "ipv6"
+	addressFamilySeparate = "separate"
+)
+
+// listenerSpec describes one listener socket to run.
+type listenerSpec struct {
+	network string
+	address string
+}
+
+// listenerPort returns the port of the mutual auth listener, which may be
+// changed at runtime through the dynamic configuration sources. The client
+// side dials remote listeners on the same port, assuming a mesh-wide
+// consistent setting.
+func (m *mutualAuthHandler) listenerPort() int {
+	m.listenerSettingsMutex.RLock()
+	defer m.listenerSettingsMutex.RUnlock()
+	if m.runtimeListenerPort != 0 {
+		return m.runtimeListenerPort
+	}
+	return m.cfg.MutualAuthListenerPort
+}
+
+// listenerDisabled returns whether the listener side is disabled, which may
+// be changed at runtime through the dynamic configuration sources.
+func (m *mutualAuthHandler) listenerDisabled() bool {
+	m.listenerSettingsMutex.RLock()
+	defer m.listenerSettingsMutex.RUnlock()
+	if m.runtimeListenerDisabled != nil {
+		return *m.runtimeListenerDisabled
+	}
+	return m.cfg.MutualAuthListenerDisabled
+}
+
+func (m *mutualAuthHandler) setListenerSettings(port int, disabled bool) {
+	m.listenerSettingsMutex.Lock()
+	defer m.listenerSettingsMutex.Unlock()
+	m.runtimeListenerPort = port
+	m.runtimeListenerDisabled = &disabled
+}
+
+// applyListenerSettings applies runtime listener settings from the dynamic
+// configuration sources: the listener can be disabled, re-enabled or rebound
+// to a new port without an agent restart. The current listener generation is
+// stopped first and its in-flight handshakes are drained (bounded by
+// handshakeDrainTimeout) before the new one is brought up.
+func (m *mutualAuthHandler) applyListenerSettings(port int, disabled bool) {
+	m.listenerCtrlMutex.Lock()
+	defer m.listenerCtrlMutex.Unlock()
+
+	if port <= 0 || port > 65535 {
+		m.log.WithField(logfields.Port, port).Warning("Ignoring invalid runtime mutual auth listener port")
+		return
+	}
+	if port == m.listenerPort() && disabled == m.listenerDisabled() {
+		return
+	}
+
+	if !m.started {
+		m.setListenerSettings(port, disabled)
+		return
+	}
+
+	m.log.WithField(logfields.Port, port).Info("Applying runtime mutual auth listener settings")
+
+	if m.cancelSocketListen != nil {
+		m.cancelSocketListen()
+		m.cancelSocketListen = nil
+		select {
+		case <-m.listenerStopped:
+		case <-time.After(handshakeDrainTimeout):
+			m.log.WithField(logfields.Duration, handshakeDrainTimeout).
+				Warning("Inbound handshakes still in flight after drain timeout, rebinding listener anyway")
+		}
+		m.listenerStopped = nil
+	}
+
+	m.setListenerSettings(port, disabled)
+
+	if disabled {
+		m.log.Info("Listener side of the mutual auth handler is disabled")
+		return
+	}
+	m.startListener()
+}
+
+// startListener starts a listener generation and waits for its first listen
+// attempt. Callers must hold listenerCtrlMutex.
+func (m *mutualAuthHandler) startListener() {
+	listenCtx, cancel := context.WithCancel(context.Background())
+	m.cancelSocketListen = cancel
+	m.listenerStopped = make(chan struct{})
+
+	ready := make(chan struct{})
+	go m.listenForConnections(listenCtx, ready, m.listenerStopped)
+	<-ready // wait for the socket to be ready
+}
+
+// listenerSpecs returns the listener sockets to run, derived from the
+// configured bind address and address family.
+func (m *mutualAuthHandler) listenerSpecs() []listenerSpec {
+	addr := net.JoinHostPort(m.cfg.MutualAuthListenerAddress, strconv.Itoa(m.listenerPort()))
+	switch m.cfg.MutualAuthListenerAddressFamily {
+	case addressFamilyIPv4:
+		return []listenerSpec{{network: "tcp4", address: addr}}
+	case addressFamilyIPv6:
+		return []listenerSpec{{network: "tcp6", address: addr}}
+	case addressFamilySeparate:
+		return []listenerSpec{
+			{network: "tcp4", address: addr},
+			{network: "tcp6", address: addr},
+		}
+	default:
+		return []listenerSpec{{network: "tcp", address: addr}}
+	}
+}
+
+func (m *mutualAuthHandler) listenForConnections(upstreamCtx context.Context, ready chan<- struct{}, stopped chan<- struct{}) {
+	defer close(stopped)
 
 	ctx, cancel := context.WithCancel(upstreamCtx)
 	defer cancel()
 
-	var lc net.ListenConfig
-	l, err := lc.Listen(ctx, "tcp", fmt.Sprintf(":%d", m.cfg.MutualAuthListenerPort))
-	if err != nil {
-		m.log.WithError(err).Fatal("Failed to start mutual auth listener")
+	// connSlots bounds the number of live handleConnection goroutines across
+	// all listener sockets, so a burst of connections cannot push the agent
+	// into resource exhaustion.
+	var connSlots chan struct{}
+	if m.cfg.MutualAuthListenerMaxConnections > 0 {
+		connSlots = make(chan struct{}, m.cfg.MutualAuthListenerMaxConnections)
+	}
+
+	// Signal readiness to hive once every listener socket made its first
+	// listen attempt, successful or not, so that a failing socket setup
+	// degrades this module instead of blocking agent startup. Failed socket
+	// setups are retried periodically.
+	var conns sync.WaitGroup
+	var firstAttempts, listeners sync.WaitGroup
+	for _, spec := range m.listenerSpecs() {
+		spec := spec
+		firstAttempts.Add(1)
+		listeners.Add(1)
+		go func() {
+			defer listeners.Done()
+			m.runListener(ctx, spec, connSlots, &conns, firstAttempts.Done)
+		}()
+	}
+	firstAttempts.Wait()
+	ready <- struct{}{}
+	listeners.Wait()
+	// Wait for the inbound handshakes accepted by this listener generation,
+	// so a runtime rebind can drain them before starting the next one.
+	conns.Wait()
+}
+
+// runListener runs one listener socket, retrying a failing socket setup
+// periodically. firstAttempt is called once after the first listen attempt,
+// successful or not.
+func (m *mutualAuthHandler) runListener(ctx context.Context, spec listenerSpec, connSlots chan struct{}, conns *sync.WaitGroup, firstAttempt func()) {
+	signaledAttempt := false
+	signalAttempt := func() {
+		if !signaledAttempt {
+			signaledAttempt = true
+			firstAttempt()
+		}
+	}
+
+	var l net.Listener
+	for {
+		var err error
+		l, err = m.transportListenConfig().Listen(ctx, spec.network, spec.address)
+		if err == nil {
+			break
+		}
+
+		m.log.WithError(err).WithField(logfields.Address, spec.address).
+			Error("Failed to start mutual auth listener, retrying")
+		if m.health != nil {
+			m.health.Degraded("Mutual auth listener is not listening", err)
+		}
+		signalAttempt()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetryInterval):
+		}
 	}
 	go func() { // shutdown socket goroutine
 		<-ctx.Done()
 		l.Close()
 	}()
 
-	m.log.WithField(logfields.Port, m.cfg.MutualAuthListenerPort).Info("Started mutual auth listener")
-	ready <- struct{}{} // signal to hive that we are ready to accept connections
+	m.log.WithField(logfields.Address, l.Addr().String()).Info("Started mutual auth listener")
+	if m.health != nil {
+		m.health.OK("Listening for mutual auth handshakes")
+	}
+	signalAttempt() // signal to hive that we are ready to accept connections
 
 	for {
 		conn, err := l.Accept()
@@ -190,36 +1061,307 @@ func (m *mutualAuthHandler) listenForConnections(upstreamCtx context.Context, re
 			}
 			continue
 		}
-		go m.handleConnection(ctx, conn)
+		if m.rateLimiter != nil && !m.rateLimiter.allow(conn.RemoteAddr(), time.Now()) {
+			conn.Close()
+			continue
+		}
+		if m.quarantine != nil && m.quarantine.isQuarantined(remoteIPOf(conn), time.Now()) {
+			m.log.WithField(logfields.IPAddr, conn.RemoteAddr().String()).
+				Warning("Refusing handshake connection from quarantined node")
+			conn.Close()
+			continue
+		}
+		if m.cfg.MutualAuthValidateSourceIP && !m.isKnownNodeConnection(conn) {
+			m.log.WithField(logfields.IPAddr, conn.RemoteAddr().String()).
+				Warning("Rejecting handshake connection from unknown source")
+			conn.Close()
+			continue
+		}
+		if connSlots != nil {
+			select {
+			case connSlots <- struct{}{}:
+			default:
+				m.log.WithField(logfields.IPAddr, conn.RemoteAddr().String()).
+					Warning("Rejecting handshake connection, concurrent connection limit reached")
+				if m.listenerMetrics != nil {
+					m.listenerMetrics.ConcurrencyLimitedConnections.Inc()
+				}
+				conn.Close()
+				continue
+			}
+		}
+		m.handshakes.Add(1)
+		conns.Add(1)
+		go func() {
+			defer m.handshakes.Done()
+			defer conns.Done()
+			if connSlots != nil {
+				defer func() { <-connSlots }()
+			}
+			defer func() {
+				if r := recover(); r != nil {
+					m.recoverHandlerPanic("handleConnection", r)
+				}
+			}()
+			m.handleConnection(m.connectionsCtx, conn)
+		}()
+	}
+}
+
+// remoteIPOf returns the remote IP of the given connection, or an empty
+// string for non-TCP transports.
+func remoteIPOf(conn net.Conn) string {
+	if tcpAddr, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
+		return tcpAddr.IP.String()
+	}
+	return ""
+}
+
+// isKnownNodeConnection returns true if the given connection originates from
+// a known cluster node. Loopback sources are always accepted, as handshakes
+// from the node itself never pass through the node ID map.
+func (m *mutualAuthHandler) isKnownNodeConnection(conn net.Conn) bool {
+	tcpAddr, ok := conn.RemoteAddr().(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	if tcpAddr.IP.IsLoopback() {
+		return true
+	}
+	if m.nodeIDHandler == nil {
+		return false
+	}
+	_, exists := m.nodeIDHandler.GetNodeID(tcpAddr.IP)
+	return exists
+}
+
+// verifyInitiatorPlacement cross-checks the identity claimed by a dialing
+// agent against the ipcache: an endpoint carrying the claimed identity must
+// be hosted on the node the connection originates from. A compromised node
+// presenting a valid certificate for an identity none of its endpoints hold
+// is rejected, even though the certificate itself verifies.
+func (m *mutualAuthHandler) verifyInitiatorPlacement(claimed identity.NumericIdentity, remoteNodeIP string) error {
+	if m.remoteIdentities == nil {
+		return nil
+	}
+	ip := net.ParseIP(remoteNodeIP)
+	if ip == nil || ip.IsLoopback() {
+		// Handshakes from the node itself do not pass through the ipcache
+		// host mapping.
+		return nil
+	}
+
+	endpointIPs := m.remoteIdentities.LookupByIdentity(claimed)
+	if len(endpointIPs) == 0 {
+		return fmt.Errorf("claimed identity %s is unknown to the ipcache", claimed.String())
+	}
+	for _, endpointIP := range endpointIPs {
+		if hostIP, _ := m.remoteIdentities.GetHostIPCache(endpointIP); hostIP != nil && hostIP.Equal(ip) {
+			return nil
+		}
+	}
+	return fmt.Errorf("claimed identity %s is not hosted on node %s", claimed.String(), remoteNodeIP)
+}
+
+// tlsConfigMinVersion returns the configured minimum TLS version of the
+// handshake, defaulting to TLS 1.3 only.
+func (m *mutualAuthHandler) tlsConfigMinVersion() uint16 {
+	if m.tlsMinVersion == 0 {
+		return tls.VersionTLS13
+	}
+	return m.tlsMinVersion
+}
+
+// serverTLSConfigForConnection returns the tls.Config to serve an inbound
+// handshake connection with. The config is cached and only rebuilt when the
+// trust bundle rotated since the last connection, so a rotation takes effect
+// for new connections without dropping the listener socket or in-flight
+// handshakes, which keep using the config they started with.
+func (m *mutualAuthHandler) serverTLSConfigForConnection() (*tls.Config, error) {
+	caBundle, err := m.cert.GetTrustBundle()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CA bundle: %w", err)
+	}
+
+	m.serverTLSMutex.Lock()
+	defer m.serverTLSMutex.Unlock()
+
+	if m.serverTLSConfig == nil || m.serverTLSBundle != caBundle {
+		if m.serverTLSConfig != nil {
+			m.log.Debug("Trust bundle rotated, swapping listener TLS config")
+		}
+		tlsConfig := &tls.Config{
+			ClientAuth:     tls.RequireAndVerifyClientCert,
+			GetCertificate: m.GetCertificateForIncomingConnection,
+			MinVersion:     m.tlsConfigMinVersion(),
+			CipherSuites:   m.tlsCipherSuites,
+			ClientCAs:      caBundle,
+			// With tickets disabled, peers always perform a full
+			// certificate exchange.
+			SessionTicketsDisabled: m.cfg.MutualAuthSessionTicketsDisabled,
+		}
+		if m.cfg.MutualAuthProtocolNegotiation {
+			tlsConfig.NextProtos = []string{authProtocolALPN}
+		}
+		if m.cfg.MutualAuthFIPSMode {
+			tlsConfig.CurvePreferences = fipsCurvePreferences
+		}
+		m.serverTLSConfig = tlsConfig
+		m.serverTLSBundle = caBundle
 	}
+
+	return m.serverTLSConfig, nil
 }
 
 func (m *mutualAuthHandler) handleConnection(ctx context.Context, conn net.Conn) {
 	defer conn.Close()
 
-	caBundle, err := m.cert.GetTrustBundle()
+	start := time.Now()
+	stage := handshakeStageSetup
+	failed := true
+	m.handshakeMetrics.observeAttempt(receiptRoleListener)
+	defer func() {
+		m.handshakeMetrics.observeResult(receiptRoleListener, stage, start, failed)
+	}()
+	record := &authAuditRecord{Timestamp: start, Role: receiptRoleListener, RemoteNodeIP: remoteIPOf(conn)}
+	defer func() {
+		record.Latency = time.Since(start)
+		if failed {
+			record.Result = auditResultFailure
+		} else {
+			record.Result = auditResultSuccess
+		}
+		m.auditor.record(record)
+	}()
+
+	ctx, span := startHandshakeSpan(ctx, "mutualauth.handleConnection",
+		attribute.String("auth.remote_node_ip", remoteIPOf(conn)),
+		attribute.String("auth.role", receiptRoleListener),
+	)
+	defer func() {
+		var spanErr error
+		if failed {
+			if record.Error != "" {
+				spanErr = errors.New(record.Error)
+			} else {
+				spanErr = errors.New("handshake failed")
+			}
+		}
+		endHandshakeSpan(span, spanErr)
+	}()
+
+	if m.cfg.MutualAuthHandshakeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.cfg.MutualAuthHandshakeTimeout)
+		defer cancel()
+	}
+
+	tlsConfig, err := m.serverTLSConfigForConnection()
 	if err != nil {
-		m.log.WithError(err).Error("failed to get CA bundle")
+		record.Error = err.Error()
+		m.log.WithError(err).Error("failed to get listener TLS config")
 		return
 	}
 
-	tlsConn := tls.Server(conn, &tls.Config{
-		ClientAuth:     tls.RequireAndVerifyClientCert,
-		GetCertificate: m.GetCertificateForIncomingConnection,
-		MinVersion:     tls.VersionTLS13,
-		ClientCAs:      caBundle,
-	})
+	tlsConn := tls.Server(conn, tlsConfig)
 	defer tlsConn.Close()
 
+	stage = handshakeStageHandshake
+	traceStage(span, handshakeStageHandshake)
 	if err := tlsConn.HandshakeContext(ctx); err != nil {
-		m.log.WithError(err).Error("failed to perform TLS handshake")
+		record.Error = err.Error()
+		m.reportPossibleClockSkew(err)
+		if m.quarantine != nil && isCertValidationError(err) {
+			m.quarantine.recordFailure(remoteIPOf(conn), time.Now())
+		}
+		m.log.WithError(m.wrapTimeout(receiptRoleListener, err)).Error("failed to perform TLS handshake")
+		return
+	}
+	if m.quarantine != nil {
+		m.quarantine.recordSuccess(remoteIPOf(conn))
+	}
+	if tlsConn.ConnectionState().DidResume {
+		m.handshakeMetrics.observeResumption(receiptRoleListener)
+	}
+	if state := tlsConn.ConnectionState(); len(state.PeerCertificates) > 0 {
+		record.SNI = state.ServerName
+		record.PeerCertSerial = state.PeerCertificates[0].SerialNumber.String()
+		record.Expiration = state.PeerCertificates[0].NotAfter
+	}
+
+	if m.revocation != nil {
+		if err := m.revocation.checkPeer(tlsConn.ConnectionState().PeerCertificates, nil); err != nil {
+			record.Error = err.Error()
+			m.log.WithError(err).Warning("Refusing handshake with revoked peer certificate")
+			return
+		}
+	}
+
+	if m.federation != nil {
+		if err := m.checkListenerFederation(tlsConn); err != nil {
+			record.Error = err.Error()
+			m.log.WithError(err).Warning("Refusing cross trust domain handshake")
+			return
+		}
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stage = handshakeStageBinding
+	traceStage(span, handshakeStageBinding)
+	var req *bindingRequest
+	sessionKeysNegotiated := true
+	if negotiatedAuthProtocol(tlsConn) {
+		var extensions []string
+		req, extensions, err = m.serveAuthProtocol(ctx, tlsConn)
+		if err != nil {
+			record.Error = err.Error()
+			m.log.WithError(err).Error("failed to serve the negotiated auth protocol")
+			return
+		}
+		sessionKeysNegotiated = slices.Contains(extensions, authExtensionSessionKeys)
+	} else if req, err = m.answerBindingRequest(tlsConn); err != nil {
+		record.Error = err.Error()
+		m.log.WithError(err).Error("failed to verify handshake binding")
+		return
+	}
+
+	failed = false
+	record.LocalIdentity = req.RemoteIdentity
+	record.RemoteIdentity = req.LocalIdentity
+
+	// From the listener's perspective, the local identity is the one the
+	// served certificate was selected for.
+	m.emitHandshakeReceipt(tlsConn, receiptRoleListener,
+		identity.NumericIdentity(req.RemoteIdentity), identity.NumericIdentity(req.LocalIdentity))
+
+	// On the negotiated auth protocol, key derivation is an extension which
+	// only takes effect if both peers announced it.
+	if m.sessionKeys != nil && sessionKeysNegotiated {
+		// The peer certificate is guaranteed to be present, the binding
+		// request has been validated against it.
+		expiration := tlsConn.ConnectionState().PeerCertificates[0].NotAfter
+		if err := m.sessionKeys.derive(tlsConn, receiptRoleListener,
+			identity.NumericIdentity(req.RemoteIdentity), identity.NumericIdentity(req.LocalIdentity), expiration); err != nil {
+			m.log.WithError(err).Warning("Failed to derive datapath session key")
+		}
 	}
 }
 
 func (m *mutualAuthHandler) GetCertificateForIncomingConnection(info *tls.ClientHelloInfo) (*tls.Certificate, error) {
 	m.log.WithField("SNI", info.ServerName).Debug("Got new TLS connection")
+	if err := validateSNIFormat(info.ServerName); err != nil {
+		if m.sniFailures != nil {
+			m.sniFailures.recordFailure(info.ServerName, time.Now())
+		}
+		return nil, fmt.Errorf("malformed SNI: %w", err)
+	}
 	id, err := m.cert.SNIToNumericIdentity(info.ServerName)
 	if err != nil {
+		if m.sniFailures != nil {
+			m.sniFailures.recordFailure(info.ServerName, time.Now())
+		}
 		return nil, fmt.Errorf("failed to get identity for SNI %s: %w", info.ServerName, err)
 	}
 
@@ -240,27 +1382,138 @@ func (m *mutualAuthHandler) GetCertificateForIncomingConnection(info *tls.Client
 		return nil, fmt.Errorf("no local endpoint present for identity %s", id.String())
 	}
 
-	return m.cert.GetCertificateForIdentity(id)
+	cert, err := m.cert.GetCertificateForIdentity(id)
+	if err != nil {
+		return nil, err
+	}
+	if cert.Leaf != nil {
+		if err := m.checkNearExpiry(cert.Leaf.NotAfter); err != nil {
+			return nil, err
+		}
+	}
+	// With OCSP enabled, staple a response for the served certificate so the
+	// peer can check its revocation status without its own lookup.
+	return m.revocation.stapleCertificate(cert), nil
 }
 
 func (m *mutualAuthHandler) onStart(ctx cell.HookContext) error {
 	m.log.Info("Starting mutual auth handler")
 
-	listenCtx, cancel := context.WithCancel(context.Background())
-	m.cancelSocketListen = cancel
+	if m.cfg.MutualAuthSessionKeys && m.sessionKeys == nil {
+		m.sessionKeys = newSessionKeyStore(m.log, m.keyProgrammer)
+	}
 
-	ready := make(chan struct{})
-	go m.listenForConnections(listenCtx, ready)
-	<-ready // wait for the socket to be ready
+	m.verifier = certs.NewVerifier(certs.VerifyConfig{
+		StrictSAN: true,
+		FIPS:      m.cfg.MutualAuthFIPSMode,
+	})
+	if m.cfg.MutualAuthFIPSMode {
+		if boringCryptoEnabled {
+			m.log.Info("Mutual auth handshake restricted to FIPS-approved algorithms, backed by BoringCrypto")
+		} else {
+			m.log.Warning("Mutual auth handshake restricted to FIPS-approved algorithms, but this binary is not built with BoringCrypto")
+		}
+		if m.health != nil {
+			// Surface the FIPS posture in the module health, shown by
+			// "cilium-dbg status --all-health".
+			fipsHealth := m.health.NewScope("fips")
+			if boringCryptoEnabled {
+				fipsHealth.OK("FIPS mode enforced, built with BoringCrypto")
+			} else {
+				fipsHealth.Degraded("FIPS mode enforced without a FIPS-validated crypto module",
+					errors.New("binary is not built with BoringCrypto"))
+			}
+		}
+	}
+
+	m.connectionsCtx, m.cancelConnections = context.WithCancel(context.Background())
+
+	m.listenerCtrlMutex.Lock()
+	defer m.listenerCtrlMutex.Unlock()
+	m.started = true
+
+	if m.listenerDisabled() {
+		m.log.Info("Listener side of the mutual auth handler is disabled")
+		return nil
+	}
+
+	m.startListener()
 	return nil
 }
 
+// handshakeDrainTimeout bounds how long the handler waits for in-flight
+// handshakes to complete on shutdown.
+const handshakeDrainTimeout = 10 * time.Second
+
 func (m *mutualAuthHandler) onStop(ctx cell.HookContext) error {
 	m.log.Info("Stopping mutual auth handler")
-	m.cancelSocketListen()
+
+	// Stop accepting new connections first, then wait (bounded) for the
+	// in-flight inbound and outbound handshakes, so that authentications
+	// which are about to complete still make it into the auth map instead
+	// of being abandoned half-finished.
+	m.listenerCtrlMutex.Lock()
+	m.started = false
+	if m.cancelSocketListen != nil {
+		m.cancelSocketListen()
+		m.cancelSocketListen = nil
+	}
+	m.listenerCtrlMutex.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		m.handshakes.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-time.After(handshakeDrainTimeout):
+		m.log.WithField(logfields.Duration, handshakeDrainTimeout).
+			Warning("Handshakes still in flight after drain timeout, cancelling them")
+	case <-ctx.Done():
+	}
+
+	if m.cancelConnections != nil {
+		m.cancelConnections()
+	}
 	return nil
 }
 
+// peerChainVerifier returns the verifier for the certificate chains
+// presented during the mutual auth handshake. SPIFFE SVIDs must carry
+// exactly one URI SAN; in FIPS mode non-approved algorithms are rejected
+// on top of that.
+func (m *mutualAuthHandler) peerChainVerifier() *certs.Verifier {
+	if m.verifier != nil {
+		return m.verifier
+	}
+	return certs.NewVerifier(certs.VerifyConfig{
+		StrictSAN: true,
+		FIPS:      m.cfg.MutualAuthFIPSMode,
+	})
+}
+
+// peerTrustBundle returns the CA pool the given peer chain is verified
+// against. With a certificate provider tracking per-trust-domain pools, the
+// chain is verified against the bundle of the trust domain its leaf claims,
+// so the CA of one federated trust domain cannot issue identities of
+// another. Other providers keep using the merged bundle.
+func (m *mutualAuthHandler) peerTrustBundle(caBundle *x509.CertPool, chain []*x509.Certificate) (*x509.CertPool, error) {
+	federated, ok := m.cert.(certs.FederatedTrustProvider)
+	if !ok || len(chain) == 0 {
+		return caBundle, nil
+	}
+	peerTD, err := certTrustDomain(chain[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trust domain of peer certificate: %w", err)
+	}
+	bundle, err := federated.GetTrustBundleForDomain(peerTD)
+	if err != nil {
+		return nil, fmt.Errorf("no trust bundle for trust domain %q: %w", peerTD, err)
+	}
+	return bundle, nil
+}
+
 // verifyPeerCertificate is used for Go's TLS library to verify certificates
 func (m *mutualAuthHandler) verifyPeerCertificate(id *identity.NumericIdentity, caBundle *x509.CertPool, certChains [][]*x509.Certificate) (*time.Time, error) {
 	if len(certChains) == 0 {
@@ -270,24 +1523,13 @@ func (m *mutualAuthHandler) verifyPeerCertificate(id *identity.NumericIdentity,
 	var expirationTime *time.Time
 
 	for _, chain := range certChains {
-		opts := x509.VerifyOptions{
-			Roots:         caBundle,
-			Intermediates: x509.NewCertPool(),
-		}
-
-		var leaf *x509.Certificate
-		for _, cert := range chain {
-			if cert.IsCA {
-				opts.Intermediates.AddCert(cert)
-			} else {
-				leaf = cert
-			}
-		}
-		if leaf == nil {
-			return nil, fmt.Errorf("no leaf certificate found")
+		bundle, err := m.peerTrustBundle(caBundle, chain)
+		if err != nil {
+			return nil, err
 		}
-		if _, err := leaf.Verify(opts); err != nil {
-			return nil, fmt.Errorf("failed to verify certificate: %w", err)
+		leaf, err := m.peerChainVerifier().VerifyChain(bundle, chain)
+		if err != nil {
+			return nil, err
 		}
 
 		if id != nil { // this will be empty in the peer connection
@@ -314,3 +1556,18 @@ func (m *mutualAuthHandler) subscribeToRotatedIdentities() <-chan certs.Certific
 func (m *mutualAuthHandler) certProviderStatus() *models.Status {
 	return m.cert.Status()
 }
+
+// resolvePeerSpiffeID returns the SPIFFE ID encoding of the given identity,
+// surfaced in the auth metadata of L7 access logs.
+func (m *mutualAuthHandler) resolvePeerSpiffeID(id identity.NumericIdentity) string {
+	return m.cert.NumericIdentityToSPIFFEID(id)
+}
+
+// datapathSessionKey returns the keying material derived from the most
+// recent handshake with the given identity pair.
+func (m *mutualAuthHandler) datapathSessionKey(localID, remoteID identity.NumericIdentity) ([]byte, bool) {
+	if m.sessionKeys == nil {
+		return nil, false
+	}
+	return m.sessionKeys.get(localID, remoteID)
+}
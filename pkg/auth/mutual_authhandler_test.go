@@ -11,10 +11,12 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"errors"
 	"fmt"
 	"math/big"
 	"net"
 	"net/url"
+	"os"
 	"reflect"
 	"strings"
 	"testing"
@@ -90,6 +92,10 @@ func (f *fakeCertificateProvider) NumericIdentityToSNI(id identity.NumericIdenti
 	return id.String() + "." + "spiffe.cilium"
 }
 
+func (f *fakeCertificateProvider) NumericIdentityToSPIFFEID(id identity.NumericIdentity) string {
+	return "spiffe://spiffe.cilium/identity/" + id.String()
+}
+
 func (f *fakeCertificateProvider) SNIToNumericIdentity(sni string) (identity.NumericIdentity, error) {
 	suffix := "." + "spiffe.cilium"
 	if !strings.HasSuffix(sni, suffix) {
@@ -439,6 +445,56 @@ func Test_mutualAuthHandler_authenticate(t *testing.T) {
 	}
 }
 
+func Test_mutualAuthHandler_applyListenerSettings(t *testing.T) {
+	certMap, keyMap, caPool := generateTestCertificates(t)
+
+	mAuthHandler := &mutualAuthHandler{
+		cfg:             MutualAuthConfig{MutualAuthListenerPort: getRandomOpenPort(t)},
+		log:             logrus.New(),
+		cert:            &fakeCertificateProvider{certMap: certMap, caPool: caPool, privkeyMap: keyMap},
+		endpointManager: &fakeEndpointGetter{},
+	}
+	mAuthHandler.onStart(context.Background())
+	defer mAuthHandler.onStop(context.Background())
+
+	ar := &authRequest{
+		localIdentity:  id1000,
+		remoteIdentity: id1001,
+		remoteNodeIP:   GetLoopBackIP(t),
+	}
+	if _, err := mAuthHandler.authenticate(ar); err != nil {
+		t.Fatalf("authenticate() on the startup port failed: %v", err)
+	}
+
+	// Rebind the listener to a new port at runtime. The client side dials
+	// the new port as well.
+	newPort := getRandomOpenPort(t)
+	mAuthHandler.applyListenerSettings(newPort, false)
+	if got := mAuthHandler.listenerPort(); got != newPort {
+		t.Errorf("listenerPort() = %d, want %d", got, newPort)
+	}
+	if _, err := mAuthHandler.authenticate(ar); err != nil {
+		t.Errorf("authenticate() after a listener rebind failed: %v", err)
+	}
+
+	// Disabling the listener at runtime refuses new handshakes, re-enabling
+	// it brings the socket back.
+	mAuthHandler.applyListenerSettings(newPort, true)
+	if _, err := mAuthHandler.authenticate(ar); err == nil {
+		t.Errorf("authenticate() against a disabled listener must fail")
+	}
+	mAuthHandler.applyListenerSettings(newPort, false)
+	if _, err := mAuthHandler.authenticate(ar); err != nil {
+		t.Errorf("authenticate() after re-enabling the listener failed: %v", err)
+	}
+
+	// Invalid runtime ports are ignored.
+	mAuthHandler.applyListenerSettings(-1, false)
+	if got := mAuthHandler.listenerPort(); got != newPort {
+		t.Errorf("listenerPort() after an invalid runtime port = %d, want %d", got, newPort)
+	}
+}
+
 func getRandomOpenPort(t *testing.T) int {
 	l, err := net.Listen("tcp", ":0")
 	if err != nil {
@@ -463,3 +519,234 @@ func GetLoopBackIP(t *testing.T) string {
 	t.Fatalf("failed to get loopback IP")
 	return ""
 }
+
+type fakeRemoteAddrConn struct {
+	net.Conn
+	remoteAddr net.Addr
+}
+
+func (c fakeRemoteAddrConn) RemoteAddr() net.Addr { return c.remoteAddr }
+
+func Test_mutualAuthHandler_isKnownNodeConnection(t *testing.T) {
+	handler := &mutualAuthHandler{
+		log: logrus.New(),
+		nodeIDHandler: newFakeNodeIDHandler(map[uint16]string{
+			2: "172.18.0.2",
+		}),
+	}
+
+	tests := []struct {
+		name string
+		addr net.Addr
+		want bool
+	}{
+		{
+			name: "known node",
+			addr: &net.TCPAddr{IP: net.ParseIP("172.18.0.2"), Port: 12345},
+			want: true,
+		},
+		{
+			name: "unknown node",
+			addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 12345},
+			want: false,
+		},
+		{
+			name: "loopback is always accepted",
+			addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 12345},
+			want: true,
+		},
+		{
+			name: "non TCP source",
+			addr: &net.UnixAddr{Name: "@test", Net: "unix"},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := handler.isKnownNodeConnection(fakeRemoteAddrConn{remoteAddr: tt.addr}); got != tt.want {
+				t.Errorf("isKnownNodeConnection() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mutualAuthHandler_checkNearExpiry(t *testing.T) {
+	notAfter := time.Now().Add(30 * time.Second)
+
+	tests := []struct {
+		name    string
+		cfg     MutualAuthConfig
+		wantErr bool
+	}{
+		{
+			name: "disabled threshold ignores near expiry",
+			cfg:  MutualAuthConfig{},
+		},
+		{
+			name: "warn proceeds with the handshake",
+			cfg: MutualAuthConfig{
+				MutualAuthNearExpiryThreshold: time.Minute,
+				MutualAuthNearExpiryAction:    nearExpiryActionWarn,
+			},
+		},
+		{
+			name: "proceed proceeds with the handshake",
+			cfg: MutualAuthConfig{
+				MutualAuthNearExpiryThreshold: time.Minute,
+				MutualAuthNearExpiryAction:    nearExpiryActionProceed,
+			},
+		},
+		{
+			name: "refuse rejects the handshake",
+			cfg: MutualAuthConfig{
+				MutualAuthNearExpiryThreshold: time.Minute,
+				MutualAuthNearExpiryAction:    nearExpiryActionRefuse,
+			},
+			wantErr: true,
+		},
+		{
+			name: "certificate outside the threshold is not affected",
+			cfg: MutualAuthConfig{
+				MutualAuthNearExpiryThreshold: 10 * time.Second,
+				MutualAuthNearExpiryAction:    nearExpiryActionRefuse,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mutualAuthHandler{cfg: tt.cfg, log: logrus.New()}
+			if err := m.checkNearExpiry(notAfter); (err != nil) != tt.wantErr {
+				t.Errorf("checkNearExpiry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_mutualAuthHandler_listenerSpecs(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  MutualAuthConfig
+		want []listenerSpec
+	}{
+		{
+			name: "default binds one dual-stack socket on all interfaces",
+			cfg:  MutualAuthConfig{MutualAuthListenerPort: 1234},
+			want: []listenerSpec{{network: "tcp", address: ":1234"}},
+		},
+		{
+			name: "specific bind address",
+			cfg: MutualAuthConfig{
+				MutualAuthListenerPort:    1234,
+				MutualAuthListenerAddress: "192.0.2.1",
+			},
+			want: []listenerSpec{{network: "tcp", address: "192.0.2.1:1234"}},
+		},
+		{
+			name: "IPv6 only",
+			cfg: MutualAuthConfig{
+				MutualAuthListenerPort:          1234,
+				MutualAuthListenerAddressFamily: addressFamilyIPv6,
+			},
+			want: []listenerSpec{{network: "tcp6", address: ":1234"}},
+		},
+		{
+			name: "separate sockets per family",
+			cfg: MutualAuthConfig{
+				MutualAuthListenerPort:          1234,
+				MutualAuthListenerAddressFamily: addressFamilySeparate,
+			},
+			want: []listenerSpec{
+				{network: "tcp4", address: ":1234"},
+				{network: "tcp6", address: ":1234"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &mutualAuthHandler{cfg: tt.cfg, log: logrus.New()}
+			if got := handler.listenerSpecs(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("listenerSpecs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mutualAuthHandler_wrapTimeout(t *testing.T) {
+	m := &mutualAuthHandler{log: logrus.New()}
+
+	tests := []struct {
+		name        string
+		err         error
+		wantTimeout bool
+	}{
+		{
+			name:        "deadline exceeded is a timeout",
+			err:         context.DeadlineExceeded,
+			wantTimeout: true,
+		},
+		{
+			name:        "network timeout is a timeout",
+			err:         &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded},
+			wantTimeout: true,
+		},
+		{
+			name: "other failures pass through unwrapped",
+			err:  errors.New("certificate validation failed"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := m.wrapTimeout(receiptRoleInitiator, tt.err)
+			if got := errors.Is(wrapped, errHandshakeTimeout); got != tt.wantTimeout {
+				t.Errorf("errors.Is(wrapTimeout(), errHandshakeTimeout) = %v, want %v", got, tt.wantTimeout)
+			}
+			if !errors.Is(wrapped, tt.err) {
+				t.Errorf("wrapTimeout() lost the original error %v", tt.err)
+			}
+		})
+	}
+}
+
+type fakeRemoteIdentityLookup struct {
+	ips     map[identity.NumericIdentity][]string
+	hostIPs map[string]net.IP
+}
+
+func (f *fakeRemoteIdentityLookup) LookupByIdentity(id identity.NumericIdentity) []string {
+	return f.ips[id]
+}
+
+func (f *fakeRemoteIdentityLookup) GetHostIPCache(ip string) (net.IP, uint8) {
+	return f.hostIPs[ip], 0
+}
+
+func Test_mutualAuthHandler_verifyInitiatorPlacement(t *testing.T) {
+	lookup := &fakeRemoteIdentityLookup{
+		ips: map[identity.NumericIdentity][]string{
+			id1000: {"10.0.1.10", "10.0.2.10"},
+		},
+		hostIPs: map[string]net.IP{
+			"10.0.1.10": net.ParseIP("192.168.1.1"),
+			"10.0.2.10": net.ParseIP("192.168.1.2"),
+		},
+	}
+	m := &mutualAuthHandler{log: logrus.New(), remoteIdentities: lookup}
+
+	if err := m.verifyInitiatorPlacement(id1000, "192.168.1.1"); err != nil {
+		t.Errorf("verifyInitiatorPlacement() with the identity hosted on the dialing node should pass, got %v", err)
+	}
+	if err := m.verifyInitiatorPlacement(id1000, "192.168.1.3"); err == nil {
+		t.Error("verifyInitiatorPlacement() with the identity hosted on a different node should fail")
+	}
+	if err := m.verifyInitiatorPlacement(idbad1, "192.168.1.1"); err == nil {
+		t.Error("verifyInitiatorPlacement() with an identity unknown to the ipcache should fail")
+	}
+	if err := m.verifyInitiatorPlacement(id1000, "127.0.0.1"); err != nil {
+		t.Errorf("verifyInitiatorPlacement() should skip loopback connections, got %v", err)
+	}
+
+	m.remoteIdentities = nil
+	if err := m.verifyInitiatorPlacement(idbad1, "192.168.1.3"); err != nil {
+		t.Errorf("verifyInitiatorPlacement() without an ipcache should pass, got %v", err)
+	}
+}
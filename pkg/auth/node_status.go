@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ciliumv2 "github.com/cilium/cilium/pkg/k8s/apis/cilium.io/v2"
+	k8sClient "github.com/cilium/cilium/pkg/k8s/client"
+	nodeTypes "github.com/cilium/cilium/pkg/node/types"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// nodeAuthStat tracks the authentication history towards a peer node.
+type nodeAuthStat struct {
+	lastSuccess   time.Time
+	failureStreak int
+}
+
+// recordNodeResult updates the per-node authentication history with the
+// outcome of an authentication attempt.
+func (a *AuthManager) recordNodeResult(key authKey, authErr error) {
+	if key.remoteNodeID == 0 {
+		return
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	stat := a.nodeAuthStats[key.remoteNodeID]
+	if authErr != nil {
+		stat.failureStreak++
+	} else {
+		stat.lastSuccess = a.clock.Now()
+		stat.failureStreak = 0
+	}
+	a.nodeAuthStats[key.remoteNodeID] = stat
+}
+
+// nodePairAuthStatuses summarizes the authentication health between the
+// local node and each of its peer nodes, merging the validity of the cached
+// auth entries with the recorded per-node success and failure history.
+func (a *AuthManager) nodePairAuthStatuses() ([]ciliumv2.AuthPeerStatus, error) {
+	healthy, err := a.nodeAuthStatuses()
+	if err != nil {
+		return nil, err
+	}
+
+	a.mutex.Lock()
+	stats := make(map[uint16]nodeAuthStat, len(a.nodeAuthStats))
+	for nodeID, stat := range a.nodeAuthStats {
+		stats[nodeID] = stat
+	}
+	a.mutex.Unlock()
+
+	nodeIDs := make(map[uint16]struct{}, len(healthy)+len(stats))
+	for nodeID := range healthy {
+		nodeIDs[nodeID] = struct{}{}
+	}
+	for nodeID := range stats {
+		nodeIDs[nodeID] = struct{}{}
+	}
+
+	peers := make([]ciliumv2.AuthPeerStatus, 0, len(nodeIDs))
+	for nodeID := range nodeIDs {
+		nodeIP := a.nodeIDHandler.GetNodeIP(nodeID)
+		if nodeIP == "" {
+			continue
+		}
+
+		stat := stats[nodeID]
+		h, ok := healthy[nodeID]
+		if !ok {
+			// No cached auth entries towards the node, judge by the
+			// failure history alone.
+			h = stat.failureStreak == 0
+		}
+
+		peer := ciliumv2.AuthPeerStatus{
+			NodeIP:        nodeIP,
+			Healthy:       h,
+			FailureStreak: stat.failureStreak,
+		}
+		if !stat.lastSuccess.IsZero() {
+			peer.LastSuccessTimestamp = stat.lastSuccess.Format(time.RFC3339)
+		}
+		peers = append(peers, peer)
+	}
+
+	sort.Slice(peers, func(i, j int) bool { return peers[i].NodeIP < peers[j].NodeIP })
+
+	return peers, nil
+}
+
+// nodeAuthStatusSync periodically publishes the per-peer authentication
+// health of the local node into its CiliumNode object, so fleet dashboards
+// can show where mutual auth is broken without scraping every node.
+type nodeAuthStatusSync struct {
+	logger    logrus.FieldLogger
+	manager   *AuthManager
+	clientset k8sClient.Clientset
+}
+
+func (n *nodeAuthStatusSync) sync(ctx context.Context) error {
+	peers, err := n.manager.nodePairAuthStatuses()
+	if err != nil {
+		return fmt.Errorf("failed to summarize node pair auth statuses: %w", err)
+	}
+
+	nodeName := nodeTypes.GetName()
+	ciliumNode, err := n.clientset.CiliumV2().CiliumNodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get CiliumNode %s: %w", nodeName, err)
+	}
+
+	auth := ciliumv2.AuthNodeStatus{Peers: peers}
+	if ciliumNode.Status.Auth.DeepEqual(&auth) {
+		return nil
+	}
+
+	ciliumNode.Status.Auth = auth
+	if _, err := n.clientset.CiliumV2().CiliumNodes().UpdateStatus(ctx, ciliumNode, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update auth status of CiliumNode %s: %w", nodeName, err)
+	}
+
+	return nil
+}
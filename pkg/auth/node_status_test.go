@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cilium/cilium/pkg/time"
+)
+
+func Test_authManager_nodePairAuthStatuses(t *testing.T) {
+	aMap := &fakeAuthMap{
+		entries: map[authKey]authInfo{
+			{localIdentity: 1000, remoteIdentity: 2000, remoteNodeID: 10, authType: 255}: {expiration: time.Now().Add(time.Hour)},
+			{localIdentity: 1000, remoteIdentity: 3000, remoteNodeID: 11, authType: 255}: {expiration: time.Now().Add(-time.Hour)},
+		},
+	}
+
+	am, err := newAuthManager(logrus.New(), []authHandler{&fakeAuthHandler{}}, aMap,
+		newFakeNodeIDHandler(map[uint16]string{10: "172.18.0.2", 11: "172.18.0.3", 12: "172.18.0.4"}), time.Second)
+	assert.NoError(t, err)
+	assert.NotNil(t, am)
+
+	handshakeErr := errors.New("handshake failed")
+	am.recordNodeResult(authKey{localIdentity: 1000, remoteIdentity: 2000, remoteNodeID: 10, authType: 255}, nil)
+	am.recordNodeResult(authKey{localIdentity: 1000, remoteIdentity: 3000, remoteNodeID: 11, authType: 255}, handshakeErr)
+	am.recordNodeResult(authKey{localIdentity: 1000, remoteIdentity: 3000, remoteNodeID: 11, authType: 255}, handshakeErr)
+	am.recordNodeResult(authKey{localIdentity: 1000, remoteIdentity: 4000, remoteNodeID: 12, authType: 255}, handshakeErr)
+	// Results towards the local node are not tracked
+	am.recordNodeResult(authKey{localIdentity: 1000, remoteIdentity: 5000, remoteNodeID: 0, authType: 255}, handshakeErr)
+
+	peers, err := am.nodePairAuthStatuses()
+	assert.NoError(t, err)
+	assert.Len(t, peers, 3)
+
+	// Node 10 has a valid entry and a recent success
+	assert.Equal(t, "172.18.0.2", peers[0].NodeIP)
+	assert.True(t, peers[0].Healthy)
+	assert.Equal(t, 0, peers[0].FailureStreak)
+	assert.NotEmpty(t, peers[0].LastSuccessTimestamp)
+
+	// Node 11 has an expired entry and two consecutive failures
+	assert.Equal(t, "172.18.0.3", peers[1].NodeIP)
+	assert.False(t, peers[1].Healthy)
+	assert.Equal(t, 2, peers[1].FailureStreak)
+	assert.Empty(t, peers[1].LastSuccessTimestamp)
+
+	// Node 12 has no cached entries, so the failure history decides
+	assert.Equal(t, "172.18.0.4", peers[2].NodeIP)
+	assert.False(t, peers[2].Healthy)
+	assert.Equal(t, 1, peers[2].FailureStreak)
+}
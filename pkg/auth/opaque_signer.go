@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+// Certificate providers may back the private key of a certificate with a
+// PKCS#11 HSM or a TPM, in which case the key only implements crypto.Signer
+// and its raw material never exists in agent memory. The TLS handshakes
+// support such keys natively, but the JWT library signs with the concrete
+// in-memory key types only - the opaque signing method below routes the JWT
+// signing operation through the crypto.Signer instead.
+
+// isOpaqueSigner returns true if the private key implements crypto.Signer
+// without being one of the standard in-memory key types.
+func isOpaqueSigner(key crypto.PrivateKey) bool {
+	switch key.(type) {
+	case *ecdsa.PrivateKey, *rsa.PrivateKey, ed25519.PrivateKey:
+		return false
+	}
+
+	_, ok := key.(crypto.Signer)
+	return ok
+}
+
+// opaqueSigningMethod signs JWTs with an opaque crypto.Signer. The algorithm
+// name and the verification of received tokens are delegated to the wrapped
+// method, only the signing operation is routed through the signer.
+type opaqueSigningMethod struct {
+	jwt.SigningMethod
+}
+
+func (m *opaqueSigningMethod) Sign(signingString string, key interface{}) (string, error) {
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return "", jwt.ErrInvalidKeyType
+	}
+
+	switch base := m.SigningMethod.(type) {
+	case *jwt.SigningMethodECDSA:
+		der, err := signer.Sign(rand.Reader, hashSigningString(base.Hash, signingString), base.Hash)
+		if err != nil {
+			return "", err
+		}
+		raw, err := ecdsaRawSignature(der, base.KeySize)
+		if err != nil {
+			return "", err
+		}
+		return jwt.EncodeSegment(raw), nil
+	case *jwt.SigningMethodRSA:
+		sig, err := signer.Sign(rand.Reader, hashSigningString(base.Hash, signingString), base.Hash)
+		if err != nil {
+			return "", err
+		}
+		return jwt.EncodeSegment(sig), nil
+	case *jwt.SigningMethodEd25519:
+		// Ed25519 signs the payload directly, without a prehash.
+		sig, err := signer.Sign(rand.Reader, []byte(signingString), crypto.Hash(0))
+		if err != nil {
+			return "", err
+		}
+		return jwt.EncodeSegment(sig), nil
+	}
+
+	return "", fmt.Errorf("signing method %s does not support opaque keys", m.Alg())
+}
+
+func hashSigningString(hash crypto.Hash, signingString string) []byte {
+	hasher := hash.New()
+	hasher.Write([]byte(signingString))
+	return hasher.Sum(nil)
+}
+
+// ecdsaRawSignature converts the ASN.1 DER signature produced by a
+// crypto.Signer into the fixed-size r||s encoding JWTs use.
+func ecdsaRawSignature(der []byte, keySize int) ([]byte, error) {
+	var sig struct {
+		R, S *big.Int
+	}
+	if _, err := asn1.Unmarshal(der, &sig); err != nil {
+		return nil, fmt.Errorf("failed to parse ECDSA signature: %w", err)
+	}
+
+	raw := make([]byte, 2*keySize)
+	sig.R.FillBytes(raw[:keySize])
+	sig.S.FillBytes(raw[keySize:])
+	return raw, nil
+}
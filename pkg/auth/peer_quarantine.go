@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"crypto/x509"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/cilium/cilium/pkg/lock"
+	"github.com/cilium/cilium/pkg/logging/logfields"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// peerQuarantine tracks remote nodes which repeatedly present certificates
+// failing validation. A node crossing the failure threshold is quarantined
+// for a cooldown, during which handshakes with it are refused cheaply before
+// any TLS work is done. Quarantine state is held in memory: it is lifted by
+// cooldown expiry or an agent restart.
+type peerQuarantine struct {
+	log logrus.FieldLogger
+
+	threshold int // validation failures after which a peer is quarantined
+	cooldown  time.Duration
+
+	mutex lock.Mutex
+	peers map[string]*quarantinedPeer
+}
+
+type quarantinedPeer struct {
+	failures         int
+	quarantinedUntil time.Time
+}
+
+func newPeerQuarantine(log logrus.FieldLogger, threshold int, cooldown time.Duration) *peerQuarantine {
+	return &peerQuarantine{
+		log:       log,
+		threshold: threshold,
+		cooldown:  cooldown,
+		peers:     map[string]*quarantinedPeer{},
+	}
+}
+
+// isQuarantined returns true if handshakes with the given node IP are
+// currently refused. Expired quarantines are lifted on the way.
+func (q *peerQuarantine) isQuarantined(nodeIP string, now time.Time) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	peer, ok := q.peers[nodeIP]
+	if !ok {
+		return false
+	}
+	if !peer.quarantinedUntil.IsZero() && now.After(peer.quarantinedUntil) {
+		q.log.WithField(logfields.IPAddr, nodeIP).
+			Info("Lifting handshake quarantine after cooldown expiry")
+		delete(q.peers, nodeIP)
+		return false
+	}
+	return !peer.quarantinedUntil.IsZero()
+}
+
+// recordFailure accounts a certificate validation failure of the given node
+// and quarantines it once the threshold is crossed.
+func (q *peerQuarantine) recordFailure(nodeIP string, now time.Time) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	peer, ok := q.peers[nodeIP]
+	if !ok {
+		peer = &quarantinedPeer{}
+		q.peers[nodeIP] = peer
+	}
+
+	peer.failures++
+	if peer.failures >= q.threshold && peer.quarantinedUntil.IsZero() {
+		peer.quarantinedUntil = now.Add(q.cooldown)
+		q.log.WithFields(logrus.Fields{
+			logfields.IPAddr:   nodeIP,
+			logfields.Duration: q.cooldown,
+			"failures":         peer.failures,
+		}).Warning("Quarantining remote node after repeated certificate validation failures")
+	}
+}
+
+// recordSuccess resets the failure accounting of a node after a successful
+// handshake, so sporadic failures do not accumulate into a quarantine.
+func (q *peerQuarantine) recordSuccess(nodeIP string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if peer, ok := q.peers[nodeIP]; ok && peer.quarantinedUntil.IsZero() {
+		delete(q.peers, nodeIP)
+	}
+}
+
+// isCertValidationError returns true if the given handshake error was caused
+// by the peer presenting an invalid certificate, as opposed to transport or
+// timeout errors which must not count towards a quarantine.
+func isCertValidationError(err error) bool {
+	var (
+		invalidErr   x509.CertificateInvalidError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+	)
+	return errors.As(err, &invalidErr) || errors.As(err, &authorityErr) || errors.As(err, &hostnameErr)
+}
@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func Test_peerQuarantine(t *testing.T) {
+	q := newPeerQuarantine(logrus.New(), 3, time.Minute)
+
+	now := time.Now()
+	nodeIP := "172.18.0.2"
+
+	// Failures below the threshold do not quarantine the peer.
+	q.recordFailure(nodeIP, now)
+	q.recordFailure(nodeIP, now)
+	if q.isQuarantined(nodeIP, now) {
+		t.Fatal("peer got quarantined below the failure threshold")
+	}
+
+	// A successful handshake resets the accounting.
+	q.recordSuccess(nodeIP)
+	q.recordFailure(nodeIP, now)
+	q.recordFailure(nodeIP, now)
+	if q.isQuarantined(nodeIP, now) {
+		t.Fatal("peer got quarantined although the failure count was reset")
+	}
+
+	// Crossing the threshold quarantines the peer.
+	q.recordFailure(nodeIP, now)
+	if !q.isQuarantined(nodeIP, now) {
+		t.Fatal("peer did not get quarantined at the failure threshold")
+	}
+	if q.isQuarantined("172.18.0.3", now) {
+		t.Fatal("unrelated peer got quarantined")
+	}
+
+	// The quarantine is lifted after the cooldown.
+	if !q.isQuarantined(nodeIP, now.Add(30*time.Second)) {
+		t.Fatal("quarantine got lifted before the cooldown expired")
+	}
+	if q.isQuarantined(nodeIP, now.Add(2*time.Minute)) {
+		t.Fatal("quarantine did not get lifted after the cooldown")
+	}
+}
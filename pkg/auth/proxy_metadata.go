@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"github.com/cilium/cilium/pkg/identity"
+	"github.com/cilium/cilium/pkg/policy"
+	"github.com/cilium/cilium/pkg/proxy/accesslog"
+)
+
+// spiffeIDResolver is implemented by auth handlers whose certificate provider
+// encodes identities as SPIFFE IDs.
+type spiffeIDResolver interface {
+	resolvePeerSpiffeID(id identity.NumericIdentity) string
+}
+
+// ProxyAuthMetadata implements accesslog.AuthMetadataProvider. It surfaces
+// the network-layer authentication verdict of the identity pair of a proxied
+// connection, so L7 access logs carry the L3/4 auth state as well.
+func (a *AuthManager) ProxyAuthMetadata(local, peer identity.NumericIdentity) *accesslog.AuthMetadata {
+	all, err := a.authmap.All()
+	if err != nil {
+		a.logger.
+			WithError(err).
+			Debug("Failed to get auth map entries for proxy auth metadata")
+		return nil
+	}
+
+	var authType policy.AuthType
+	authenticated := false
+	now := a.clock.Now()
+	for k, info := range all {
+		if k.localIdentity == local && k.remoteIdentity == peer && info.expiration.After(now) {
+			authenticated = true
+			authType = k.authType
+			break
+		}
+	}
+	if !authenticated {
+		return nil
+	}
+
+	metadata := &accesslog.AuthMetadata{
+		Verdict: accesslog.AuthVerdictAuthenticated,
+	}
+	if resolver, ok := a.authHandlers[authType].(spiffeIDResolver); ok {
+		metadata.PeerSpiffeID = resolver.resolvePeerSpiffeID(peer)
+	}
+
+	return metadata
+}
+
+// newProxyAuthMetadataProvider exposes the auth manager to the L7 proxy
+// integration. It returns nil if authentication processing is disabled.
+func newProxyAuthMetadataProvider(mgr *AuthManager) accesslog.AuthMetadataProvider {
+	if mgr == nil {
+		return nil
+	}
+	return mgr
+}
@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cilium/cilium/pkg/identity"
+	"github.com/cilium/cilium/pkg/proxy/accesslog"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// Fake AuthHandler which resolves SPIFFE IDs for its peers
+type spiffeAuthHandler struct {
+	fakeAuthHandler
+}
+
+func (r *spiffeAuthHandler) resolvePeerSpiffeID(id identity.NumericIdentity) string {
+	return "spiffe://test.cilium.io/identity/" + id.String()
+}
+
+func Test_authManager_ProxyAuthMetadata(t *testing.T) {
+	aMap := &fakeAuthMap{
+		entries: map[authKey]authInfo{
+			{localIdentity: 1000, remoteIdentity: 2000, remoteNodeID: 1, authType: 255}: {expiration: time.Now().Add(time.Hour)},
+			{localIdentity: 1000, remoteIdentity: 3000, remoteNodeID: 1, authType: 255}: {expiration: time.Now().Add(-time.Hour)},
+		},
+	}
+
+	am, err := newAuthManager(logrus.New(), []authHandler{&spiffeAuthHandler{}}, aMap, nil, time.Second)
+	assert.NoError(t, err)
+	assert.NotNil(t, am)
+
+	metadata := am.ProxyAuthMetadata(1000, 2000)
+	assert.NotNil(t, metadata)
+	assert.Equal(t, accesslog.AuthVerdictAuthenticated, metadata.Verdict)
+	assert.Equal(t, "spiffe://test.cilium.io/identity/2000", metadata.PeerSpiffeID)
+
+	// Expired entries do not count as authenticated.
+	assert.Nil(t, am.ProxyAuthMetadata(1000, 3000))
+
+	// Unknown identity pairs carry no auth metadata.
+	assert.Nil(t, am.ProxyAuthMetadata(2000, 1000))
+}
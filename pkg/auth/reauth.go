@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"context"
+	"fmt"
+)
+
+// Certificate rotation events already trigger a re-authentication of the
+// affected auth map entries, so entries are usually refreshed long before
+// they expire. When no rotation arrives in time - e.g. the peer certificate
+// still has plenty of lifetime left while the entry inherited a short one -
+// the entry expires and the datapath drops traffic until policy resurfaces
+// the key with an auth required signal. The re-auth scheduler closes this gap
+// by proactively re-running the authentication of entries which are about to
+// expire.
+
+// reauthExpiringEntries re-authenticates the auth map entries which expire
+// within the configured lead time. It runs periodically as a timer job.
+func (a *AuthManager) reauthExpiringEntries(ctx context.Context) error {
+	all, err := a.authmap.All()
+	if err != nil {
+		return fmt.Errorf("failed to get all auth map entries: %w", err)
+	}
+
+	now := a.clock.Now()
+	horizon := now.Add(a.reauthBeforeExpiry)
+	for k, info := range all {
+		// Entries which already expired are left to the datapath and the
+		// garbage collector - re-authenticating them cannot prevent a gap
+		// which already happened.
+		if info.expiration.After(now) && !info.expiration.After(horizon) {
+			a.logger.
+				WithField("key", k).
+				WithField("expiration", info.expiration).
+				Debug("Auth entry is about to expire, proactively re-authenticating")
+			a.handleAuthenticationFunc(a, k, true)
+		}
+	}
+
+	return nil
+}
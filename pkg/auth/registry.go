@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cilium/hive/cell"
+
+	"github.com/cilium/cilium/api/v1/models"
+	"github.com/cilium/cilium/pkg/auth/certs"
+	"github.com/cilium/cilium/pkg/identity"
+	"github.com/cilium/cilium/pkg/policy"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// The auth handlers are collected through the hive value group "authHandlers"
+// and selected per policy auth type at runtime. The in-tree handlers join the
+// group directly; out-of-tree handlers (e.g. HSM-backed flows) implement the
+// exported AuthHandler interface and join it through RegisterAuthHandler,
+// without patching the in-tree constructors:
+//
+//	cell.Provide(func(...) auth.AuthHandlerOut {
+//		return auth.RegisterAuthHandler(myHandler)
+//	})
+
+// AuthHandler is the interface implemented by out-of-tree auth handlers.
+// It mirrors the internal handler contract with exported types.
+type AuthHandler interface {
+	// Authenticate performs the authentication of the given request and
+	// returns the expiration of the resulting auth entry.
+	Authenticate(request *AuthRequest) (*AuthResponse, error)
+
+	// AuthType returns the policy auth type the handler serves. Providing
+	// two handlers for the same auth type is a startup error.
+	AuthType() policy.AuthType
+
+	// SubscribeToRotatedIdentities returns a channel of certificate rotation
+	// events triggering re-authentication, or nil if the handler has none.
+	SubscribeToRotatedIdentities() <-chan certs.CertificateRotationEvent
+
+	// CertProviderStatus returns the status of the certificate provider
+	// backing the handler, or nil if it has none.
+	CertProviderStatus() *models.Status
+}
+
+// AuthRequest describes the authentication of one identity pair against a
+// remote node.
+type AuthRequest struct {
+	LocalIdentity  identity.NumericIdentity
+	RemoteIdentity identity.NumericIdentity
+	RemoteNodeIP   string
+}
+
+// AuthResponse carries the outcome of a successful authentication.
+type AuthResponse struct {
+	// ExpirationTime is when the authentication expires, typically bounded
+	// by the certificate lifetimes involved.
+	ExpirationTime time.Time
+}
+
+// AuthHandlerOut joins a registered handler into the auth handler group.
+type AuthHandlerOut struct {
+	cell.Out
+
+	AuthHandler authHandler `group:"authHandlers"`
+}
+
+// RegisterAuthHandler adapts an exported AuthHandler into the auth handler
+// group, to be returned from a cell.Provide constructor.
+func RegisterAuthHandler(handler AuthHandler) AuthHandlerOut {
+	return AuthHandlerOut{
+		AuthHandler: &registeredAuthHandler{handler: handler},
+	}
+}
+
+// registeredAuthHandler adapts the exported handler contract onto the
+// internal one.
+type registeredAuthHandler struct {
+	handler AuthHandler
+}
+
+func (r *registeredAuthHandler) authenticate(ar *authRequest) (*authResponse, error) {
+	if ar == nil {
+		return nil, errors.New("authRequest is nil")
+	}
+	resp, err := r.handler.Authenticate(&AuthRequest{
+		LocalIdentity:  ar.localIdentity,
+		RemoteIdentity: ar.remoteIdentity,
+		RemoteNodeIP:   ar.remoteNodeIP,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("auth handler for auth type %s returned no response", r.handler.AuthType())
+	}
+	return &authResponse{
+		expirationTime: resp.ExpirationTime,
+	}, nil
+}
+
+func (r *registeredAuthHandler) authType() policy.AuthType {
+	return r.handler.AuthType()
+}
+
+func (r *registeredAuthHandler) subscribeToRotatedIdentities() <-chan certs.CertificateRotationEvent {
+	return r.handler.SubscribeToRotatedIdentities()
+}
+
+func (r *registeredAuthHandler) certProviderStatus() *models.Status {
+	return r.handler.CertProviderStatus()
+}
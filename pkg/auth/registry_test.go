@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cilium/cilium/api/v1/models"
+	"github.com/cilium/cilium/pkg/auth/certs"
+	"github.com/cilium/cilium/pkg/policy"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// externalAuthHandler is an out-of-tree style handler implementing the
+// exported AuthHandler interface.
+type externalAuthHandler struct {
+	expiration time.Time
+	err        error
+
+	lastRequest *AuthRequest
+}
+
+func (e *externalAuthHandler) Authenticate(request *AuthRequest) (*AuthResponse, error) {
+	e.lastRequest = request
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &AuthResponse{ExpirationTime: e.expiration}, nil
+}
+
+func (e *externalAuthHandler) AuthType() policy.AuthType {
+	return policy.AuthType(200)
+}
+
+func (e *externalAuthHandler) SubscribeToRotatedIdentities() <-chan certs.CertificateRotationEvent {
+	return nil
+}
+
+func (e *externalAuthHandler) CertProviderStatus() *models.Status {
+	return nil
+}
+
+func Test_registeredAuthHandler(t *testing.T) {
+	expiration := time.Now().Add(time.Hour)
+	external := &externalAuthHandler{expiration: expiration}
+
+	out := RegisterAuthHandler(external)
+	handler := out.AuthHandler
+
+	assert.Equal(t, policy.AuthType(200), handler.authType())
+	assert.Nil(t, handler.subscribeToRotatedIdentities())
+	assert.Nil(t, handler.certProviderStatus())
+
+	resp, err := handler.authenticate(&authRequest{
+		localIdentity:  1000,
+		remoteIdentity: 2000,
+		remoteNodeIP:   "172.18.0.2",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, expiration, resp.expirationTime)
+	assert.Equal(t, &AuthRequest{
+		LocalIdentity:  1000,
+		RemoteIdentity: 2000,
+		RemoteNodeIP:   "172.18.0.2",
+	}, external.lastRequest)
+
+	_, err = handler.authenticate(nil)
+	assert.Error(t, err)
+
+	external.err = errors.New("HSM unavailable")
+	_, err = handler.authenticate(&authRequest{})
+	assert.ErrorContains(t, err, "HSM unavailable")
+}
+
+func Test_registeredAuthHandler_dispatch(t *testing.T) {
+	external := &externalAuthHandler{expiration: time.Now().Add(time.Hour)}
+
+	am, err := newAuthManager(logrus.New(),
+		[]authHandler{newAlwaysPassAuthHandler(logrus.New()), RegisterAuthHandler(external).AuthHandler},
+		&fakeAuthMap{entries: map[authKey]authInfo{}}, newFakeNodeIDHandler(map[uint16]string{10: "172.18.0.2"}), time.Second)
+	assert.NoError(t, err)
+	assert.NotNil(t, am)
+
+	err = am.authenticate(authKey{localIdentity: 1000, remoteIdentity: 2000, remoteNodeID: 10, authType: 200})
+	assert.NoError(t, err)
+	assert.NotNil(t, external.lastRequest)
+	assert.Equal(t, "172.18.0.2", external.lastRequest.RemoteNodeIP)
+}
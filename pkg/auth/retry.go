@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"context"
+
+	"github.com/cilium/cilium/pkg/backoff"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// Transient authentication failures - a restarting remote agent, a listener
+// socket which is not up yet - otherwise leave the datapath dropping traffic
+// until policy triggers the next auth required signal. The retry queue
+// re-runs failed authentications with exponential backoff and jitter instead
+// of waiting for the datapath to resurface them.
+
+// authRetryState tracks the failed authentication attempts of a key.
+type authRetryState struct {
+	attempts  int
+	nextRetry time.Time
+}
+
+// scheduleRetry queues a failed authentication for a retry with exponential
+// backoff. The key is dropped from the queue once the configured number of
+// attempts is exhausted - further retries are then up to the datapath.
+func (a *AuthManager) scheduleRetry(key authKey) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.retryAttempts <= 0 {
+		return
+	}
+
+	state, ok := a.retries[key]
+	if !ok {
+		state = &authRetryState{}
+		a.retries[key] = state
+	}
+	state.attempts++
+	if state.attempts > a.retryAttempts {
+		delete(a.retries, key)
+		a.logger.
+			WithField("key", key).
+			WithField("attempts", a.retryAttempts).
+			Warning("Giving up on retrying failed authentication")
+		return
+	}
+	state.nextRetry = a.clock.Now().Add(a.retryBackoff.Duration(state.attempts))
+
+	a.logger.
+		WithField("key", key).
+		WithField("attempt", state.attempts).
+		WithField("nextRetry", state.nextRetry).
+		Debug("Scheduling retry of failed authentication")
+}
+
+// clearRetry drops a key from the retry queue after a successful
+// authentication.
+func (a *AuthManager) clearRetry(key authKey) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	delete(a.retries, key)
+}
+
+// dueRetries returns the keys whose backoff expired.
+func (a *AuthManager) dueRetries() []authKey {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	now := a.clock.Now()
+	var due []authKey
+	for key, state := range a.retries {
+		if !state.nextRetry.After(now) {
+			due = append(due, key)
+		}
+	}
+	return due
+}
+
+// retryFailedAuthentications re-runs the authentications whose backoff
+// expired. It runs periodically as a timer job. A retry which fails again is
+// re-queued with the next backoff by the regular failure path.
+func (a *AuthManager) retryFailedAuthentications(ctx context.Context) error {
+	for _, key := range a.dueRetries() {
+		a.logger.
+			WithField("key", key).
+			Debug("Retrying failed authentication")
+		a.handleAuthenticationFunc(a, key, true)
+	}
+	return nil
+}
+
+// newRetryBackoff returns the backoff applied between the retries of a
+// failed authentication.
+func newRetryBackoff(cfg config) backoff.Exponential {
+	return backoff.Exponential{
+		Min:    cfg.MeshAuthRetryBackoffMin,
+		Max:    cfg.MeshAuthRetryBackoffMax,
+		Jitter: true,
+	}
+}
@@ -0,0 +1,297 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ocsp"
+
+	"github.com/cilium/cilium/pkg/lock"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// revocationFetchTimeout bounds the fetch of a single CRL or OCSP response.
+const revocationFetchTimeout = 10 * time.Second
+
+// maxRevocationResponseSize bounds the size of a fetched CRL or OCSP
+// response, so a misbehaving distribution point cannot exhaust agent memory.
+const maxRevocationResponseSize = 10 << 20 // 10 MiB
+
+// revocationChecker checks peer certificates against the CRL distribution
+// points and OCSP responders listed in the certificate, so a compromised
+// workload identity can be revoked without waiting for its certificate to
+// expire. Chain validity has already been established by the TLS handshake
+// when the checker runs; it only determines the revocation status of the
+// peer leaf.
+//
+// Lookup results are cached per certificate serial for a TTL, bounding the
+// lookup traffic to one fetch per certificate and TTL. A lookup which cannot
+// be completed - unreachable distribution point, certificate without any
+// revocation source - is logged and ignored unless hard-fail is configured,
+// matching the soft-fail stance of common TLS stacks.
+type revocationChecker struct {
+	log logrus.FieldLogger
+
+	crlEnabled  bool
+	ocspEnabled bool
+	hardFail    bool
+	cacheTTL    time.Duration
+
+	client *http.Client
+
+	mutex   lock.Mutex
+	status  map[string]revocationStatus
+	staples map[string]ocspStaple
+}
+
+// revocationStatus is the cached outcome of a completed revocation lookup.
+type revocationStatus struct {
+	revoked   bool
+	checkedAt time.Time
+}
+
+// ocspStaple is a cached raw OCSP response for a local certificate, stapled
+// into inbound handshakes. A nil raw response caches a failed fetch, so a
+// broken responder is not retried on every inbound connection.
+type ocspStaple struct {
+	raw       []byte
+	fetchedAt time.Time
+}
+
+func newRevocationChecker(log logrus.FieldLogger, cfg MutualAuthConfig) *revocationChecker {
+	return &revocationChecker{
+		log:         log,
+		crlEnabled:  cfg.MutualAuthRevocationCRL,
+		ocspEnabled: cfg.MutualAuthRevocationOCSP,
+		hardFail:    cfg.MutualAuthRevocationHardFail,
+		cacheTTL:    cfg.MutualAuthRevocationCacheTTL,
+		client:      &http.Client{Timeout: revocationFetchTimeout},
+		status:      map[string]revocationStatus{},
+		staples:     map[string]ocspStaple{},
+	}
+}
+
+// checkPeer checks the revocation status of the leaf of the given peer
+// chain. A stapled OCSP response received during the handshake is consumed
+// before any network lookup. Returns an error if the certificate has been
+// revoked, or - with hard-fail configured - if its status could not be
+// determined.
+func (r *revocationChecker) checkPeer(chain []*x509.Certificate, staple []byte) error {
+	if r == nil || len(chain) == 0 {
+		return nil
+	}
+	leaf := chain[0]
+	var issuer *x509.Certificate
+	if len(chain) > 1 {
+		issuer = chain[1]
+	}
+	serial := leaf.SerialNumber.String()
+
+	now := time.Now()
+	r.mutex.Lock()
+	entry, cached := r.status[serial]
+	r.mutex.Unlock()
+	if cached && now.Sub(entry.checkedAt) < r.cacheTTL {
+		if entry.revoked {
+			return fmt.Errorf("certificate %s has been revoked", serial)
+		}
+		return nil
+	}
+
+	revoked, err := r.lookup(leaf, issuer, staple)
+	if err != nil {
+		if r.hardFail {
+			return fmt.Errorf("failed to determine revocation status of certificate %s: %w", serial, err)
+		}
+		r.log.WithError(err).WithField("serial", serial).
+			Debug("Failed to determine certificate revocation status, proceeding")
+		return nil
+	}
+
+	r.mutex.Lock()
+	r.status[serial] = revocationStatus{revoked: revoked, checkedAt: now}
+	r.mutex.Unlock()
+
+	if revoked {
+		return fmt.Errorf("certificate %s has been revoked", serial)
+	}
+	return nil
+}
+
+// lookup determines the revocation status of the given leaf, trying the
+// stapled OCSP response, the OCSP responders and the CRL distribution points
+// of the certificate in that order. Both OCSP and CRL require the issuer
+// certificate to be part of the presented chain, as an unverifiable
+// revocation answer is worthless.
+func (r *revocationChecker) lookup(leaf, issuer *x509.Certificate, staple []byte) (bool, error) {
+	if issuer == nil {
+		return false, errors.New("peer did not present its issuer certificate")
+	}
+
+	var errs []error
+
+	if r.ocspEnabled {
+		if len(staple) > 0 {
+			resp, err := ocsp.ParseResponseForCert(staple, leaf, issuer)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to parse stapled OCSP response: %w", err))
+			} else if resp.Status != ocsp.Unknown {
+				return resp.Status == ocsp.Revoked, nil
+			}
+		}
+		for _, server := range leaf.OCSPServer {
+			resp, _, err := r.fetchOCSP(leaf, issuer, server)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if resp.Status == ocsp.Unknown {
+				continue
+			}
+			return resp.Status == ocsp.Revoked, nil
+		}
+	}
+
+	if r.crlEnabled {
+		for _, url := range leaf.CRLDistributionPoints {
+			revoked, err := r.fetchCRL(leaf, issuer, url)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			return revoked, nil
+		}
+	}
+
+	if len(errs) > 0 {
+		return false, errors.Join(errs...)
+	}
+	return false, errors.New("certificate carries no usable revocation source")
+}
+
+// fetchOCSP queries the given OCSP responder for the status of the leaf and
+// returns the parsed and the raw response.
+func (r *revocationChecker) fetchOCSP(leaf, issuer *x509.Certificate, server string) (*ocsp.Response, []byte, error) {
+	reqDER, err := ocsp.CreateRequest(leaf, issuer, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create OCSP request: %w", err)
+	}
+	httpResp, err := r.client.Post(server, "application/ocsp-request", bytes.NewReader(reqDER))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query OCSP responder %s: %w", server, err)
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("OCSP responder %s returned status %s", server, httpResp.Status)
+	}
+	raw, err := io.ReadAll(io.LimitReader(httpResp.Body, maxRevocationResponseSize))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read OCSP response of %s: %w", server, err)
+	}
+	resp, err := ocsp.ParseResponseForCert(raw, leaf, issuer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse OCSP response of %s: %w", server, err)
+	}
+	return resp, raw, nil
+}
+
+// fetchCRL fetches the CRL at the given distribution point, verifies it is
+// signed by the issuer and still current, and returns whether it lists the
+// leaf as revoked.
+func (r *revocationChecker) fetchCRL(leaf, issuer *x509.Certificate, url string) (bool, error) {
+	httpResp, err := r.client.Get(url)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch CRL from %s: %w", url, err)
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("CRL distribution point %s returned status %s", url, httpResp.Status)
+	}
+	raw, err := io.ReadAll(io.LimitReader(httpResp.Body, maxRevocationResponseSize))
+	if err != nil {
+		return false, fmt.Errorf("failed to read CRL from %s: %w", url, err)
+	}
+	crl, err := x509.ParseRevocationList(raw)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse CRL from %s: %w", url, err)
+	}
+	if err := crl.CheckSignatureFrom(issuer); err != nil {
+		return false, fmt.Errorf("CRL from %s is not signed by the certificate issuer: %w", url, err)
+	}
+	if !crl.NextUpdate.IsZero() && time.Now().After(crl.NextUpdate) {
+		return false, fmt.Errorf("CRL from %s is stale, next update was due %s", url, crl.NextUpdate)
+	}
+	for _, entry := range crl.RevokedCertificateEntries {
+		if entry.SerialNumber.Cmp(leaf.SerialNumber) == 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// stapleCertificate returns a copy of the given certificate with a cached
+// OCSP response for its leaf stapled, so peers can verify the revocation
+// status without contacting the responder themselves. The certificate is
+// returned unmodified when OCSP is disabled, the certificate carries no
+// issuer, or no response could be obtained.
+func (r *revocationChecker) stapleCertificate(cert *tls.Certificate) *tls.Certificate {
+	if r == nil || !r.ocspEnabled || cert == nil || cert.Leaf == nil || len(cert.Certificate) < 2 {
+		return cert
+	}
+	issuer, err := x509.ParseCertificate(cert.Certificate[1])
+	if err != nil {
+		return cert
+	}
+	raw := r.stapleFor(cert.Leaf, issuer)
+	if raw == nil {
+		return cert
+	}
+	stapled := *cert
+	stapled.OCSPStaple = raw
+	return &stapled
+}
+
+// stapleFor returns a raw OCSP response for the given leaf, fetched from its
+// responders and cached for the TTL. Failed fetches are cached as well, so a
+// broken responder does not add a fetch timeout to every inbound handshake.
+func (r *revocationChecker) stapleFor(leaf, issuer *x509.Certificate) []byte {
+	serial := leaf.SerialNumber.String()
+
+	now := time.Now()
+	r.mutex.Lock()
+	entry, cached := r.staples[serial]
+	r.mutex.Unlock()
+	if cached && now.Sub(entry.fetchedAt) < r.cacheTTL {
+		return entry.raw
+	}
+
+	for _, server := range leaf.OCSPServer {
+		resp, raw, err := r.fetchOCSP(leaf, issuer, server)
+		if err != nil {
+			r.log.WithError(err).WithField("serial", serial).
+				Debug("Failed to fetch OCSP response to staple")
+			continue
+		}
+		if resp.Status == ocsp.Unknown {
+			continue
+		}
+		r.mutex.Lock()
+		r.staples[serial] = ocspStaple{raw: raw, fetchedAt: now}
+		r.mutex.Unlock()
+		return raw
+	}
+
+	r.mutex.Lock()
+	r.staples[serial] = ocspStaple{fetchedAt: now}
+	r.mutex.Unlock()
+	return nil
+}
@@ -0,0 +1,266 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ocsp"
+)
+
+// revocationTestPKI is a CA signing leaves carrying revocation sources, with
+// the serials the test wants reported as revoked.
+type revocationTestPKI struct {
+	caCert *x509.Certificate
+	caKey  *ecdsa.PrivateKey
+}
+
+func newRevocationTestPKI(t *testing.T) *revocationTestPKI {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate CA key: %v", err)
+	}
+	caTmpl := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "revocation test ca"},
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		SerialNumber:          big.NewInt(1),
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to sign CA certificate: %v", err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatalf("failed to parse CA certificate: %v", err)
+	}
+	return &revocationTestPKI{caCert: caCert, caKey: caKey}
+}
+
+// issueLeaf issues a leaf with the given serial, CRL distribution points and
+// OCSP servers, returning the certificate and its raw DER.
+func (p *revocationTestPKI) issueLeaf(t *testing.T, serial int64, crlURLs, ocspURLs []string) (*x509.Certificate, []byte) {
+	t.Helper()
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate leaf key: %v", err)
+	}
+	leafTmpl := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "revocation test leaf"},
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		SerialNumber:          big.NewInt(serial),
+		CRLDistributionPoints: crlURLs,
+		OCSPServer:            ocspURLs,
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTmpl, p.caCert, &leafKey.PublicKey, p.caKey)
+	if err != nil {
+		t.Fatalf("failed to sign leaf certificate: %v", err)
+	}
+	leafCert, err := x509.ParseCertificate(leafDER)
+	if err != nil {
+		t.Fatalf("failed to parse leaf certificate: %v", err)
+	}
+	return leafCert, leafDER
+}
+
+// serveCRL runs a distribution point serving a CRL which lists the given
+// serials as revoked.
+func (p *revocationTestPKI) serveCRL(t *testing.T, revokedSerials ...int64) *httptest.Server {
+	t.Helper()
+
+	var entries []x509.RevocationListEntry
+	for _, serial := range revokedSerials {
+		entries = append(entries, x509.RevocationListEntry{
+			SerialNumber:   big.NewInt(serial),
+			RevocationTime: time.Now(),
+		})
+	}
+	crlDER, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:                    big.NewInt(1),
+		ThisUpdate:                time.Now().Add(-time.Hour),
+		NextUpdate:                time.Now().Add(time.Hour),
+		RevokedCertificateEntries: entries,
+	}, p.caCert, p.caKey)
+	if err != nil {
+		t.Fatalf("failed to create CRL: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(crlDER)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// serveOCSP runs an OCSP responder reporting the given serials as revoked
+// and everything else as good.
+func (p *revocationTestPKI) serveOCSP(t *testing.T, revokedSerials ...int64) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		req, err := ocsp.ParseRequest(body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		tmpl := ocsp.Response{
+			Status:       ocsp.Good,
+			SerialNumber: req.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Hour),
+			NextUpdate:   time.Now().Add(time.Hour),
+		}
+		for _, serial := range revokedSerials {
+			if req.SerialNumber.Cmp(big.NewInt(serial)) == 0 {
+				tmpl.Status = ocsp.Revoked
+				tmpl.RevokedAt = time.Now()
+				tmpl.RevocationReason = ocsp.KeyCompromise
+			}
+		}
+		respDER, err := ocsp.CreateResponse(p.caCert, p.caCert, tmpl, p.caKey)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write(respDER)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestRevocationChecker(crl, ocspEnabled, hardFail bool) *revocationChecker {
+	return newRevocationChecker(logrus.New(), MutualAuthConfig{
+		MutualAuthRevocationCRL:      crl,
+		MutualAuthRevocationOCSP:     ocspEnabled,
+		MutualAuthRevocationHardFail: hardFail,
+		MutualAuthRevocationCacheTTL: time.Hour,
+	})
+}
+
+func Test_revocationChecker_checkPeer_CRL(t *testing.T) {
+	pki := newRevocationTestPKI(t)
+	server := pki.serveCRL(t, 2001)
+	goodLeaf, _ := pki.issueLeaf(t, 2000, []string{server.URL}, nil)
+	revokedLeaf, _ := pki.issueLeaf(t, 2001, []string{server.URL}, nil)
+
+	checker := newTestRevocationChecker(true, false, false)
+
+	if err := checker.checkPeer([]*x509.Certificate{goodLeaf, pki.caCert}, nil); err != nil {
+		t.Errorf("checkPeer() with a good certificate failed: %v", err)
+	}
+	if err := checker.checkPeer([]*x509.Certificate{revokedLeaf, pki.caCert}, nil); err == nil {
+		t.Errorf("checkPeer() with a revoked certificate must fail")
+	}
+
+	// Results are cached, so the outcome survives the distribution point
+	// going away.
+	server.Close()
+	if err := checker.checkPeer([]*x509.Certificate{goodLeaf, pki.caCert}, nil); err != nil {
+		t.Errorf("checkPeer() with a cached good result failed: %v", err)
+	}
+	if err := checker.checkPeer([]*x509.Certificate{revokedLeaf, pki.caCert}, nil); err == nil {
+		t.Errorf("checkPeer() with a cached revoked result must fail")
+	}
+}
+
+func Test_revocationChecker_checkPeer_OCSP(t *testing.T) {
+	pki := newRevocationTestPKI(t)
+	server := pki.serveOCSP(t, 3001)
+	goodLeaf, _ := pki.issueLeaf(t, 3000, nil, []string{server.URL})
+	revokedLeaf, _ := pki.issueLeaf(t, 3001, nil, []string{server.URL})
+
+	checker := newTestRevocationChecker(false, true, false)
+
+	if err := checker.checkPeer([]*x509.Certificate{goodLeaf, pki.caCert}, nil); err != nil {
+		t.Errorf("checkPeer() with a good certificate failed: %v", err)
+	}
+	if err := checker.checkPeer([]*x509.Certificate{revokedLeaf, pki.caCert}, nil); err == nil {
+		t.Errorf("checkPeer() with a revoked certificate must fail")
+	}
+}
+
+func Test_revocationChecker_checkPeer_failureModes(t *testing.T) {
+	pki := newRevocationTestPKI(t)
+	leaf, _ := pki.issueLeaf(t, 4000, []string{"http://127.0.0.1:1/crl"}, nil)
+	plainLeaf, _ := pki.issueLeaf(t, 4001, nil, nil)
+
+	softFail := newTestRevocationChecker(true, false, false)
+	if err := softFail.checkPeer([]*x509.Certificate{leaf, pki.caCert}, nil); err != nil {
+		t.Errorf("checkPeer() with an unreachable distribution point must soft-fail, got %v", err)
+	}
+	if err := softFail.checkPeer([]*x509.Certificate{plainLeaf, pki.caCert}, nil); err != nil {
+		t.Errorf("checkPeer() with a certificate without revocation sources must soft-fail, got %v", err)
+	}
+	if err := softFail.checkPeer([]*x509.Certificate{leaf}, nil); err != nil {
+		t.Errorf("checkPeer() without an issuer certificate must soft-fail, got %v", err)
+	}
+
+	hardFail := newTestRevocationChecker(true, false, true)
+	if err := hardFail.checkPeer([]*x509.Certificate{leaf, pki.caCert}, nil); err == nil {
+		t.Errorf("checkPeer() with an unreachable distribution point must hard-fail")
+	}
+	if err := hardFail.checkPeer([]*x509.Certificate{leaf}, nil); err == nil {
+		t.Errorf("checkPeer() without an issuer certificate must hard-fail")
+	}
+}
+
+func Test_revocationChecker_stapleCertificate(t *testing.T) {
+	pki := newRevocationTestPKI(t)
+	server := pki.serveOCSP(t)
+	leaf, leafDER := pki.issueLeaf(t, 5000, nil, []string{server.URL})
+
+	checker := newTestRevocationChecker(false, true, false)
+	cert := &tls.Certificate{
+		Certificate: [][]byte{leafDER, pki.caCert.Raw},
+		Leaf:        leaf,
+	}
+
+	stapled := checker.stapleCertificate(cert)
+	if len(stapled.OCSPStaple) == 0 {
+		t.Fatalf("stapleCertificate() did not staple an OCSP response")
+	}
+	if len(cert.OCSPStaple) != 0 {
+		t.Errorf("stapleCertificate() modified the original certificate")
+	}
+
+	// The staple allows the peer to verify the status without contacting the
+	// responder itself.
+	server.Close()
+	verifier := newTestRevocationChecker(false, true, true)
+	if err := verifier.checkPeer([]*x509.Certificate{leaf, pki.caCert}, stapled.OCSPStaple); err != nil {
+		t.Errorf("checkPeer() with a stapled OCSP response failed: %v", err)
+	}
+
+	// Without OCSP enabled or without the issuer in the chain, the
+	// certificate is passed through unmodified.
+	if got := newTestRevocationChecker(true, false, false).stapleCertificate(cert); got != cert {
+		t.Errorf("stapleCertificate() with OCSP disabled must return the certificate unmodified")
+	}
+	leafOnly := &tls.Certificate{Certificate: [][]byte{leafDER}, Leaf: leaf}
+	if got := checker.stapleCertificate(leafOnly); got != leafOnly {
+		t.Errorf("stapleCertificate() without an issuer must return the certificate unmodified")
+	}
+}
@@ -0,0 +1,198 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	datapathTypes "github.com/cilium/cilium/pkg/datapath/types"
+	"github.com/cilium/cilium/pkg/identity"
+	"github.com/cilium/cilium/pkg/lock"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+const (
+	// sessionKeyLabel is the label under which the datapath keying material
+	// is exported from the TLS session of the mutual auth handshake.
+	sessionKeyLabel = "cilium mutual auth datapath key"
+
+	// sessionKeyLen is the length of the derived keying material. It matches
+	// the key length of the datapath encryption primitives (AES-256 and
+	// ChaCha20-Poly1305).
+	sessionKeyLen = 32
+)
+
+type sessionKeyID struct {
+	localIdentity  identity.NumericIdentity
+	remoteIdentity identity.NumericIdentity
+}
+
+type sessionKey struct {
+	key        []byte
+	expiration time.Time
+}
+
+// sessionKeyStore holds the per-identity-pair keying material derived from
+// the mutual auth handshakes. The datapath can use it to seed encryption
+// keys or key rotation hints tied to the authenticated identities instead of
+// only the node keys. Both ends of a handshake derive the same material from
+// their shared TLS session. Derived keys are additionally pushed into the
+// datapath programmer, if one is wired.
+type sessionKeyStore struct {
+	log logrus.FieldLogger
+
+	// programmer receives the derived keys for installation into the
+	// datapath. Optional; without it the material is only served on demand
+	// through AuthManager.DatapathSessionKey.
+	programmer datapathTypes.AuthSessionKeyProgrammer
+
+	mutex lock.RWMutex
+	keys  map[sessionKeyID]sessionKey
+}
+
+func newSessionKeyStore(log logrus.FieldLogger, programmer datapathTypes.AuthSessionKeyProgrammer) *sessionKeyStore {
+	return &sessionKeyStore{
+		log:        log,
+		programmer: programmer,
+		keys:       make(map[sessionKeyID]sessionKey),
+	}
+}
+
+// derive exports keying material for the authenticated identity pair from
+// the TLS session and stores it under the local perspective of the given
+// role. The export context is built in initiator orientation, so initiator
+// and listener derive the same key bytes for the pair. Keys of other pairs
+// which expired in the meantime are wiped on the way.
+func (s *sessionKeyStore) derive(tlsConn *tls.Conn, role string, localID, remoteID identity.NumericIdentity, expiration time.Time) error {
+	initiatorID, listenerID := localID, remoteID
+	if role == receiptRoleListener {
+		initiatorID, listenerID = remoteID, localID
+	}
+
+	context := make([]byte, 0, 8)
+	context = binary.BigEndian.AppendUint32(context, initiatorID.Uint32())
+	context = binary.BigEndian.AppendUint32(context, listenerID.Uint32())
+
+	state := tlsConn.ConnectionState()
+	key, err := state.ExportKeyingMaterial(sessionKeyLabel, context, sessionKeyLen)
+	if err != nil {
+		return fmt.Errorf("failed to export datapath keying material: %w", err)
+	}
+
+	s.mutex.Lock()
+
+	now := time.Now()
+	var expired []sessionKeyID
+	for id, k := range s.keys {
+		if now.After(k.expiration) {
+			wipeSessionKey(k)
+			delete(s.keys, id)
+			expired = append(expired, id)
+		}
+	}
+
+	id := sessionKeyID{localIdentity: localID, remoteIdentity: remoteID}
+	if old, ok := s.keys[id]; ok {
+		wipeSessionKey(old)
+	}
+	s.keys[id] = sessionKey{key: key, expiration: expiration}
+
+	// Program the datapath outside of the lock, implementations may block.
+	s.mutex.Unlock()
+
+	for _, e := range expired {
+		s.programDelete(e)
+	}
+	s.programUpsert(id, key, expiration)
+
+	s.log.
+		WithField("localIdentity", localID).
+		WithField("remoteIdentity", remoteID).
+		WithField("expiration", expiration).
+		Debug("Derived datapath session key from mutual auth handshake")
+
+	return nil
+}
+
+// programUpsert hands a private copy of the derived key to the datapath
+// programmer. The authentication stands even if programming fails, the
+// datapath falls back to its node keys.
+func (s *sessionKeyStore) programUpsert(id sessionKeyID, key []byte, expiration time.Time) {
+	if s.programmer == nil {
+		return
+	}
+
+	cp := make([]byte, len(key))
+	copy(cp, key)
+	if err := s.programmer.UpsertAuthSessionKey(id.localIdentity, id.remoteIdentity, cp, expiration); err != nil {
+		s.log.
+			WithError(err).
+			WithField("localIdentity", id.localIdentity).
+			WithField("remoteIdentity", id.remoteIdentity).
+			Warning("Failed to program datapath session key")
+	}
+}
+
+// programDelete removes the key of an expired identity pair from the
+// datapath programmer.
+func (s *sessionKeyStore) programDelete(id sessionKeyID) {
+	if s.programmer == nil {
+		return
+	}
+
+	if err := s.programmer.DeleteAuthSessionKey(id.localIdentity, id.remoteIdentity); err != nil {
+		s.log.
+			WithError(err).
+			WithField("localIdentity", id.localIdentity).
+			WithField("remoteIdentity", id.remoteIdentity).
+			Warning("Failed to remove programmed datapath session key")
+	}
+}
+
+// get returns a copy of the current keying material for the identity pair,
+// or false if none is available.
+func (s *sessionKeyStore) get(localID, remoteID identity.NumericIdentity) ([]byte, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	k, ok := s.keys[sessionKeyID{localIdentity: localID, remoteIdentity: remoteID}]
+	if !ok || time.Now().After(k.expiration) {
+		return nil, false
+	}
+
+	key := make([]byte, len(k.key))
+	copy(key, k.key)
+	return key, true
+}
+
+// wipeSessionKey zeroizes the key material before the entry is dropped.
+func wipeSessionKey(k sessionKey) {
+	for i := range k.key {
+		k.key[i] = 0
+	}
+}
+
+// sessionKeyProvider is implemented by auth handlers which derive datapath
+// keying material from their handshakes.
+type sessionKeyProvider interface {
+	datapathSessionKey(localID, remoteID identity.NumericIdentity) ([]byte, bool)
+}
+
+// DatapathSessionKey returns the keying material derived from the most
+// recent mutual auth handshake between the two identities, or false if none
+// is available. The returned slice is a private copy of the caller.
+func (a *AuthManager) DatapathSessionKey(localID, remoteID identity.NumericIdentity) ([]byte, bool) {
+	for _, h := range a.authHandlers {
+		if provider, ok := h.(sessionKeyProvider); ok {
+			if key, ok := provider.datapathSessionKey(localID, remoteID); ok {
+				return key, true
+			}
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cilium/cilium/pkg/identity"
+	"github.com/cilium/cilium/pkg/lock"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// fakeSessionKeyProgrammer records the keys pushed into the datapath.
+type fakeSessionKeyProgrammer struct {
+	mutex   lock.Mutex
+	keys    map[sessionKeyID][]byte
+	deleted []sessionKeyID
+}
+
+func (f *fakeSessionKeyProgrammer) UpsertAuthSessionKey(localID, remoteID identity.NumericIdentity, key []byte, expiration time.Time) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.keys == nil {
+		f.keys = map[sessionKeyID][]byte{}
+	}
+	f.keys[sessionKeyID{localIdentity: localID, remoteIdentity: remoteID}] = key
+	return nil
+}
+
+func (f *fakeSessionKeyProgrammer) DeleteAuthSessionKey(localID, remoteID identity.NumericIdentity) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.deleted = append(f.deleted, sessionKeyID{localIdentity: localID, remoteIdentity: remoteID})
+	return nil
+}
+
+func (f *fakeSessionKeyProgrammer) programmedKey(localID, remoteID identity.NumericIdentity) []byte {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.keys[sessionKeyID{localIdentity: localID, remoteIdentity: remoteID}]
+}
+
+func (f *fakeSessionKeyProgrammer) deletedIDs() []sessionKeyID {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return append([]sessionKeyID{}, f.deleted...)
+}
+
+func Test_mutualAuthHandler_sessionKeys(t *testing.T) {
+	certMap, keyMap, caPool := generateTestCertificates(t)
+
+	mAuthHandler := &mutualAuthHandler{
+		cfg: MutualAuthConfig{
+			MutualAuthListenerPort:   getRandomOpenPort(t),
+			MutualAuthConnectTimeout: 5 * time.Second,
+			MutualAuthSessionKeys:    true,
+		},
+		log:             logrus.New(),
+		cert:            &fakeCertificateProvider{certMap: certMap, caPool: caPool, privkeyMap: keyMap},
+		endpointManager: &fakeEndpointGetter{},
+	}
+	mAuthHandler.onStart(context.Background())
+	defer mAuthHandler.onStop(context.Background())
+
+	_, err := mAuthHandler.authenticate(&authRequest{
+		localIdentity:  id1000,
+		remoteIdentity: id1001,
+		remoteNodeIP:   GetLoopBackIP(t),
+	})
+	assert.NoError(t, err)
+
+	initiatorKey, ok := mAuthHandler.datapathSessionKey(id1000, id1001)
+	assert.True(t, ok)
+	assert.Len(t, initiatorKey, sessionKeyLen)
+
+	// The listener derives the same key under its own perspective once it
+	// finishes handling the connection.
+	var listenerKey []byte
+	assert.Eventually(t, func() bool {
+		var ok bool
+		listenerKey, ok = mAuthHandler.datapathSessionKey(id1001, id1000)
+		return ok
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, initiatorKey, listenerKey)
+
+	// No key material for pairs which never authenticated.
+	_, ok = mAuthHandler.datapathSessionKey(id1000, idbad1)
+	assert.False(t, ok)
+}
+
+func Test_mutualAuthHandler_sessionKeyProgrammer(t *testing.T) {
+	certMap, keyMap, caPool := generateTestCertificates(t)
+
+	programmer := &fakeSessionKeyProgrammer{}
+	mAuthHandler := &mutualAuthHandler{
+		cfg: MutualAuthConfig{
+			MutualAuthListenerPort:   getRandomOpenPort(t),
+			MutualAuthConnectTimeout: 5 * time.Second,
+			MutualAuthSessionKeys:    true,
+		},
+		log:             logrus.New(),
+		cert:            &fakeCertificateProvider{certMap: certMap, caPool: caPool, privkeyMap: keyMap},
+		endpointManager: &fakeEndpointGetter{},
+		keyProgrammer:   programmer,
+	}
+	mAuthHandler.onStart(context.Background())
+	defer mAuthHandler.onStop(context.Background())
+
+	// An expired key of an unrelated pair is removed from the datapath on
+	// the next derivation.
+	expiredID := sessionKeyID{localIdentity: id1000, remoteIdentity: idbad1}
+	mAuthHandler.sessionKeys.keys[expiredID] = sessionKey{
+		key:        make([]byte, sessionKeyLen),
+		expiration: time.Now().Add(-time.Minute),
+	}
+
+	_, err := mAuthHandler.authenticate(&authRequest{
+		localIdentity:  id1000,
+		remoteIdentity: id1001,
+		remoteNodeIP:   GetLoopBackIP(t),
+	})
+	assert.NoError(t, err)
+
+	// The initiator perspective is programmed synchronously, the listener
+	// perspective once the connection handling finishes.
+	key, ok := mAuthHandler.datapathSessionKey(id1000, id1001)
+	assert.True(t, ok)
+	assert.Equal(t, key, programmer.programmedKey(id1000, id1001))
+	assert.Eventually(t, func() bool {
+		return programmer.programmedKey(id1001, id1000) != nil
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Contains(t, programmer.deletedIDs(), expiredID)
+}
@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cilium/hive/cell"
+	"github.com/sirupsen/logrus"
+
+	"github.com/cilium/cilium/pkg/lock"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+const (
+	// sniFailureWindow is the window over which SNI resolution failures are
+	// accounted.
+	sniFailureWindow = 1 * time.Minute
+
+	// sniFailureThreshold is the number of failures within a window upon
+	// which an anomaly is reported.
+	sniFailureThreshold = 10
+
+	// sniSampleSize is the number of offending SNIs kept per window for
+	// reporting.
+	sniSampleSize = 5
+)
+
+// sniFailureTracker tracks the rate of SNI-to-identity resolution failures
+// on inbound handshake connections. Occasional failures are expected (e.g.
+// right after an endpoint moved), but an unusual rate points to port
+// scanning or a version mismatch between nodes and is reported as a health
+// degradation, with a sample of the offending SNIs.
+type sniFailureTracker struct {
+	log    logrus.FieldLogger
+	health cell.Health
+
+	mutex       lock.Mutex
+	windowStart time.Time
+	failures    int
+	samples     []string
+}
+
+func newSNIFailureTracker(log logrus.FieldLogger, health cell.Health) *sniFailureTracker {
+	return &sniFailureTracker{
+		log:    log,
+		health: health,
+	}
+}
+
+// recordFailure accounts a failed SNI resolution and reports an anomaly once
+// the failure rate of the current window crosses the threshold.
+func (t *sniFailureTracker) recordFailure(sni string, now time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if now.Sub(t.windowStart) >= sniFailureWindow {
+		t.windowStart = now
+		t.failures = 0
+		t.samples = t.samples[:0]
+	}
+
+	t.failures++
+	if len(t.samples) < sniSampleSize {
+		t.samples = append(t.samples, sni)
+	}
+
+	// Report once per window, when the threshold is crossed.
+	if t.failures == sniFailureThreshold {
+		t.log.WithFields(logrus.Fields{
+			"failures":    t.failures,
+			"window":      sniFailureWindow,
+			"sampledSNIs": strings.Join(t.samples, ", "),
+		}).Warning("Unusual rate of unknown SNIs on inbound handshake connections, possible scanning or version mismatch")
+
+		if t.health != nil {
+			t.health.Degraded("Unusual rate of unknown SNIs on inbound handshake connections",
+				fmt.Errorf("%d failures within %s, sampled SNIs: %s", t.failures, sniFailureWindow, strings.Join(t.samples, ", ")))
+		}
+	}
+}
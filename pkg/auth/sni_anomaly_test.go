@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func Test_sniFailureTracker_recordFailure(t *testing.T) {
+	tracker := newSNIFailureTracker(logrus.New(), nil)
+
+	now := time.Now()
+
+	for i := 0; i < sniFailureThreshold; i++ {
+		tracker.recordFailure(fmt.Sprintf("sni-%d.test.cilium", i), now)
+	}
+
+	if tracker.failures != sniFailureThreshold {
+		t.Fatalf("got %d failures, want %d", tracker.failures, sniFailureThreshold)
+	}
+
+	// Only a bounded sample of the offending SNIs is kept.
+	if len(tracker.samples) != sniSampleSize {
+		t.Fatalf("got %d samples, want %d", len(tracker.samples), sniSampleSize)
+	}
+	if tracker.samples[0] != "sni-0.test.cilium" {
+		t.Fatalf("got first sample %q, want %q", tracker.samples[0], "sni-0.test.cilium")
+	}
+
+	// A failure past the window resets the accounting.
+	tracker.recordFailure("late.test.cilium", now.Add(2*sniFailureWindow))
+	if tracker.failures != 1 {
+		t.Fatalf("got %d failures after window reset, want 1", tracker.failures)
+	}
+	if len(tracker.samples) != 1 || tracker.samples[0] != "late.test.cilium" {
+		t.Fatalf("got samples %v after window reset, want only the late SNI", tracker.samples)
+	}
+}
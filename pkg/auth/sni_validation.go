@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// maxSNILength bounds the SNI hostnames accepted on inbound handshake
+	// connections, matching the DNS name length limit.
+	maxSNILength = 253
+
+	// maxSNILabelLength is the DNS label length limit.
+	maxSNILabelLength = 63
+)
+
+// validateSNIFormat rejects SNI values that are not well-formed DNS names
+// before they reach the SNI-to-identity codec of the certificate provider.
+// The TLS stack does not restrict the SNI beyond basic syntax, so this
+// enforces the stricter shape mutual auth expects and guards the provider
+// against oversized or otherwise odd inputs from malformed peers.
+func validateSNIFormat(sni string) error {
+	if sni == "" {
+		return errors.New("SNI is empty")
+	}
+	if len(sni) > maxSNILength {
+		return fmt.Errorf("SNI exceeds %d characters", maxSNILength)
+	}
+
+	for _, label := range strings.Split(sni, ".") {
+		if label == "" {
+			return errors.New("SNI contains an empty label")
+		}
+		if len(label) > maxSNILabelLength {
+			return fmt.Errorf("SNI label exceeds %d characters", maxSNILabelLength)
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return errors.New("SNI label starts or ends with a hyphen")
+		}
+		for i := 0; i < len(label); i++ {
+			c := label[i]
+			if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '-' {
+				return fmt.Errorf("SNI contains invalid character %q", c)
+			}
+		}
+	}
+
+	return nil
+}
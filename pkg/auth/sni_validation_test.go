@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_validateSNIFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		sni     string
+		wantErr bool
+	}{
+		{
+			name: "accept canonical mutual auth SNI",
+			sni:  "1234.test.cilium.io",
+		},
+		{
+			name:    "reject empty SNI",
+			sni:     "",
+			wantErr: true,
+		},
+		{
+			name:    "reject oversized SNI",
+			sni:     strings.Repeat("a", maxSNILength+1),
+			wantErr: true,
+		},
+		{
+			name:    "reject oversized label",
+			sni:     strings.Repeat("a", maxSNILabelLength+1) + ".test.cilium.io",
+			wantErr: true,
+		},
+		{
+			name:    "reject empty label",
+			sni:     "1234..test.cilium.io",
+			wantErr: true,
+		},
+		{
+			name:    "reject trailing dot",
+			sni:     "1234.test.cilium.io.",
+			wantErr: true,
+		},
+		{
+			name:    "reject label with leading hyphen",
+			sni:     "1234.-test.cilium.io",
+			wantErr: true,
+		},
+		{
+			name:    "reject invalid characters",
+			sni:     "1234.test.cilium.io/../escape",
+			wantErr: true,
+		},
+		{
+			name:    "reject non-ASCII characters",
+			sni:     "1234.test.cïlium.io",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateSNIFormat(tt.sni); (err != nil) != tt.wantErr {
+				t.Errorf("validateSNIFormat(%q) error = %v, wantErr %v", tt.sni, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func FuzzValidateSNIFormat(f *testing.F) {
+	f.Add("1234.test.cilium.io")
+	f.Add("")
+	f.Add("..")
+	f.Add(strings.Repeat("a", maxSNILength+1))
+	f.Add("1234.test.cilium.io\x00")
+
+	f.Fuzz(func(t *testing.T, sni string) {
+		if err := validateSNIFormat(sni); err != nil {
+			return
+		}
+
+		// Whatever passes validation must be a bounded DNS name made up of
+		// non-empty alphanumeric-and-hyphen labels.
+		if len(sni) == 0 || len(sni) > maxSNILength {
+			t.Fatalf("accepted SNI %q violates the length bounds", sni)
+		}
+		for _, label := range strings.Split(sni, ".") {
+			if len(label) == 0 || len(label) > maxSNILabelLength {
+				t.Fatalf("accepted SNI %q contains a label violating the length bounds", sni)
+			}
+			for i := 0; i < len(label); i++ {
+				c := label[i]
+				if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '-' {
+					t.Fatalf("accepted SNI %q contains invalid character %q", sni, c)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,204 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package spire
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/cilium/hive/cell"
+
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// bundleEndpointTimeout bounds a single fetch from a SPIFFE bundle endpoint.
+const bundleEndpointTimeout = 10 * time.Second
+
+// maxBundleSize bounds the size of a fetched bundle document, so a
+// misbehaving endpoint cannot exhaust the agent's memory.
+const maxBundleSize = 1 << 20 // 1 MiB
+
+// spiffeBundleDoc is the SPIFFE bundle format served by https_web bundle
+// endpoints: a JWK set whose x509-svid keys carry the CA certificates of
+// the trust domain, plus an optional refresh hint.
+type spiffeBundleDoc struct {
+	Keys []struct {
+		Use string   `json:"use"`
+		X5C []string `json:"x5c"`
+	} `json:"keys"`
+	RefreshHint int64 `json:"spiffe_refresh_hint"`
+}
+
+// parseBundleEndpoints converts the configured "<trust-domain>=<URL>"
+// mappings into a map of trust domain to bundle endpoint URL.
+func parseBundleEndpoints(endpoints []string) (map[string]string, error) {
+	parsed := make(map[string]string, len(endpoints))
+	for _, endpoint := range endpoints {
+		trustDomain, url, found := strings.Cut(endpoint, "=")
+		if !found || trustDomain == "" || !strings.HasPrefix(url, "https://") {
+			return nil, fmt.Errorf("invalid bundle endpoint %q, expected format <trust-domain>=https://<endpoint>", endpoint)
+		}
+		parsed[trustDomain] = url
+	}
+	return parsed, nil
+}
+
+// parseSPIFFEBundle extracts the CA certificates and the refresh hint from
+// a SPIFFE bundle document.
+func parseSPIFFEBundle(doc []byte) ([]*x509.Certificate, time.Duration, error) {
+	var bundle spiffeBundleDoc
+	if err := json.Unmarshal(doc, &bundle); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse bundle document: %w", err)
+	}
+
+	var roots []*x509.Certificate
+	for _, key := range bundle.Keys {
+		if key.Use != "x509-svid" {
+			continue
+		}
+		for _, der := range key.X5C {
+			raw, err := base64.StdEncoding.DecodeString(der)
+			if err != nil {
+				return nil, 0, fmt.Errorf("failed to decode x5c certificate: %w", err)
+			}
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return nil, 0, fmt.Errorf("failed to parse x5c certificate: %w", err)
+			}
+			roots = append(roots, cert)
+		}
+	}
+	if len(roots) == 0 {
+		return nil, 0, fmt.Errorf("bundle document contains no x509-svid keys")
+	}
+
+	return roots, time.Duration(bundle.RefreshHint) * time.Second, nil
+}
+
+// fetchBundleEndpoint fetches and parses the bundle document of the given
+// SPIFFE bundle endpoint. The https_web profile authenticates the endpoint
+// with web PKI, so the default TLS roots apply.
+func (s *SpireDelegateClient) fetchBundleEndpoint(ctx context.Context, url string) ([]*x509.Certificate, time.Duration, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, bundleEndpointTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("bundle endpoint returned status %s", resp.Status)
+	}
+	doc, err := io.ReadAll(io.LimitReader(resp.Body, maxBundleSize))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return parseSPIFFEBundle(doc)
+}
+
+// refreshBundleEndpoint periodically fetches the trust bundle of a federated
+// trust domain from its bundle endpoint. The endpoint's refresh hint decides
+// the fetch interval, falling back to the configured interval when the
+// endpoint does not provide one. A failed fetch keeps the previously fetched
+// bundle, so transient endpoint outages do not break running federations.
+func (s *SpireDelegateClient) refreshBundleEndpoint(ctx context.Context, trustDomain, url string) {
+	// Each federated trust domain reports under its own health scope, so a
+	// broken endpoint does not mask the status of the others.
+	var health cell.Health
+	if s.health != nil {
+		health = s.health.NewScope("federated-bundle-" + trustDomain)
+	}
+
+	for {
+		interval := s.cfg.SpiffeBundleRefreshInterval
+
+		roots, refreshHint, err := s.fetchBundleEndpoint(ctx, url)
+		if err != nil {
+			s.log.
+				WithError(err).
+				WithField("trust_domain", trustDomain).
+				Error("Failed to fetch federated trust bundle, keeping the previous bundle")
+			if health != nil {
+				health.Degraded(fmt.Sprintf("Failed to fetch trust bundle of %s", trustDomain), err)
+			}
+		} else {
+			if refreshHint > 0 {
+				interval = refreshHint
+			}
+			s.storeFederatedBundle(trustDomain, roots)
+			if health != nil {
+				health.OK(fmt.Sprintf("Trust bundle of %s is up to date", trustDomain))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// storeFederatedBundle updates the cached bundle of a federated trust domain
+// and rebuilds the trust bundle if its CA certificates changed.
+func (s *SpireDelegateClient) storeFederatedBundle(trustDomain string, roots []*x509.Certificate) {
+	s.federatedBundleMutex.Lock()
+	changed := !equalRoots(s.federatedBundles[trustDomain], roots)
+	if changed {
+		s.federatedBundles[trustDomain] = roots
+	}
+	s.federatedBundleMutex.Unlock()
+
+	if changed {
+		s.log.
+			WithField("trust_domain", trustDomain).
+			WithField("nr_of_certificates", len(roots)).
+			Info("Federated trust bundle updated")
+		s.rebuildTrustBundle()
+	}
+}
+
+// rebuildTrustBundle rebuilds the trust bundle served by GetTrustBundle from
+// the local SPIRE bundle and the fetched federated bundles. A fresh pool is
+// built on every change, so consumers caching the pool by identity pick the
+// rotation up.
+func (s *SpireDelegateClient) rebuildTrustBundle() {
+	pool := x509.NewCertPool()
+	for _, cert := range s.localRoots {
+		pool.AddCert(cert)
+	}
+	s.federatedBundleMutex.RLock()
+	for _, roots := range s.federatedBundles {
+		for _, cert := range roots {
+			pool.AddCert(cert)
+		}
+	}
+	s.federatedBundleMutex.RUnlock()
+	s.trustBundle = pool
+}
+
+func equalRoots(a, b []*x509.Certificate) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package spire
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_parseBundleEndpoints(t *testing.T) {
+	tests := []struct {
+		name      string
+		endpoints []string
+		want      map[string]string
+		wantErr   bool
+	}{
+		{
+			name:      "no endpoints",
+			endpoints: nil,
+			want:      map[string]string{},
+		},
+		{
+			name:      "valid endpoints",
+			endpoints: []string{"other.cilium.io=https://spire.other.cilium.io/bundle"},
+			want:      map[string]string{"other.cilium.io": "https://spire.other.cilium.io/bundle"},
+		},
+		{
+			name:      "error on missing URL",
+			endpoints: []string{"other.cilium.io"},
+			wantErr:   true,
+		},
+		{
+			name:      "error on non-https URL",
+			endpoints: []string{"other.cilium.io=http://spire.other.cilium.io/bundle"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseBundleEndpoints(tt.endpoints)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseBundleEndpoints() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("parseBundleEndpoints() = %v, want %v", got, tt.want)
+			}
+			for trustDomain, url := range tt.want {
+				if got[trustDomain] != url {
+					t.Errorf("parseBundleEndpoints()[%s] = %v, want %v", trustDomain, got[trustDomain], url)
+				}
+			}
+		})
+	}
+}
+
+// newTestCA generates a self-signed CA certificate for bundle tests.
+func newTestCA(t *testing.T, commonName string) *x509.Certificate {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 512)
+	if err != nil {
+		t.Fatalf("failed to generate CA key: %v", err)
+	}
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: commonName},
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		SerialNumber:          big.NewInt(1),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to sign CA certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse CA certificate: %v", err)
+	}
+	return cert
+}
+
+// bundleDocFor returns a SPIFFE bundle document carrying the given CA
+// certificate as an x509-svid key.
+func bundleDocFor(ca *x509.Certificate, refreshHint int64) string {
+	return fmt.Sprintf(`{
+		"keys": [
+			{"use": "jwt-svid", "kty": "RSA"},
+			{"use": "x509-svid", "kty": "RSA", "x5c": [%q]}
+		],
+		"spiffe_refresh_hint": %d
+	}`, base64.StdEncoding.EncodeToString(ca.Raw), refreshHint)
+}
+
+func Test_parseSPIFFEBundle(t *testing.T) {
+	ca := newTestCA(t, "other.cilium.io")
+
+	roots, refreshHint, err := parseSPIFFEBundle([]byte(bundleDocFor(ca, 300)))
+	if err != nil {
+		t.Fatalf("parseSPIFFEBundle() error = %v", err)
+	}
+	if len(roots) != 1 || !roots[0].Equal(ca) {
+		t.Errorf("parseSPIFFEBundle() roots = %v, want the CA certificate", roots)
+	}
+	if refreshHint != 300*time.Second {
+		t.Errorf("parseSPIFFEBundle() refreshHint = %v, want %v", refreshHint, 300*time.Second)
+	}
+
+	if _, _, err := parseSPIFFEBundle([]byte("not json")); err == nil {
+		t.Errorf("parseSPIFFEBundle() expected error for malformed document")
+	}
+	if _, _, err := parseSPIFFEBundle([]byte(`{"keys": [{"use": "jwt-svid"}]}`)); err == nil {
+		t.Errorf("parseSPIFFEBundle() expected error for document without x509-svid keys")
+	}
+}
+
+func TestSpireDelegateClient_federatedBundleRefresh(t *testing.T) {
+	localCA := newTestCA(t, "test.cilium.io")
+	federatedCA := newTestCA(t, "other.cilium.io")
+
+	failing := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, bundleDocFor(federatedCA, 0))
+	}))
+	defer srv.Close()
+
+	s := &SpireDelegateClient{
+		cfg: SpireDelegateConfig{
+			SpiffeTrustDomain: "test.cilium.io",
+		},
+		log:              log,
+		localRoots:       []*x509.Certificate{localCA},
+		federatedBundles: map[string][]*x509.Certificate{},
+	}
+	s.rebuildTrustBundle()
+
+	verifies := func(ca *x509.Certificate) bool {
+		bundle, err := s.GetTrustBundle()
+		if err != nil {
+			t.Fatalf("GetTrustBundle() error = %v", err)
+		}
+		_, err = ca.Verify(x509.VerifyOptions{Roots: bundle})
+		return err == nil
+	}
+
+	// Before the first fetch only the local trust domain verifies.
+	if !verifies(localCA) {
+		t.Errorf("local CA does not verify against the trust bundle")
+	}
+	if verifies(federatedCA) {
+		t.Errorf("federated CA verifies before its bundle got fetched")
+	}
+
+	roots, _, err := s.fetchBundleEndpoint(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("fetchBundleEndpoint() error = %v", err)
+	}
+	s.storeFederatedBundle("other.cilium.io", roots)
+
+	if !verifies(localCA) || !verifies(federatedCA) {
+		t.Errorf("local and federated CA must verify after the bundle got fetched")
+	}
+
+	// A failing endpoint keeps the previously fetched bundle.
+	failing = true
+	if _, _, err := s.fetchBundleEndpoint(context.Background(), srv.URL); err == nil {
+		t.Errorf("fetchBundleEndpoint() expected error for failing endpoint")
+	}
+	if !verifies(federatedCA) {
+		t.Errorf("federated CA must still verify after a failed refresh")
+	}
+}
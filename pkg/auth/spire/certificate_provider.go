@@ -10,13 +10,28 @@ import (
 	"fmt"
 	"strings"
 
+	delegatedidentityv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/agent/delegatedidentity/v1"
+
 	"github.com/cilium/cilium/api/v1/models"
 	"github.com/cilium/cilium/pkg/auth/certs"
+	"github.com/cilium/cilium/pkg/defaults"
 	"github.com/cilium/cilium/pkg/identity"
 )
 
 // This file implements the CertificateProvider interface
 
+// SNI and SPIFFE ID encoding versions. Version 1 encodes the numeric
+// identity as a bare decimal label ("<id>.<trust-domain>", SPIFFE path
+// "/identity/<id>"), version 2 prefixes the label with its version
+// ("v2-<id>.<trust-domain>", SPIFFE path "/identity/v2/<id>"). Inbound
+// values of both versions are always accepted, so a new encoding can roll
+// out node by node without breaking handshakes mid-upgrade; the configured
+// version only selects the encoding emitted for outbound handshakes.
+const (
+	sniEncodingV1 = 1
+	sniEncodingV2 = 2
+)
+
 func (s *SpireDelegateClient) GetTrustBundle() (*x509.CertPool, error) {
 	if s.trustBundle == nil {
 		return nil, errors.New("trust bundle not yet available")
@@ -24,12 +39,64 @@ func (s *SpireDelegateClient) GetTrustBundle() (*x509.CertPool, error) {
 	return s.trustBundle, nil
 }
 
+// GetTrustBundleForDomain gives the CA pool of a single trust domain: the
+// local SPIRE roots for the accepted trust domains, the fetched federated
+// bundle for a federated trust domain. Peer certificates are verified
+// against the pool of their own trust domain only, so the CA of one
+// federated trust domain cannot issue identities of another.
+func (s *SpireDelegateClient) GetTrustBundleForDomain(trustDomain string) (*x509.CertPool, error) {
+	if s.isAcceptedTrustDomain(trustDomain) {
+		if len(s.localRoots) == 0 {
+			return nil, errors.New("trust bundle not yet available")
+		}
+		pool := x509.NewCertPool()
+		for _, cert := range s.localRoots {
+			pool.AddCert(cert)
+		}
+		return pool, nil
+	}
+
+	s.federatedBundleMutex.RLock()
+	roots, ok := s.federatedBundles[trustDomain]
+	s.federatedBundleMutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown trust domain %q", trustDomain)
+	}
+	pool := x509.NewCertPool()
+	for _, cert := range roots {
+		pool.AddCert(cert)
+	}
+	return pool, nil
+}
+
 func (s *SpireDelegateClient) GetCertificateForIdentity(id identity.NumericIdentity) (*tls.Certificate, error) {
 	spiffeID := s.sniToSPIFFEID(id)
+
+	// Hold the store lock until the private key has been parsed into its own
+	// memory: once an SVID is dropped from the store, its raw key bytes are
+	// zeroized under the write lock.
 	s.svidStoreMutex.RLock()
-	svid, ok := s.svidStore[spiffeID]
-	s.svidStoreMutex.RUnlock()
-	if !ok {
+	defer s.svidStoreMutex.RUnlock()
+
+	// With tenant trust domains, the SVID of an identity may be issued under
+	// a namespace-specific trust domain instead of the primary one. Prefer
+	// the primary trust domain, then fall back to the others. Within each
+	// trust domain, the SVID may be registered under either path encoding
+	// version.
+	var svid *delegatedidentityv1.X509SVIDWithKey
+lookup:
+	for _, trustDomain := range s.acceptedTrustDomains() {
+		for _, key := range []string{
+			"spiffe://" + trustDomain + "/identity/" + id.String(),
+			"spiffe://" + trustDomain + "/identity/v2/" + id.String(),
+		} {
+			if cand, ok := s.svidStore[key]; ok {
+				svid = cand
+				break lookup
+			}
+		}
+	}
+	if svid == nil {
 		return nil, fmt.Errorf("no SPIFFE ID for %s", spiffeID)
 	}
 
@@ -66,42 +133,128 @@ func (s *SpireDelegateClient) GetCertificateForIdentity(id identity.NumericIdent
 }
 
 func (s *SpireDelegateClient) sniToSPIFFEID(id identity.NumericIdentity) string {
+	if s.cfg.SpiffeSNIEncodingVersion == sniEncodingV2 {
+		return "spiffe://" + s.cfg.SpiffeTrustDomain + "/identity/v2/" + id.String()
+	}
 	return "spiffe://" + s.cfg.SpiffeTrustDomain + "/identity/" + id.String()
 }
 
+func (s *SpireDelegateClient) NumericIdentityToSPIFFEID(id identity.NumericIdentity) string {
+	return s.sniToSPIFFEID(id)
+}
+
+// acceptedTrustDomains returns the primary trust domain followed by the
+// compat trust domains still accepted during a trust domain migration and
+// the tenant trust domains namespace-scoped identities are issued under.
+func (s *SpireDelegateClient) acceptedTrustDomains() []string {
+	domains := append([]string{s.cfg.SpiffeTrustDomain}, s.cfg.SpiffeCompatTrustDomains...)
+	return append(domains, s.cfg.SpiffeTenantTrustDomains...)
+}
+
 func (s *SpireDelegateClient) spiffeIDToNumericIdentity(spiffeID string) (identity.NumericIdentity, error) {
-	prefix := "spiffe://" + s.cfg.SpiffeTrustDomain + "/identity/"
-	if !strings.HasPrefix(spiffeID, prefix) {
-		return 0, fmt.Errorf("SPIFFE ID %s does not belong to our trust domain or is not in the valid format", spiffeID)
+	for _, trustDomain := range s.acceptedTrustDomains() {
+		prefix := "spiffe://" + trustDomain + "/identity/"
+		if strings.HasPrefix(spiffeID, prefix) {
+			// Both path encoding versions are accepted during a migration.
+			label := strings.TrimPrefix(spiffeID, prefix)
+			label = strings.TrimPrefix(label, "v2/")
+			return parseIdentityLabel(label)
+		}
 	}
 
-	idStr := strings.TrimPrefix(spiffeID, prefix)
-	return identity.ParseNumericIdentity(idStr)
+	return 0, fmt.Errorf("SPIFFE ID %s does not belong to our trust domain or is not in the valid format", spiffeID)
 }
 
-func (s *SpireDelegateClient) ValidateIdentity(id identity.NumericIdentity, cert *x509.Certificate) (bool, error) {
-	spiffeID := s.sniToSPIFFEID(id)
+// parseIdentityLabel parses the numeric identity encoded in an SNI or
+// SPIFFE ID. Only the canonical decimal encoding produced by
+// NumericIdentityToSNI is accepted: ParseNumericIdentity alone would also
+// take base prefixes ("0x10"), leading zeros as octal and sign characters,
+// which would allow multiple spellings to resolve to the same identity.
+func parseIdentityLabel(label string) (identity.NumericIdentity, error) {
+	if label == "" || (len(label) > 1 && label[0] == '0') {
+		return 0, fmt.Errorf("%q is not a canonical decimal identity", label)
+	}
+	for i := 0; i < len(label); i++ {
+		if label[i] < '0' || label[i] > '9' {
+			return 0, fmt.Errorf("%q is not a canonical decimal identity", label)
+		}
+	}
+
+	return identity.ParseNumericIdentity(label)
+}
 
+func (s *SpireDelegateClient) ValidateIdentity(id identity.NumericIdentity, cert *x509.Certificate) (bool, error) {
 	// Spec: SVIDs containing more than one URI SAN MUST be rejected
 	if len(cert.URIs) != 1 {
 		return false, errors.New("SPIFFE IDs must have exactly one URI SAN")
 	}
 
-	return cert.URIs[0].String() == spiffeID, nil
+	// Identities mapped to an external system are validated against the
+	// configured SPIFFE ID instead of the Cilium identity encoding.
+	if spiffeID, ok := s.externalIDs[id]; ok {
+		return cert.URIs[0].String() == spiffeID, nil
+	}
+
+	for _, trustDomain := range s.acceptedTrustDomains() {
+		// Both path encoding versions are accepted during a migration.
+		if cert.URIs[0].String() == "spiffe://"+trustDomain+"/identity/"+id.String() ||
+			cert.URIs[0].String() == "spiffe://"+trustDomain+"/identity/v2/"+id.String() {
+			return true, nil
+		}
+	}
+
+	return false, nil
 }
 
 func (s *SpireDelegateClient) NumericIdentityToSNI(id identity.NumericIdentity) string {
-	return id.String() + "." + s.cfg.SpiffeTrustDomain
+	label := id.String()
+	if s.cfg.SpiffeSNIEncodingVersion == sniEncodingV2 {
+		label = "v2-" + label
+	}
+	if cluster := s.sniClusterLabel(); cluster != "" {
+		return label + "." + cluster + "." + s.cfg.SpiffeTrustDomain
+	}
+	return label + "." + s.cfg.SpiffeTrustDomain
+}
+
+// sniClusterLabel returns the cluster label qualifying the identity in
+// outbound SNIs, or "" when no cluster label is emitted. Identities are
+// global across a ClusterMesh, so the identity alone would be ambiguous
+// between clusters; carrying the cluster name lets cross-cluster peers
+// attribute the handshake to the originating cluster. The default cluster
+// name is not emitted, which keeps the single-cluster encoding unchanged.
+func (s *SpireDelegateClient) sniClusterLabel() string {
+	if s.clusterName == defaults.ClusterName {
+		return ""
+	}
+	return s.clusterName
 }
 
 func (s *SpireDelegateClient) SNIToNumericIdentity(sni string) (identity.NumericIdentity, error) {
-	suffix := "." + s.cfg.SpiffeTrustDomain
-	if !strings.HasSuffix(sni, suffix) {
-		return 0, fmt.Errorf("SNI %s does not belong to our trust domain", sni)
+	for _, trustDomain := range s.acceptedTrustDomains() {
+		suffix := "." + trustDomain
+		if strings.HasSuffix(sni, suffix) {
+			label := strings.TrimSuffix(sni, suffix)
+			// Peers in other clusters of a ClusterMesh qualify the identity
+			// with their cluster name. Identities are global across the mesh,
+			// so the cluster label only has to be well formed - it does not
+			// have to be known locally.
+			if dot := strings.IndexByte(label, '.'); dot >= 0 {
+				cluster := label[dot+1:]
+				if cluster == "" || strings.Contains(cluster, ".") {
+					return 0, fmt.Errorf("SNI %s carries a malformed cluster label", sni)
+				}
+				label = label[:dot]
+			}
+			// Both encoding versions are accepted during a migration. A
+			// version 1 label never starts with "v2-", as only canonical
+			// decimal labels parse.
+			label = strings.TrimPrefix(label, "v2-")
+			return parseIdentityLabel(label)
+		}
 	}
 
-	idStr := strings.TrimSuffix(sni, suffix)
-	return identity.ParseNumericIdentity(idStr)
+	return 0, fmt.Errorf("SNI %s does not belong to our trust domain", sni)
 }
 
 func (s *SpireDelegateClient) SubscribeToRotatedIdentities() <-chan certs.CertificateRotationEvent {
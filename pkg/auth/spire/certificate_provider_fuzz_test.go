@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package spire
+
+import (
+	"testing"
+)
+
+func FuzzSNIToNumericIdentity(f *testing.F) {
+	f.Add("1234.test.cilium.io")
+	f.Add("hacker.test.cilium.io")
+	f.Add("0x10.test.cilium.io")
+	f.Add("007.test.cilium.io")
+	f.Add(".test.cilium.io")
+	f.Add("99999999999999999999.test.cilium.io")
+
+	f.Fuzz(func(t *testing.T, sni string) {
+		s := &SpireDelegateClient{
+			cfg: SpireDelegateConfig{
+				SpiffeTrustDomain: "test.cilium.io",
+			},
+			log: log,
+		}
+
+		id, err := s.SNIToNumericIdentity(sni)
+		if err != nil {
+			return
+		}
+
+		// Accepted SNIs must round-trip back to the exact same SNI, so that
+		// each identity has a single valid encoding.
+		if got := s.NumericIdentityToSNI(id); got != sni {
+			t.Fatalf("SNI %q resolved to identity %d, which encodes back to %q", sni, id, got)
+		}
+	})
+}
+
+func FuzzSpiffeIDToNumericIdentity(f *testing.F) {
+	f.Add("spiffe://test.cilium.io/identity/1234")
+	f.Add("spiffe://test.cilium.io/identity/0x10")
+	f.Add("spiffe://test.cilium.io/identity/")
+	f.Add("spiffe://other.example.com/identity/1234")
+
+	f.Fuzz(func(t *testing.T, spiffeID string) {
+		s := &SpireDelegateClient{
+			cfg: SpireDelegateConfig{
+				SpiffeTrustDomain: "test.cilium.io",
+			},
+			log: log,
+		}
+
+		id, err := s.spiffeIDToNumericIdentity(spiffeID)
+		if err != nil {
+			return
+		}
+
+		if got := s.sniToSPIFFEID(id); got != spiffeID {
+			t.Fatalf("SPIFFE ID %q resolved to identity %d, which encodes back to %q", spiffeID, id, got)
+		}
+	})
+}
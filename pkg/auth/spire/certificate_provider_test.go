@@ -31,9 +31,11 @@ func TestSpireDelegateClient_NumericIdentityToSNI(t *testing.T) {
 		id identity.NumericIdentity
 	}
 	tests := []struct {
-		name string
-		args args
-		want string
+		name    string
+		args    args
+		version int
+		cluster string
+		want    string
 	}{
 		{
 			name: "convert valid numeric identity",
@@ -42,14 +44,49 @@ func TestSpireDelegateClient_NumericIdentityToSNI(t *testing.T) {
 			},
 			want: "1234.test.cilium.io",
 		},
+		{
+			name: "convert valid numeric identity with v2 encoding",
+			args: args{
+				id: 1234,
+			},
+			version: sniEncodingV2,
+			want:    "v2-1234.test.cilium.io",
+		},
+		{
+			name: "qualify the identity with a non-default cluster name",
+			args: args{
+				id: 1234,
+			},
+			cluster: "cluster1",
+			want:    "1234.cluster1.test.cilium.io",
+		},
+		{
+			name: "qualify the v2 identity with a non-default cluster name",
+			args: args{
+				id: 1234,
+			},
+			version: sniEncodingV2,
+			cluster: "cluster1",
+			want:    "v2-1234.cluster1.test.cilium.io",
+		},
+		{
+			name: "do not qualify the identity with the default cluster name",
+			args: args{
+				id: 1234,
+			},
+			cluster: "default",
+			want:    "1234.test.cilium.io",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &SpireDelegateClient{
 				cfg: SpireDelegateConfig{
-					SpiffeTrustDomain: "test.cilium.io",
+					SpiffeTrustDomain:        "test.cilium.io",
+					SpiffeSNIEncodingVersion: tt.version,
 				},
-				log: log,
+				clusterName: tt.cluster,
+				log:         log,
 			}
 			if got := s.NumericIdentityToSNI(tt.args.id); got != tt.want {
 				t.Errorf("SpireDelegateClient.NumericIdentityToSNI() = %v, want %v", got, tt.want)
@@ -92,6 +129,54 @@ func TestSpireDelegateClient_SNIToNumericIdentity(t *testing.T) {
 			want:    0,
 			wantErr: true,
 		},
+		{
+			name: "convert valid v2 SNI",
+			args: args{
+				sni: "v2-1234.test.cilium.io",
+			},
+			want:    1234,
+			wantErr: false,
+		},
+		{
+			name: "error on convert invalid v2 SNI under trust domain",
+			args: args{
+				sni: "v2-hacker.test.cilium.io",
+			},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "convert valid cluster-qualified SNI",
+			args: args{
+				sni: "1234.cluster1.test.cilium.io",
+			},
+			want:    1234,
+			wantErr: false,
+		},
+		{
+			name: "convert valid cluster-qualified v2 SNI",
+			args: args{
+				sni: "v2-1234.cluster1.test.cilium.io",
+			},
+			want:    1234,
+			wantErr: false,
+		},
+		{
+			name: "error on convert SNI with more than one cluster label",
+			args: args{
+				sni: "1234.extra.cluster1.test.cilium.io",
+			},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "error on convert cluster-qualified SNI with invalid identity",
+			args: args{
+				sni: "hacker.cluster1.test.cilium.io",
+			},
+			want:    0,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -118,9 +203,10 @@ func TestSpireDelegateClient_sniToSPIFFEID(t *testing.T) {
 		id identity.NumericIdentity
 	}
 	tests := []struct {
-		name string
-		args args
-		want string
+		name    string
+		args    args
+		version int
+		want    string
 	}{
 		{
 			name: "convert valid numeric identity",
@@ -129,12 +215,21 @@ func TestSpireDelegateClient_sniToSPIFFEID(t *testing.T) {
 			},
 			want: "spiffe://test.cilium.io/identity/1234",
 		},
+		{
+			name: "convert valid numeric identity with v2 encoding",
+			args: args{
+				id: 1234,
+			},
+			version: sniEncodingV2,
+			want:    "spiffe://test.cilium.io/identity/v2/1234",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &SpireDelegateClient{
 				cfg: SpireDelegateConfig{
-					SpiffeTrustDomain: "test.cilium.io",
+					SpiffeTrustDomain:        "test.cilium.io",
+					SpiffeSNIEncodingVersion: tt.version,
 				},
 				log: log,
 			}
@@ -147,6 +242,7 @@ func TestSpireDelegateClient_sniToSPIFFEID(t *testing.T) {
 
 func TestSpireDelegateClient_ValidateIdentity(t *testing.T) {
 	urlFor1234, _ := url.Parse("spiffe://test.cilium.io/identity/1234")
+	urlFor1234V2, _ := url.Parse("spiffe://test.cilium.io/identity/v2/1234")
 	urlFor9999, _ := url.Parse("spiffe://test.cilium.io/identity/9999")
 
 	type args struct {
@@ -169,6 +265,16 @@ func TestSpireDelegateClient_ValidateIdentity(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "validate with correct v2 SPIFFE ID",
+			args: args{
+				id: 1234,
+				cert: &x509.Certificate{
+					URIs: []*url.URL{urlFor1234V2},
+				},
+			},
+			want: true,
+		},
 		{
 			name: "not validate with incorrect SPIFFE ID",
 			args: args{
@@ -271,6 +377,75 @@ func TestSpireDelegateClient_GetTrustBundle(t *testing.T) {
 	}
 }
 
+func TestSpireDelegateClient_GetTrustBundleForDomain(t *testing.T) {
+	localRoot := &x509.Certificate{
+		Raw: []byte("local"),
+		Subject: pkix.Name{
+			CommonName: "local.test.cilium.io",
+		},
+		IsCA: true,
+	}
+	federatedRoot := &x509.Certificate{
+		Raw: []byte("federated"),
+		Subject: pkix.Name{
+			CommonName: "federated.test.cilium.io",
+		},
+		IsCA: true,
+	}
+
+	s := &SpireDelegateClient{
+		cfg: SpireDelegateConfig{
+			SpiffeTrustDomain: "test.cilium.io",
+		},
+		log:        log,
+		localRoots: []*x509.Certificate{localRoot},
+		federatedBundles: map[string][]*x509.Certificate{
+			"other.cilium.io": {federatedRoot},
+		},
+	}
+
+	localPool := x509.NewCertPool()
+	localPool.AddCert(localRoot)
+	federatedPool := x509.NewCertPool()
+	federatedPool.AddCert(federatedRoot)
+
+	tests := []struct {
+		name        string
+		trustDomain string
+		want        *x509.CertPool
+		wantErr     bool
+	}{
+		{
+			name:        "local trust domain gives the local roots",
+			trustDomain: "test.cilium.io",
+			want:        localPool,
+		},
+		{
+			name:        "federated trust domain gives its fetched bundle",
+			trustDomain: "other.cilium.io",
+			want:        federatedPool,
+		},
+		{
+			name:        "unknown trust domain is an error",
+			trustDomain: "unknown.cilium.io",
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.GetTrustBundleForDomain(tt.trustDomain)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SpireDelegateClient.GetTrustBundleForDomain() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.want != nil && !tt.want.Equal(got) {
+				t.Errorf("SpireDelegateClient.GetTrustBundleForDomain() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestSpireDelegateClient_GetCertificateForIdentity(t *testing.T) {
 	certURL, err := url.Parse("spiffe://spiffe.cilium/identity/1234")
 	if err != nil {
@@ -398,6 +573,67 @@ func TestSpireDelegateClient_GetCertificateForIdentity(t *testing.T) {
 	}
 }
 
+func TestSpireDelegateClient_GetCertificateForIdentity_tenantTrustDomain(t *testing.T) {
+	certURL, err := url.Parse("spiffe://tenant-a.cilium.io/identity/1234")
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	leafKey, err := rsa.GenerateKey(rand.Reader, 512)
+	if err != nil {
+		t.Fatalf("failed to generate leaf key: %v", err)
+	}
+	leafCert := &x509.Certificate{
+		NotAfter:     time.Now().Add(time.Hour),
+		URIs:         []*url.URL{certURL},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		SerialNumber: big.NewInt(1),
+	}
+	leafCertBytes, err := x509.CreateCertificate(rand.Reader, leafCert, leafCert, &leafKey.PublicKey, leafKey)
+	if err != nil {
+		t.Fatalf("failed to sign leaf certificate: %v", err)
+	}
+	leafPKCS8Key, err := x509.MarshalPKCS8PrivateKey(leafKey)
+	if err != nil {
+		t.Fatalf("failed to marshal leaf key: %v", err)
+	}
+
+	s := &SpireDelegateClient{
+		cfg: SpireDelegateConfig{
+			SpiffeTrustDomain:        "test.cilium.io",
+			SpiffeTenantTrustDomains: []string{"tenant-a.cilium.io"},
+		},
+		log: log,
+		svidStore: map[string]*delegatedidentityv1.X509SVIDWithKey{
+			"spiffe://tenant-a.cilium.io/identity/1234": {
+				X509Svid: &types.X509SVID{
+					Id: &types.SPIFFEID{
+						TrustDomain: "tenant-a.cilium.io",
+						Path:        "/identity/1234",
+					},
+					CertChain: [][]byte{leafCertBytes},
+				},
+				X509SvidKey: leafPKCS8Key,
+			},
+		},
+	}
+
+	// The SVID issued under the tenant trust domain is found.
+	got, err := s.GetCertificateForIdentity(1234)
+	if err != nil {
+		t.Fatalf("SpireDelegateClient.GetCertificateForIdentity() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Certificate, [][]byte{leafCertBytes}) {
+		t.Errorf("SpireDelegateClient.GetCertificateForIdentity() = %v, want %v", got.Certificate, [][]byte{leafCertBytes})
+	}
+
+	// Without the tenant trust domain configured, the SVID is not accepted.
+	s.cfg.SpiffeTenantTrustDomains = nil
+	if _, err := s.GetCertificateForIdentity(1234); err == nil {
+		t.Errorf("SpireDelegateClient.GetCertificateForIdentity() expected error for unaccepted trust domain")
+	}
+}
+
 func TestSpireDelegateClient_SubscribeToRotatedIdentities(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -21,6 +21,8 @@ import (
 
 	"github.com/cilium/cilium/pkg/auth/certs"
 	"github.com/cilium/cilium/pkg/backoff"
+	cmtypes "github.com/cilium/cilium/pkg/clustermesh/types"
+	"github.com/cilium/cilium/pkg/identity"
 	"github.com/cilium/cilium/pkg/lock"
 	"github.com/cilium/cilium/pkg/logging"
 	"github.com/cilium/cilium/pkg/time"
@@ -30,6 +32,11 @@ type SpireDelegateClient struct {
 	cfg SpireDelegateConfig
 	log logrus.FieldLogger
 
+	// clusterName is the name of the local cluster. In a ClusterMesh, the
+	// outbound SNI encoding qualifies the identity with it, see
+	// NumericIdentityToSNI.
+	clusterName string
+
 	connectionAttempts int
 
 	stream      delegatedidentityv1.DelegatedIdentity_SubscribeToX509SVIDsClient
@@ -39,6 +46,37 @@ type SpireDelegateClient struct {
 	svidStoreMutex lock.RWMutex
 	trustBundle    *x509.CertPool
 
+	// localRoots are the CA certificates of the last bundle update received
+	// from the SPIRE agent, retained so the trust bundle can be rebuilt when
+	// a federated bundle changes.
+	localRoots []*x509.Certificate
+
+	// bundleEndpoints maps federated trust domains to the SPIFFE bundle
+	// endpoint their trust bundle is fetched from, see SpiffeBundleEndpoints.
+	bundleEndpoints      map[string]string
+	federatedBundles     map[string][]*x509.Certificate
+	federatedBundleMutex lock.RWMutex
+
+	health cell.Health
+
+	// connectionHealth reports the state of the connection to the SPIRE
+	// Delegate API, bundleHealth the staleness of the received trust
+	// bundle. Both are scopes of the module health, surfaced by
+	// "cilium-dbg status --all-health".
+	connectionHealth cell.Health
+	bundleHealth     cell.Health
+
+	// localBundleNotAfter is the latest expiration among the CA
+	// certificates of the last bundle update, localBundleReceived whether
+	// one arrived yet.
+	localBundleNotAfter time.Time
+	localBundleReceived bool
+	bundleStateMutex    lock.RWMutex
+
+	// externalIDs maps numeric identities of external systems to the SPIFFE
+	// IDs they present, see SpiffeExternalIDMappings.
+	externalIDs map[identity.NumericIdentity]string
+
 	cancelListenForUpdates context.CancelFunc
 
 	rotatedIdentitiesChan chan certs.CertificateRotationEvent
@@ -54,6 +92,42 @@ type SpireDelegateConfig struct {
 	SpireAdminSocketPath string `mapstructure:"mesh-auth-spire-admin-socket"`
 	SpiffeTrustDomain    string `mapstructure:"mesh-auth-spiffe-trust-domain"`
 	RotatedQueueSize     int    `mapstructure:"mesh-auth-rotated-identities-queue-size"`
+
+	// SpiffeSNIEncodingVersion selects the version of the SNI and SPIFFE ID
+	// encoding emitted for outbound handshakes. Inbound values of both
+	// versions are always accepted, so the encoding can be migrated node by
+	// node without breaking handshakes mid-upgrade.
+	SpiffeSNIEncodingVersion int `mapstructure:"mesh-auth-spiffe-sni-encoding-version"`
+
+	// SpiffeCompatTrustDomains are additional trust domains accepted while
+	// migrating from one trust domain to another. Certificates are always
+	// issued for SpiffeTrustDomain, but peers presenting identities from one
+	// of the compat domains are still accepted until the migration finished.
+	SpiffeCompatTrustDomains []string `mapstructure:"mesh-auth-spiffe-compat-trust-domains"`
+
+	// SpiffeTenantTrustDomains are the tenant trust domains the operator
+	// issues namespace-scoped identities under. SVIDs delivered for these
+	// trust domains are ingested next to the primary trust domain, and peers
+	// presenting identities from them are accepted.
+	SpiffeTenantTrustDomains []string `mapstructure:"mesh-auth-spiffe-tenant-trust-domains"`
+
+	// SpiffeBundleEndpoints maps federated trust domains to the SPIFFE
+	// bundle endpoint (https_web profile) their trust bundle is fetched
+	// from, in the form "<trust-domain>=https://<endpoint>". The fetched
+	// bundles are merged into the trust bundle used for peer verification.
+	SpiffeBundleEndpoints []string `mapstructure:"mesh-auth-spiffe-bundle-endpoints"`
+
+	// SpiffeBundleRefreshInterval is the interval in which federated trust
+	// bundles are refreshed when the bundle endpoint does not provide a
+	// refresh hint.
+	SpiffeBundleRefreshInterval time.Duration `mapstructure:"mesh-auth-spiffe-bundle-refresh-interval"`
+
+	// SpiffeExternalIDMappings maps numeric identities (typically
+	// CIDR-backed identities covering external systems) to the SPIFFE IDs
+	// those systems present, in the form "<identity>=<SPIFFE ID>". Peer
+	// certificates of mapped identities are validated against the configured
+	// SPIFFE ID instead of the Cilium identity encoding.
+	SpiffeExternalIDMappings []string `mapstructure:"mesh-auth-spiffe-external-ids"`
 }
 
 var Cell = cell.Module(
@@ -63,28 +137,60 @@ var Cell = cell.Module(
 	cell.Config(SpireDelegateConfig{}),
 )
 
-func newSpireDelegateClient(lc cell.Lifecycle, cfg SpireDelegateConfig, log logrus.FieldLogger) certs.CertificateProvider {
+func newSpireDelegateClient(lc cell.Lifecycle, health cell.Health, cfg SpireDelegateConfig, clusterInfo cmtypes.ClusterInfo, log logrus.FieldLogger) (*SpireDelegateClient, error) {
 	if cfg.SpireAdminSocketPath == "" {
 		log.Info("Spire Delegate API Client is disabled as no socket path is configured")
-		return nil
+		return nil, nil
+	}
+
+	externalIDs, err := parseExternalIDMappings(cfg.SpiffeExternalIDMappings)
+	if err != nil {
+		return nil, err
 	}
+
+	bundleEndpoints, err := parseBundleEndpoints(cfg.SpiffeBundleEndpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.SpiffeSNIEncodingVersion {
+	case 0, sniEncodingV1, sniEncodingV2: // 0 is the zero value, treated as version 1
+	default:
+		return nil, fmt.Errorf("unsupported SNI encoding version %d", cfg.SpiffeSNIEncodingVersion)
+	}
+
 	client := &SpireDelegateClient{
 		cfg:                   cfg,
 		log:                   log,
+		clusterName:           clusterInfo.Name,
+		health:                health,
+		externalIDs:           externalIDs,
+		bundleEndpoints:       bundleEndpoints,
+		federatedBundles:      map[string][]*x509.Certificate{},
 		svidStore:             map[string]*delegatedidentityv1.X509SVIDWithKey{},
 		rotatedIdentitiesChan: make(chan certs.CertificateRotationEvent, cfg.RotatedQueueSize),
 		logLimiter:            logging.NewLimiter(10*time.Second, 3),
 	}
+	if health != nil {
+		client.connectionHealth = health.NewScope("delegate-connection")
+		client.bundleHealth = health.NewScope("trust-bundle")
+	}
 
 	lc.Append(cell.Hook{OnStart: client.onStart, OnStop: client.onStop})
 
-	return client
+	return client, nil
 }
 
 func (cfg SpireDelegateConfig) Flags(flags *pflag.FlagSet) {
-	flags.StringVar(&cfg.SpireAdminSocketPath, "mesh-auth-spire-admin-socket", "", "The path for the SPIRE admin agent Unix socket.") // default is /run/spire/sockets/admin.sock
+	flags.StringVar(&cfg.SpireAdminSocketPath, "mesh-auth-spire-admin-socket", "", "The path for the SPIRE admin agent Unix socket ('auto' to discover it in well-known locations).") // default is /run/spire/sockets/admin.sock
 	flags.StringVar(&cfg.SpiffeTrustDomain, "mesh-auth-spiffe-trust-domain", "spiffe.cilium", "The trust domain for the SPIFFE identity.")
 	flags.IntVar(&cfg.RotatedQueueSize, "mesh-auth-rotated-identities-queue-size", 1024, "The size of the queue for signaling rotated identities.")
+	flags.IntVar(&cfg.SpiffeSNIEncodingVersion, "mesh-auth-spiffe-sni-encoding-version", sniEncodingV1, "Version of the SNI and SPIFFE ID encoding emitted for outbound handshakes (1 or 2). Inbound values of both versions are always accepted.")
+	flags.StringSliceVar(&cfg.SpiffeCompatTrustDomains, "mesh-auth-spiffe-compat-trust-domains", nil, "Additional SPIFFE trust domains accepted from peers during a trust domain migration.")
+	flags.StringSliceVar(&cfg.SpiffeTenantTrustDomains, "mesh-auth-spiffe-tenant-trust-domains", nil, "Tenant SPIFFE trust domains under which namespace-scoped identities are issued.")
+	flags.StringSliceVar(&cfg.SpiffeBundleEndpoints, "mesh-auth-spiffe-bundle-endpoints", nil, "SPIFFE bundle endpoints ('<trust-domain>=https://<endpoint>') federated trust bundles are fetched from.")
+	flags.DurationVar(&cfg.SpiffeBundleRefreshInterval, "mesh-auth-spiffe-bundle-refresh-interval", 5*time.Minute, "Interval in which federated trust bundles are refreshed if the bundle endpoint provides no refresh hint.")
+	flags.StringSliceVar(&cfg.SpiffeExternalIDMappings, "mesh-auth-spiffe-external-ids", nil, "Mappings of numeric identities to the SPIFFE IDs of external systems, in the form '<identity>=<SPIFFE ID>'.")
 }
 
 func (s *SpireDelegateClient) onStart(ctx cell.HookContext) error {
@@ -92,6 +198,12 @@ func (s *SpireDelegateClient) onStart(ctx cell.HookContext) error {
 
 	listenCtx, cancel := context.WithCancel(context.Background())
 	go s.listenForUpdates(listenCtx)
+	go s.watchBundleStaleness(listenCtx)
+	s.reportBundleStaleness()
+
+	for trustDomain, url := range s.bundleEndpoints {
+		go s.refreshBundleEndpoint(listenCtx, trustDomain, url)
+	}
 
 	s.cancelListenForUpdates = cancel
 
@@ -127,6 +239,9 @@ func (s *SpireDelegateClient) listenForUpdates(ctx context.Context) {
 			return
 		case e := <-err:
 			s.log.WithError(e).Error("Error in delegate stream, restarting")
+			if s.connectionHealth != nil {
+				s.connectionHealth.Degraded("SPIRE delegate stream interrupted, reconnecting", e)
+			}
 			time.Sleep(backoffTime.Duration(s.connectionAttempts))
 			cancel()
 			s.connectionAttempts++
@@ -185,7 +300,7 @@ func (s *SpireDelegateClient) handleX509SVIDUpdate(svids []*delegatedidentityv1.
 
 	for _, svid := range svids {
 
-		if svid.X509Svid.Id.TrustDomain != s.cfg.SpiffeTrustDomain {
+		if !s.isAcceptedTrustDomain(svid.X509Svid.Id.TrustDomain) {
 			s.log.
 				WithField("trust_domain", svid.X509Svid.Id.TrustDomain).
 				Debug("Skipping X509-SVID update as it does not match ours")
@@ -218,7 +333,19 @@ func (s *SpireDelegateClient) handleX509SVIDUpdate(svids []*delegatedidentityv1.
 
 	s.svidStoreMutex.RUnlock()
 
+	for _, svid := range newSvidStore {
+		s.lockKeyMaterial(svid)
+	}
+
 	s.svidStoreMutex.Lock()
+	// The store is replaced wholesale, so every SVID of the old store that
+	// did not carry over is now unreferenced: zeroize its private key
+	// instead of leaving the bytes to the garbage collector.
+	for key, svid := range s.svidStore {
+		if newSvidStore[key] != svid {
+			s.wipeKeyMaterial(svid)
+		}
+	}
 	s.svidStore = newSvidStore
 	s.svidStoreMutex.Unlock()
 
@@ -272,7 +399,7 @@ func (s *SpireDelegateClient) handleX509SVIDUpdate(svids []*delegatedidentityv1.
 }
 
 func (s *SpireDelegateClient) handleX509BundleUpdate(bundles map[string][]byte) {
-	pool := x509.NewCertPool()
+	var roots []*x509.Certificate
 
 	for trustDomain, bundle := range bundles {
 		s.log.
@@ -288,12 +415,70 @@ func (s *SpireDelegateClient) handleX509BundleUpdate(bundles map[string][]byte)
 			continue
 		}
 
-		for _, cert := range certs {
-			pool.AddCert(cert)
+		roots = append(roots, certs...)
+	}
+
+	s.localRoots = roots
+	s.rebuildTrustBundle()
+
+	s.bundleStateMutex.Lock()
+	s.localBundleReceived = true
+	s.localBundleNotAfter = latestNotAfter(roots)
+	s.bundleStateMutex.Unlock()
+	s.reportBundleStaleness()
+}
+
+// latestNotAfter returns the latest expiration among the given CA
+// certificates. The bundle as a whole stays usable as long as one of its
+// roots is still valid.
+func latestNotAfter(roots []*x509.Certificate) time.Time {
+	var notAfter time.Time
+	for _, root := range roots {
+		if root.NotAfter.After(notAfter) {
+			notAfter = root.NotAfter
 		}
 	}
+	return notAfter
+}
+
+// bundleStalenessCheckInterval is the interval in which the staleness of the
+// trust bundle is re-evaluated between bundle updates.
+const bundleStalenessCheckInterval = 1 * time.Minute
+
+// reportBundleStaleness publishes whether the trust bundle received from the
+// SPIRE agent is still usable. The bundle is stale once every CA certificate
+// in it expired, peer verification is bound to fail from then on.
+func (s *SpireDelegateClient) reportBundleStaleness() {
+	if s.bundleHealth == nil {
+		return
+	}
 
-	s.trustBundle = pool
+	s.bundleStateMutex.RLock()
+	received, notAfter := s.localBundleReceived, s.localBundleNotAfter
+	s.bundleStateMutex.RUnlock()
+
+	switch {
+	case !received:
+		s.bundleHealth.Degraded("Trust bundle not received from the SPIRE agent yet", errors.New("no bundle update received"))
+	case time.Now().After(notAfter):
+		s.bundleHealth.Degraded("Trust bundle is stale", fmt.Errorf("all CA certificates expired, the latest at %s", notAfter))
+	default:
+		s.bundleHealth.OK(fmt.Sprintf("Trust bundle valid until %s", notAfter))
+	}
+}
+
+// watchBundleStaleness periodically re-evaluates the staleness of the trust
+// bundle, so an expiry without further bundle updates still surfaces in the
+// module health.
+func (s *SpireDelegateClient) watchBundleStaleness(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(bundleStalenessCheckInterval):
+			s.reportBundleStaleness()
+		}
+	}
 }
 
 func (s *SpireDelegateClient) openStream(ctx context.Context) {
@@ -317,6 +502,9 @@ func (s *SpireDelegateClient) openStream(ctx context.Context) {
 			s.connected = false
 			s.lastConnectError = err
 			s.connectedMutex.Unlock()
+			if s.connectionHealth != nil {
+				s.connectionHealth.Degraded("SPIRE Delegate API is not connected", err)
+			}
 
 			time.Sleep(backoffTime.Duration(s.connectionAttempts))
 			s.connectionAttempts++
@@ -327,16 +515,53 @@ func (s *SpireDelegateClient) openStream(ctx context.Context) {
 		s.connected = true
 		s.lastConnectError = nil
 		s.connectedMutex.Unlock()
+		if s.connectionHealth != nil {
+			s.connectionHealth.OK("Connected to the SPIRE Delegate API")
+		}
 		break
 	}
 }
 
+// autoAdminSocketPath requests discovery of the SPIRE admin socket in
+// well-known locations. This supports sidecar-less deployments where the
+// socket location depends on how the SPIRE agent got deployed on the host.
+const autoAdminSocketPath = "auto"
+
+var wellKnownAdminSocketPaths = []string{
+	"/run/spire/sockets/admin.sock",
+	"/var/run/spire/sockets/admin.sock",
+	"/run/spire/admin.sock",
+}
+
+// adminSocketPath returns the path of the SPIRE admin socket to connect to.
+// If discovery is requested, the first existing well-known location wins.
+func (s *SpireDelegateClient) adminSocketPath() (string, error) {
+	if s.cfg.SpireAdminSocketPath != autoAdminSocketPath {
+		if _, err := os.Stat(s.cfg.SpireAdminSocketPath); errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("SPIRE admin socket (%s) does not exist: %w", s.cfg.SpireAdminSocketPath, err)
+		}
+		return s.cfg.SpireAdminSocketPath, nil
+	}
+
+	for _, path := range wellKnownAdminSocketPaths {
+		if _, err := os.Stat(path); err == nil {
+			s.log.
+				WithField("socket_path", path).
+				Debug("Discovered SPIRE admin socket")
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("no SPIRE admin socket found in well-known locations %v", wellKnownAdminSocketPaths)
+}
+
 func (s *SpireDelegateClient) initWatcher(ctx context.Context) (delegatedidentityv1.DelegatedIdentity_SubscribeToX509SVIDsClient, delegatedidentityv1.DelegatedIdentity_SubscribeToX509BundlesClient, error) {
-	if _, err := os.Stat(s.cfg.SpireAdminSocketPath); errors.Is(err, os.ErrNotExist) {
-		return nil, nil, fmt.Errorf("SPIRE admin socket (%s) does not exist: %w", s.cfg.SpireAdminSocketPath, err)
+	socketPath, err := s.adminSocketPath()
+	if err != nil {
+		return nil, nil, err
 	}
 
-	unixPath := fmt.Sprintf("unix://%s", s.cfg.SpireAdminSocketPath)
+	unixPath := fmt.Sprintf("unix://%s", socketPath)
 
 	conn, err := grpc.NewClient(unixPath, grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithDefaultCallOptions(
@@ -371,6 +596,15 @@ func (s *SpireDelegateClient) initWatcher(ctx context.Context) (delegatedidentit
 	return stream, trustStream, nil
 }
 
+func (s *SpireDelegateClient) isAcceptedTrustDomain(trustDomain string) bool {
+	for _, accepted := range s.acceptedTrustDomains() {
+		if trustDomain == accepted {
+			return true
+		}
+	}
+	return false
+}
+
 func equalCertChains(a, b [][]byte) bool {
 	if len(a) != len(b) {
 		return false
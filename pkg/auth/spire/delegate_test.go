@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package spire
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/cilium/hive/cell"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeHealth records the last status reported to it.
+type fakeHealth struct {
+	lastLevel  cell.Level
+	lastStatus string
+}
+
+func (f *fakeHealth) OK(status string) {
+	f.lastLevel = cell.StatusOK
+	f.lastStatus = status
+}
+
+func (f *fakeHealth) Degraded(reason string, err error) {
+	f.lastLevel = cell.StatusDegraded
+	f.lastStatus = reason
+}
+
+func (f *fakeHealth) Stopped(reason string)            {}
+func (f *fakeHealth) NewScope(name string) cell.Health { return f }
+func (f *fakeHealth) Close()                           {}
+
+func TestSpireDelegateClient_reportBundleStaleness(t *testing.T) {
+	health := &fakeHealth{}
+	client := &SpireDelegateClient{
+		log:          logrus.New(),
+		bundleHealth: health,
+	}
+
+	// No bundle update received yet.
+	client.reportBundleStaleness()
+	if health.lastLevel != cell.StatusDegraded {
+		t.Errorf("reportBundleStaleness() without a bundle reported %s, want %s", health.lastLevel, cell.StatusDegraded)
+	}
+
+	// A bundle with a still valid CA certificate.
+	client.localBundleReceived = true
+	client.localBundleNotAfter = time.Now().Add(time.Hour)
+	client.reportBundleStaleness()
+	if health.lastLevel != cell.StatusOK {
+		t.Errorf("reportBundleStaleness() with a valid bundle reported %s, want %s", health.lastLevel, cell.StatusOK)
+	}
+
+	// All CA certificates of the bundle expired.
+	client.localBundleNotAfter = time.Now().Add(-time.Minute)
+	client.reportBundleStaleness()
+	if health.lastLevel != cell.StatusDegraded {
+		t.Errorf("reportBundleStaleness() with a stale bundle reported %s, want %s", health.lastLevel, cell.StatusDegraded)
+	}
+}
+
+func Test_latestNotAfter(t *testing.T) {
+	if got := latestNotAfter(nil); !got.IsZero() {
+		t.Errorf("latestNotAfter() without roots = %s, want the zero time", got)
+	}
+
+	early := time.Now().Add(time.Hour)
+	late := time.Now().Add(2 * time.Hour)
+	roots := []*x509.Certificate{
+		{NotAfter: early},
+		{NotAfter: late},
+	}
+	if got := latestNotAfter(roots); !got.Equal(late) {
+		t.Errorf("latestNotAfter() = %s, want %s", got, late)
+	}
+}
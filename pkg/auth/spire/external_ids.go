@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package spire
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cilium/cilium/pkg/identity"
+)
+
+// parseExternalIDMappings parses the configured mappings of numeric
+// identities to external SPIFFE IDs. Each entry has the form
+// "<numeric identity>=<SPIFFE ID>", e.g.
+// "16777217=spiffe://prod.example.com/database". The numeric identity is
+// typically a CIDR-backed identity covering the external system, which this
+// mapping allows to participate in mutual auth with its own SPIFFE ID.
+func parseExternalIDMappings(mappings []string) (map[identity.NumericIdentity]string, error) {
+	if len(mappings) == 0 {
+		return nil, nil
+	}
+
+	external := make(map[identity.NumericIdentity]string, len(mappings))
+	for _, mapping := range mappings {
+		idStr, spiffeID, ok := strings.Cut(mapping, "=")
+		if !ok {
+			return nil, fmt.Errorf("external SPIFFE ID mapping %q is not of the form <identity>=<SPIFFE ID>", mapping)
+		}
+
+		id, err := identity.ParseNumericIdentity(idStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid numeric identity in external SPIFFE ID mapping %q: %w", mapping, err)
+		}
+		if !strings.HasPrefix(spiffeID, "spiffe://") {
+			return nil, fmt.Errorf("invalid SPIFFE ID in external SPIFFE ID mapping %q", mapping)
+		}
+		if existing, ok := external[id]; ok && existing != spiffeID {
+			return nil, fmt.Errorf("conflicting external SPIFFE ID mappings for identity %s: %q and %q", id, existing, spiffeID)
+		}
+
+		external[id] = spiffeID
+	}
+
+	return external, nil
+}
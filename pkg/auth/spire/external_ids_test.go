@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package spire
+
+import (
+	"crypto/x509"
+	"net/url"
+	"testing"
+
+	"github.com/cilium/cilium/pkg/identity"
+)
+
+func certWithURIs(uris ...*url.URL) *x509.Certificate {
+	return &x509.Certificate{URIs: uris}
+}
+
+func Test_parseExternalIDMappings(t *testing.T) {
+	tests := []struct {
+		name     string
+		mappings []string
+		want     map[identity.NumericIdentity]string
+		wantErr  bool
+	}{
+		{
+			name: "no mappings",
+		},
+		{
+			name:     "valid mappings",
+			mappings: []string{"16777217=spiffe://prod.example.com/database", "16777218=spiffe://prod.example.com/cache"},
+			want: map[identity.NumericIdentity]string{
+				16777217: "spiffe://prod.example.com/database",
+				16777218: "spiffe://prod.example.com/cache",
+			},
+		},
+		{
+			name:     "missing separator",
+			mappings: []string{"16777217"},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid numeric identity",
+			mappings: []string{"database=spiffe://prod.example.com/database"},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid SPIFFE ID",
+			mappings: []string{"16777217=https://prod.example.com/database"},
+			wantErr:  true,
+		},
+		{
+			name: "conflicting mappings",
+			mappings: []string{
+				"16777217=spiffe://prod.example.com/database",
+				"16777217=spiffe://prod.example.com/cache",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseExternalIDMappings(tt.mappings)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseExternalIDMappings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseExternalIDMappings() = %v, want %v", got, tt.want)
+			}
+			for id, spiffeID := range tt.want {
+				if got[id] != spiffeID {
+					t.Errorf("parseExternalIDMappings()[%d] = %q, want %q", id, got[id], spiffeID)
+				}
+			}
+		})
+	}
+}
+
+func TestSpireDelegateClient_ValidateIdentity_externalID(t *testing.T) {
+	externalURL, _ := url.Parse("spiffe://prod.example.com/database")
+	otherURL, _ := url.Parse("spiffe://prod.example.com/cache")
+
+	s := &SpireDelegateClient{
+		cfg: SpireDelegateConfig{
+			SpiffeTrustDomain: "test.cilium.io",
+		},
+		log: log,
+		externalIDs: map[identity.NumericIdentity]string{
+			16777217: "spiffe://prod.example.com/database",
+		},
+	}
+
+	valid, err := s.ValidateIdentity(16777217, certWithURIs(externalURL))
+	if err != nil || !valid {
+		t.Errorf("ValidateIdentity() = %v, %v, want valid", valid, err)
+	}
+
+	valid, err = s.ValidateIdentity(16777217, certWithURIs(otherURL))
+	if err != nil || valid {
+		t.Errorf("ValidateIdentity() = %v, %v, want invalid", valid, err)
+	}
+
+	// Unmapped identities keep using the Cilium identity encoding.
+	valid, err = s.ValidateIdentity(1234, certWithURIs(externalURL))
+	if err != nil || valid {
+		t.Errorf("ValidateIdentity() = %v, %v, want invalid for unmapped identity", valid, err)
+	}
+}
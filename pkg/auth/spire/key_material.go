@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package spire
+
+import (
+	delegatedidentityv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/agent/delegatedidentity/v1"
+	"golang.org/x/sys/unix"
+)
+
+// lockKeyMaterial pins the private key bytes of an SVID into resident
+// memory so they cannot be written out to swap. This is best-effort: it can
+// fail due to RLIMIT_MEMLOCK, in which case the key remains usable, just
+// without the extra protection.
+func (s *SpireDelegateClient) lockKeyMaterial(svid *delegatedidentityv1.X509SVIDWithKey) {
+	if len(svid.X509SvidKey) == 0 {
+		return
+	}
+	if err := unix.Mlock(svid.X509SvidKey); err != nil {
+		s.log.WithError(err).Debug("Failed to lock private key material into memory")
+	}
+}
+
+// wipeKeyMaterial zeroizes the private key bytes of an SVID that is no
+// longer referenced by the store, so stale key copies do not linger on the
+// heap until the garbage collector reuses them, and unpins the memory
+// again. Callers must hold the svid store write lock to ensure no reader is
+// still using the key.
+func (s *SpireDelegateClient) wipeKeyMaterial(svid *delegatedidentityv1.X509SVIDWithKey) {
+	if len(svid.X509SvidKey) == 0 {
+		return
+	}
+	for i := range svid.X509SvidKey {
+		svid.X509SvidKey[i] = 0
+	}
+	_ = unix.Munlock(svid.X509SvidKey)
+	svid.X509SvidKey = nil
+}
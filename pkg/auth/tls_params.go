@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+// Values of the --mesh-auth-mutual-tls-min-version flag.
+const (
+	tlsVersion12 = "1.2"
+	tlsVersion13 = "1.3"
+)
+
+// parseTLSMinVersion maps the configured minimum TLS version to its
+// constant. The empty value keeps the TLS 1.3-only default of the handshake.
+func parseTLSMinVersion(version string) (uint16, error) {
+	switch version {
+	case "", tlsVersion13:
+		return tls.VersionTLS13, nil
+	case tlsVersion12:
+		return tls.VersionTLS12, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version %q, must be %s or %s", version, tlsVersion12, tlsVersion13)
+	}
+}
+
+// parseTLSCipherSuites maps the configured IANA cipher suite names to their
+// IDs, restricted to the TLS 1.2 suites the crypto/tls package considers
+// secure. The TLS 1.3 suites are not configurable in Go and remain
+// unaffected.
+func parseTLSCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	supported := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		supported[suite.Name] = suite.ID
+	}
+
+	suites := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := supported[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported or insecure TLS cipher suite %q", name)
+		}
+		suites = append(suites, id)
+	}
+	return suites, nil
+}
@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+func Test_parseTLSMinVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    uint16
+		wantErr bool
+	}{
+		{version: "", want: tls.VersionTLS13},
+		{version: "1.3", want: tls.VersionTLS13},
+		{version: "1.2", want: tls.VersionTLS12},
+		{version: "1.1", wantErr: true},
+		{version: "tls13", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseTLSMinVersion(tt.version)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseTLSMinVersion(%q) error = %v, wantErr %v", tt.version, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseTLSMinVersion(%q) = %d, want %d", tt.version, got, tt.want)
+		}
+	}
+}
+
+func Test_parseTLSCipherSuites(t *testing.T) {
+	suites, err := parseTLSCipherSuites(nil)
+	if err != nil || suites != nil {
+		t.Errorf("parseTLSCipherSuites(nil) = %v, %v, want the Go defaults", suites, err)
+	}
+
+	suites, err = parseTLSCipherSuites([]string{
+		"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+		"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+	})
+	if err != nil {
+		t.Fatalf("parseTLSCipherSuites() of valid suites failed: %v", err)
+	}
+	want := []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384, tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}
+	if len(suites) != len(want) || suites[0] != want[0] || suites[1] != want[1] {
+		t.Errorf("parseTLSCipherSuites() = %v, want %v", suites, want)
+	}
+
+	// Insecure suites are rejected, they are not in tls.CipherSuites().
+	if _, err := parseTLSCipherSuites([]string{"TLS_RSA_WITH_RC4_128_SHA"}); err == nil {
+		t.Errorf("parseTLSCipherSuites() accepted an insecure cipher suite")
+	}
+	if _, err := parseTLSCipherSuites([]string{"NOT_A_SUITE"}); err == nil {
+		t.Errorf("parseTLSCipherSuites() accepted an unknown cipher suite")
+	}
+}
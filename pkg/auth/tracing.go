@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the handshake spans. The spans
+// are recorded through the global trace provider, so they are no-ops unless
+// the embedding binary installs an OpenTelemetry SDK.
+const tracerName = "github.com/cilium/cilium/pkg/auth"
+
+// startHandshakeSpan opens a span for a handshake code path. The stage
+// transitions of the handshake are attached with traceStage, so the
+// handshake latency can be broken down into dial, certificate fetch, TLS
+// handshake and verification without one span per stage.
+func startHandshakeSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// traceStage marks the transition into a handshake stage on the span. The
+// event timestamps delimit the time spent per stage.
+func traceStage(span trace.Span, stage string) {
+	span.AddEvent("stage/" + stage)
+}
+
+// endHandshakeSpan records the outcome on the span and ends it.
+func endHandshakeSpan(span trace.Span, err error) {
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetStatus(codes.Ok, "")
+	}
+	span.End()
+}
+
+// traceProviderCall wraps a certificate provider call in a child span, so
+// the share of the handshake latency spent on the provider (e.g. a SPIRE
+// delegate round trip) shows up in the trace.
+func traceProviderCall[T any](ctx context.Context, name string, call func() (T, error)) (T, error) {
+	_, span := otel.Tracer(tracerName).Start(ctx, name)
+	value, err := call()
+	endHandshakeSpan(span, err)
+	return value, err
+}
@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package vaultcerts
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cilium/cilium/api/v1/models"
+	"github.com/cilium/cilium/pkg/auth/certs"
+	"github.com/cilium/cilium/pkg/identity"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// This file implements the CertificateProvider interface. The SNI and SPIFFE
+// ID encoding matches the version 1 encoding of the SPIRE provider, so a
+// cluster can be migrated between the two providers.
+
+func (p *Provider) GetTrustBundle() (*x509.CertPool, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if p.trustBundle == nil {
+		return nil, errors.New("trust bundle not yet available")
+	}
+	return p.trustBundle, nil
+}
+
+func (p *Provider) GetCertificateForIdentity(id identity.NumericIdentity) (*tls.Certificate, error) {
+	p.mutex.RLock()
+	cert, ok := p.certificates[id]
+	p.mutex.RUnlock()
+
+	// Certificates past two thirds of their lifetime are re-issued instead
+	// of being served from the cache, so auth entries never get tied to a
+	// certificate which expires almost immediately.
+	if ok && time.Until(cert.Leaf.NotAfter) >= p.cfg.VaultCertificateTTL/3 {
+		return cert, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.httpClient.Timeout)
+	defer cancel()
+	cert, err := p.issueCertificate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mutex.Lock()
+	p.certificates[id] = cert
+	p.mutex.Unlock()
+
+	return cert, nil
+}
+
+func (p *Provider) ValidateIdentity(id identity.NumericIdentity, cert *x509.Certificate) (bool, error) {
+	// Spec: SVIDs containing more than one URI SAN MUST be rejected
+	if len(cert.URIs) != 1 {
+		return false, errors.New("SPIFFE IDs must have exactly one URI SAN")
+	}
+	return cert.URIs[0].String() == p.spiffeID(id), nil
+}
+
+func (p *Provider) NumericIdentityToSNI(id identity.NumericIdentity) string {
+	return id.String() + "." + p.cfg.VaultTrustDomain
+}
+
+func (p *Provider) NumericIdentityToSPIFFEID(id identity.NumericIdentity) string {
+	return p.spiffeID(id)
+}
+
+func (p *Provider) SNIToNumericIdentity(sni string) (identity.NumericIdentity, error) {
+	suffix := "." + p.cfg.VaultTrustDomain
+	if !strings.HasSuffix(sni, suffix) {
+		return 0, fmt.Errorf("SNI %s does not belong to our trust domain", sni)
+	}
+
+	// Only the canonical decimal encoding produced by NumericIdentityToSNI
+	// is accepted.
+	label := strings.TrimSuffix(sni, suffix)
+	if label == "" || (len(label) > 1 && label[0] == '0') {
+		return 0, fmt.Errorf("%q is not a canonical decimal identity", label)
+	}
+	for i := 0; i < len(label); i++ {
+		if label[i] < '0' || label[i] > '9' {
+			return 0, fmt.Errorf("%q is not a canonical decimal identity", label)
+		}
+	}
+	return identity.ParseNumericIdentity(label)
+}
+
+func (p *Provider) SubscribeToRotatedIdentities() <-chan certs.CertificateRotationEvent {
+	return p.rotatedIdentitiesChan
+}
+
+func (p *Provider) Status() *models.Status {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if p.lastError != nil {
+		return &models.Status{
+			State: models.StatusStateFailure,
+			Msg:   fmt.Sprintf("Vault request failed: %q", p.lastError),
+		}
+	}
+	if p.trustBundle == nil {
+		return &models.Status{
+			State: models.StatusStateFailure,
+			Msg:   "Trust bundle not yet fetched from Vault",
+		}
+	}
+	return &models.Status{
+		State: models.StatusStateOk,
+		Msg:   fmt.Sprintf("%d certificates issued", len(p.certificates)),
+	}
+}
+
+func (p *Provider) spiffeID(id identity.NumericIdentity) string {
+	return "spiffe://" + p.cfg.VaultTrustDomain + "/identity/" + id.String()
+}
@@ -0,0 +1,439 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package vaultcerts
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/cilium/hive/cell"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+
+	"github.com/cilium/cilium/pkg/auth/certs"
+	"github.com/cilium/cilium/pkg/identity"
+	"github.com/cilium/cilium/pkg/lock"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// Cell provides a certificate provider which issues per-identity short-lived
+// certificates from the PKI secrets engine of a HashiCorp Vault server. The
+// Vault HTTP API is consumed directly, so no Vault SDK dependency is needed.
+var Cell = cell.Module(
+	"vault-cert-provider",
+	"Vault PKI Certificate Provider",
+
+	cell.Provide(newVaultCertificateProvider),
+	cell.Config(Config{}),
+)
+
+// Supported values of the Vault auth method configuration.
+const (
+	authMethodToken      = "token"
+	authMethodKubernetes = "kubernetes"
+)
+
+// rotationQueueSize mirrors the default queue size of the SPIRE delegate client.
+const rotationQueueSize = 1024
+
+type Config struct {
+	// VaultAddress is the URL of the Vault server. An empty value disables
+	// the provider.
+	VaultAddress string `mapstructure:"mesh-auth-vault-address"`
+
+	// VaultPKIMount is the mount path of the PKI secrets engine the
+	// certificates are issued from.
+	VaultPKIMount string `mapstructure:"mesh-auth-vault-pki-mount"`
+
+	// VaultRole is the PKI role the certificates are issued under.
+	VaultRole string `mapstructure:"mesh-auth-vault-role"`
+
+	// VaultAuthMethod selects how the agent authenticates to Vault: "token"
+	// reads a Vault token from VaultTokenFile, "kubernetes" logs in with the
+	// service account token of the agent pod.
+	VaultAuthMethod string `mapstructure:"mesh-auth-vault-auth-method"`
+
+	// VaultTokenFile is the file the Vault token is read from for the
+	// "token" auth method.
+	VaultTokenFile string `mapstructure:"mesh-auth-vault-token-file"`
+
+	// VaultKubernetesAuthMount is the mount path of the Kubernetes auth
+	// method for the "kubernetes" auth method.
+	VaultKubernetesAuthMount string `mapstructure:"mesh-auth-vault-kubernetes-auth-mount"`
+
+	// VaultKubernetesTokenFile is the file the service account token is read
+	// from for the "kubernetes" auth method.
+	VaultKubernetesTokenFile string `mapstructure:"mesh-auth-vault-kubernetes-token-file"`
+
+	// VaultCertificateTTL is the requested lifetime of the issued
+	// certificates. Certificates past two thirds of their lifetime are
+	// re-issued and announced as rotated.
+	VaultCertificateTTL time.Duration `mapstructure:"mesh-auth-vault-certificate-ttl"`
+
+	// VaultRefreshInterval is the interval in which the trust bundle is
+	// refreshed and expiring certificates are re-issued.
+	VaultRefreshInterval time.Duration `mapstructure:"mesh-auth-vault-refresh-interval"`
+
+	// VaultTrustDomain is the trust domain the certificates are issued
+	// under, used for the SNI and SPIFFE ID encoding of identities.
+	VaultTrustDomain string `mapstructure:"mesh-auth-vault-trust-domain"`
+}
+
+func (cfg Config) Flags(flags *pflag.FlagSet) {
+	flags.StringVar(&cfg.VaultAddress, "mesh-auth-vault-address", "",
+		"URL of the Vault server certificates are issued from, empty to disable the Vault certificate provider")
+	flags.StringVar(&cfg.VaultPKIMount, "mesh-auth-vault-pki-mount", "pki",
+		"Mount path of the Vault PKI secrets engine")
+	flags.StringVar(&cfg.VaultRole, "mesh-auth-vault-role", "cilium-mesh-auth",
+		"Vault PKI role the certificates are issued under")
+	flags.StringVar(&cfg.VaultAuthMethod, "mesh-auth-vault-auth-method", authMethodKubernetes,
+		"Auth method used to authenticate to Vault (token or kubernetes)")
+	flags.StringVar(&cfg.VaultTokenFile, "mesh-auth-vault-token-file", "",
+		"File the Vault token is read from for the token auth method")
+	flags.StringVar(&cfg.VaultKubernetesAuthMount, "mesh-auth-vault-kubernetes-auth-mount", "kubernetes",
+		"Mount path of the Vault Kubernetes auth method")
+	flags.StringVar(&cfg.VaultKubernetesTokenFile, "mesh-auth-vault-kubernetes-token-file", "/var/run/secrets/kubernetes.io/serviceaccount/token",
+		"File the service account token is read from for the Kubernetes auth method")
+	flags.DurationVar(&cfg.VaultCertificateTTL, "mesh-auth-vault-certificate-ttl", 1*time.Hour,
+		"Requested lifetime of the certificates issued from Vault")
+	flags.DurationVar(&cfg.VaultRefreshInterval, "mesh-auth-vault-refresh-interval", 1*time.Minute,
+		"Interval in which the Vault trust bundle is refreshed and expiring certificates are re-issued")
+	flags.StringVar(&cfg.VaultTrustDomain, "mesh-auth-vault-trust-domain", "spiffe.cilium",
+		"Trust domain used for the SNI and SPIFFE ID encoding of the Vault issued certificates")
+}
+
+// Provider implements certs.CertificateProvider on top of the Vault PKI
+// secrets engine.
+type Provider struct {
+	cfg    Config
+	log    logrus.FieldLogger
+	health cell.Health
+
+	httpClient *http.Client
+
+	tokenMutex lock.RWMutex
+	token      string
+
+	mutex        lock.RWMutex
+	trustBundle  *x509.CertPool
+	bundlePEM    []byte
+	certificates map[identity.NumericIdentity]*tls.Certificate
+	lastError    error
+
+	rotatedIdentitiesChan chan certs.CertificateRotationEvent
+
+	cancelRefresh context.CancelFunc
+}
+
+func newVaultCertificateProvider(lc cell.Lifecycle, health cell.Health, cfg Config, log logrus.FieldLogger) (*Provider, error) {
+	if cfg.VaultAddress == "" {
+		return nil, nil
+	}
+
+	switch cfg.VaultAuthMethod {
+	case authMethodToken:
+		if cfg.VaultTokenFile == "" {
+			return nil, fmt.Errorf("the %q auth method requires a token file to be configured", authMethodToken)
+		}
+	case authMethodKubernetes:
+	default:
+		return nil, fmt.Errorf("unsupported Vault auth method %q", cfg.VaultAuthMethod)
+	}
+
+	p := &Provider{
+		cfg:                   cfg,
+		log:                   log,
+		health:                health,
+		httpClient:            &http.Client{Timeout: 10 * time.Second},
+		certificates:          map[identity.NumericIdentity]*tls.Certificate{},
+		rotatedIdentitiesChan: make(chan certs.CertificateRotationEvent, rotationQueueSize),
+	}
+
+	lc.Append(cell.Hook{OnStart: p.onStart, OnStop: p.onStop})
+
+	return p, nil
+}
+
+func (p *Provider) onStart(ctx cell.HookContext) error {
+	// Login and bundle fetch failures must not block agent startup - Vault
+	// may become reachable only after the network of the node converged. The
+	// refresh loop retries and the provider reports degraded health until it
+	// succeeded.
+	if err := p.login(ctx); err != nil {
+		p.log.WithError(err).Warning("Failed to authenticate to Vault, retrying in the background")
+		p.setLastError(err)
+	} else if err := p.refreshTrustBundle(ctx); err != nil {
+		p.log.WithError(err).Warning("Failed to fetch trust bundle from Vault, retrying in the background")
+		p.setLastError(err)
+	}
+
+	refreshCtx, cancel := context.WithCancel(context.Background())
+	p.cancelRefresh = cancel
+	go p.refreshLoop(refreshCtx)
+
+	p.log.WithField("address", p.cfg.VaultAddress).Info("Vault PKI certificate provider is running")
+	return nil
+}
+
+func (p *Provider) onStop(ctx cell.HookContext) error {
+	if p.cancelRefresh != nil {
+		p.cancelRefresh()
+	}
+	return nil
+}
+
+// login acquires the Vault token used for the issue requests.
+func (p *Provider) login(ctx context.Context) error {
+	switch p.cfg.VaultAuthMethod {
+	case authMethodToken:
+		token, err := os.ReadFile(p.cfg.VaultTokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read Vault token file: %w", err)
+		}
+		p.setToken(strings.TrimSpace(string(token)))
+		return nil
+	case authMethodKubernetes:
+		jwt, err := os.ReadFile(p.cfg.VaultKubernetesTokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read service account token file: %w", err)
+		}
+
+		var resp struct {
+			Auth struct {
+				ClientToken string `json:"client_token"`
+			} `json:"auth"`
+		}
+		err = p.request(ctx, http.MethodPost, "auth/"+p.cfg.VaultKubernetesAuthMount+"/login", map[string]string{
+			"jwt":  strings.TrimSpace(string(jwt)),
+			"role": p.cfg.VaultRole,
+		}, &resp)
+		if err != nil {
+			return fmt.Errorf("failed to login to Vault: %w", err)
+		}
+		if resp.Auth.ClientToken == "" {
+			return fmt.Errorf("vault login response contains no client token")
+		}
+		p.setToken(resp.Auth.ClientToken)
+		return nil
+	}
+	return fmt.Errorf("unsupported Vault auth method %q", p.cfg.VaultAuthMethod)
+}
+
+func (p *Provider) setToken(token string) {
+	p.tokenMutex.Lock()
+	p.token = token
+	p.tokenMutex.Unlock()
+}
+
+func (p *Provider) currentToken() string {
+	p.tokenMutex.RLock()
+	defer p.tokenMutex.RUnlock()
+	return p.token
+}
+
+// request performs an authenticated request against the Vault HTTP API.
+func (p *Provider) request(ctx context.Context, method, path string, body any, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request body: %w", err)
+		}
+		reqBody = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(p.cfg.VaultAddress, "/")+"/v1/"+path, reqBody)
+	if err != nil {
+		return err
+	}
+	if token := p.currentToken(); token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("vault request %s failed with status %d: %s", path, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("failed to decode response of %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// issueCertificate requests a fresh certificate for the given identity from
+// the PKI secrets engine.
+func (p *Provider) issueCertificate(ctx context.Context, id identity.NumericIdentity) (*tls.Certificate, error) {
+	var resp struct {
+		Data struct {
+			Certificate string   `json:"certificate"`
+			PrivateKey  string   `json:"private_key"`
+			CAChain     []string `json:"ca_chain"`
+		} `json:"data"`
+	}
+	err := p.request(ctx, http.MethodPost, p.cfg.VaultPKIMount+"/issue/"+p.cfg.VaultRole, map[string]any{
+		"common_name": p.NumericIdentityToSNI(id),
+		"uri_sans":    p.spiffeID(id),
+		"ttl":         p.cfg.VaultCertificateTTL.String(),
+	}, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue certificate for identity %s: %w", id.String(), err)
+	}
+
+	chain := resp.Data.Certificate
+	for _, ca := range resp.Data.CAChain {
+		chain += "\n" + ca
+	}
+	cert, err := tls.X509KeyPair([]byte(chain), []byte(resp.Data.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse issued certificate: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse issued leaf certificate: %w", err)
+	}
+	cert.Leaf = leaf
+
+	return &cert, nil
+}
+
+// refreshTrustBundle fetches the CA chain of the PKI secrets engine. A fresh
+// pool is only built when the chain changed, as consumers detect a trust
+// bundle rotation by pointer comparison.
+func (p *Provider) refreshTrustBundle(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		strings.TrimSuffix(p.cfg.VaultAddress, "/")+"/v1/"+p.cfg.VaultPKIMount+"/ca_chain", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	pem, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch CA chain with status %d: %s", resp.StatusCode, strings.TrimSpace(string(pem)))
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if bytes.Equal(p.bundlePEM, pem) {
+		return nil
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("no CA certificates found in the CA chain")
+	}
+	if p.trustBundle != nil {
+		p.log.Info("Vault CA chain rotated, swapping trust bundle")
+	}
+	p.trustBundle = pool
+	p.bundlePEM = pem
+	return nil
+}
+
+// refreshLoop periodically refreshes the trust bundle and re-issues
+// certificates nearing their expiry.
+func (p *Provider) refreshLoop(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.VaultRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if p.currentToken() == "" {
+			if err := p.login(ctx); err != nil {
+				p.log.WithError(err).Warning("Failed to authenticate to Vault")
+				p.setLastError(err)
+				continue
+			}
+		}
+
+		if err := p.refreshTrustBundle(ctx); err != nil {
+			p.log.WithError(err).Warning("Failed to refresh trust bundle from Vault")
+			p.setLastError(err)
+			continue
+		}
+
+		p.renewExpiringCertificates(ctx)
+		p.setLastError(nil)
+	}
+}
+
+// renewExpiringCertificates re-issues certificates past two thirds of their
+// lifetime and announces them as rotated, so the auth entries relying on them
+// are refreshed before the certificates expire.
+func (p *Provider) renewExpiringCertificates(ctx context.Context) {
+	for _, id := range p.expiringIdentities() {
+		cert, err := p.issueCertificate(ctx, id)
+		if err != nil {
+			p.log.WithError(err).WithField("identity", id).
+				Warning("Failed to renew certificate")
+			continue
+		}
+
+		p.mutex.Lock()
+		p.certificates[id] = cert
+		p.mutex.Unlock()
+
+		p.emitRotationEvent(id)
+	}
+}
+
+func (p *Provider) expiringIdentities() []identity.NumericIdentity {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	var expiring []identity.NumericIdentity
+	for id, cert := range p.certificates {
+		if time.Until(cert.Leaf.NotAfter) < p.cfg.VaultCertificateTTL/3 {
+			expiring = append(expiring, id)
+		}
+	}
+	return expiring
+}
+
+func (p *Provider) emitRotationEvent(id identity.NumericIdentity) {
+	select {
+	case p.rotatedIdentitiesChan <- certs.CertificateRotationEvent{Identity: id}:
+	default:
+		p.log.WithField("identity", id).
+			Warning("Skipping rotation event, queue is full")
+	}
+}
+
+func (p *Provider) setLastError(err error) {
+	p.mutex.Lock()
+	p.lastError = err
+	p.mutex.Unlock()
+}
@@ -0,0 +1,200 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package vaultcerts
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cilium/cilium/pkg/auth/certs"
+	"github.com/cilium/cilium/pkg/identity"
+)
+
+func Test_Provider_loginAndIssue(t *testing.T) {
+	ca := newTestCA(t)
+	vault := newFakeVault(t, ca)
+	srv := httptest.NewServer(vault)
+	defer srv.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("sa-jwt"), 0o600))
+
+	p := newTestProvider(t, srv.URL)
+	p.cfg.VaultKubernetesTokenFile = tokenFile
+
+	require.NoError(t, p.login(context.Background()))
+	assert.Equal(t, "test-token", p.currentToken())
+
+	require.NoError(t, p.refreshTrustBundle(context.Background()))
+	bundle, err := p.GetTrustBundle()
+	require.NoError(t, err)
+	assert.NotNil(t, bundle)
+
+	cert, err := p.GetCertificateForIdentity(identity.NumericIdentity(1234))
+	require.NoError(t, err)
+	require.NotNil(t, cert.Leaf)
+	assert.Equal(t, "spiffe://test.cilium.io/identity/1234", cert.Leaf.URIs[0].String())
+
+	// The freshly issued certificate is served from the cache.
+	cached, err := p.GetCertificateForIdentity(identity.NumericIdentity(1234))
+	require.NoError(t, err)
+	assert.Same(t, cert, cached)
+	assert.Equal(t, 1, vault.issuedCerts)
+}
+
+func Test_Provider_renewExpiringCertificates(t *testing.T) {
+	ca := newTestCA(t)
+	vault := newFakeVault(t, ca)
+	srv := httptest.NewServer(vault)
+	defer srv.Close()
+
+	p := newTestProvider(t, srv.URL)
+	p.setToken("test-token")
+
+	// A certificate past two thirds of its lifetime is re-issued and
+	// announced as rotated.
+	p.certificates[identity.NumericIdentity(1234)] = &tls.Certificate{
+		Leaf: &x509.Certificate{NotAfter: time.Now().Add(time.Minute)},
+	}
+	p.renewExpiringCertificates(context.Background())
+
+	event := <-p.SubscribeToRotatedIdentities()
+	assert.Equal(t, identity.NumericIdentity(1234), event.Identity)
+	assert.Equal(t, 1, vault.issuedCerts)
+
+	cert, err := p.GetCertificateForIdentity(identity.NumericIdentity(1234))
+	require.NoError(t, err)
+	assert.Greater(t, time.Until(cert.Leaf.NotAfter), 30*time.Minute)
+}
+
+func newTestProvider(t *testing.T, address string) *Provider {
+	t.Helper()
+	return &Provider{
+		cfg: Config{
+			VaultAddress:             address,
+			VaultPKIMount:            "pki",
+			VaultRole:                "cilium-mesh-auth",
+			VaultAuthMethod:          authMethodKubernetes,
+			VaultKubernetesAuthMount: "kubernetes",
+			VaultCertificateTTL:      time.Hour,
+			VaultTrustDomain:         "test.cilium.io",
+		},
+		log:                   logrus.New(),
+		httpClient:            &http.Client{Timeout: 5 * time.Second},
+		certificates:          map[identity.NumericIdentity]*tls.Certificate{},
+		rotatedIdentitiesChan: make(chan certs.CertificateRotationEvent, rotationQueueSize),
+	}
+}
+
+// fakeVault answers the subset of the Vault HTTP API used by the provider.
+type fakeVault struct {
+	t  *testing.T
+	ca *testCA
+
+	issuedCerts int
+}
+
+func newFakeVault(t *testing.T, ca *testCA) *fakeVault {
+	return &fakeVault{t: t, ca: ca}
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/v1/auth/kubernetes/login":
+		var req map[string]string
+		require.NoError(v.t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(v.t, "sa-jwt", req["jwt"])
+		require.Equal(v.t, "cilium-mesh-auth", req["role"])
+		json.NewEncoder(w).Encode(map[string]any{
+			"auth": map[string]any{"client_token": "test-token"},
+		})
+	case "/v1/pki/ca_chain":
+		w.Write(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: v.ca.cert.Raw}))
+	case "/v1/pki/issue/cilium-mesh-auth":
+		require.Equal(v.t, "test-token", r.Header.Get("X-Vault-Token"))
+		var req map[string]any
+		require.NoError(v.t, json.NewDecoder(r.Body).Decode(&req))
+		certPEM, keyPEM := v.ca.issue(v.t, req["uri_sans"].(string))
+		v.issuedCerts++
+		json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{
+				"certificate": string(certPEM),
+				"private_key": string(keyPEM),
+				"ca_chain":    []string{string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: v.ca.cert.Raw}))},
+			},
+		})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		SerialNumber:          big.NewInt(1),
+		BasicConstraintsValid: true,
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(certBytes)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) issue(t *testing.T, spiffeID string) (certPEM, keyPEM []byte) {
+	t.Helper()
+
+	spiffeURI, err := url.Parse(spiffeID)
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		NotAfter:     time.Now().Add(time.Hour),
+		URIs:         []*url.URL{spiffeURI},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		SerialNumber: big.NewInt(2),
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes})
+}
@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/cilium/cilium/pkg/logging/logfields"
+)
+
+// wireguardPeerGater is the subset of the WireGuard agent used to install and
+// remove peers based on the mutual authentication state of their node.
+type wireguardPeerGater interface {
+	SetPeerAuthStatus(nodeIP net.IP, healthy bool) error
+}
+
+// wireguardAuthGate periodically condenses the auth map cache into a per-node
+// authentication health and forwards it to the WireGuard agent, tying the
+// encryption membership of a node to its authentication state.
+type wireguardAuthGate struct {
+	logger  logrus.FieldLogger
+	manager *AuthManager
+	wgAgent wireguardPeerGater
+}
+
+func (w *wireguardAuthGate) sync(_ context.Context) error {
+	statuses, err := w.manager.nodeAuthStatuses()
+	if err != nil {
+		return fmt.Errorf("failed to gather per-node auth statuses: %w", err)
+	}
+
+	for nodeID, healthy := range statuses {
+		nodeIP := w.manager.nodeIDHandler.GetNodeIP(nodeID)
+		if nodeIP == "" {
+			continue
+		}
+		if err := w.wgAgent.SetPeerAuthStatus(net.ParseIP(nodeIP), healthy); err != nil {
+			w.logger.
+				WithError(err).
+				WithField(logfields.IPAddr, nodeIP).
+				Warning("Failed to update WireGuard peer auth status")
+		}
+	}
+
+	return nil
+}
+
+// nodeAuthStatuses reports for each remote node with cached auth entries
+// whether all of them are still valid. A node with at least one expired entry
+// is considered unhealthy until re-authentication replaced the entry. Nodes
+// without required identity pairs, i.e. without auth entries, are not
+// reported at all.
+func (a *AuthManager) nodeAuthStatuses() (map[uint16]bool, error) {
+	entries, err := a.authmap.All()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all auth map entries: %w", err)
+	}
+
+	now := a.clock.Now()
+	statuses := make(map[uint16]bool)
+	for k, v := range entries {
+		if k.remoteNodeID == 0 {
+			// Entries for endpoints hosted by the local node are not subject
+			// to WireGuard peer gating.
+			continue
+		}
+		healthy, ok := statuses[k.remoteNodeID]
+		if !ok {
+			healthy = true
+		}
+		statuses[k.remoteNodeID] = healthy && now.Before(v.expiration)
+	}
+
+	return statuses, nil
+}
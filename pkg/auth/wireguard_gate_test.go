@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package auth
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// Fake WireGuard peer gater which records the reported statuses
+type fakeWireguardPeerGater struct {
+	statuses map[string]bool
+}
+
+func (f *fakeWireguardPeerGater) SetPeerAuthStatus(nodeIP net.IP, healthy bool) error {
+	f.statuses[nodeIP.String()] = healthy
+	return nil
+}
+
+func Test_wireguardAuthGate_sync(t *testing.T) {
+	aMap := &fakeAuthMap{
+		entries: map[authKey]authInfo{
+			// Node 10 has one valid and one expired entry -> unhealthy
+			{localIdentity: 1000, remoteIdentity: 2000, remoteNodeID: 10, authType: 255}: {expiration: time.Now().Add(time.Hour)},
+			{localIdentity: 1000, remoteIdentity: 3000, remoteNodeID: 10, authType: 255}: {expiration: time.Now().Add(-time.Hour)},
+			// Node 11 only has valid entries -> healthy
+			{localIdentity: 1000, remoteIdentity: 4000, remoteNodeID: 11, authType: 255}: {expiration: time.Now().Add(time.Hour)},
+			// Local node entries are not subject to gating
+			{localIdentity: 1000, remoteIdentity: 5000, remoteNodeID: 0, authType: 255}: {expiration: time.Now().Add(-time.Hour)},
+			// Node 12 has no node IP mapping and is skipped
+			{localIdentity: 1000, remoteIdentity: 6000, remoteNodeID: 12, authType: 255}: {expiration: time.Now().Add(time.Hour)},
+		},
+	}
+
+	am, err := newAuthManager(logrus.New(), []authHandler{&fakeAuthHandler{}}, aMap,
+		newFakeNodeIDHandler(map[uint16]string{10: "172.18.0.2", 11: "172.18.0.3"}), time.Second)
+	assert.NoError(t, err)
+	assert.NotNil(t, am)
+
+	gater := &fakeWireguardPeerGater{statuses: map[string]bool{}}
+	gate := &wireguardAuthGate{
+		logger:  logrus.New(),
+		manager: am,
+		wgAgent: gater,
+	}
+
+	assert.NoError(t, gate.sync(context.Background()))
+	assert.Equal(t, map[string]bool{
+		"172.18.0.2": false,
+		"172.18.0.3": true,
+	}, gater.statuses)
+}
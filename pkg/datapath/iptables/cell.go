@@ -8,6 +8,7 @@ import (
 	"github.com/spf13/pflag"
 
 	"github.com/cilium/cilium/pkg/datapath/iptables/ipset"
+	"github.com/cilium/cilium/pkg/datapath/types"
 	"github.com/cilium/cilium/pkg/option"
 	"github.com/cilium/cilium/pkg/time"
 )
@@ -22,10 +23,11 @@ var Cell = cell.Module(
 	ipset.Cell,
 
 	cell.Config(defaultConfig),
-	cell.ProvidePrivate(func(
-		cfg *option.DaemonConfig,
-	) SharedConfig {
+	cell.ProvidePrivate(func(p sharedConfigParams) SharedConfig {
+		cfg := p.Cfg
 		return SharedConfig{
+			MutualAuthListenerPort: p.MutualAuthFilter.Port,
+
 			TunnelingEnabled:                cfg.TunnelingEnabled(),
 			NodeIpsetNeeded:                 cfg.NodeIpsetNeeded(),
 			IptablesMasqueradingIPv4Enabled: cfg.IptablesMasqueradingIPv4Enabled(),
@@ -48,6 +50,16 @@ var Cell = cell.Module(
 	cell.Provide(newIptablesManager),
 )
 
+type sharedConfigParams struct {
+	cell.In
+
+	Cfg *option.DaemonConfig
+
+	// MutualAuthFilter is provided by the auth cell. It is optional so that
+	// hives without mutual authentication can still use this cell.
+	MutualAuthFilter types.MutualAuthPortFilter `optional:"true"`
+}
+
 type Config struct {
 	// IPTablesLockTimeout defines the "-w" iptables option when the
 	// iptables CLI is directly invoked from the Cilium agent.
@@ -97,4 +109,8 @@ type SharedConfig struct {
 	EnableMasqueradeRouteSource bool
 	EnableL7Proxy               bool
 	InstallIptRules             bool
+
+	// MutualAuthListenerPort is the mutual authentication listener port to
+	// restrict to traffic from cluster nodes, 0 to install no filter.
+	MutualAuthListenerPort uint16
 }
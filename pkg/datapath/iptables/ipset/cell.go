@@ -15,6 +15,7 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/cilium/cilium/pkg/datapath/tables"
+	"github.com/cilium/cilium/pkg/datapath/types"
 	"github.com/cilium/cilium/pkg/option"
 	"github.com/cilium/cilium/pkg/time"
 )
@@ -51,12 +52,25 @@ var Cell = cell.Module(
 			}
 		},
 
-		func(cfg *option.DaemonConfig) config {
-			return config{NodeIPSetNeeded: cfg.NodeIpsetNeeded()}
+		func(p configParams) config {
+			// The node IP sets are also needed when the mutual auth
+			// listener port is restricted to traffic from cluster nodes,
+			// as the filter rules match against them.
+			return config{NodeIPSetNeeded: p.Cfg.NodeIpsetNeeded() || p.MutualAuthFilter.Port != 0}
 		},
 	),
 )
 
+type configParams struct {
+	cell.In
+
+	Cfg *option.DaemonConfig
+
+	// MutualAuthFilter is provided by the auth cell. It is optional so that
+	// hives without mutual authentication can still use this cell.
+	MutualAuthFilter types.MutualAuthPortFilter `optional:"true"`
+}
+
 type config struct {
 	NodeIPSetNeeded bool
 }
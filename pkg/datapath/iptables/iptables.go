@@ -789,6 +789,50 @@ func (m *Manager) copyProxyRules(oldChain string, match string) error {
 	return nil
 }
 
+// installMutualAuthFilterRules restricts the mutual auth listener port to
+// traffic originating from cluster nodes, matched via the node IP sets. This
+// makes the port invisible to pods and external scanners even if the
+// listener socket binds to all interfaces.
+func (m *Manager) installMutualAuthFilterRules() error {
+	if m.sharedCfg.MutualAuthListenerPort == 0 {
+		return nil
+	}
+	port := strconv.FormatUint(uint64(m.sharedCfg.MutualAuthListenerPort), 10)
+
+	var progs []iptablesInterface
+	if m.sharedCfg.EnableIPv4 {
+		progs = append(progs, ip4tables)
+	}
+	if m.sharedCfg.EnableIPv6 {
+		progs = append(progs, ip6tables)
+	}
+
+	for _, prog := range progs {
+		if err := prog.runProg([]string{
+			"-t", "filter",
+			"-A", ciliumInputChain,
+			"-p", "tcp",
+			"--dport", port,
+			"-m", "set", "--match-set", prog.getIpset(), "src",
+			"-m", "comment", "--comment", "cilium: ACCEPT mutual auth traffic from cluster nodes",
+			"-j", "ACCEPT"}); err != nil {
+			return err
+		}
+
+		if err := prog.runProg([]string{
+			"-t", "filter",
+			"-A", ciliumInputChain,
+			"-p", "tcp",
+			"--dport", port,
+			"-m", "comment", "--comment", "cilium: DROP mutual auth traffic from unknown sources",
+			"-j", "DROP"}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Redirect packets to the host proxy via TPROXY, as directed by the Cilium
 // datapath bpf programs via skb marks.
 func (m *Manager) addProxyRules(prog runnable, ip string, proxyPort uint16, name string) error {
@@ -1516,6 +1560,10 @@ func (m *Manager) installRules(state desiredState) error {
 		return fmt.Errorf("cannot install static proxy rules: %w", err)
 	}
 
+	if err := m.installMutualAuthFilterRules(); err != nil {
+		return fmt.Errorf("cannot install mutual auth filter rules: %w", err)
+	}
+
 	if err := m.addCiliumAcceptXfrmRules(); err != nil {
 		return fmt.Errorf("cannot install xfrm rules: %w", err)
 	}
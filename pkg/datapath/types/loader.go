@@ -11,6 +11,8 @@ import (
 
 	"github.com/cilium/cilium/pkg/datapath/loader/metrics"
 	"github.com/cilium/cilium/pkg/datapath/tunnel"
+	"github.com/cilium/cilium/pkg/identity"
+	"github.com/cilium/cilium/pkg/time"
 )
 
 // Loader is an interface to abstract out loading of datapath programs.
@@ -73,6 +75,28 @@ type IptablesManager interface {
 	RemoveNoTrackRules(ip netip.Addr, port uint16)
 }
 
+// MutualAuthPortFilter describes the datapath filter restricting the mutual
+// authentication listener port to traffic from cluster nodes. A Port of 0
+// disables the filter.
+type MutualAuthPortFilter struct {
+	Port uint16
+}
+
+// AuthSessionKeyProgrammer is implemented by datapath integrations which can
+// key payload encryption per authenticated identity pair, scoping WireGuard
+// or IPsec keys to the service identities instead of the node keys. The auth
+// subsystem pushes the keying material derived from its mutual auth
+// handshakes into it.
+type AuthSessionKeyProgrammer interface {
+	// UpsertAuthSessionKey installs or replaces the symmetric key for the
+	// identity pair. The key is valid until the given expiration and owned
+	// by the callee.
+	UpsertAuthSessionKey(localIdentity, remoteIdentity identity.NumericIdentity, key []byte, expiration time.Time) error
+
+	// DeleteAuthSessionKey removes the key for the identity pair.
+	DeleteAuthSessionKey(localIdentity, remoteIdentity identity.NumericIdentity) error
+}
+
 // CompilationLock is a interface over a mutex, it is used by both the loader, daemon
 // and endpoint manager to lock the compilation process. This is a bit of a layer violation
 // since certain methods on the loader such as CompileAndLoad and CompileOrLoad expect the
@@ -40,6 +40,7 @@ import (
 	"github.com/cilium/cilium/pkg/identity"
 	"github.com/cilium/cilium/pkg/identity/cache"
 	ipamOption "github.com/cilium/cilium/pkg/ipam/option"
+	cilium_v2 "github.com/cilium/cilium/pkg/k8s/apis/cilium.io/v2"
 	slim_corev1 "github.com/cilium/cilium/pkg/k8s/slim/k8s/api/core/v1"
 	"github.com/cilium/cilium/pkg/labels"
 	"github.com/cilium/cilium/pkg/labelsfilter"
@@ -420,6 +421,11 @@ type Endpoint struct {
 
 	// NetNsCookie is the network namespace cookie of the Endpoint.
 	NetNsCookie uint64
+
+	// authStatus is the last authentication status summary pushed by the
+	// auth subsystem for this endpoint's identity.
+	// mutable! must hold the endpoint lock to read
+	authStatus *cilium_v2.EndpointAuthStatus
 }
 
 func (e *Endpoint) GetRealizedRedirects() (redirects map[string]uint16) {
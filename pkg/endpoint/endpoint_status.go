@@ -72,7 +72,17 @@ func (e *Endpoint) GetCiliumEndpointStatus() *cilium_v2.EndpointStatus {
 		State:               compressEndpointState(e.getModelCurrentStateRLocked()),
 		Encryption:          cilium_v2.EncryptionSpec{Key: int(node.GetEndpointEncryptKeyIndex())},
 		NamedPorts:          e.getNamedPortsModel(),
+		Auth:                e.authStatus.DeepCopy(),
 	}
 
 	return status
 }
+
+// SetAuthStatus updates the authentication status summary of the endpoint.
+// It is pushed by the auth subsystem and synced to the CiliumEndpoint object
+// with the rest of the endpoint status.
+func (e *Endpoint) SetAuthStatus(status *cilium_v2.EndpointAuthStatus) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.authStatus = status
+}
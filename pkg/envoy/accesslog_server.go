@@ -29,14 +29,16 @@ type AccessLogServer struct {
 	socketPath         string
 	proxyGID           uint
 	localEndpointStore *LocalEndpointStore
+	authMetadata       accesslog.AuthMetadataProvider
 	stopCh             chan struct{}
 }
 
-func newAccessLogServer(envoySocketDir string, proxyGID uint, localEndpointStore *LocalEndpointStore) *AccessLogServer {
+func newAccessLogServer(envoySocketDir string, proxyGID uint, localEndpointStore *LocalEndpointStore, authMetadata accesslog.AuthMetadataProvider) *AccessLogServer {
 	return &AccessLogServer{
 		socketPath:         getAccessLogSocketPath(envoySocketDir),
 		proxyGID:           proxyGID,
 		localEndpointStore: localEndpointStore,
+		authMetadata:       authMetadata,
 	}
 }
 
@@ -151,7 +153,7 @@ func (s *AccessLogServer) handleConn(ctx context.Context, conn *net.UnixConn) {
 			return log, fmt.Sprintf("%s: Access log message: %s", pblog.PolicyName, pblog.String())
 		})
 
-		r := logRecord(ctx, &pblog)
+		r := logRecord(ctx, &pblog, s.proxyAuthMetadata(&pblog))
 
 		// Update proxy stats for the endpoint if it still exists
 		localEndpoint := s.localEndpointStore.getLocalEndpoint(pblog.PolicyName)
@@ -168,7 +170,26 @@ func (s *AccessLogServer) handleConn(ctx context.Context, conn *net.UnixConn) {
 	}
 }
 
-func logRecord(ctx context.Context, pblog *cilium.LogEntry) *logger.LogRecord {
+// proxyAuthMetadata looks up the network-layer authentication verdict for the
+// identity pair of the proxied connection, if the auth subsystem is enabled.
+// Source/destination of access log messages are always given in request
+// direction, so the local endpoint is the destination for ingress and the
+// source for egress.
+func (s *AccessLogServer) proxyAuthMetadata(pblog *cilium.LogEntry) *accesslog.AuthMetadata {
+	if s.authMetadata == nil {
+		return nil
+	}
+
+	local := identity.NumericIdentity(pblog.DestinationSecurityId)
+	peer := identity.NumericIdentity(pblog.SourceSecurityId)
+	if !pblog.IsIngress {
+		local, peer = peer, local
+	}
+
+	return s.authMetadata.ProxyAuthMetadata(local, peer)
+}
+
+func logRecord(ctx context.Context, pblog *cilium.LogEntry, authMetadata *accesslog.AuthMetadata) *logger.LogRecord {
 	var kafkaRecord *accesslog.LogRecordKafka
 	var kafkaTopics []string
 
@@ -226,6 +247,7 @@ func logRecord(ctx context.Context, pblog *cilium.LogEntry) *logger.LogRecord {
 		logger.LogTags.Timestamp(time.Unix(int64(pblog.Timestamp/1000000000), int64(pblog.Timestamp%1000000000))),
 		logger.LogTags.Verdict(GetVerdict(pblog), pblog.CiliumRuleRef),
 		logger.LogTags.Addressing(ctx, addrInfo),
+		logger.LogTags.Auth(authMetadata),
 		l7tags,
 	)
 	r.Log()
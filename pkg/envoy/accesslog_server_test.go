@@ -63,7 +63,7 @@ func TestKafkaLogNoTopic(t *testing.T) {
 				ApiVersion:    3,
 				ApiKey:        1,
 			}},
-		})
+		}, nil)
 
 		require.Len(t, notifier.kafka, 1)
 		require.Equal(t, `{"ErrorCode":42,"APIVersion":3,"APIKey":"fetch","CorrelationID":76541,"Topic":{}}`, notifier.kafka[0])
@@ -82,7 +82,7 @@ func TestKafkaLogSingleTopic(t *testing.T) {
 				ApiKey:        1,
 				Topics:        []string{"topic 1"},
 			}},
-		})
+		}, nil)
 
 		require.Len(t, notifier.kafka, 1)
 		require.Equal(t, `{"ErrorCode":42,"APIVersion":3,"APIKey":"fetch","CorrelationID":76541,"Topic":{"Topic":"topic 1"}}`, notifier.kafka[0])
@@ -103,7 +103,7 @@ func TestKafkaLogMultipleTopics(t *testing.T) {
 				ApiKey:        1,
 				Topics:        []string{"topic 1", "topic 2"},
 			}},
-		})
+		}, nil)
 
 		require.Len(t, notifier.kafka, 2)
 		require.Equal(t, `{"ErrorCode":42,"APIVersion":3,"APIKey":"fetch","CorrelationID":76541,"Topic":{"Topic":"topic 1"}}`, notifier.kafka[0])
@@ -23,6 +23,7 @@ import (
 	"github.com/cilium/cilium/pkg/k8s/utils"
 	"github.com/cilium/cilium/pkg/option"
 	"github.com/cilium/cilium/pkg/promise"
+	"github.com/cilium/cilium/pkg/proxy/accesslog"
 	"github.com/cilium/cilium/pkg/proxy/endpoint"
 	"github.com/cilium/cilium/pkg/shortener"
 	"github.com/cilium/cilium/pkg/time"
@@ -202,6 +203,11 @@ type accessLogServerParams struct {
 	Lifecycle          cell.Lifecycle
 	LocalEndpointStore *LocalEndpointStore
 	EnvoyProxyConfig   envoyProxyConfig
+
+	// AuthMetadata is provided by the auth subsystem and enriches the access
+	// logs with the network-layer auth verdict. It is nil if authentication
+	// processing is disabled.
+	AuthMetadata accesslog.AuthMetadataProvider `optional:"true"`
 }
 
 func newEnvoyAccessLogServer(params accessLogServerParams) *AccessLogServer {
@@ -210,7 +216,7 @@ func newEnvoyAccessLogServer(params accessLogServerParams) *AccessLogServer {
 		return nil
 	}
 
-	accessLogServer := newAccessLogServer(GetSocketDir(option.Config.RunDir), params.EnvoyProxyConfig.ProxyGID, params.LocalEndpointStore)
+	accessLogServer := newAccessLogServer(GetSocketDir(option.Config.RunDir), params.EnvoyProxyConfig.ProxyGID, params.LocalEndpointStore, params.AuthMetadata)
 
 	params.Lifecycle.Append(cell.Hook{
 		OnStart: func(_ cell.HookContext) error {
@@ -70,7 +70,7 @@ func TestEnvoy(t *testing.T) {
 	require.NoError(t, err)
 	defer xdsServer.stop()
 
-	accessLogServer := newAccessLogServer(testRunDir, 1337, localEndpointStore)
+	accessLogServer := newAccessLogServer(testRunDir, 1337, localEndpointStore, nil)
 	require.NotNil(t, accessLogServer)
 	err = accessLogServer.start()
 	require.NoError(t, err)
@@ -174,7 +174,7 @@ func TestEnvoyNACK(t *testing.T) {
 	require.NoError(t, err)
 	defer xdsServer.stop()
 
-	accessLogServer := newAccessLogServer(testRunDir, 1337, localEndpointStore)
+	accessLogServer := newAccessLogServer(testRunDir, 1337, localEndpointStore, nil)
 	require.NotNil(t, accessLogServer)
 	err = accessLogServer.start()
 	require.NoError(t, err)
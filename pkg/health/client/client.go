@@ -217,6 +217,9 @@ func formatPathStatus(w io.Writer, name string, cp *models.PathStatus, indent st
 	if cp.HTTP != nil {
 		formatConnectivityStatus(w, cp.HTTP, "HTTP to agent", indent)
 	}
+	if cp.Auth != nil {
+		formatConnectivityStatus(w, cp.Auth, "Auth handshake port", indent)
+	}
 }
 
 // allPathsAreHealthyOrUnknown checks whether ICMP and TCP(HTTP) connectivity
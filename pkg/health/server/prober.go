@@ -4,6 +4,7 @@
 package server
 
 import (
+	"crypto/tls"
 	"net"
 	"strconv"
 	"strings"
@@ -60,6 +61,7 @@ func (p *prober) copyResultRLocked(ip string) *models.PathStatus {
 	paths := map[**models.ConnectivityStatus]*models.ConnectivityStatus{
 		&result.Icmp: status.Icmp,
 		&result.HTTP: status.HTTP,
+		&result.Auth: status.Auth,
 	}
 	for res, value := range paths {
 		if value != nil {
@@ -297,6 +299,57 @@ func (p *prober) httpProbe(node string, ip string) *models.ConnectivityStatus {
 	return result
 }
 
+// authProbe checks that the mutual authentication handshake port of the
+// given node is reachable, and reports unreachable ports as warnings ahead
+// of the first authentication-required drop.
+func (p *prober) authProbe(node string, ip string) *models.ConnectivityStatus {
+	result := &models.ConnectivityStatus{}
+
+	addr := net.JoinHostPort(ip, strconv.Itoa(p.server.Config.MutualAuthPort))
+
+	scopedLog := log.WithFields(logrus.Fields{
+		logfields.NodeName: node,
+		logfields.IPAddr:   ip,
+		logfields.Port:     p.server.Config.MutualAuthPort,
+	})
+
+	start := time.Now()
+	if err := probeAuthPort(addr, p.server.Config.ProbeDeadline); err != nil {
+		scopedLog.WithError(err).Warning("Mutual authentication port is not reachable")
+		result.Status = err.Error()
+		return result
+	}
+
+	result.Latency = time.Since(start).Nanoseconds()
+	if logging.CanLogAt(log.Logger, logrus.DebugLevel) {
+		scopedLog.Debug("Mutual authentication port probe successful")
+	}
+	return result
+}
+
+// probeAuthPort connects to the given auth listener address and runs a TLS
+// handshake against it. Only a failure to connect is an error: the listener
+// refuses to serve its certificate without the SNI of one of its local
+// identities, so the handshake of the probe is expected to fail - a
+// completed TCP connect proves a listener is serving the port, whatever the
+// subsequent handshake outcome.
+func probeAuthPort(addr string, timeout time.Duration) error {
+	dialer := net.Dialer{Timeout: timeout}
+	rawConn, err := dialer.Dial("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer rawConn.Close()
+
+	//nolint:gosec // the probe checks reachability only and authenticates nothing
+	conn := tls.Client(rawConn, &tls.Config{InsecureSkipVerify: true})
+	conn.SetDeadline(time.Now().Add(timeout))
+	if err := conn.Handshake(); err == nil {
+		conn.Close()
+	}
+	return nil
+}
+
 func (p *prober) getIPsByNode() map[string][]*net.IPAddr {
 	p.RLock()
 	defer p.RUnlock()
@@ -351,10 +404,16 @@ func (p *prober) runHTTPProbe() {
 				scopedLog.Debugf("Failed to probe: %s", resp.Status)
 			}
 
+			var authResp *models.ConnectivityStatus
+			if p.server.Config.MutualAuthPort > 0 {
+				authResp = p.authProbe(name, ip.String())
+			}
+
 			peer := ipString(ip.String())
 			p.Lock()
 			if _, ok := p.results[peer]; ok {
 				p.results[peer].HTTP = resp
+				p.results[peer].Auth = authResp
 			} else {
 				// While we weren't holding the lock, the
 				// pinger's OnIdle() callback fired and updated
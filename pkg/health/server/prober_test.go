@@ -5,10 +5,13 @@ package server
 
 import (
 	"bytes"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -227,3 +230,32 @@ func TestProbersetNodes(t *testing.T) {
 	}
 	require.Equal(t, sortNodes(expected4), sortNodes(nodes4))
 }
+
+func TestProbeAuthPort(t *testing.T) {
+	// A listener which, like the mutual auth listener, refuses to serve a
+	// certificate for the SNI of the probe. The handshake fails after the
+	// connection is established, which must still count as reachable.
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		GetCertificate: func(info *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return nil, errors.New("unknown SNI")
+		},
+	})
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	require.NoError(t, probeAuthPort(listener.Addr().String(), time.Second))
+
+	// An unreachable port is an error.
+	listener.Close()
+	require.Error(t, probeAuthPort(listener.Addr().String(), time.Second))
+}
@@ -38,7 +38,10 @@ type Config struct {
 	ProbeInterval time.Duration
 	ProbeDeadline time.Duration
 	HTTPPathPort  int
-	HealthAPISpec *healthApi.Spec
+	// MutualAuthPort is the port of the mutual authentication handshake
+	// listener on the remote nodes. A value of 0 disables the probe.
+	MutualAuthPort int
+	HealthAPISpec  *healthApi.Spec
 }
 
 // ipString is an IP address used as a more descriptive type name in maps.
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package client
+
+import (
+	"encoding/json"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	ciliumv2 "github.com/cilium/cilium/pkg/k8s/apis/cilium.io/v2"
+)
+
+// The apiserver prunes every field not declared in the structural schema of
+// a CRD, so a stale pregenerated CRD silently discards what the agent
+// writes. These tests marshal populated auth status structs and assert all
+// their JSON fields are declared in the pregenerated CRDs, failing when the
+// Go types gain fields without the CRD YAMLs being regenerated.
+
+// schemaOfPath descends the given property path of the CRD's structural
+// schema.
+func schemaOfPath(t *testing.T, crdName string, path ...string) *apiextensionsv1.JSONSchemaProps {
+	t.Helper()
+
+	crd := GetPregeneratedCRD(crdName)
+	schema := crd.Spec.Versions[0].Schema.OpenAPIV3Schema
+	for _, field := range path {
+		next, ok := schema.Properties[field]
+		if !ok {
+			t.Fatalf("pregenerated CRD %s declares no %q property at path %v, regenerate the CRDs", crdName, field, path)
+		}
+		schema = &next
+	}
+	return schema
+}
+
+// assertSchemaCovers marshals the given struct and asserts every JSON field
+// is declared in the schema.
+func assertSchemaCovers(t *testing.T, schema *apiextensionsv1.JSONSchemaProps, v any) {
+	t.Helper()
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal %T: %v", v, err)
+	}
+	fields := map[string]any{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		t.Fatalf("failed to unmarshal %T: %v", v, err)
+	}
+
+	for field := range fields {
+		if _, ok := schema.Properties[field]; !ok {
+			t.Errorf("field %q of %T is missing from the pregenerated CRD, regenerate the CRDs", field, v)
+		}
+	}
+}
+
+func Test_pregeneratedCiliumEndpointCRDCoversAuthStatus(t *testing.T) {
+	schema := schemaOfPath(t, CEPCRDName, "status", "auth")
+
+	assertSchemaCovers(t, schema, ciliumv2.EndpointAuthStatus{
+		AuthenticatedPeers:     1,
+		PendingAuthentications: 1,
+		LastFailureMsg:         "failure",
+		LastFailureTimestamp:   "timestamp",
+	})
+}
+
+func Test_pregeneratedCiliumNodeCRDCoversAuthStatus(t *testing.T) {
+	peers := schemaOfPath(t, CNCRDName, "status", "auth", "peers")
+	if peers.Items == nil || peers.Items.Schema == nil {
+		t.Fatal("pregenerated CiliumNode CRD declares no schema for the auth peer list, regenerate the CRDs")
+	}
+
+	assertSchemaCovers(t, peers.Items.Schema, ciliumv2.AuthPeerStatus{
+		NodeIP:               "192.0.2.1",
+		Healthy:              true,
+		LastSuccessTimestamp: "timestamp",
+		FailureStreak:        1,
+	})
+}
@@ -82,6 +82,33 @@ type EndpointStatus struct {
 	State string `json:"state,omitempty"`
 
 	NamedPorts models.NamedPorts `json:"named-ports,omitempty"`
+
+	// Auth summarizes the mutual authentication state of the endpoint's
+	// identity.
+	//
+	// +kubebuilder:validation:Optional
+	Auth *EndpointAuthStatus `json:"auth,omitempty"`
+}
+
+// EndpointAuthStatus summarizes the mutual authentication state of the
+// identity of an endpoint, so authentication problems of a workload can be
+// debugged through the CiliumEndpoint object instead of agent logs.
+type EndpointAuthStatus struct {
+	// AuthenticatedPeers is the number of peer identities this endpoint's
+	// identity currently holds a valid authentication with.
+	AuthenticatedPeers int `json:"authenticated-peers"`
+
+	// PendingAuthentications is the number of in-flight authentications for
+	// this endpoint's identity.
+	PendingAuthentications int `json:"pending-authentications,omitempty"`
+
+	// LastFailureMsg is the error of the most recent failed authentication
+	// involving this endpoint's identity.
+	LastFailureMsg string `json:"last-failure-msg,omitempty"`
+
+	// LastFailureTimestamp is the time of the most recent failed
+	// authentication involving this endpoint's identity.
+	LastFailureTimestamp string `json:"last-failure-timestamp,omitempty"`
 }
 
 // +k8s:deepcopy-gen=false
@@ -438,6 +465,44 @@ type NodeStatus struct {
 	//
 	// +kubebuilder:validation:Optional
 	AlibabaCloud alibabaCloudTypes.ENIStatus `json:"alibaba-cloud,omitempty"`
+
+	// Auth is the mutual authentication health of this node towards its
+	// peer nodes.
+	//
+	// +kubebuilder:validation:Optional
+	Auth AuthNodeStatus `json:"auth,omitempty"`
+}
+
+// AuthNodeStatus is the mutual authentication status of a node towards its
+// peer nodes.
+type AuthNodeStatus struct {
+	// Peers is the authentication health per peer node.
+	//
+	// +kubebuilder:validation:Optional
+	Peers []AuthPeerStatus `json:"peers,omitempty"`
+}
+
+// AuthPeerStatus describes the mutual authentication health between the
+// local node and one of its peer nodes.
+type AuthPeerStatus struct {
+	// NodeIP is the IP address of the peer node.
+	NodeIP string `json:"node-ip"`
+
+	// Healthy is true if all cached authentications with the peer node are
+	// still valid.
+	Healthy bool `json:"healthy"`
+
+	// LastSuccessTimestamp is the timestamp of the most recent successful
+	// authentication with the peer node, in RFC3339 format.
+	//
+	// +kubebuilder:validation:Optional
+	LastSuccessTimestamp string `json:"last-success-timestamp,omitempty"`
+
+	// FailureStreak is the number of consecutive failed authentications
+	// with the peer node since the last success.
+	//
+	// +kubebuilder:validation:Optional
+	FailureStreak int `json:"failure-streak,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -16,6 +16,43 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthNodeStatus) DeepCopyInto(out *AuthNodeStatus) {
+	*out = *in
+	if in.Peers != nil {
+		in, out := &in.Peers, &out.Peers
+		*out = make([]AuthPeerStatus, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthNodeStatus.
+func (in *AuthNodeStatus) DeepCopy() *AuthNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthPeerStatus) DeepCopyInto(out *AuthPeerStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthPeerStatus.
+func (in *AuthPeerStatus) DeepCopy() *AuthPeerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthPeerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CiliumClusterwideEnvoyConfig) DeepCopyInto(out *CiliumClusterwideEnvoyConfig) {
 	*out = *in
@@ -1038,6 +1075,22 @@ func (in *EncryptionSpec) DeepCopy() *EncryptionSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointAuthStatus) DeepCopyInto(out *EndpointAuthStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointAuthStatus.
+func (in *EndpointAuthStatus) DeepCopy() *EndpointAuthStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointAuthStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EndpointIdentity) DeepCopyInto(out *EndpointIdentity) {
 	*out = *in
@@ -1214,6 +1267,11 @@ func (in *EndpointStatus) DeepCopyInto(out *EndpointStatus) {
 			}
 		}
 	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(EndpointAuthStatus)
+		**out = **in
+	}
 	return
 }
 
@@ -1355,6 +1413,7 @@ func (in *NodeStatus) DeepCopyInto(out *NodeStatus) {
 	in.Azure.DeepCopyInto(&out.Azure)
 	in.IPAM.DeepCopyInto(&out.IPAM)
 	in.AlibabaCloud.DeepCopyInto(&out.AlibabaCloud)
+	in.Auth.DeepCopyInto(&out.Auth)
 	return
 }
 
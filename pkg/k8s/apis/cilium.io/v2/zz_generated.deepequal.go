@@ -65,6 +65,56 @@ func (in *AllowedIdentityList) DeepEqual(other *AllowedIdentityList) bool {
 	return true
 }
 
+// DeepEqual is an autogenerated deepequal function, deeply comparing the
+// receiver with other. in must be non-nil.
+func (in *AuthNodeStatus) DeepEqual(other *AuthNodeStatus) bool {
+	if other == nil {
+		return false
+	}
+
+	if ((in.Peers != nil) && (other.Peers != nil)) || ((in.Peers == nil) != (other.Peers == nil)) {
+		in, other := &in.Peers, &other.Peers
+		if other == nil {
+			return false
+		}
+
+		if len(*in) != len(*other) {
+			return false
+		} else {
+			for i, inElement := range *in {
+				if !inElement.DeepEqual(&(*other)[i]) {
+					return false
+				}
+			}
+		}
+	}
+
+	return true
+}
+
+// DeepEqual is an autogenerated deepequal function, deeply comparing the
+// receiver with other. in must be non-nil.
+func (in *AuthPeerStatus) DeepEqual(other *AuthPeerStatus) bool {
+	if other == nil {
+		return false
+	}
+
+	if in.NodeIP != other.NodeIP {
+		return false
+	}
+	if in.Healthy != other.Healthy {
+		return false
+	}
+	if in.LastSuccessTimestamp != other.LastSuccessTimestamp {
+		return false
+	}
+	if in.FailureStreak != other.FailureStreak {
+		return false
+	}
+
+	return true
+}
+
 // DeepEqual is an autogenerated deepequal function, deeply comparing the
 // receiver with other. in must be non-nil.
 func (in *CiliumClusterwideEnvoyConfig) DeepEqual(other *CiliumClusterwideEnvoyConfig) bool {
@@ -721,6 +771,29 @@ func (in *EncryptionSpec) DeepEqual(other *EncryptionSpec) bool {
 	return true
 }
 
+// DeepEqual is an autogenerated deepequal function, deeply comparing the
+// receiver with other. in must be non-nil.
+func (in *EndpointAuthStatus) DeepEqual(other *EndpointAuthStatus) bool {
+	if other == nil {
+		return false
+	}
+
+	if in.AuthenticatedPeers != other.AuthenticatedPeers {
+		return false
+	}
+	if in.PendingAuthentications != other.PendingAuthentications {
+		return false
+	}
+	if in.LastFailureMsg != other.LastFailureMsg {
+		return false
+	}
+	if in.LastFailureTimestamp != other.LastFailureTimestamp {
+		return false
+	}
+
+	return true
+}
+
 // DeepEqual is an autogenerated deepequal function, deeply comparing the
 // receiver with other. in must be non-nil.
 func (in *EndpointIdentity) DeepEqual(other *EndpointIdentity) bool {
@@ -931,6 +1004,14 @@ func (in *EndpointStatus) DeepEqual(other *EndpointStatus) bool {
 		}
 	}
 
+	if (in.Auth == nil) != (other.Auth == nil) {
+		return false
+	} else if in.Auth != nil {
+		if !in.Auth.DeepEqual(other.Auth) {
+			return false
+		}
+	}
+
 	return true
 }
 
@@ -1174,6 +1255,10 @@ func (in *NodeStatus) DeepEqual(other *NodeStatus) bool {
 		return false
 	}
 
+	if !in.Auth.DeepEqual(&other.Auth) {
+		return false
+	}
+
 	return true
 }
 
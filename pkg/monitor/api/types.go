@@ -231,6 +231,8 @@ const (
 	AgentNotifyIPCacheDeleted
 	AgentNotifyServiceUpserted
 	AgentNotifyServiceDeleted
+	AgentNotifyAuthSuccess
+	AgentNotifyAuthFail
 )
 
 // AgentNotifications is a map of all supported agent notification types.
@@ -248,6 +250,8 @@ var AgentNotifications = map[AgentNotification]string{
 	AgentNotifyPolicyDeleted:             "Policy deleted",
 	AgentNotifyServiceDeleted:            "Service deleted",
 	AgentNotifyServiceUpserted:           "Service upserted",
+	AgentNotifyAuthSuccess:               "Authentication succeeded",
+	AgentNotifyAuthFail:                  "Authentication failed",
 }
 
 func resolveAgentType(t AgentNotification) string {
@@ -510,6 +514,37 @@ func ServiceDeleteMessage(id uint32) AgentNotifyMessage {
 	}
 }
 
+// AuthNotification structures authentication success or failure notifications
+type AuthNotification struct {
+	LocalIdentity  uint32 `json:"local-identity"`
+	RemoteIdentity uint32 `json:"remote-identity"`
+	RemoteNodeIP   string `json:"remote-node-ip,omitempty"`
+	AuthType       string `json:"auth-type"`
+	Error          string `json:"error,omitempty"`
+}
+
+// AuthMessage constructs an agent notification message for the outcome of an
+// authentication attempt between two identities
+func AuthMessage(localIdentity, remoteIdentity uint32, remoteNodeIP, authType string, err error) AgentNotifyMessage {
+	notification := AuthNotification{
+		LocalIdentity:  localIdentity,
+		RemoteIdentity: remoteIdentity,
+		RemoteNodeIP:   remoteNodeIP,
+		AuthType:       authType,
+	}
+
+	typ := AgentNotifyAuthSuccess
+	if err != nil {
+		notification.Error = err.Error()
+		typ = AgentNotifyAuthFail
+	}
+
+	return AgentNotifyMessage{
+		Type:         typ,
+		Notification: notification,
+	}
+}
+
 const (
 	// PolicyIngress is the value of Flags&PolicyNotifyFlagDirection for ingress traffic
 	PolicyIngress = 1
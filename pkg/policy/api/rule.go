@@ -14,9 +14,10 @@ import (
 type AuthenticationMode string
 
 const (
-	AuthenticationModeDisabled   AuthenticationMode = "disabled" // Always succeeds
-	AuthenticationModeRequired   AuthenticationMode = "required" // Mutual TLS with SPIFFE as certificate provider by default
-	AuthenticationModeAlwaysFail AuthenticationMode = "test-always-fail"
+	AuthenticationModeDisabled    AuthenticationMode = "disabled"     // Always succeeds
+	AuthenticationModeRequired    AuthenticationMode = "required"     // Mutual TLS with SPIFFE as certificate provider by default
+	AuthenticationModeRequiredJWT AuthenticationMode = "required-jwt" // JWT-SVID exchange over HTTP instead of a mutual TLS handshake
+	AuthenticationModeAlwaysFail  AuthenticationMode = "test-always-fail"
 )
 
 // Authentication specifies the kind of cryptographic authentication required for the traffic to
@@ -24,7 +25,7 @@ const (
 type Authentication struct {
 	// Mode is the required authentication mode for the allowed traffic, if any.
 	//
-	// +kubebuilder:validation:Enum=disabled;required;test-always-fail
+	// +kubebuilder:validation:Enum=disabled;required;required-jwt;test-always-fail
 	// +kubebuilder:validation:Required
 	Mode AuthenticationMode `json:"mode"`
 }
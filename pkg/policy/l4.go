@@ -243,6 +243,9 @@ const (
 	AuthTypeSpire
 	// AuthTypeAlwaysFail is a simple auth type that always denies the request
 	AuthTypeAlwaysFail
+	// AuthTypeJWT is an auth type that exchanges SPIFFE JWT-SVIDs over HTTP
+	// instead of performing a mutual TLS handshake
+	AuthTypeJWT
 )
 
 type HasAuthType bool
@@ -271,6 +274,8 @@ func GetAuthType(auth *api.Authentication) (HasAuthType, AuthType) {
 		return ExplicitAuthType, AuthTypeDisabled
 	case api.AuthenticationModeRequired:
 		return ExplicitAuthType, AuthTypeSpire
+	case api.AuthenticationModeRequiredJWT:
+		return ExplicitAuthType, AuthTypeJWT
 	case api.AuthenticationModeAlwaysFail:
 		return ExplicitAuthType, AuthTypeAlwaysFail
 	default:
@@ -293,6 +298,8 @@ func (a AuthType) String() string {
 		return "spire"
 	case AuthTypeAlwaysFail:
 		return "test-always-fail"
+	case AuthTypeJWT:
+		return "jwt"
 	}
 	return "Unknown-auth-type-" + strconv.FormatUint(uint64(a.Uint8()), 10)
 }
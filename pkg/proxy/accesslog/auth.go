@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package accesslog
+
+import (
+	"github.com/cilium/cilium/pkg/identity"
+)
+
+// AuthVerdictAuthenticated is the auth verdict of a connection covered by a
+// valid network-layer mutual authentication between the two identities.
+const AuthVerdictAuthenticated = "authenticated"
+
+// AuthMetadata reflects the network-layer mutual authentication state of the
+// connection a proxied request traveled on.
+type AuthMetadata struct {
+	// Verdict is the network-layer authentication verdict for the identity
+	// pair of the connection.
+	Verdict string
+
+	// PeerSpiffeID is the SPIFFE ID of the authenticated peer identity, if
+	// known.
+	PeerSpiffeID string `json:"PeerSpiffeID,omitempty"`
+}
+
+// AuthMetadataProvider supplies the network-layer authentication metadata of
+// an identity pair, so the L7 proxy integration can unify the L3/4 auth
+// verdict with L7 access logs. It is implemented by the auth subsystem.
+type AuthMetadataProvider interface {
+	// ProxyAuthMetadata returns the authentication metadata for the
+	// connection between the given local and peer identity, or nil if no
+	// authentication took place between the two.
+	ProxyAuthMetadata(local, peer identity.NumericIdentity) *AuthMetadata
+}
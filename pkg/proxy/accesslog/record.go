@@ -178,6 +178,11 @@ type LogRecord struct {
 	// the Verdict field is set to VerdictDenied. Otherwise it's set to nil.
 	DropReason *DropReason
 
+	// Auth reflects the network-layer mutual authentication state of the
+	// connection the request traveled on. It is set to nil if no
+	// authentication took place.
+	Auth *AuthMetadata `json:"Auth,omitempty"`
+
 	// The following are the protocol specific parts. Only one of the
 	// following should ever be set. Unused fields will be omitted
 
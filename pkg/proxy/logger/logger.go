@@ -202,6 +202,13 @@ func (logTags) L7(h *accesslog.LogRecordL7) LogTag {
 	}
 }
 
+// Auth attaches network-layer authentication information to the log record
+func (logTags) Auth(a *accesslog.AuthMetadata) LogTag {
+	return func(lr *LogRecord) {
+		lr.Auth = a
+	}
+}
+
 // ApplyTags applies tags to an existing log record
 //
 // Example:
@@ -565,6 +565,54 @@ func (a *Agent) DeletePeer(nodeName string) error {
 	return nil
 }
 
+// SetPeerAuthStatus installs or removes the peer associated with the given
+// node IP from the WireGuard device, depending on whether mutual
+// authentication with that node is currently healthy. A revoked peer keeps
+// its configuration and queued AllowedIPs updates, so that it can be
+// re-installed without loss once authentication recovers. Unknown node IPs
+// are ignored.
+func (a *Agent) SetPeerAuthStatus(nodeIP net.IP, healthy bool) error {
+	if nodeIP == nil {
+		return nil
+	}
+
+	a.Lock()
+	defer a.Unlock()
+
+	nodeName, ok := a.nodeNameByNodeIP[nodeIP.String()]
+	if !ok {
+		return nil
+	}
+	peer := a.peerByNodeName[nodeName]
+	if peer == nil || peer.authRevoked == !healthy {
+		return nil
+	}
+
+	if !healthy {
+		log.WithFields(logrus.Fields{
+			logfields.NodeName: nodeName,
+			logfields.PubKey:   peer.pubKey,
+		}).Info("Removing WireGuard peer due to unhealthy mutual authentication")
+		if err := a.deletePeerByPubKey(peer.pubKey); err != nil {
+			return err
+		}
+		peer.authRevoked = true
+		return nil
+	}
+
+	log.WithFields(logrus.Fields{
+		logfields.NodeName: nodeName,
+		logfields.PubKey:   peer.pubKey,
+	}).Info("Re-installing WireGuard peer after mutual authentication recovered")
+	peer.authRevoked = false
+	// Re-queue all AllowedIPs which were synced to the device before the
+	// peer was removed.
+	for _, ip := range peer.allowedIPs {
+		peer.queueAllowedIPsInsert(ip)
+	}
+	return a.updatePeerByConfig(peer)
+}
+
 func (a *Agent) deletePeerByPubKey(pubKey wgtypes.Key) error {
 	log.WithField(logfields.PubKey, pubKey).Debug("Removing peer")
 
@@ -589,6 +637,13 @@ func (a *Agent) deletePeerByPubKey(pubKey wgtypes.Key) error {
 
 // updatePeerByConfig updates the WireGuard kernel peer config based on peerConfig p
 func (a *Agent) updatePeerByConfig(p *peerConfig) error {
+	if p.authRevoked {
+		// The peer is currently removed from the device due to unhealthy
+		// mutual authentication. Queued AllowedIPs updates are applied once
+		// the peer is re-installed.
+		return nil
+	}
+
 	addedIPs, removedIPs := p.queuedAllowedIPUpdates()
 	peer := wgtypes.PeerConfig{
 		PublicKey:  p.pubKey,
@@ -822,6 +877,10 @@ type peerConfig struct {
 	allowedIPs         map[netip.Prefix]net.IPNet
 	needsInsert        map[netip.Prefix]net.IPNet
 	needsRemove        map[netip.Prefix]net.IPNet
+
+	// authRevoked is true while the peer is removed from the device because
+	// mutual authentication with its node is unhealthy.
+	authRevoked bool
 }
 
 func (p *peerConfig) lazyInitMaps() {
@@ -342,6 +342,46 @@ func TestAgent_PeerConfig_WithEncryptNode(t *testing.T) {
 	require.Equal(t, true, containsIP(maps.Values(k8s1.allowedIPs), iputil.IPToPrefix(k8s1NodeIPv6)))
 }
 
+func TestAgent_PeerAuthGating(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	wgClient := newFakeWgClient()
+	wgAgent, ipCache := newTestAgent(ctx, wgClient)
+	defer ipCache.Shutdown()
+
+	ipCache.Upsert(pod1IPv4Str, k8s1NodeIPv4, 0, nil, ipcache.Identity{ID: 1, Source: source.Kubernetes})
+
+	err := wgAgent.UpdatePeer(k8s1NodeName, k8s1PubKey, k8s1NodeIPv4, k8s1NodeIPv6)
+	require.NoError(t, err)
+
+	key1, err := wgtypes.ParseKey(k8s1PubKey)
+	require.NoError(t, err)
+	require.Contains(t, wgClient.peers, key1)
+	numAllowedIPs := len(wgClient.peers[key1].AllowedIPs)
+
+	// Unhealthy auth removes the peer from the device, but keeps its config
+	require.NoError(t, wgAgent.SetPeerAuthStatus(k8s1NodeIPv4, false))
+	require.NotContains(t, wgClient.peers, key1)
+	require.Contains(t, wgAgent.peerByNodeName, k8s1NodeName)
+
+	// IPCache updates while revoked are queued, not applied to the device
+	ipCache.Upsert(pod2IPv4Str, k8s1NodeIPv4, 0, nil, ipcache.Identity{ID: 2, Source: source.Kubernetes})
+	require.NotContains(t, wgClient.peers, key1)
+
+	// Repeated reports of the same state are no-ops
+	require.NoError(t, wgAgent.SetPeerAuthStatus(k8s1NodeIPv4, false))
+	require.NotContains(t, wgClient.peers, key1)
+
+	// Recovery re-installs the peer with all AllowedIPs, including queued ones
+	require.NoError(t, wgAgent.SetPeerAuthStatus(k8s1NodeIPv4, true))
+	require.Contains(t, wgClient.peers, key1)
+	require.Len(t, wgClient.peers[key1].AllowedIPs, numAllowedIPs+1)
+	require.True(t, containsIP(slices.Values(wgClient.peers[key1].AllowedIPs), pod2IPv4))
+
+	// Unknown node IPs are ignored
+	require.NoError(t, wgAgent.SetPeerAuthStatus(k8s2NodeIPv4, false))
+}
+
 func TestAgent_AllowedIPsRestoration(t *testing.T) {
 	ctx := context.Background()
 